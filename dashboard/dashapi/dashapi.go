@@ -315,6 +315,8 @@ type CrashFlags int64
 
 const (
 	CrashUnderStrace CrashFlags = 1 << iota
+	// The reproducer crashes the kernel less often than the manager's reliability threshold.
+	CrashUnreliableRepro
 )
 
 // Crash describes a single kernel crash (potentially with repro).
@@ -259,6 +259,8 @@ const (
 	OperatorCompareNeq
 	OperatorBinaryAnd
 	OperatorOr
+	OperatorAdd
+	OperatorSub
 )
 
 type BinaryExpression struct {
@@ -273,11 +273,17 @@ func fmtExpressionRec(sb *strings.Builder, t *Type, parentPrio int) {
 		sb.WriteString("&")
 	case OperatorOr:
 		sb.WriteString("||")
+	case OperatorAdd:
+		sb.WriteString("+")
+	case OperatorSub:
+		sb.WriteString("-")
 	default:
 		panic(fmt.Sprintf("unknown operator %q", be.Operator))
 	}
 	sb.WriteByte(' ')
-	fmtExpressionRec(sb, be.Right, myPrio)
+	// Operators are left-associative, so the right operand with the same priority
+	// needs parentheses (a - (b - c)).
+	fmtExpressionRec(sb, be.Right, myPrio+1)
 	if parentheses {
 		sb.WriteByte(')')
 	}
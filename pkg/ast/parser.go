@@ -438,7 +438,7 @@ type operatorInfo struct {
 	prio int
 }
 
-const maxOperatorPrio = 2
+const maxOperatorPrio = 3
 
 // The highest priority is 0.
 var binaryOperators = map[token]operatorInfo{
@@ -446,10 +446,12 @@ var binaryOperators = map[token]operatorInfo{
 	tokCmpEq:  {op: OperatorCompareEq, prio: 1},
 	tokCmpNeq: {op: OperatorCompareNeq, prio: 1},
 	tokBinAnd: {op: OperatorBinaryAnd, prio: 2},
+	tokAdd:    {op: OperatorAdd, prio: 3},
+	tokSub:    {op: OperatorSub, prio: 3},
 }
 
 // Parse out a single Type object, which can either be a plain object or an expression.
-// For now, only expressions constructed via '(', ')', "==", "!=", '&', '||' are supported.
+// For now, only expressions constructed via '(', ')', "==", "!=", '&', '||', '+', '-' are supported.
 func (p *parser) parseType() *Type {
 	return p.parseBinaryExpr(0)
 }
@@ -40,6 +40,8 @@ const (
 	tokCmpEq
 	tokCmpNeq
 	tokOr
+	tokAdd
+	tokSub
 
 	tokEOF
 )
@@ -56,6 +58,7 @@ var punctuation = [256]token{
 	',':  tokComma,
 	':':  tokColon,
 	'&':  tokBinAnd,
+	'+':  tokAdd,
 }
 
 var tok2str = [...]string{
@@ -75,6 +78,8 @@ var tok2str = [...]string{
 	tokCmpEq:     "==",
 	tokCmpNeq:    "!=",
 	tokOr:        "||",
+	tokAdd:       "+",
+	tokSub:       "-",
 }
 
 func init() {
@@ -182,6 +187,10 @@ func (s *scanner) Scan() (tok token, lit string, pos Pos) {
 	case s.ch == '`':
 		tok = tokStringHex
 		lit = s.scanStr(pos)
+	case s.ch == '-' && !s.nextIsDigit():
+		// Minus is an operator only if it's not a part of a negative number (e.g. "value[a] - 1").
+		tok = tokSub
+		s.next()
 	case s.ch >= '0' && s.ch <= '9' || s.ch == '-':
 		tok = tokInt
 		lit = s.scanInt(pos)
@@ -208,6 +217,10 @@ func (s *scanner) Scan() (tok token, lit string, pos Pos) {
 	return
 }
 
+func (s *scanner) nextIsDigit() bool {
+	return s.off+1 < len(s.data) && s.data[s.off+1] >= '0' && s.data[s.off+1] <= '9'
+}
+
 func (s *scanner) scanStr(pos Pos) string {
 	// TODO(dvyukov): get rid of <...> strings, that's only includes
 	closing := s.ch
@@ -0,0 +1,484 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package btf parses BPF Type Format (BTF) type information.
+// The format is described in https://docs.kernel.org/bpf/btf.html.
+// Kernels built with CONFIG_DEBUG_INFO_BTF contain it in the .BTF section of vmlinux
+// and expose it in /sys/kernel/btf/vmlinux (and in /sys/kernel/btf/MODULE for modules,
+// these are split BTF that refers to the vmlinux types).
+package btf
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+)
+
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindInt
+	KindPtr
+	KindArray
+	KindStruct
+	KindUnion
+	KindEnum
+	KindFwd
+	KindTypedef
+	KindVolatile
+	KindConst
+	KindRestrict
+	KindFunc
+	KindFuncProto
+	KindVar
+	KindDatasec
+	KindFloat
+	KindDeclTag
+	KindTypeTag
+	KindEnum64
+)
+
+var kindNames = [...]string{
+	KindUnknown:   "void",
+	KindInt:       "int",
+	KindPtr:       "ptr",
+	KindArray:     "array",
+	KindStruct:    "struct",
+	KindUnion:     "union",
+	KindEnum:      "enum",
+	KindFwd:       "fwd",
+	KindTypedef:   "typedef",
+	KindVolatile:  "volatile",
+	KindConst:     "const",
+	KindRestrict:  "restrict",
+	KindFunc:      "func",
+	KindFuncProto: "func_proto",
+	KindVar:       "var",
+	KindDatasec:   "datasec",
+	KindFloat:     "float",
+	KindDeclTag:   "decl_tag",
+	KindTypeTag:   "type_tag",
+	KindEnum64:    "enum64",
+}
+
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("kind%d", int(k))
+}
+
+// Int encoding bits.
+const (
+	IntSigned = 1 << 0
+	IntChar   = 1 << 1
+	IntBool   = 1 << 2
+)
+
+// Type is a single BTF type. Which fields are set depends on the Kind.
+type Type struct {
+	ID   int
+	Kind Kind
+	Name string
+	// Size in bytes for ints, floats, enums, structs, unions and datasecs.
+	Size int
+	// Referenced type for pointers, typedefs, type qualifiers, funcs, vars and tags;
+	// element type for arrays; return type for function prototypes.
+	// Nil means void.
+	Ref *Type
+	// Encoding (IntSigned/IntChar/IntBool) and number of bits for ints.
+	Encoding int
+	Bits     int
+	// Number of elements for arrays.
+	Len int
+	// Members of structs and unions, parameters of function prototypes.
+	Members []Member
+	// Values of enums.
+	Values []EnumValue
+	// Set for signed enums and for forward declarations of unions.
+	KindFlag bool
+}
+
+type Member struct {
+	Name      string
+	Type      *Type
+	BitOffset int
+	// Non-zero for bitfields.
+	BitSize int
+}
+
+type EnumValue struct {
+	Name  string
+	Value int64
+}
+
+func (t *Type) String() string {
+	if t == nil {
+		return "void"
+	}
+	if t.Name == "" {
+		return fmt.Sprintf("%v #%v", t.Kind, t.ID)
+	}
+	return fmt.Sprintf("%v %v", t.Kind, t.Name)
+}
+
+// Skip returns the type with typedefs and type qualifiers skipped.
+func (t *Type) Skip() *Type {
+	for t != nil {
+		switch t.Kind {
+		case KindTypedef, KindVolatile, KindConst, KindRestrict, KindTypeTag:
+			t = t.Ref
+		default:
+			return t
+		}
+	}
+	return nil
+}
+
+// SkipQualifiers returns the type with type qualifiers (but not typedefs) skipped.
+func (t *Type) SkipQualifiers() *Type {
+	for t != nil {
+		switch t.Kind {
+		case KindVolatile, KindConst, KindRestrict, KindTypeTag:
+			t = t.Ref
+		default:
+			return t
+		}
+	}
+	return nil
+}
+
+// IsConst returns whether the type has the const qualifier (possibly among other qualifiers).
+func (t *Type) IsConst() bool {
+	for ; t != nil; t = t.Ref {
+		switch t.Kind {
+		case KindConst:
+			return true
+		case KindVolatile, KindRestrict, KindTypeTag:
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// ByteSize returns size of the type in bytes, ptrSize is used for pointers.
+func (t *Type) ByteSize(ptrSize int) int {
+	t = t.Skip()
+	if t == nil {
+		return 0
+	}
+	switch t.Kind {
+	case KindPtr:
+		return ptrSize
+	case KindArray:
+		return t.Len * t.Ref.ByteSize(ptrSize)
+	}
+	return t.Size
+}
+
+// Align returns the natural alignment of the type (BTF does not contain explicit alignment attributes).
+func (t *Type) Align(ptrSize int) int {
+	t = t.Skip()
+	if t == nil {
+		return 1
+	}
+	switch t.Kind {
+	case KindPtr:
+		return ptrSize
+	case KindArray:
+		return t.Ref.Align(ptrSize)
+	case KindStruct, KindUnion:
+		align := 1
+		for _, m := range t.Members {
+			align = max(align, m.Type.Align(ptrSize))
+		}
+		return align
+	case KindInt, KindEnum, KindEnum64, KindFloat:
+		// Note: on some 32-bit arches 8-byte ints are 4-byte aligned, which we don't know here.
+		return min(max(t.Size, 1), 16)
+	}
+	return 1
+}
+
+// Spec is a parsed BTF blob.
+type Spec struct {
+	// All types indexed by ID, Types[0] is nil (void).
+	Types   []*Type
+	byName  map[string][]*Type
+	order   binary.ByteOrder
+	base    *Spec
+	strings []byte
+	// Offset of the first string of this spec (non-zero for split BTF).
+	strBase int
+}
+
+const (
+	magic      = 0xeb9f
+	headerSize = 24
+)
+
+// Load loads BTF from an ELF file (.BTF section) or from a raw BTF file (e.g. /sys/kernel/btf/vmlinux).
+func Load(file string) (*Spec, error) {
+	return LoadSplit(file, nil)
+}
+
+// LoadSplit is like Load, but loads split BTF (e.g. /sys/kernel/btf/MODULE) on top of the base BTF.
+func LoadSplit(file string, base *Spec) (*Spec, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte(elf.ELFMAG)) {
+		ef, err := elf.NewFile(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %v: %w", file, err)
+		}
+		sec := ef.Section(".BTF")
+		if sec == nil {
+			return nil, fmt.Errorf("%v does not have .BTF section (CONFIG_DEBUG_INFO_BTF is not enabled?)", file)
+		}
+		if data, err = sec.Data(); err != nil {
+			return nil, fmt.Errorf("failed to read .BTF section of %v: %w", file, err)
+		}
+	}
+	spec, err := ParseSplit(data, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse BTF in %v: %w", file, err)
+	}
+	return spec, nil
+}
+
+// Parse parses a raw BTF blob.
+func Parse(data []byte) (*Spec, error) {
+	return ParseSplit(data, nil)
+}
+
+// ParseSplit parses a raw split BTF blob, types and strings of which continue the base BTF.
+func ParseSplit(data []byte, base *Spec) (*Spec, error) {
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("BTF is too short: %v bytes", len(data))
+	}
+	spec := &Spec{
+		Types:  []*Type{nil},
+		byName: make(map[string][]*Type),
+		base:   base,
+	}
+	switch {
+	case binary.LittleEndian.Uint16(data) == magic:
+		spec.order = binary.LittleEndian
+	case binary.BigEndian.Uint16(data) == magic:
+		spec.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("bad BTF magic 0x%x", binary.LittleEndian.Uint16(data))
+	}
+	if base != nil {
+		if base.order != spec.order {
+			return nil, fmt.Errorf("split BTF has different endianness than the base BTF")
+		}
+		spec.Types = slices.Clone(base.Types)
+		spec.strBase = base.strBase + len(base.strings)
+	}
+	if ver := data[2]; ver != 1 {
+		return nil, fmt.Errorf("unsupported BTF version %v", ver)
+	}
+	hdrLen := spec.order.Uint32(data[4:])
+	typeOff, typeLen := spec.order.Uint32(data[8:]), spec.order.Uint32(data[12:])
+	strOff, strLen := spec.order.Uint32(data[16:]), spec.order.Uint32(data[20:])
+	section := func(off, size uint32) ([]byte, error) {
+		start, end := uint64(hdrLen)+uint64(off), uint64(hdrLen)+uint64(off)+uint64(size)
+		if hdrLen < headerSize || end > uint64(len(data)) {
+			return nil, fmt.Errorf("BTF section [%v, %v) is out of bounds (%v bytes)", start, end, len(data))
+		}
+		return data[start:end], nil
+	}
+	types, err := section(typeOff, typeLen)
+	if err != nil {
+		return nil, err
+	}
+	if spec.strings, err = section(strOff, strLen); err != nil {
+		return nil, err
+	}
+	if err := spec.parseTypes(types); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// rawType holds type IDs that are resolved to pointers after all types are parsed.
+type rawType struct {
+	ref     uint32
+	members []uint32
+}
+
+func (spec *Spec) parseTypes(data []byte) error {
+	first := len(spec.Types)
+	var raws []rawType
+	for len(data) != 0 {
+		t, raw, rest, err := spec.parseType(data)
+		if err != nil {
+			return fmt.Errorf("type #%v: %w", len(spec.Types), err)
+		}
+		t.ID = len(spec.Types)
+		spec.Types = append(spec.Types, t)
+		raws = append(raws, raw)
+		data = rest
+	}
+	for i, raw := range raws {
+		t := spec.Types[first+i]
+		var err error
+		if t.Ref, err = spec.typeByID(raw.ref); err != nil {
+			return fmt.Errorf("%v: %w", t, err)
+		}
+		for j, id := range raw.members {
+			if t.Members[j].Type, err = spec.typeByID(id); err != nil {
+				return fmt.Errorf("%v: %w", t, err)
+			}
+		}
+		if t.Name != "" {
+			spec.byName[t.Name] = append(spec.byName[t.Name], t)
+		}
+	}
+	return nil
+}
+
+// Lookup returns all types with the given name (there may be several with different kinds).
+func (spec *Spec) Lookup(name string) []*Type {
+	var res []*Type
+	if spec.base != nil {
+		res = spec.base.Lookup(name)
+	}
+	return append(res, spec.byName[name]...)
+}
+
+func (spec *Spec) typeByID(id uint32) (*Type, error) {
+	if int64(id) >= int64(len(spec.Types)) {
+		return nil, fmt.Errorf("reference to non-existent type #%v", id)
+	}
+	return spec.Types[id], nil
+}
+
+func (spec *Spec) parseType(data []byte) (*Type, rawType, []byte, error) {
+	var raw rawType
+	if len(data) < 12 {
+		return nil, raw, nil, fmt.Errorf("truncated type")
+	}
+	name, err := spec.str(spec.order.Uint32(data))
+	if err != nil {
+		return nil, raw, nil, err
+	}
+	info := spec.order.Uint32(data[4:])
+	sizeOrType := spec.order.Uint32(data[8:])
+	vlen := int(info & 0xffff)
+	t := &Type{
+		Kind:     Kind((info >> 24) & 0x1f),
+		Name:     name,
+		KindFlag: info>>31 != 0,
+	}
+	data = data[12:]
+	// Size of the kind-specific data that follows the common part.
+	extra := 0
+	switch t.Kind {
+	case KindInt:
+		extra = 4
+	case KindArray:
+		extra = 12
+	case KindStruct, KindUnion, KindDatasec, KindEnum64:
+		extra = 12 * vlen
+	case KindEnum, KindFuncProto:
+		extra = 8 * vlen
+	case KindVar, KindDeclTag:
+		extra = 4
+	case KindPtr, KindFwd, KindTypedef, KindVolatile, KindConst, KindRestrict, KindFunc, KindFloat, KindTypeTag:
+	default:
+		return nil, raw, nil, fmt.Errorf("unknown kind %v", int(t.Kind))
+	}
+	if len(data) < extra {
+		return nil, raw, nil, fmt.Errorf("truncated %v", t.Kind)
+	}
+	extraData, rest := data[:extra], data[extra:]
+	switch t.Kind {
+	case KindInt:
+		v := spec.order.Uint32(extraData)
+		t.Size = int(sizeOrType)
+		t.Encoding = int(v>>24) & 0xf
+		t.Bits = int(v & 0xff)
+	case KindFloat, KindDatasec:
+		t.Size = int(sizeOrType)
+	case KindArray:
+		raw.ref = spec.order.Uint32(extraData)
+		t.Len = int(spec.order.Uint32(extraData[8:]))
+	case KindStruct, KindUnion:
+		t.Size = int(sizeOrType)
+		for i := 0; i < vlen; i++ {
+			m := extraData[i*12:]
+			name, err := spec.str(spec.order.Uint32(m))
+			if err != nil {
+				return nil, raw, nil, err
+			}
+			member := Member{Name: name}
+			offset := spec.order.Uint32(m[8:])
+			if t.KindFlag {
+				member.BitOffset = int(offset & 0xffffff)
+				member.BitSize = int(offset >> 24)
+			} else {
+				member.BitOffset = int(offset)
+			}
+			t.Members = append(t.Members, member)
+			raw.members = append(raw.members, spec.order.Uint32(m[4:]))
+		}
+	case KindEnum, KindEnum64:
+		t.Size = int(sizeOrType)
+		size := extra / max(vlen, 1)
+		for i := 0; i < vlen; i++ {
+			v := extraData[i*size:]
+			name, err := spec.str(spec.order.Uint32(v))
+			if err != nil {
+				return nil, raw, nil, err
+			}
+			val := int64(int32(spec.order.Uint32(v[4:])))
+			if !t.KindFlag {
+				val = int64(spec.order.Uint32(v[4:]))
+			}
+			if t.Kind == KindEnum64 {
+				val = int64(uint64(spec.order.Uint32(v[8:]))<<32 | uint64(spec.order.Uint32(v[4:])))
+			}
+			t.Values = append(t.Values, EnumValue{Name: name, Value: val})
+		}
+	case KindFuncProto:
+		raw.ref = sizeOrType
+		for i := 0; i < vlen; i++ {
+			p := extraData[i*8:]
+			name, err := spec.str(spec.order.Uint32(p))
+			if err != nil {
+				return nil, raw, nil, err
+			}
+			t.Members = append(t.Members, Member{Name: name})
+			raw.members = append(raw.members, spec.order.Uint32(p[4:]))
+		}
+	case KindFwd:
+	default:
+		raw.ref = sizeOrType
+	}
+	return t, raw, rest, nil
+}
+
+func (spec *Spec) str(off uint32) (string, error) {
+	if int(off) < spec.strBase {
+		return spec.base.str(off)
+	}
+	data := spec.strings
+	pos := int(off) - spec.strBase
+	if pos >= len(data) {
+		return "", fmt.Errorf("string offset %v is out of bounds", off)
+	}
+	end := bytes.IndexByte(data[pos:], 0)
+	if end == -1 {
+		return "", fmt.Errorf("string at offset %v is not terminated", off)
+	}
+	return string(data[pos : pos+end]), nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package btf
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// builder produces raw BTF blobs for tests.
+type builder struct {
+	types   []byte
+	strings []byte
+	strBase int
+}
+
+func newBuilder(base *builder) *builder {
+	b := &builder{strings: []byte{0}}
+	if base != nil {
+		b.strBase = base.strBase + len(base.strings)
+		b.strings = nil
+	}
+	return b
+}
+
+func (b *builder) str(s string) uint32 {
+	if s == "" {
+		return 0
+	}
+	off := uint32(b.strBase + len(b.strings))
+	b.strings = append(append(b.strings, s...), 0)
+	return off
+}
+
+func (b *builder) typ(name string, kind Kind, vlen int, kindFlag bool, sizeOrType uint32, extra ...uint32) {
+	info := uint32(kind)<<24 | uint32(vlen)
+	if kindFlag {
+		info |= 1 << 31
+	}
+	b.types = binary.LittleEndian.AppendUint32(b.types, b.str(name))
+	b.types = binary.LittleEndian.AppendUint32(b.types, info)
+	b.types = binary.LittleEndian.AppendUint32(b.types, sizeOrType)
+	for _, v := range extra {
+		b.types = binary.LittleEndian.AppendUint32(b.types, v)
+	}
+}
+
+func (b *builder) data() []byte {
+	var data []byte
+	data = binary.LittleEndian.AppendUint16(data, magic)
+	data = append(data, 1, 0)
+	data = binary.LittleEndian.AppendUint32(data, headerSize)
+	data = binary.LittleEndian.AppendUint32(data, 0)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(b.types)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(b.types)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(b.strings)))
+	data = append(data, b.types...)
+	return append(data, b.strings...)
+}
+
+func buildBase() *builder {
+	b := newBuilder(nil)
+	// #1: int.
+	b.typ("int", KindInt, 0, false, 4, IntSigned<<24|32)
+	// #2: struct s { int a; int b:3; struct s* p; }.
+	b.typ("s", KindStruct, 3, true, 16,
+		b.str("a"), 1, 0,
+		b.str("b"), 1, 3<<24|32,
+		b.str("p"), 3, 64)
+	// #3: struct s*.
+	b.typ("", KindPtr, 0, false, 2)
+	// #4: enum e { A = 1, B = -1 }.
+	b.typ("e", KindEnum, 2, true, 4, b.str("A"), 1, b.str("B"), 0xffffffff)
+	// #5: typedef enum e e_t.
+	b.typ("e_t", KindTypedef, 0, false, 4)
+	// #6: const e_t.
+	b.typ("", KindConst, 0, false, 5)
+	// #7: int[10].
+	b.typ("", KindArray, 0, false, 0, 1, 1, 10)
+	return b
+}
+
+func TestParse(t *testing.T) {
+	spec, err := Parse(buildBase().data())
+	require.NoError(t, err)
+	require.Len(t, spec.Types, 8)
+	assert.Nil(t, spec.Types[0])
+
+	intType := spec.Types[1]
+	assert.Equal(t, KindInt, intType.Kind)
+	assert.Equal(t, 4, intType.Size)
+	assert.Equal(t, IntSigned, intType.Encoding)
+	assert.Equal(t, 32, intType.Bits)
+
+	s := spec.Types[2]
+	assert.Equal(t, "struct s", s.String())
+	assert.Equal(t, []Member{
+		{Name: "a", Type: intType, BitOffset: 0},
+		{Name: "b", Type: intType, BitOffset: 32, BitSize: 3},
+		{Name: "p", Type: spec.Types[3], BitOffset: 64},
+	}, s.Members)
+	assert.Equal(t, s, spec.Types[3].Ref)
+	assert.Equal(t, 16, s.ByteSize(8))
+	assert.Equal(t, 8, s.Align(8))
+	assert.Equal(t, 4, s.Align(4))
+
+	e := spec.Types[4]
+	assert.Equal(t, []EnumValue{{"A", 1}, {"B", -1}}, e.Values)
+	constTypedef := spec.Types[6]
+	assert.True(t, constTypedef.IsConst())
+	assert.Equal(t, spec.Types[5], constTypedef.SkipQualifiers())
+	assert.Equal(t, e, constTypedef.Skip())
+
+	arr := spec.Types[7]
+	assert.Equal(t, 10, arr.Len)
+	assert.Equal(t, intType, arr.Ref)
+	assert.Equal(t, 40, arr.ByteSize(8))
+	assert.Equal(t, 4, arr.Align(8))
+
+	assert.Equal(t, []*Type{s}, spec.Lookup("s"))
+	assert.Empty(t, spec.Lookup("nonexistent"))
+}
+
+func TestParseSplit(t *testing.T) {
+	baseBuilder := buildBase()
+	base, err := Parse(baseBuilder.data())
+	require.NoError(t, err)
+	b := newBuilder(baseBuilder)
+	b.typ("s_t", KindTypedef, 0, false, 2) // #8
+	b.typ("", KindPtr, 0, false, 8)        // #9
+	spec, err := ParseSplit(b.data(), base)
+	require.NoError(t, err)
+	require.Len(t, spec.Types, 10)
+	typedef := spec.Types[8]
+	assert.Equal(t, "s_t", typedef.Name)
+	assert.Equal(t, 8, typedef.ID)
+	assert.Equal(t, base.Types[2], typedef.Ref)
+	assert.Equal(t, typedef, spec.Types[9].Ref)
+	assert.Equal(t, []*Type{base.Types[2]}, spec.Lookup("s"))
+	assert.Equal(t, []*Type{typedef}, spec.Lookup("s_t"))
+	// The base must not be affected.
+	assert.Len(t, base.Types, 8)
+	assert.Empty(t, base.Lookup("s_t"))
+}
+
+func TestParseErrors(t *testing.T) {
+	good := buildBase().data()
+	badRef := newBuilder(nil)
+	badRef.typ("", KindPtr, 0, false, 42)
+	truncated := newBuilder(nil)
+	truncated.typ("s", KindStruct, 2, false, 8, truncated.str("a"), 0, 0)
+	for _, test := range []struct {
+		data []byte
+		err  string
+	}{
+		{good[:10], "BTF is too short: 10 bytes"},
+		{append([]byte{0x12, 0x34}, good[2:]...), "bad BTF magic 0x3412"},
+		{append([]byte{good[0], good[1], 2}, good[3:]...), "unsupported BTF version 2"},
+		{good[:len(good)-1], "BTF section [176, 199) is out of bounds (198 bytes)"},
+		{badRef.data(), "ptr #1: reference to non-existent type #42"},
+		{truncated.data(), "type #1: truncated struct"},
+	} {
+		_, err := Parse(test.data)
+		assert.EqualError(t, err, test.err)
+	}
+}
+
+func TestLoadVmlinux(t *testing.T) {
+	const file = "/sys/kernel/btf/vmlinux"
+	if _, err := os.Stat(file); err != nil {
+		t.Skipf("no %v: %v", file, err)
+	}
+	spec, err := Load(file)
+	require.NoError(t, err)
+	var timespec *Type
+	for _, typ := range spec.Lookup("timespec64") {
+		if typ.Kind == KindStruct {
+			timespec = typ
+		}
+	}
+	require.NotNil(t, timespec)
+	assert.Equal(t, 16, timespec.Size)
+	require.Len(t, timespec.Members, 2)
+	assert.Equal(t, "tv_sec", timespec.Members[0].Name)
+	assert.Equal(t, "tv_nsec", timespec.Members[1].Name)
+	assert.Equal(t, 64, timespec.Members[1].BitOffset)
+}
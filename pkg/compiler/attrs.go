@@ -5,6 +5,8 @@ package compiler
 
 import (
 	"reflect"
+	"slices"
+	"sort"
 
 	"github.com/google/syzkaller/pkg/ast"
 	"github.com/google/syzkaller/prog"
@@ -41,12 +43,21 @@ var (
 	attrInOut      = &attrDesc{Name: "inout"}
 	attrOutOverlay = &attrDesc{Name: "out_overlay"}
 	attrIf         = &attrDesc{Name: "if", Type: exprAttr}
+	attrEq         = &attrDesc{Name: "eq", Type: exprAttr}
+	attrLe         = &attrDesc{Name: "le", Type: exprAttr}
+	attrLt         = &attrDesc{Name: "lt", Type: exprAttr}
+	attrGe         = &attrDesc{Name: "ge", Type: exprAttr}
+	attrGt         = &attrDesc{Name: "gt", Type: exprAttr}
+
+	// Value constraint attributes in the order of the corresponding prog.ConstraintOp values.
+	constraintAttrs = []*attrDesc{attrEq, attrLe, attrLt, attrGe, attrGt}
 
 	structAttrs      = makeAttrs(attrPacked, attrSize, attrAlign)
 	unionAttrs       = makeAttrs(attrVarlen, attrSize)
-	structFieldAttrs = makeAttrs(attrIn, attrOut, attrInOut, attrOutOverlay, attrIf)
-	unionFieldAttrs  = makeAttrs(attrIn, attrIf) // attrIn is safe.
-	callAttrs        = make(map[string]*attrDesc)
+	structFieldAttrs = makeAttrs(append([]*attrDesc{attrIn, attrOut, attrInOut, attrOutOverlay, attrIf},
+		constraintAttrs...)...)
+	unionFieldAttrs = makeAttrs(attrIn, attrIf) // attrIn is safe.
+	callAttrs       = make(map[string]*attrDesc)
 )
 
 func init() {
@@ -72,6 +83,10 @@ func init() {
 	}
 }
 
+func isConstraintAttr(desc *attrDesc) bool {
+	return desc != nil && slices.Contains(constraintAttrs, desc)
+}
+
 func initCallAttrs() {
 	attrs := reflect.TypeOf(prog.SyscallAttrs{})
 	for i := 0; i < attrs.NumField(); i++ {
@@ -111,3 +126,32 @@ func makeAttrs(attrs ...*attrDesc) map[string]*attrDesc {
 	}
 	return m
 }
+
+// AttrNames contains sorted names of attributes that can be used in descriptions.
+type AttrNames struct {
+	Struct      []string
+	Union       []string
+	StructField []string
+	UnionField  []string
+	Call        []string
+}
+
+// Attrs returns names of all supported attributes.
+func Attrs() AttrNames {
+	return AttrNames{
+		Struct:      sortedAttrNames(structAttrs),
+		Union:       sortedAttrNames(unionAttrs),
+		StructField: sortedAttrNames(structFieldAttrs),
+		UnionField:  sortedAttrNames(unionFieldAttrs),
+		Call:        sortedAttrNames(callAttrs),
+	}
+}
+
+func sortedAttrNames(attrs map[string]*attrDesc) []string {
+	var names []string
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
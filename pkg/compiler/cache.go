@@ -0,0 +1,318 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/hash"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+// Cache allows to incrementally recompile descriptions after changes in some of the files.
+//
+// Parsed files are kept in memory and are keyed by the file contents hash
+// (re-parsing all files is faster than decoding persisted ASTs).
+// Results of semantic checks are kept per target and per file, and can be persisted
+// across runs with Save/LoadCache. When a file changes, check results of the file and
+// of all files that transitively refer to types, resources and flags defined in it
+// are invalidated. The rest of the compilation depends on all files, so it is done
+// for the whole target, and the result is always the same as without the cache.
+type Cache struct {
+	mu     sync.Mutex
+	state  cacheState
+	parsed map[string]*parsedFile
+}
+
+type cacheState struct {
+	Version string
+	// File contents hashes.
+	Files   map[string]string
+	Targets map[string]*targetCache
+}
+
+type targetCache struct {
+	// Hash of consts the results were obtained with.
+	Consts string
+	// Files that passed semantic checks.
+	Checked map[string]*checkedFile
+}
+
+type checkedFile struct {
+	Warnings []cachedWarning
+}
+
+type cachedWarning struct {
+	Pos ast.Pos
+	Msg string
+}
+
+type parsedFile struct {
+	hash string
+	desc *ast.Description
+}
+
+// NewCache creates an empty cache. The version must change whenever the compiler changes
+// (e.g. it can be a hash of the binary), results of other versions are not reused.
+func NewCache(version string) *Cache {
+	return &Cache{
+		state: cacheState{
+			Version: version,
+			Files:   make(map[string]string),
+			Targets: make(map[string]*targetCache),
+		},
+		parsed: make(map[string]*parsedFile),
+	}
+}
+
+// LoadCache loads the cache saved with Save. If the file does not exist, is corrupted,
+// or was saved by a different version, it returns an empty cache.
+func LoadCache(file, version string) *Cache {
+	cache := NewCache(version)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cache
+	}
+	var state cacheState
+	if err := json.Unmarshal(data, &state); err != nil || state.Version != version ||
+		state.Files == nil || state.Targets == nil {
+		return cache
+	}
+	cache.state = state
+	return cache
+}
+
+func (cache *Cache) Save(file string) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if err := osutil.MkdirAll(filepath.Dir(file)); err != nil {
+		return err
+	}
+	return osutil.WriteJSON(file, cache.state)
+}
+
+// ParseGlob is like ast.ParseGlob, but re-parses only files that changed since the previous call,
+// and invalidates check results affected by the changes. The returned description must not be modified.
+func (cache *Cache) ParseGlob(glob string, eh ast.ErrorHandler) *ast.Description {
+	if eh == nil {
+		eh = ast.LoggingHandler
+	}
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		eh(ast.Pos{}, fmt.Sprintf("failed to find input files: %v", err))
+		return nil
+	}
+	if len(files) == 0 {
+		eh(ast.Pos{}, fmt.Sprintf("no files matched by glob %q", glob))
+		return nil
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	desc := &ast.Description{}
+	hashes := make(map[string]string)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			eh(ast.Pos{}, fmt.Sprintf("failed to read input file: %v", err))
+			return nil
+		}
+		hashes[f] = hash.String(data)
+		parsed := cache.parsed[f]
+		if parsed == nil || parsed.hash != hashes[f] {
+			desc1 := ast.Parse(data, f, eh)
+			if desc1 == nil {
+				desc = nil
+				continue
+			}
+			parsed = &parsedFile{hash: hashes[f], desc: desc1}
+			cache.parsed[f] = parsed
+		}
+		if desc != nil {
+			desc.Nodes = append(desc.Nodes, parsed.desc.Nodes...)
+		}
+	}
+	if desc != nil {
+		cache.invalidate(glob, desc, hashes)
+	}
+	return desc
+}
+
+func (cache *Cache) invalidate(glob string, desc *ast.Description, hashes map[string]string) {
+	changed := make(map[string]bool)
+	for file, sum := range hashes {
+		if cache.state.Files[file] != sum {
+			cache.state.Files[file] = sum
+			changed[file] = true
+		}
+	}
+	for file := range cache.state.Files {
+		if match, _ := filepath.Match(glob, file); match && hashes[file] == "" {
+			delete(cache.state.Files, file)
+			delete(cache.parsed, file)
+			changed[file] = true
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	affected := dependents(fileDeps(desc), changed)
+	for _, tc := range cache.state.Targets {
+		for file := range affected {
+			delete(tc.Checked, file)
+		}
+	}
+}
+
+// Compile is like the Compile function, but skips semantic checks of files that are not affected by changes
+// since the previous successful compilation for the same target and consts.
+// The description must be returned by ParseGlob.
+// Compile can be called concurrently for different targets.
+func (cache *Cache) Compile(desc *ast.Description, consts map[string]uint64, target *targets.Target,
+	eh ast.ErrorHandler) *Prog {
+	if consts == nil {
+		return Compile(desc, consts, target, eh)
+	}
+	tc := cache.target(target, consts)
+	comp := createCompiler(desc.Clone(), target, eh)
+	comp.checked = tc.Checked
+	comp.fileWarnings = make(map[string][]warn)
+	prg := comp.compile(consts)
+	if prg == nil {
+		return nil
+	}
+	for _, decl := range comp.desc.Nodes {
+		pos, _, _ := decl.Info()
+		if pos.Builtin() || tc.Checked[pos.File] != nil {
+			continue
+		}
+		checked := &checkedFile{}
+		for _, w := range comp.fileWarnings[pos.File] {
+			checked.Warnings = append(checked.Warnings, cachedWarning{w.pos, w.msg})
+		}
+		tc.Checked[pos.File] = checked
+	}
+	return prg
+}
+
+func (cache *Cache) target(target *targets.Target, consts map[string]uint64) *targetCache {
+	buf := new(bytes.Buffer)
+	for _, name := range slices.Sorted(maps.Keys(consts)) {
+		fmt.Fprintf(buf, "%v=%v\n", name, consts[name])
+	}
+	sum := hash.String(buf.Bytes())
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	key := target.OS + "/" + target.Arch
+	tc := cache.state.Targets[key]
+	if tc == nil || tc.Consts != sum {
+		tc = &targetCache{
+			Consts:  sum,
+			Checked: make(map[string]*checkedFile),
+		}
+		cache.state.Targets[key] = tc
+	}
+	return tc
+}
+
+// checkCached says if semantic checks of the node can be skipped since the node
+// and everything it refers to did not change since the cached compilation.
+func (comp *compiler) checkCached(n ast.Node) bool {
+	pos, _, _ := n.Info()
+	if comp.checked[pos.File] == nil {
+		return false
+	}
+	// Template instantiations are checked if any of the files that use them changed.
+	if s, ok := n.(*ast.Struct); ok {
+		for file := range comp.structFiles[s] {
+			if comp.checked[file] == nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cachedWarnings returns warnings produced by checks of the file in the cached compilation.
+func (comp *compiler) cachedWarnings(file string) []warn {
+	var res []warn
+	for _, w := range comp.checked[file].Warnings {
+		res = append(res, warn{w.Pos, w.Msg})
+	}
+	return res
+}
+
+// fileDeps returns the type reference graph of the descriptions: for each file it returns
+// the set of other files that define types, resources and flags referenced in the file.
+func fileDeps(desc *ast.Description) map[string]map[string]bool {
+	defs := make(map[string]string)
+	for _, n := range desc.Nodes {
+		switch n.(type) {
+		case *ast.Resource, *ast.Struct, *ast.TypeDef, *ast.IntFlags, *ast.StrFlags:
+			pos, _, name := n.Info()
+			defs[name] = pos.File
+		case *ast.Call:
+			// Calls can be referenced in value constraints.
+			pos, _, name := n.Info()
+			defs[name] = pos.File
+		}
+	}
+	deps := make(map[string]map[string]bool)
+	for _, decl := range desc.Nodes {
+		pos, _, _ := decl.Info()
+		addDep := func(name string) {
+			def, ok := defs[name]
+			if !ok || def == pos.File {
+				return
+			}
+			if deps[pos.File] == nil {
+				deps[pos.File] = make(map[string]bool)
+			}
+			deps[pos.File][def] = true
+		}
+		ast.Recursive(func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Type:
+				addDep(n.Ident)
+				for _, col := range n.Colon {
+					addDep(col.Ident)
+				}
+			case *ast.Int:
+				addDep(n.Ident)
+			}
+			return true
+		})(decl)
+	}
+	return deps
+}
+
+// dependents returns the files and all files that transitively depend on them.
+func dependents(deps map[string]map[string]bool, files map[string]bool) map[string]bool {
+	users := make(map[string][]string)
+	for file, fileDeps := range deps {
+		for dep := range fileDeps {
+			users[dep] = append(users[dep], file)
+		}
+	}
+	res := make(map[string]bool)
+	queue := slices.Collect(maps.Keys(files))
+	for len(queue) != 0 {
+		file := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if res[file] {
+			continue
+		}
+		res[file] = true
+		queue = append(queue, users[file]...)
+	}
+	return res
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/sys/generated"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileDeps(t *testing.T) {
+	desc := parseFiles(t, map[string]string{
+		"a.txt": "a_flags = 1, 2\nresource a_res[int32]\n",
+		"b.txt": "b_struct {\n\tf0\tflags[a_flags, int32]\n\tf1\tint32[0:a_flags]\n}\n",
+		"c.txt": "type c_templ[T] {\n\tf0\tT\n}\nc_call(a ptr[in, c_templ[b_struct]]) a_res\n",
+		"d.txt": "d_call(a int32)\n",
+	})
+	deps := fileDeps(desc)
+	assert.Equal(t, map[string]map[string]bool{
+		"b.txt": {"a.txt": true},
+		"c.txt": {"a.txt": true, "b.txt": true},
+	}, deps)
+	assert.Equal(t, map[string]bool{"a.txt": true, "b.txt": true, "c.txt": true},
+		dependents(deps, map[string]bool{"a.txt": true}))
+	assert.Equal(t, map[string]bool{"b.txt": true, "c.txt": true},
+		dependents(deps, map[string]bool{"b.txt": true}))
+	assert.Equal(t, map[string]bool{"d.txt": true},
+		dependents(deps, map[string]bool{"d.txt": true}))
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	ct := newCacheTest(t, targets.TestOS, targets.TestArch64, map[string]string{
+		"a.txt": "a_struct {\n\tf0\tint32\n}\n" +
+			"a_varlen {\n\tf0\tint8\n\tf1\tarray[int8]\n}\n" +
+			"foo$a(a ptr[in, a_struct])\n",
+		"b.txt": "b_struct {\n\tf0\ta_struct\n\tf1\tlen[f2, int32]\n\tf2\tarray[a_varlen]\n}\n" +
+			"foo$b(a ptr[in, b_struct])\n",
+		"c.txt": "foo$c(a ptr[in, int32])\n",
+	})
+	ct.consts = map[string]uint64{"SYS_foo": 1}
+	ct.check([]string{"a.txt", "b.txt", "c.txt"}, 1)
+	ct.check(nil, 1)
+	ct.write("a.txt", "\n"+ct.files["a.txt"])
+	ct.check([]string{"a.txt", "b.txt"}, 1)
+	ct.write("c.txt", "foo$c(a ptr[in, int64])\n")
+	ct.check([]string{"c.txt"}, 1)
+	ct.write("b.txt", "b_struct {\n\tf0\ta_struct\n\tf1\tarray[a_varlen]\n}\nfoo$b(a ptr[in, b_struct])\n")
+	ct.check([]string{"b.txt"}, 0)
+
+	ct.write("d.txt", "foo$d(a ptr[in, a_struct])\n")
+	ct.check([]string{"d.txt"}, 0)
+	require.NoError(t, os.Remove(filepath.Join(ct.dir, "d.txt")))
+	delete(ct.files, "d.txt")
+	ct.check(nil, 0)
+
+	cacheFile := filepath.Join(t.TempDir(), "cache")
+	require.NoError(t, ct.cache.Save(cacheFile))
+	ct.cache = LoadCache(cacheFile, "v1")
+	ct.check(nil, 0)
+	ct.cache = LoadCache(cacheFile, "v2")
+	ct.check([]string{"a.txt", "b.txt", "c.txt"}, 0)
+
+	ct.consts["SYS_bar"] = 2
+	ct.check([]string{"a.txt", "b.txt", "c.txt"}, 0)
+}
+
+func TestCacheIdentical(t *testing.T) {
+	oses := []string{targets.TestOS}
+	if !testing.Short() {
+		oses = append(oses, targets.Linux)
+	}
+	for _, OS := range oses {
+		t.Run(OS, func(t *testing.T) {
+			arch := targets.AMD64
+			if OS == targets.TestOS {
+				arch = targets.TestArch64
+			}
+			ct := newCacheTestFromDir(t, OS, arch)
+			ct.compile(ct.cache)
+			ct.compare()
+			files := slices.Sorted(maps.Keys(ct.files))
+			edits := []string{files[0], files[len(files)/2], files[len(files)-1]}
+			if OS == targets.Linux {
+				// Compilation of linux descriptions is slow, so test only one change.
+				edits = edits[1:2]
+			}
+			// Shift positions in some of the files, this changes warnings produced by checks
+			// of the dependent files.
+			for _, file := range edits {
+				ct.write(file, "\n"+ct.files[file])
+				ct.compare()
+			}
+		})
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	const edited = "dev_loop.txt"
+	for _, incremental := range []bool{false, true} {
+		b.Run(fmt.Sprintf("incremental=%v", incremental), func(b *testing.B) {
+			ct := newCacheTestFromDir(b, targets.Linux, targets.AMD64)
+			contents := ct.files[edited]
+			if incremental {
+				ct.compile(ct.cache)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Alternate the file contents, so that the file is changed on each iteration.
+				if i%2 == 0 {
+					ct.write(edited, "\n"+contents)
+				} else {
+					ct.write(edited, contents)
+				}
+				if incremental {
+					ct.compile(ct.cache)
+				} else {
+					ct.compile(nil)
+				}
+			}
+		})
+	}
+}
+
+type cacheTest struct {
+	t      testing.TB
+	target *targets.Target
+	dir    string
+	files  map[string]string
+	consts map[string]uint64
+	cache  *Cache
+}
+
+func newCacheTest(t testing.TB, OS, arch string, files map[string]string) *cacheTest {
+	ct := &cacheTest{
+		t:      t,
+		target: targets.List[OS][arch],
+		dir:    t.TempDir(),
+		files:  make(map[string]string),
+		cache:  NewCache("v1"),
+	}
+	for file, data := range files {
+		ct.write(file, data)
+	}
+	return ct
+}
+
+func newCacheTestFromDir(t testing.TB, OS, arch string) *cacheTest {
+	dir := filepath.Join("..", "..", "sys", OS)
+	constFile := DeserializeConstFile(filepath.Join(dir, "*.const"), nil)
+	require.NotNil(t, constFile)
+	files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		contents[filepath.Base(file)] = string(data)
+	}
+	ct := newCacheTest(t, OS, arch, contents)
+	if OS == targets.TestOS {
+		desc := ast.ParseGlob(filepath.Join(ct.dir, "*.txt"), nil)
+		FabricateSyscallConsts(ct.target, ExtractConsts(desc, ct.target, nil), constFile)
+	}
+	ct.consts = constFile.Arch(arch)
+	return ct
+}
+
+func (ct *cacheTest) write(file, data string) {
+	ct.files[file] = data
+	require.NoError(ct.t, os.WriteFile(filepath.Join(ct.dir, file), []byte(data), 0644))
+}
+
+type cacheResult struct {
+	data     []byte
+	warnings []string
+}
+
+func (ct *cacheTest) compile(cache *Cache) *cacheResult {
+	res := new(cacheResult)
+	eh := func(pos ast.Pos, msg string) {
+		res.warnings = append(res.warnings, fmt.Sprintf("%v: %v", pos, msg))
+	}
+	glob := filepath.Join(ct.dir, "*.txt")
+	var prg *Prog
+	if cache == nil {
+		prg = Compile(ast.ParseGlob(glob, eh), ct.consts, ct.target, eh)
+	} else {
+		prg = cache.Compile(cache.ParseGlob(glob, eh), ct.consts, ct.target, eh)
+	}
+	require.NotNil(ct.t, prg, "compilation failed:\n%v", res.warnings)
+	data, err := generated.Serialize(&generated.Desc{
+		Syscalls:  prg.Syscalls,
+		Resources: prg.Resources,
+		Types:     prg.Types,
+	})
+	require.NoError(ct.t, err)
+	res.data = data
+	sort.Strings(res.warnings)
+	return res
+}
+
+// compare checks that the cached compilation produces the same result as the full one.
+func (ct *cacheTest) compare() {
+	full := ct.compile(nil)
+	cached := ct.compile(ct.cache)
+	assert.Equal(ct.t, full.warnings, cached.warnings)
+	assert.True(ct.t, slices.Equal(full.data, cached.data), "cached compilation output differs")
+}
+
+// check checks that the cached compilation re-checks only the expected files
+// and produces the expected number of field path warnings.
+func (ct *cacheTest) check(rechecked []string, fieldWarnings int) {
+	ct.t.Helper()
+	glob := filepath.Join(ct.dir, "*.txt")
+	desc := ct.cache.ParseGlob(glob, nil)
+	require.NotNil(ct.t, desc)
+	tc := ct.cache.target(ct.target, ct.consts)
+	cached := make(map[string]bool)
+	for file := range tc.Checked {
+		cached[file] = true
+	}
+	var warnings []string
+	eh := func(pos ast.Pos, msg string) {
+		warnings = append(warnings, msg)
+	}
+	require.NotNil(ct.t, ct.cache.Compile(desc, ct.consts, ct.target, eh), "%q", warnings)
+	var got []string
+	for file := range tc.Checked {
+		if !cached[file] {
+			got = append(got, filepath.Base(file))
+		}
+	}
+	sort.Strings(got)
+	assert.Equal(ct.t, rechecked, got)
+	assert.Len(ct.t, warnings, fieldWarnings, "%q", warnings)
+	ct.compare()
+}
+
+func parseFiles(t *testing.T, files map[string]string) *ast.Description {
+	desc := &ast.Description{}
+	for _, file := range slices.Sorted(maps.Keys(files)) {
+		desc1 := ast.Parse([]byte(files[file]), file, nil)
+		require.NotNil(t, desc1)
+		desc.Nodes = append(desc.Nodes, desc1.Nodes...)
+	}
+	return desc
+}
@@ -313,6 +313,7 @@ func (comp *compiler) checkTypes() {
 }
 
 func (comp *compiler) checkTypeValues() {
+	// Note: this is not skipped for cached nodes since const type check also truncates values.
 	for _, decl := range comp.desc.Nodes {
 		switch n := decl.(type) {
 		case *ast.Call, *ast.Struct, *ast.Resource, *ast.TypeDef:
@@ -343,6 +344,9 @@ func (comp *compiler) checkTypeValues() {
 
 func (comp *compiler) checkAttributeValues() {
 	for _, decl := range comp.desc.Nodes {
+		if comp.checkCached(decl) {
+			continue
+		}
 		switch n := decl.(type) {
 		case *ast.Struct:
 			for _, attr := range n.Attrs {
@@ -355,6 +359,10 @@ func (comp *compiler) checkAttributeValues() {
 			st := decl.(*ast.Struct)
 			hasOutOverlay := false
 			for _, f := range st.Fields {
+				if !st.IsUnion {
+					_, exprs, _ := comp.parseAttrs(structFieldAttrs, f, f.Attrs)
+					comp.checkConstraints(f, exprs)
+				}
 				isOut := hasOutOverlay
 				for _, attr := range f.Attrs {
 					switch attr.Ident {
@@ -415,15 +423,33 @@ func (comp *compiler) checkRequiredCallAttrs(call *ast.Call, callAttrNames map[s
 }
 
 func (comp *compiler) checkFieldPaths() {
-	warned := make(map[string]bool)
+	// Warnings are deduplicated per file since check results are cached per file,
+	// duplicates across files are dropped when warnings are reported.
+	warned := make(map[string]map[string]bool)
+	replayed := make(map[string]bool)
 	for _, decl := range comp.desc.Nodes {
 		switch n := decl.(type) {
 		case *ast.Call:
+			file := n.Pos.File
+			if comp.checkCached(n) {
+				if !replayed[file] {
+					replayed[file] = true
+					comp.warnings = append(comp.warnings, comp.cachedWarnings(file)...)
+				}
+				continue
+			}
+			if warned[file] == nil {
+				warned[file] = make(map[string]bool)
+			}
+			warnings := len(comp.warnings)
 			for _, arg := range n.Args {
 				checked := make(map[string]bool)
 
 				parents := []parentDesc{{fields: n.Args, call: n.Name.Name}}
-				comp.checkFieldPathsRec(arg.Type, arg.Type, parents, checked, warned, true)
+				comp.checkFieldPathsRec(arg.Type, arg.Type, parents, checked, warned[file], true)
+			}
+			if comp.fileWarnings != nil {
+				comp.fileWarnings[file] = append(comp.fileWarnings[file], comp.warnings[warnings:]...)
 			}
 		}
 	}
@@ -493,7 +519,7 @@ func (comp *compiler) checkFieldPathsRec(t0, t *ast.Type, parents []parentDesc,
 					if !ok || exprType.Ident != valueIdent {
 						return true
 					}
-					comp.validateFieldPath(exprType.Args[0], t0, exprType, parents, warned)
+					comp.validateFieldPath(exprType.Args[0], t0, exprType, attrDesc, parents, warned)
 					return false
 				})(attr.Args[0])
 			}
@@ -505,14 +531,15 @@ func (comp *compiler) checkFieldPathsRec(t0, t *ast.Type, parents []parentDesc,
 		argDesc := desc.Args[i]
 		switch argDesc.Type {
 		case typeArgLenTarget:
-			comp.validateFieldPath(arg, t0, t, parents, warned)
+			comp.validateFieldPath(arg, t0, t, nil, parents, warned)
 		case typeArgType:
 			comp.checkFieldPathsRec(t0, arg, parents, checked, warned, argDesc.IsArg)
 		}
 	}
 }
 
-func (comp *compiler) validateFieldPath(arg, fieldType, t *ast.Type, parents []parentDesc,
+// attr is the field attribute that contains the path for value paths, and nil for len paths.
+func (comp *compiler) validateFieldPath(arg, fieldType, t *ast.Type, attr *attrDesc, parents []parentDesc,
 	warned map[string]bool) {
 	targets := append([]*ast.Type{arg}, arg.Colon...)
 	const maxParents = 2
@@ -559,10 +586,10 @@ func (comp *compiler) validateFieldPath(arg, fieldType, t *ast.Type, parents []p
 			parents = parents[:len(parents)-1]
 		}
 	}
-	comp.validateFieldPathRec(fieldType, t, targets, parents, warned)
+	comp.validateFieldPathRec(fieldType, t, attr, targets, parents, warned)
 }
 
-func (comp *compiler) validateFieldPathRec(t0, t *ast.Type, targets []*ast.Type,
+func (comp *compiler) validateFieldPathRec(t0, t *ast.Type, attr *attrDesc, targets []*ast.Type,
 	parents []parentDesc, warned map[string]bool) {
 	if len(targets) == 0 {
 		if t.Ident == "offsetof" {
@@ -583,7 +610,7 @@ func (comp *compiler) validateFieldPathRec(t0, t *ast.Type, targets []*ast.Type,
 			comp.error(target.Pos, "%v target %v refers to itself", t.Ident, target.Ident)
 			return
 		}
-		if !comp.checkPathField(target, t, fld) {
+		if !comp.checkPathField(target, t, attr, fld) {
 			return
 		}
 		if len(targets) == 0 {
@@ -601,7 +628,7 @@ func (comp *compiler) validateFieldPathRec(t0, t *ast.Type, targets []*ast.Type,
 				}
 			}
 			if isValuePath {
-				comp.checkExprLastField(target, fld)
+				comp.checkExprLastField(target, attr, fld)
 			}
 			return
 		}
@@ -616,7 +643,7 @@ func (comp *compiler) validateFieldPathRec(t0, t *ast.Type, targets []*ast.Type,
 			return
 		}
 		parents = append(parents, parentDesc{name: parentTargetName(s), fields: s.Fields})
-		comp.validateFieldPathRec(t0, t, targets, parents, warned)
+		comp.validateFieldPathRec(t0, t, attr, targets, parents, warned)
 		return
 	}
 	for pi := len(parents) - 1; pi >= 0; pi-- {
@@ -625,7 +652,7 @@ func (comp *compiler) validateFieldPathRec(t0, t *ast.Type, targets []*ast.Type,
 			parent.name == "" && target.Ident == prog.SyscallRef {
 			parents1 := make([]parentDesc, pi+1)
 			copy(parents1, parents[:pi+1])
-			comp.validateFieldPathRec(t0, t, targets, parents1, warned)
+			comp.validateFieldPathRec(t0, t, attr, targets, parents1, warned)
 			return
 		}
 	}
@@ -637,7 +664,7 @@ func (comp *compiler) validateFieldPathRec(t0, t *ast.Type, targets []*ast.Type,
 	warned[warnKey] = true
 }
 
-func (comp *compiler) checkPathField(target, t *ast.Type, field *ast.Field) bool {
+func (comp *compiler) checkPathField(target, t *ast.Type, pathAttr *attrDesc, field *ast.Field) bool {
 	for _, attr := range field.Attrs {
 		desc := structFieldAttrs[attr.Ident]
 		if desc == attrIf {
@@ -645,18 +672,53 @@ func (comp *compiler) checkPathField(target, t *ast.Type, field *ast.Field) bool
 				field.Name.Name, t.Ident)
 			return false
 		}
+		// Constraints are applied after conditional fields are selected,
+		// so changing the value must not change the selection.
+		if pathAttr == attrIf && isConstraintAttr(desc) {
+			comp.error(target.Pos, "%s has constraints, so if condition cannot reference it",
+				field.Name.Name)
+			return false
+		}
 	}
 
 	return true
 }
 
-func (comp *compiler) checkExprLastField(target *ast.Type, field *ast.Field) {
+func (comp *compiler) checkExprLastField(target *ast.Type, attr *attrDesc, field *ast.Field) {
 	_, desc := comp.derefPointers(field.Type)
+	if desc == typeLen && isConstraintAttr(attr) {
+		// Constraints are applied after sizes are calculated, so they can refer to sizes.
+		return
+	}
 	if desc != typeInt && desc != typeFlags && desc != typeConst {
 		comp.error(target.Pos, "%v does not refer to a constant, an integer, or a flag", field.Name.Name)
 	}
 }
 
+func (comp *compiler) checkConstraints(f *ast.Field, exprs map[*attrDesc]prog.Expression) {
+	var constraints []string
+	for _, attr := range constraintAttrs {
+		if exprs[attr] != nil {
+			constraints = append(constraints, attr.Name)
+		}
+	}
+	if len(constraints) == 0 {
+		return
+	}
+	if exprs[attrIf] != nil {
+		comp.error(f.Pos, "conditional field %v may not have constraints", f.Name.Name)
+	}
+	if desc := comp.getTypeDesc(f.Type); desc != typeInt {
+		comp.error(f.Pos, "%v constraint can only be used on integer fields, field %v is %v",
+			constraints[0], f.Name.Name, f.Type.Ident)
+	} else if len(f.Type.Colon) != 0 {
+		comp.error(f.Pos, "bitfields may not have constraints")
+	}
+	if exprs[attrEq] != nil && len(constraints) > 1 {
+		comp.error(f.Pos, "eq constraint can't be combined with other constraints")
+	}
+}
+
 func (comp *compiler) checkExprFieldType(t *ast.Type) {
 	desc := comp.getTypeDesc(t)
 	if desc == typeInt && len(t.Colon) != 0 {
@@ -1458,6 +1520,9 @@ func (comp *compiler) checkVarlens() {
 	for _, decl := range comp.desc.Nodes {
 		switch n := decl.(type) {
 		case *ast.Struct:
+			if comp.checkCached(n) {
+				continue
+			}
 			comp.checkVarlen(n)
 		}
 	}
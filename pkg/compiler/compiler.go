@@ -74,8 +74,12 @@ func createCompiler(desc *ast.Description, target *targets.Target, eh ast.ErrorH
 
 // Compile compiles sys description.
 func Compile(desc *ast.Description, consts map[string]uint64, target *targets.Target, eh ast.ErrorHandler) *Prog {
-	comp := createCompiler(desc.Clone(), target, eh)
+	return createCompiler(desc.Clone(), target, eh).compile(consts)
+}
+
+func (comp *compiler) compile(consts map[string]uint64) *Prog {
 	comp.filterArch()
+	comp.lowerCallRefs()
 	comp.typecheck()
 	comp.flattenFlags()
 	// The subsequent, more complex, checks expect basic validity of the tree,
@@ -111,8 +115,13 @@ func Compile(desc *ast.Description, consts map[string]uint64, target *targets.Ta
 	if comp.errors != 0 {
 		return nil
 	}
+	// The same warning may be produced when checking different files.
+	reported := make(map[warn]bool)
 	for _, w := range comp.warnings {
-		eh(w.pos, w.msg)
+		if !reported[w] {
+			reported[w] = true
+			comp.eh(w.pos, w.msg)
+		}
 	}
 	return prg
 }
@@ -141,6 +150,10 @@ type compiler struct {
 	builtinConsts  map[string]uint64
 	fileMetas      map[string]Meta
 	recursiveQuery map[ast.Node]bool
+
+	// Set when compiling with Cache.
+	checked      map[string]*checkedFile
+	fileWarnings map[string][]warn
 }
 
 type warn struct {
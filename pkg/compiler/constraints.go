@@ -0,0 +1,185 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/prog"
+)
+
+// lowerCallRefs lowers constraints that refer to output fields of other calls
+// (eq[value[call:arg:field]]) to implicit resources: the referenced output field becomes
+// the resource constructor, and the constrained field becomes the resource consumer.
+// This way the existing resource machinery makes the field equal to the value returned
+// by the other call most of the time, and occasionally uses special resource values instead.
+// Must be called before typecheck since it adds resources and changes field types.
+func (comp *compiler) lowerCallRefs() {
+	calls := make(map[string]*ast.Call)
+	structs := make(map[string]*ast.Struct)
+	for _, decl := range comp.desc.Nodes {
+		switch n := decl.(type) {
+		case *ast.Call:
+			calls[n.Name.Name] = n
+		case *ast.Struct:
+			structs[n.Name.Name] = n
+		}
+	}
+	isCallRef := func(path *ast.Type) bool {
+		// Other calls are referenced as call:arg:field, this also allows fields named as calls.
+		name := path.Ident
+		if len(path.Colon) < 2 || name == prog.ParentRef || name == prog.SyscallRef ||
+			structs[name] != nil {
+			return false
+		}
+		return calls[name] != nil || comp.unsupported["syscall "+name]
+	}
+	resources := make(map[*ast.Field]*ast.Resource)
+	var newResources []ast.Node
+	for _, decl := range comp.desc.Nodes {
+		s, ok := decl.(*ast.Struct)
+		if !ok || s.IsUnion {
+			continue
+		}
+		for _, f := range s.Fields {
+			for i := 0; i < len(f.Attrs); i++ {
+				attr := f.Attrs[i]
+				desc := structFieldAttrs[attr.Ident]
+				if !isConstraintAttr(desc) || len(attr.Args) != 1 {
+					continue
+				}
+				if path := valuePath(attr.Args[0]); desc == attrEq && path != nil && isCallRef(path) {
+					comp.lowerCallRef(f, attr, path, calls, structs, resources, &newResources)
+					f.Attrs = append(f.Attrs[:i:i], f.Attrs[i+1:]...)
+					i--
+					continue
+				}
+				ast.Recursive(func(n ast.Node) bool {
+					if path := valuePath(n); path != nil && isCallRef(path) {
+						comp.error(path.Pos, "references to other calls can only be used"+
+							" as eq[value[call:arg:field]]")
+						return false
+					}
+					return true
+				})(attr.Args[0])
+			}
+		}
+	}
+	comp.desc.Nodes = append(comp.desc.Nodes, newResources...)
+}
+
+func (comp *compiler) lowerCallRef(f *ast.Field, attr, path *ast.Type, calls map[string]*ast.Call,
+	structs map[string]*ast.Struct, resources map[*ast.Field]*ast.Resource, newResources *[]ast.Node) {
+	for _, attr1 := range f.Attrs {
+		if attr1 == attr {
+			continue
+		}
+		if desc := structFieldAttrs[attr1.Ident]; desc == attrIf || isConstraintAttr(desc) {
+			comp.error(f.Pos, "eq constraint that refers to another call can't be combined"+
+				" with other constraints or conditions")
+			return
+		}
+	}
+	call := calls[path.Ident]
+	if call == nil {
+		// The call is not supported on this arch, so the field is left unconstrained.
+		return
+	}
+	outStruct, outField := comp.findOutField(call, path, structs)
+	if outField == nil {
+		return
+	}
+	res := resources[outField]
+	outType := outField.Type
+	if res != nil {
+		// The field was already lowered to a resource for another reference.
+		outType = res.Base
+	}
+	if !isPlainInt(f.Type) || !isPlainInt(outType) || f.Type.Ident != outType.Ident {
+		comp.error(path.Pos, "field %v of type %v can't be equal to field %v of type %v"+
+			" (both must be integers of the same type)",
+			f.Name.Name, f.Type.Ident, outField.Name.Name, outType.Ident)
+		return
+	}
+	if res == nil {
+		name := outStruct.Name.Name + "$" + outField.Name.Name
+		res = &ast.Resource{
+			Pos:  f.Pos,
+			Name: &ast.Ident{Pos: f.Pos, Name: name},
+			Base: outField.Type,
+		}
+		resources[outField] = res
+		*newResources = append(*newResources, res)
+		outField.Type = &ast.Type{Pos: outField.Type.Pos, Ident: name}
+	}
+	f.Type = &ast.Type{Pos: f.Type.Pos, Ident: res.Name.Name}
+}
+
+// findOutField returns the field the call:arg:field... path refers to.
+// The field must be an output field of a struct.
+func (comp *compiler) findOutField(call *ast.Call, path *ast.Type, structs map[string]*ast.Struct) (
+	*ast.Struct, *ast.Field) {
+	var typ *ast.Type
+	for _, arg := range call.Args {
+		if arg.Name.Name == path.Colon[0].Ident {
+			typ = arg.Type
+		}
+	}
+	if typ == nil {
+		comp.error(path.Colon[0].Pos, "call %v does not have argument %v", call.Name.Name, path.Colon[0].Ident)
+		return nil, nil
+	}
+	dir := "in"
+	var s *ast.Struct
+	var field *ast.Field
+	for _, elem := range path.Colon[1:] {
+		if (typ.Ident == "ptr" || typ.Ident == "ptr64") && len(typ.Args) == 2 {
+			dir, typ = typ.Args[0].Ident, typ.Args[1]
+		}
+		s = structs[typ.Ident]
+		if s == nil || len(typ.Args) != 0 {
+			comp.error(elem.Pos, "%v is not a struct, can't refer to its field %v", typ.Ident, elem.Ident)
+			return nil, nil
+		}
+		field = nil
+		for _, fld := range s.Fields {
+			for _, attr := range fld.Attrs {
+				switch structFieldAttrs[attr.Ident] {
+				case attrOutOverlay:
+					dir = "out"
+				case attrIn, attrOut, attrInOut:
+					if fld.Name.Name == elem.Ident {
+						dir = attr.Ident
+					}
+				}
+			}
+			if fld.Name.Name == elem.Ident {
+				field = fld
+				break
+			}
+		}
+		if field == nil {
+			comp.error(elem.Pos, "%v does not have field %v", s.Name.Name, elem.Ident)
+			return nil, nil
+		}
+		typ = field.Type
+	}
+	if dir != "out" && dir != "inout" {
+		comp.error(path.Pos, "%v is not an output field of call %v", field.Name.Name, call.Name.Name)
+		return nil, nil
+	}
+	return s, field
+}
+
+// valuePath returns the path of a value[path] reference, or nil if n is not a value reference.
+func valuePath(n ast.Node) *ast.Type {
+	t, ok := n.(*ast.Type)
+	if !ok || t.Ident != valueIdent || len(t.Args) != 1 {
+		return nil
+	}
+	return t.Args[0]
+}
+
+func isPlainInt(t *ast.Type) bool {
+	return builtinTypes[t.Ident] == typeInt && len(t.Args) == 0 && len(t.Colon) == 0
+}
@@ -543,9 +543,20 @@ func (comp *compiler) genField(f *ast.Field, argSize uint64, overlayDir prog.Dir
 		HasDirection: hasDir,
 		Direction:    dir,
 		Condition:    exprAttrs[attrIf],
+		Constraints:  comp.genConstraints(exprAttrs),
 	}
 }
 
+func (comp *compiler) genConstraints(exprAttrs map[*attrDesc]prog.Expression) []prog.Constraint {
+	var res []prog.Constraint
+	for op, attr := range constraintAttrs {
+		if expr := exprAttrs[attr]; expr != nil {
+			res = append(res, prog.Constraint{Op: prog.ConstraintOp(op), Value: expr})
+		}
+	}
+	return res
+}
+
 var conditionalFieldWrapper = &ast.Struct{}
 
 // For structs, we wrap conditional fields in anonymous unions with a @void field.
@@ -628,6 +639,8 @@ var binaryOperatorMap = map[ast.Operator]prog.BinaryOperator{
 	ast.OperatorCompareNeq: prog.OperatorCompareNeq,
 	ast.OperatorBinaryAnd:  prog.OperatorBinaryAnd,
 	ast.OperatorOr:         prog.OperatorOr,
+	ast.OperatorAdd:        prog.OperatorAdd,
+	ast.OperatorSub:        prog.OperatorSub,
 }
 
 func (comp *compiler) genExpression(t *ast.Type) prog.Expression {
@@ -1209,3 +1209,18 @@ func init() {
 		panic(fmt.Sprintf("failed to parse builtins: %v: %v", pos, msg))
 	})
 }
+
+// BuiltinTypes returns sorted names of builtin types and type templates (e.g. int32, ptr, bool8).
+func BuiltinTypes() []string {
+	var names []string
+	for name := range builtinTypes {
+		names = append(names, name)
+	}
+	for _, n := range builtinDescs.Nodes {
+		if typedef, ok := n.(*ast.TypeDef); ok {
+			names = append(names, typedef.Name.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -52,12 +52,50 @@ func Write(p *prog.Prog, opts Options) ([]byte, error) {
 	return ctx.generateSource()
 }
 
+// WriteConcurrent generates C source that runs the programs concurrently, each in its own proc,
+// the same way syz-execprog runs programs pinned to procs.
+// The number of procs is set to the number of programs, and opts.Repeat is required.
+func WriteConcurrent(progs []*prog.Prog, opts Options) ([]byte, error) {
+	if len(progs) == 0 {
+		return nil, fmt.Errorf("csource: no programs")
+	}
+	if len(progs) == 1 {
+		return Write(progs[0], opts)
+	}
+	if !opts.Repeat {
+		return nil, fmt.Errorf("csource: concurrent programs require Repeat")
+	}
+	opts.Procs = len(progs)
+	if err := opts.Check(progs[0].Target.OS); err != nil {
+		return nil, fmt.Errorf("csource: invalid opts: %w", err)
+	}
+	p := &prog.Prog{Target: progs[0].Target}
+	var callProcs []int
+	for proc, p1 := range progs {
+		for _, c := range p1.Clone().Calls {
+			p.Calls = append(p.Calls, c)
+			callProcs = append(callProcs, proc)
+		}
+	}
+	ctx := &context{
+		p:         p,
+		opts:      opts,
+		target:    p.Target,
+		sysTarget: targets.Get(p.Target.OS, p.Target.Arch),
+		calls:     make(map[string]uint64),
+		callProcs: callProcs,
+	}
+	return ctx.generateSource()
+}
+
 type context struct {
 	p         *prog.Prog
 	opts      Options
 	target    *prog.Target
 	sysTarget *targets.Target
 	calls     map[string]uint64 // CallName -> NR
+	// For concurrent programs, the proc that executes the call.
+	callProcs []int
 }
 
 func generateSandboxFunctionSignature(sandboxName string, sandboxArg int) string {
@@ -181,6 +219,9 @@ func (ctx *context) filterCalls() {
 			p = ctx.p.Clone()
 		}
 		p.RemoveCall(i)
+		if ctx.callProcs != nil {
+			ctx.callProcs = append(ctx.callProcs[:i:i], ctx.callProcs[i+1:]...)
+		}
 	}
 	ctx.p = p
 }
@@ -196,6 +237,10 @@ func (ctx *context) generateSyscalls(calls []string, hasVars bool) string {
 		if opts.Trace {
 			fmt.Fprintf(buf, "\tfprintf(stderr, \"### start\\n\");\n")
 		}
+		if ctx.callProcs != nil {
+			ctx.generateProcSyscalls(buf, calls)
+			return buf.String()
+		}
 		for _, c := range calls {
 			fmt.Fprintf(buf, "%s", c)
 		}
@@ -206,6 +251,10 @@ func (ctx *context) generateSyscalls(calls []string, hasVars bool) string {
 		fmt.Fprintf(buf, "\tswitch (call) {\n")
 		for i, c := range calls {
 			fmt.Fprintf(buf, "\tcase %v:\n", i)
+			if ctx.callProcs != nil {
+				// Each proc executes only the calls of its own program.
+				fmt.Fprintf(buf, "\t\tif (procid != %v)\n\t\t\tbreak;\n", ctx.callProcs[i])
+			}
 			fmt.Fprintf(buf, "%s", strings.ReplaceAll(c, "\t", "\t\t"))
 			fmt.Fprintf(buf, "\t\tbreak;\n")
 		}
@@ -214,6 +263,24 @@ func (ctx *context) generateSyscalls(calls []string, hasVars bool) string {
 	return buf.String()
 }
 
+// generateProcSyscalls emits calls of each of the concurrent programs under the corresponding procid.
+func (ctx *context) generateProcSyscalls(buf *bytes.Buffer, calls []string) {
+	fmt.Fprintf(buf, "\tswitch (procid) {\n")
+	for i, c := range calls {
+		if i == 0 || ctx.callProcs[i] != ctx.callProcs[i-1] {
+			if i != 0 {
+				fmt.Fprintf(buf, "\t\tbreak;\n")
+			}
+			fmt.Fprintf(buf, "\tcase %v:\n", ctx.callProcs[i])
+		}
+		fmt.Fprintf(buf, "%s", strings.ReplaceAll(c, "\t", "\t\t"))
+	}
+	if len(calls) != 0 {
+		fmt.Fprintf(buf, "\t\tbreak;\n")
+	}
+	fmt.Fprintf(buf, "\t}\n")
+}
+
 func (ctx *context) generateSyscallDefines() string {
 	var calls []string
 	for name, nr := range ctx.calls {
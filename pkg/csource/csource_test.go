@@ -7,18 +7,22 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/syzkaller/executor"
+	"github.com/google/syzkaller/pkg/osutil"
 	"github.com/google/syzkaller/pkg/testutil"
 	"github.com/google/syzkaller/prog"
 	_ "github.com/google/syzkaller/sys"
 	"github.com/google/syzkaller/sys/targets"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func init() {
@@ -300,6 +304,203 @@ syscall(SYS_csource8, /*num=*/(intptr_t)-1);
 	}
 }
 
+func TestWriteConcurrent(t *testing.T) {
+	t.Parallel()
+	target, err := prog.GetTarget(targets.TestOS, targets.TestArch64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progs []*prog.Prog
+	for _, text := range []string{"r0 = csource0(0x1)\ncsource1(r0)\n", "csource7(0x2)\n"} {
+		p, err := target.Deserialize([]byte(text), prog.Strict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		progs = append(progs, p)
+	}
+	for _, threaded := range []bool{false, true} {
+		t.Run(fmt.Sprintf("threaded=%v", threaded), func(t *testing.T) {
+			opts := Options{
+				Threaded: threaded,
+				Repeat:   true,
+				Procs:    1,
+				Slowdown: 1,
+				Sandbox:  "none",
+			}
+			src, err := WriteConcurrent(progs, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Regexp(t, `for \(procid = 0; procid < 2; procid\+\+\)`, string(src))
+			if threaded {
+				assert.Regexp(t, `case 2:\s+if \(procid != 1\)\s+break;\s+syscall\(SYS_csource7`, string(src))
+			} else {
+				assert.Regexp(t, `case 0:\s+res = syscall\(SYS_csource0[^}]*\}?[^}]*syscall\(SYS_csource1`+
+					`[^}]*break;\s+case 1:\s+syscall\(SYS_csource7`, string(src))
+			}
+			bin, err := Build(target, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			os.Remove(bin)
+		})
+	}
+	_, err = WriteConcurrent(progs, Options{Sandbox: "none"})
+	assert.Error(t, err)
+}
+
+func TestKselftest(t *testing.T) {
+	t.Parallel()
+	for _, target := range prog.AllTargets() {
+		sysTarget := targets.Get(target.OS, target.Arch)
+		if target.OS != targets.Linux || runtime.GOOS != sysTarget.BuildOS {
+			continue
+		}
+		t.Run(target.Arch, func(t *testing.T) {
+			if target.Arch != runtime.GOARCH && testing.Short() {
+				return
+			}
+			if err := sysTarget.BrokenCompiler; err != "" {
+				t.Skipf("target compiler is broken: %v", err)
+			}
+			t.Parallel()
+			testKselftest(t, target)
+		})
+	}
+}
+
+func testKselftest(t *testing.T, target *prog.Target) {
+	// The tests are built in a subdirectory of the selftests dir, like in the kernel tree.
+	selftests := t.TempDir()
+	header, err := os.ReadFile(filepath.Join("testdata", "kselftest.h"))
+	require.NoError(t, err)
+	require.NoError(t, osutil.WriteFile(filepath.Join(selftests, "kselftest.h"), header))
+	testDir := filepath.Join(selftests, "syzkaller")
+	require.NoError(t, osutil.MkdirAll(testDir))
+
+	rs := testutil.RandSource(t)
+	p := target.Generate(rs, 10, target.DefaultChoiceTable())
+	for i, opts := range []Options{{Slowdown: 1}, ExecutorOpts} {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			test, err := WriteKselftest(p, opts, KselftestParams{
+				Name:     "repro",
+				Title:    "KASAN: use-after-free Read in foo",
+				Duration: time.Minute,
+			})
+			require.NoError(t, err)
+			src := string(test.Source)
+			assert.True(t, strings.HasPrefix(src, "// SPDX-License-Identifier: GPL-2.0\n"))
+			assert.Contains(t, src, "Regression test for: KASAN: use-after-free Read in foo")
+			assert.NotContains(t, src, "executing program")
+			assert.NotContains(t, src, "autogenerated")
+			assert.Regexp(t, `#include <[a-z/]+\.h>\n\n#include "\.\./kselftest\.h"\n`, src)
+			assert.Contains(t, src, "static int reproducer_main(void)")
+			assert.NotContains(t, src, "do_sandbox")
+			assert.Equal(t, "TEST_GEN_PROGS += repro\n$(OUTPUT)/repro: LDLIBS += -lpthread\n",
+				string(test.Makefile))
+			assert.Equal(t, "timeout=75\n", string(test.Settings))
+			bin, err := build(target, test.Source, "", "", "-I", testDir, "-lpthread")
+			if err != nil {
+				t.Fatal(err)
+			}
+			os.Remove(bin)
+		})
+	}
+
+	_, err = WriteKselftest(p, Options{Slowdown: 1}, KselftestParams{Name: "bad name", Duration: time.Minute})
+	assert.Error(t, err)
+	_, err = WriteKselftest(p, Options{Slowdown: 1, Leak: true}, KselftestParams{Name: "leak", Duration: time.Minute})
+	assert.Error(t, err)
+	test, err := WriteKselftest(p, Options{Slowdown: 1}, KselftestParams{Name: "short", Duration: 10 * time.Second})
+	require.NoError(t, err)
+	assert.Nil(t, test.Settings)
+}
+
+func TestKselftestHelpers(t *testing.T) {
+	target, err := prog.GetTarget(targets.Linux, targets.AMD64)
+	require.NoError(t, err)
+	opts := Options{
+		Threaded:      true,
+		Repeat:        true,
+		Procs:         2,
+		Slowdown:      1,
+		Sandbox:       sandboxNamespace,
+		NetInjection:  true,
+		NetDevices:    true,
+		NetReset:      true,
+		Cgroups:       true,
+		BinfmtMisc:    true,
+		CloseFDs:      true,
+		KCSAN:         true,
+		DevlinkPCI:    true,
+		NicVF:         true,
+		USB:           true,
+		VhciInjection: true,
+		Wifi:          true,
+		IEEE802154:    true,
+		Sysctl:        true,
+		Swap:          true,
+		UseTmpDir:     true,
+		HandleSegv:    true,
+	}
+	require.NoError(t, opts.Check(targets.Linux))
+	unused := []string{
+		"initialize_netdevices",
+		"initialize_devlink_pci",
+		"initialize_wifi_devices",
+		"initialize_vhci",
+		"setup_cgroups",
+		"setup_binfmt_misc",
+		"setup_sysctl",
+		"setup_swap",
+		"setup_usb",
+		"setup_802154",
+		"reset_net_namespace",
+		"setup_kcsan",
+	}
+	tests := []struct {
+		prog     string
+		sandbox  bool
+		required []string
+	}{
+		{
+			prog: "getpid()\n",
+		},
+		{
+			prog:     "syz_emit_ethernet(0x0, 0x0, 0x0)\n",
+			sandbox:  true,
+			required: []string{"initialize_tun"},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			p, err := target.Deserialize([]byte(test.prog), prog.NonStrict)
+			require.NoError(t, err)
+			ks, err := WriteKselftest(p, opts, KselftestParams{Name: "repro", Duration: time.Minute})
+			require.NoError(t, err)
+			src := string(ks.Source)
+			for _, helper := range unused {
+				assert.NotContains(t, src, helper)
+			}
+			for _, helper := range test.required {
+				assert.Contains(t, src, helper)
+			}
+			if test.sandbox {
+				assert.Contains(t, src, "do_sandbox_none")
+			} else {
+				assert.NotContains(t, src, "do_sandbox")
+				assert.NotContains(t, src, "sandbox_common")
+				assert.NotContains(t, src, "initialize_tun")
+			}
+			// The rest of the environment is still the same.
+			assert.Contains(t, src, "close_fds")
+			assert.Contains(t, src, "use_temporary_dir")
+			assert.Contains(t, src, "install_segv_handler")
+		})
+	}
+}
+
 func generateSandboxFunctionSignatureTestCase(t *testing.T, sandbox string, sandboxArg int, expected, message string) {
 	actual := generateSandboxFunctionSignature(sandbox, sandboxArg)
 	assert.Equal(t, actual, expected, message)
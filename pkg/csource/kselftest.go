@@ -0,0 +1,280 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package csource
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+// KselftestParams control packaging of a reproducer as a kselftest.
+type KselftestParams struct {
+	// Name of the test binary.
+	Name string
+	// Title of the bug the reproducer triggers (optional), it's mentioned in the test comment.
+	Title string
+	// Duration is for how long the reproducer runs. The test passes if the kernel
+	// has not reported any bugs in the meantime.
+	Duration time.Duration
+}
+
+// Kselftest is a reproducer packaged as a regression test for the kernel selftests
+// (tools/testing/selftests). The test expects to be placed into a subdirectory of the selftests,
+// next to the kselftest.h header.
+type Kselftest struct {
+	Source []byte
+	// Makefile lines that build the test, they need to be added to the Makefile of the directory.
+	Makefile []byte
+	// Lines for the settings file of the directory, only set if the default kselftest timeout
+	// is not enough for the test.
+	Settings []byte
+}
+
+// Timeout of a single test in the kselftest runner, unless it's overridden in the settings file.
+const kselftestDefaultTimeout = 45 * time.Second
+
+var kselftestNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// WriteKselftest generates a kselftest from program p. The test runs the reproducer in a child process
+// and fails if the kernel log (/dev/kmsg) contains a kernel bug report after the run.
+// The parts of the environment set up by opts that the program does not need (sandboxing,
+// cgroups, network devices, etc) are not included into the test.
+func WriteKselftest(p *prog.Prog, opts Options, params KselftestParams) (*Kselftest, error) {
+	if p.Target.OS != targets.Linux {
+		return nil, fmt.Errorf("csource: kselftests are only supported for %v", targets.Linux)
+	}
+	if !kselftestNameRe.MatchString(params.Name) {
+		return nil, fmt.Errorf("csource: bad kselftest name %q", params.Name)
+	}
+	if params.Duration < time.Second {
+		return nil, fmt.Errorf("csource: kselftest duration %v is too short", params.Duration)
+	}
+	if opts.Leak {
+		// Kmemleak reports are not printed to the kernel log, so the test won't see them.
+		return nil, fmt.Errorf("csource: memory leak kselftests are not supported")
+	}
+	opts = kselftestOpts(p, opts)
+	src, err := Write(p, opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &context{
+		p:         p,
+		opts:      opts,
+		target:    p.Target,
+		sysTarget: targets.Get(p.Target.OS, p.Target.Arch),
+	}
+	return &Kselftest{
+		Source:   ctx.kselftestSource(src, params),
+		Makefile: kselftestMakefile(params),
+		Settings: kselftestSettings(params),
+	}, nil
+}
+
+// kselftestOpts drops the parts of the syzkaller test environment that the program does not need.
+// The kselftest runner provides its own environment, and the test should be as small as possible.
+func kselftestOpts(p *prog.Prog, opts Options) Options {
+	uses := func(prefixes ...string) bool {
+		for _, call := range p.Calls {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(call.Meta.CallName, prefix) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	ret := Options{
+		Threaded:    opts.Threaded,
+		Repeat:      opts.Repeat,
+		RepeatTimes: opts.RepeatTimes,
+		Procs:       opts.Procs,
+		Slowdown:    opts.Slowdown,
+		// The devices are only set up in a sandbox, and the pseudo-syscalls don't work without them.
+		NetInjection:  opts.NetInjection && uses("syz_emit_ethernet", "syz_extract_tcp_res"),
+		VhciInjection: opts.VhciInjection && uses("syz_emit_vhci"),
+		Wifi:          opts.Wifi && uses("syz_80211_"),
+		// Closing fds is needed to avoid deadlocks between repeated runs, see executor/common_linux.h.
+		CloseFDs:      opts.CloseFDs && opts.Repeat,
+		UseTmpDir:     opts.UseTmpDir,
+		HandleSegv:    opts.HandleSegv,
+		CallComments:  opts.CallComments,
+		LegacyOptions: opts.LegacyOptions,
+	}
+	if ret.NetInjection || ret.VhciInjection || ret.Wifi {
+		ret.Sandbox = sandboxNone
+	}
+	return ret
+}
+
+var (
+	autogeneratedRe = regexp.MustCompile(`// autogenerated by syzkaller.*\n`)
+	progMarkerRe    = regexp.MustCompile(`\t*if \(write\(1, "executing program\\n".*\{\n?\t*\}\n`)
+	systemIncludeRe = regexp.MustCompile(`(?m)^#include <.*>\n`)
+)
+
+func (ctx *context) kselftestSource(src []byte, params KselftestParams) []byte {
+	src = autogeneratedRe.ReplaceAll(src, nil)
+	// The marker is only used by syzkaller to separate programs in the console output.
+	src = progMarkerRe.ReplaceAll(src, nil)
+	src = bytes.Replace(src, []byte("int main(void)"), []byte("static int reproducer_main(void)"), 1)
+	// Keep the TAP output parsable.
+	src = bytes.ReplaceAll(src, []byte("printf(\"the reproducer may not work as expected:"),
+		[]byte("ksft_print_msg(\"the reproducer may not work as expected:"))
+	src = append(src, fmt.Sprintf(kselftestHarness, params.Name, int(params.Duration/time.Second))...)
+	src = ctx.hoistIncludes(src)
+	// kselftest.h goes after the system includes, since the source defines feature test macros
+	// (e.g. _GNU_SOURCE) that must precede all of them.
+	if loc := systemIncludeRe.FindAllIndex(src, -1); len(loc) != 0 {
+		end := loc[len(loc)-1][1]
+		src = append(src[:end:end], append([]byte("\n#include \"../kselftest.h\"\n"), src[end:]...)...)
+	}
+	src = ctx.removeEmptyLines(src)
+
+	header := new(bytes.Buffer)
+	fmt.Fprintf(header, "// SPDX-License-Identifier: GPL-2.0\n")
+	fmt.Fprintf(header, "/*\n")
+	if params.Title != "" {
+		fmt.Fprintf(header, " * Regression test for: %v\n *\n", strings.ReplaceAll(params.Title, "*/", "* /"))
+	}
+	fmt.Fprintf(header, " * Generated by syzkaller (https://github.com/google/syzkaller) from a reproducer.\n")
+	fmt.Fprintf(header, " * The test fails if the kernel reports a bug while the reproducer runs.\n")
+	fmt.Fprintf(header, " */\n\n")
+	return append(header.Bytes(), bytes.TrimLeft(src, "\n")...)
+}
+
+func kselftestMakefile(params KselftestParams) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "TEST_GEN_PROGS += %v\n", params.Name)
+	fmt.Fprintf(buf, "$(OUTPUT)/%v: LDLIBS += -lpthread\n", params.Name)
+	return buf.Bytes()
+}
+
+func kselftestSettings(params KselftestParams) []byte {
+	// Leave some time for the setup and for the kernel log to settle after the run.
+	const margin = 15 * time.Second
+	timeout := params.Duration + margin
+	if timeout <= kselftestDefaultTimeout {
+		return nil
+	}
+	return []byte(fmt.Sprintf("timeout=%v\n", int((timeout+time.Second-1)/time.Second)))
+}
+
+// The harness is printed with the test name and the reproducer duration in seconds.
+const kselftestHarness = `
+#include <errno.h>
+#include <fcntl.h>
+#include <signal.h>
+#include <stdbool.h>
+#include <string.h>
+#include <sys/types.h>
+#include <sys/wait.h>
+#include <time.h>
+#include <unistd.h>
+
+#define KSFT_TEST_NAME "%[1]v"
+#define KSFT_REPRO_DURATION_SEC %[2]v
+
+static const char* const kernel_bug_markers[] = {
+	"BUG:",
+	"WARNING:",
+	"INFO: task hung",
+	"INFO: rcu detected stall",
+	"INFO: possible circular locking dependency",
+	"general protection fault",
+	"Unable to handle kernel",
+	"kernel BUG at",
+	"Kernel panic",
+	"Oops:",
+	"UBSAN:",
+};
+
+static int kmsg_open(void)
+{
+	int fd = open("/dev/kmsg", O_RDONLY | O_NONBLOCK);
+	if (fd == -1)
+		return -1;
+	// Skip all messages printed before the test.
+	lseek(fd, 0, SEEK_END);
+	return fd;
+}
+
+// Returns whether the new kernel log records contain a bug report.
+static bool kmsg_has_bug(int fd)
+{
+	char buf[8192];
+	bool found = false;
+	for (;;) {
+		ssize_t n = read(fd, buf, sizeof(buf) - 1);
+		if (n == -1 && errno == EPIPE)
+			continue; // some records were overwritten
+		if (n <= 0)
+			break;
+		buf[n] = 0;
+		// The record is "prefix;message\n".
+		char* msg = strchr(buf, ';');
+		msg = msg ? msg + 1 : buf;
+		for (size_t i = 0; i < sizeof(kernel_bug_markers) / sizeof(kernel_bug_markers[0]); i++) {
+			if (strstr(msg, kernel_bug_markers[i])) {
+				ksft_print_msg("kernel: %%s", msg);
+				found = true;
+				break;
+			}
+		}
+	}
+	return found;
+}
+
+static int harness_sleep_ms(long ms)
+{
+	struct timespec ts = {ms / 1000, (ms %% 1000) * 1000000};
+	return nanosleep(&ts, NULL);
+}
+
+int main(void)
+{
+	ksft_print_header();
+	ksft_set_plan(1);
+	int kmsg = kmsg_open();
+	if (kmsg == -1)
+		ksft_exit_skip("can't open /dev/kmsg: %%s\n", strerror(errno));
+	fflush(stdout);
+	pid_t pid = fork();
+	if (pid == -1)
+		ksft_exit_fail_msg("fork failed: %%s\n", strerror(errno));
+	if (pid == 0) {
+		setpgid(0, 0);
+		_exit(reproducer_main());
+	}
+	int status = 0;
+	bool exited = false;
+	for (long ms = 0; ms < KSFT_REPRO_DURATION_SEC * 1000; ms += 100) {
+		if (waitpid(pid, &status, WNOHANG) == pid) {
+			exited = true;
+			break;
+		}
+		harness_sleep_ms(100);
+	}
+	if (!exited) {
+		kill(-pid, SIGKILL);
+		kill(pid, SIGKILL);
+		waitpid(pid, &status, 0);
+	}
+	// Give the kernel some time to finish printing the report.
+	sleep(1);
+	if (kmsg_has_bug(kmsg)) {
+		ksft_test_result_fail(KSFT_TEST_NAME "\n");
+		ksft_exit_fail();
+	}
+	ksft_test_result_pass(KSFT_TEST_NAME "\n");
+	ksft_exit_pass();
+	return 0;
+}
+`
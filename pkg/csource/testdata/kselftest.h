@@ -0,0 +1,86 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// A minimal subset of tools/testing/selftests/kselftest.h used to build generated kselftests.
+
+#ifndef __KSELFTEST_H
+#define __KSELFTEST_H
+
+#include <stdarg.h>
+#include <stdio.h>
+#include <stdlib.h>
+
+#define KSFT_PASS 0
+#define KSFT_FAIL 1
+#define KSFT_SKIP 4
+
+static unsigned int ksft_test_num;
+
+static inline void ksft_print_header(void)
+{
+	printf("TAP version 13\n");
+}
+
+static inline void ksft_set_plan(unsigned int plan)
+{
+	printf("1..%u\n", plan);
+}
+
+static inline void ksft_print_msg(const char* msg, ...)
+{
+	va_list args;
+	va_start(args, msg);
+	printf("# ");
+	vprintf(msg, args);
+	va_end(args);
+}
+
+static inline void ksft_test_result_pass(const char* msg, ...)
+{
+	va_list args;
+	va_start(args, msg);
+	printf("ok %u ", ++ksft_test_num);
+	vprintf(msg, args);
+	va_end(args);
+}
+
+static inline void ksft_test_result_fail(const char* msg, ...)
+{
+	va_list args;
+	va_start(args, msg);
+	printf("not ok %u ", ++ksft_test_num);
+	vprintf(msg, args);
+	va_end(args);
+}
+
+static inline int ksft_exit_pass(void)
+{
+	exit(KSFT_PASS);
+}
+
+static inline int ksft_exit_fail(void)
+{
+	exit(KSFT_FAIL);
+}
+
+static inline int ksft_exit_fail_msg(const char* msg, ...)
+{
+	va_list args;
+	va_start(args, msg);
+	printf("Bail out! ");
+	vprintf(msg, args);
+	va_end(args);
+	exit(KSFT_FAIL);
+}
+
+static inline int ksft_exit_skip(const char* msg, ...)
+{
+	va_list args;
+	va_start(args, msg);
+	printf("ok %u # SKIP ", ++ksft_test_num);
+	vprintf(msg, args);
+	va_end(args);
+	exit(KSFT_SKIP);
+}
+
+#endif
@@ -0,0 +1,296 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package desctest implements unit tests for syscall descriptions.
+// Unlike sys/OS/test programs that check behavior of the executor and the kernel,
+// these tests check properties of the descriptions themselves without executing anything.
+//
+// Tests are stored in sys/OS/*.txt.test files next to the descriptions. The format is:
+//
+//	# test: ipv4 header
+//	# arch: amd64 arm64
+//	# mem: 0x7f0000000000 4500001c xxxx 0000
+//	syz_emit_ethernet(AUTO, &(0x7f0000000000)=...)
+//
+//	# test: netlink route
+//	# generate: sendmsg$nl_route 100
+//
+// A test starts with "# test: name" line. "# arch:" restricts the test to the given arches.
+// A test either contains a program (all non-comment lines until the next test), or a "# generate:"
+// directive with a syscall name and an optional number of programs to generate (10 by default).
+// Generated programs are also mutated (without deliberate corruption of len fields) and checked again.
+// "# mem: addr bytes" lines assert that after all copyins of the program the memory at addr contains
+// the given hex bytes, "xx" denotes any byte. Addresses are specified the same way as pointers
+// in the program (e.g. 0x7f0000000000 is the beginning of the data area), so they don't depend on the arch.
+//
+// The following is checked for all programs:
+//   - the program survives serialization and strict deserialization round trip
+//     without changes in the text and exec encodings;
+//   - the exec encoding of the program can be decoded;
+//   - values of all len fields match sizes of the corresponding args;
+//   - checksums computed by the executor are still valid after all copyins of the call.
+package desctest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/google/syzkaller/prog"
+)
+
+type Test struct {
+	Name   string
+	File   string
+	Line   int
+	Arches []string
+	// Prog is the program text, empty for generated tests.
+	Prog []byte
+	// Generate is the name of the syscall to generate programs for.
+	Generate string
+	Count    int
+	Mem      []MemCheck
+}
+
+// MemCheck is an expectation of memory contents after all copyins of the program.
+type MemCheck struct {
+	Addr uint64
+	Data []byte
+	// Any is set for bytes that may have any value.
+	Any []bool
+}
+
+const (
+	defaultCount = 10
+	// Keep in sync with prog.encodingAddrBase.
+	encodingAddrBase = 0x7f0000000000
+)
+
+// Parse parses tests from the contents of a .txt.test file.
+func Parse(data []byte, file string) ([]*Test, error) {
+	var tests []*Test
+	var test *Test
+	var text []byte
+	finish := func() error {
+		if test == nil {
+			return nil
+		}
+		test.Prog = text
+		text = nil
+		if test.Generate == "" && len(bytes.TrimSpace(test.Prog)) == 0 {
+			return fmt.Errorf("%v:%v: test %q has neither program nor generate directive",
+				file, test.Line, test.Name)
+		}
+		if test.Generate != "" && len(test.Mem) != 0 {
+			return fmt.Errorf("%v:%v: test %q: mem expectations require a fixed program",
+				file, test.Line, test.Name)
+		}
+		tests = append(tests, test)
+		return nil
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			if test == nil {
+				return nil, fmt.Errorf("%v:%v: program outside of a test", file, lineNo)
+			}
+			if test.Generate != "" {
+				return nil, fmt.Errorf("%v:%v: test %q has both program and generate directive",
+					file, lineNo, test.Name)
+			}
+			text = append(text, line...)
+			text = append(text, '\n')
+			continue
+		}
+		directive, value, ok := strings.Cut(strings.TrimSpace(line[1:]), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if directive == "test" {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			test = &Test{Name: value, File: file, Line: lineNo}
+			continue
+		}
+		if test == nil {
+			continue
+		}
+		var err error
+		switch directive {
+		case "arch":
+			test.Arches = append(test.Arches, strings.Fields(value)...)
+		case "generate":
+			if len(text) != 0 {
+				return nil, fmt.Errorf("%v:%v: test %q has both program and generate directive",
+					file, lineNo, test.Name)
+			}
+			err = parseGenerate(test, value)
+		case "mem":
+			err = parseMem(test, value)
+		default:
+			// Not a directive, just a comment with a colon.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", file, lineNo, err)
+		}
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return tests, nil
+}
+
+func parseGenerate(test *Test, value string) error {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("bad generate directive %q, want: call [count]", value)
+	}
+	test.Generate = fields[0]
+	test.Count = defaultCount
+	if len(fields) == 2 {
+		count, err := strconv.Atoi(fields[1])
+		if err != nil || count <= 0 {
+			return fmt.Errorf("bad generate count %q", fields[1])
+		}
+		test.Count = count
+	}
+	return nil
+}
+
+func parseMem(test *Test, value string) error {
+	addrStr, dataStr, _ := strings.Cut(value, " ")
+	addr, err := strconv.ParseUint(addrStr, 0, 64)
+	if err != nil {
+		return fmt.Errorf("bad mem address %q", addrStr)
+	}
+	dataStr = strings.Join(strings.Fields(dataStr), "")
+	if dataStr == "" || len(dataStr)%2 != 0 {
+		return fmt.Errorf("bad mem data %q", dataStr)
+	}
+	check := MemCheck{Addr: addr}
+	for i := 0; i < len(dataStr); i += 2 {
+		if dataStr[i:i+2] == "xx" {
+			check.Data = append(check.Data, 0)
+			check.Any = append(check.Any, true)
+			continue
+		}
+		b, err := hex.DecodeString(dataStr[i : i+2])
+		if err != nil {
+			return fmt.Errorf("bad mem data %q", dataStr)
+		}
+		check.Data = append(check.Data, b[0])
+		check.Any = append(check.Any, false)
+	}
+	test.Mem = append(test.Mem, check)
+	return nil
+}
+
+// Supported says if the test should run on the target.
+func (test *Test) Supported(target *prog.Target) bool {
+	if len(test.Arches) == 0 {
+		return true
+	}
+	for _, arch := range test.Arches {
+		if arch == target.Arch {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the test on the target, rs is used to generate and mutate programs.
+func Run(target *prog.Target, test *Test, rs rand.Source) error {
+	if test.Generate == "" {
+		p, err := target.Deserialize(test.Prog, prog.NonStrict)
+		if err != nil {
+			return fmt.Errorf("failed to deserialize program: %w", err)
+		}
+		return check(p, test.Mem)
+	}
+	meta := target.SyscallMap[test.Generate]
+	if meta == nil {
+		return fmt.Errorf("unknown syscall %v", test.Generate)
+	}
+	ct := target.DefaultChoiceTable()
+	opts := prog.DefaultMutateOpts
+	opts.KeepSizes = true
+	for i := 0; i < test.Count; i++ {
+		p := target.GenSampleProg(meta, rs)
+		if err := check(p, nil); err != nil {
+			return fmt.Errorf("generated program: %w", err)
+		}
+		p.MutateWithOpts(rs, prog.RecommendedCalls, ct, nil, nil, opts)
+		if err := check(p, nil); err != nil {
+			return fmt.Errorf("mutated program: %w", err)
+		}
+	}
+	return nil
+}
+
+func check(p *prog.Prog, memChecks []MemCheck) error {
+	data := p.Serialize()
+	if err := p.CheckSizes(); err != nil {
+		return fmt.Errorf("%w\n%s", err, data)
+	}
+	exec, err := p.SerializeForExec()
+	if err != nil {
+		return fmt.Errorf("failed to serialize for exec: %w\n%s", err, data)
+	}
+	verbose := p.SerializeVerbose()
+	p1, err := p.Target.Deserialize(verbose, prog.Strict)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize serialized program: %w\n%s", err, verbose)
+	}
+	if data1 := p1.Serialize(); !bytes.Equal(data, data1) {
+		return fmt.Errorf("program changed after serialization round trip\noriginal:\n%s\nnew:\n%s",
+			data, data1)
+	}
+	if exec1, err := p1.SerializeForExec(); err != nil || !bytes.Equal(exec, exec1) {
+		return fmt.Errorf("exec encoding changed after serialization round trip (%v)\n%s", err, data)
+	}
+	execProg, err := p.Target.DeserializeExec(exec, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decode exec program: %w\n%s", err, data)
+	}
+	mem := newMemory(p.Target)
+	for _, call := range execProg.Calls {
+		if err := mem.copyinCall(call); err != nil {
+			return fmt.Errorf("%w\n%s", err, data)
+		}
+	}
+	for _, check := range memChecks {
+		if check.Addr < encodingAddrBase {
+			return fmt.Errorf("memory address %#x is outside of the data area", check.Addr)
+		}
+		got := mem.read(check.Addr-encodingAddrBase+p.Target.DataOffset, uint64(len(check.Data)))
+		for i := range got {
+			if !check.Any[i] && got[i] != check.Data[i] {
+				return fmt.Errorf("memory at %#x:\n got: %v\nwant: %v\n%s",
+					check.Addr, hex.EncodeToString(got), check, data)
+			}
+		}
+	}
+	return nil
+}
+
+func (check MemCheck) String() string {
+	var res []byte
+	for i, b := range check.Data {
+		if check.Any[i] {
+			res = append(res, "xx"...)
+		} else {
+			res = hex.AppendEncode(res, []byte{b})
+		}
+	}
+	return string(res)
+}
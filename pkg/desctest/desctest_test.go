@@ -0,0 +1,167 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package desctest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/syzkaller/pkg/testutil"
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescriptions(t *testing.T) {
+	t.Parallel()
+	for _, target := range prog.AllTargets() {
+		files, err := filepath.Glob(filepath.Join("..", "..", "sys", target.OS, "*.txt.test"))
+		require.NoError(t, err)
+		if len(files) == 0 {
+			continue
+		}
+		t.Run(target.OS+"/"+target.Arch, func(t *testing.T) {
+			t.Parallel()
+			for _, file := range files {
+				data, err := os.ReadFile(file)
+				require.NoError(t, err)
+				tests, err := Parse(data, filepath.Base(file))
+				require.NoError(t, err)
+				for _, test := range tests {
+					if !test.Supported(target) {
+						continue
+					}
+					t.Run(filepath.Base(file)+"/"+test.Name, func(t *testing.T) {
+						if err := Run(target, test, testutil.RandSource(t)); err != nil {
+							t.Fatalf("%v:%v: %v", test.File, test.Line, err)
+						}
+					})
+				}
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	data := `
+# Comments before the first test are ignored.
+
+# test: fixed
+# arch: 64 32
+# mem: 0x7f0000000000 0102 xx04
+# Comment: not a directive.
+test$length0(&(0x7f0000000000)={0xff, 0x2})
+test$length1(&(0x7f0000001000)={0xff, 0x4})
+
+# test: generated
+# generate: test$length0 5
+
+# test: generated default
+# generate: test$length1
+`
+	tests, err := Parse([]byte(data), "file.txt.test")
+	require.NoError(t, err)
+	assert.Equal(t, []*Test{
+		{
+			Name:   "fixed",
+			File:   "file.txt.test",
+			Line:   4,
+			Arches: []string{"64", "32"},
+			Prog: []byte("test$length0(&(0x7f0000000000)={0xff, 0x2})\n" +
+				"test$length1(&(0x7f0000001000)={0xff, 0x4})\n"),
+			Mem: []MemCheck{{
+				Addr: 0x7f0000000000,
+				Data: []byte{1, 2, 0, 4},
+				Any:  []bool{false, false, true, false},
+			}},
+		},
+		{
+			Name:     "generated",
+			File:     "file.txt.test",
+			Line:     11,
+			Generate: "test$length0",
+			Count:    5,
+		},
+		{
+			Name:     "generated default",
+			File:     "file.txt.test",
+			Line:     14,
+			Generate: "test$length1",
+			Count:    defaultCount,
+		},
+	}, tests)
+}
+
+func TestParseErrors(t *testing.T) {
+	// nolint: lll
+	tests := map[string]string{
+		"test$length0()": "file:1: program outside of a test",
+		"# test: a\n# generate: test$length0\nfoo()":     "file:3: test \"a\" has both program and generate directive",
+		"# test: a\nfoo()\n# generate: test$length0":     "file:3: test \"a\" has both program and generate directive",
+		"# test: a\n# generate: test$length0 x":          "file:2: bad generate count \"x\"",
+		"# test: a\n# mem: foo 00\nfoo()":                "file:2: bad mem address \"foo\"",
+		"# test: a\n# mem: 0x0 0g\nfoo()":                "file:2: bad mem data \"0g\"",
+		"# test: a\n# generate: foo\n# mem: 0x0 00":      "file:1: test \"a\": mem expectations require a fixed program",
+		"# test: a\n# arch: 64\n\n# test: b\nfoo()":      "file:1: test \"a\" has neither program nor generate directive",
+		"# test: a\n# generate: test$length0 1 2\nfoo()": "file:2: bad generate directive \"test$length0 1 2\", want: call [count]",
+	}
+	for data, want := range tests {
+		_, err := Parse([]byte(data), "file")
+		if assert.Error(t, err, data) {
+			assert.Equal(t, want, err.Error(), data)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	target, err := prog.GetTarget("test", "64")
+	require.NoError(t, err)
+	mem := newMemory(target)
+	require.NoError(t, mem.writeInt(0x10, 0xabcd, 2, prog.FormatNative, 0, 0))
+	require.NoError(t, mem.writeInt(0x12, 0xabcd, 2, prog.FormatBigEndian, 0, 0))
+	require.NoError(t, mem.writeInt(0x14, 0x5, 1, prog.FormatNative, 0, 4))
+	require.NoError(t, mem.writeInt(0x14, 0xa, 1, prog.FormatNative, 4, 4))
+	require.NoError(t, mem.writeInt(0x15, 0x2a, 20, prog.FormatStrDec, 0, 0))
+	assert.Equal(t, []byte("\xcd\xab\xab\xcd\xa5"+"00000000000000000042"+"\x00"), mem.read(0x10, 26))
+	assert.Error(t, mem.writeInt(0x0, 0, 3, prog.FormatNative, 0, 0))
+
+	// Example from RFC 1071: the sum of 0001 f203 f4f5 f6f7 is ddf2 (in big-endian words).
+	mem.write(0x100, []byte{0x00, 0x01, 0xf2, 0x03, 0xf4, 0xf5, 0xf6, 0xf7})
+	csum := prog.ExecArgCsum{
+		Size: 2,
+		Kind: prog.ExecArgCsumInet,
+		Chunks: []prog.ExecCsumChunk{
+			{Kind: prog.ExecArgCsumChunkData, Value: 0x100, Size: 8},
+		},
+	}
+	assert.Equal(t, ^uint16(0xf2dd), mem.csumInet(csum))
+	csum.Chunks = append(csum.Chunks, prog.ExecCsumChunk{Kind: prog.ExecArgCsumChunkConst, Value: 0x0d22, Size: 2})
+	assert.Equal(t, uint16(0), mem.csumInet(csum))
+}
+
+func TestStaleChecksum(t *testing.T) {
+	target, err := prog.GetTarget("test", "64")
+	require.NoError(t, err)
+	csum := prog.ExecCopyin{
+		Addr: 0x100,
+		Arg: prog.ExecArgCsum{
+			Size: 2,
+			Kind: prog.ExecArgCsumInet,
+			Chunks: []prog.ExecCsumChunk{
+				{Kind: prog.ExecArgCsumChunkData, Value: 0x100, Size: 4},
+			},
+		},
+	}
+	data := prog.ExecCopyin{Addr: 0x102, Arg: prog.ExecArgData{Data: []byte{0x12, 0x34}}}
+	call := prog.ExecCall{
+		Meta:   target.SyscallMap["test$csum_ipv4"],
+		Copyin: []prog.ExecCopyin{data, csum},
+	}
+	assert.NoError(t, newMemory(target).copyinCall(call))
+	call.Copyin = []prog.ExecCopyin{csum, data}
+	assert.EqualError(t, newMemory(target).copyinCall(call),
+		"call test$csum_ipv4: checksum at 0x100 is 0xffff, but the checksummed data has 0xcbed")
+}
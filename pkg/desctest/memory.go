@@ -0,0 +1,182 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package desctest
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/syzkaller/prog"
+)
+
+// memory emulates copyin of exec programs the same way the executor does it.
+// Memory that was never written reads as zeros. Results of calls are replaced with their default values,
+// and the proc pid is 0.
+type memory struct {
+	bigEndian bool
+	order     binary.ByteOrder
+	data      map[uint64]byte
+}
+
+func newMemory(target *prog.Target) *memory {
+	mem := &memory{
+		bigEndian: target.BigEndian,
+		order:     binary.LittleEndian,
+		data:      make(map[uint64]byte),
+	}
+	if target.BigEndian {
+		mem.order = binary.BigEndian
+	}
+	return mem
+}
+
+func (mem *memory) read(addr, size uint64) []byte {
+	res := make([]byte, size)
+	for i := range res {
+		res[i] = mem.data[addr+uint64(i)]
+	}
+	return res
+}
+
+func (mem *memory) write(addr uint64, data []byte) {
+	for i, b := range data {
+		mem.data[addr+uint64(i)] = b
+	}
+}
+
+// copyinCall does all copyins of the call and checks that checksums written by them
+// stay valid after the rest of the copyins.
+func (mem *memory) copyinCall(call prog.ExecCall) error {
+	type csum struct {
+		addr uint64
+		arg  prog.ExecArgCsum
+		// Memory under the checksum before it was written.
+		prev []byte
+	}
+	var csums []csum
+	for _, copyin := range call.Copyin {
+		switch arg := copyin.Arg.(type) {
+		case prog.ExecArgConst:
+			if err := mem.writeInt(copyin.Addr, arg.Value, arg.Size, arg.Format,
+				arg.BitfieldOffset, arg.BitfieldLength); err != nil {
+				return fmt.Errorf("call %v: copyin at %#x: %w", call.Meta.Name, copyin.Addr, err)
+			}
+		case prog.ExecArgResult:
+			if err := mem.writeInt(copyin.Addr, arg.Default, arg.Size, arg.Format, 0, 0); err != nil {
+				return fmt.Errorf("call %v: copyin at %#x: %w", call.Meta.Name, copyin.Addr, err)
+			}
+		case prog.ExecArgData:
+			mem.write(copyin.Addr, arg.Data)
+		case prog.ExecArgCsum:
+			if arg.Kind != prog.ExecArgCsumInet || arg.Size != 2 {
+				return fmt.Errorf("call %v: bad checksum kind %v size %v at %#x",
+					call.Meta.Name, arg.Kind, arg.Size, copyin.Addr)
+			}
+			prev := mem.read(copyin.Addr, arg.Size)
+			buf := make([]byte, 2)
+			mem.order.PutUint16(buf, mem.csumInet(arg))
+			mem.write(copyin.Addr, buf)
+			csums = append(csums, csum{copyin.Addr, arg, prev})
+		default:
+			return fmt.Errorf("call %v: unexpected copyin arg %#v", call.Meta.Name, arg)
+		}
+	}
+	for _, csum := range csums {
+		got := mem.read(csum.addr, csum.arg.Size)
+		mem.write(csum.addr, csum.prev)
+		want := mem.csumInet(csum.arg)
+		mem.write(csum.addr, got)
+		if val := mem.order.Uint16(got); val != want {
+			return fmt.Errorf("call %v: checksum at %#x is %#x, but the checksummed data has %#x",
+				call.Meta.Name, csum.addr, val, want)
+		}
+	}
+	return nil
+}
+
+func (mem *memory) writeInt(addr, val, size uint64, format prog.BinaryFormat, bfOff, bfLen uint64) error {
+	switch format {
+	case prog.FormatStrDec:
+		mem.write(addr, []byte(fmt.Sprintf("%020d", val)))
+		return nil
+	case prog.FormatStrHex:
+		mem.write(addr, []byte(fmt.Sprintf("0x%016x", val)))
+		return nil
+	case prog.FormatStrOct:
+		mem.write(addr, []byte(fmt.Sprintf("%023o", val)))
+		return nil
+	}
+	order := mem.order
+	if format == prog.FormatBigEndian {
+		order = binary.BigEndian
+	}
+	if bfLen != 0 {
+		shift := bfOff
+		if mem.bigEndian {
+			shift = size*8 - bfOff - bfLen
+		}
+		mask := (uint64(1)<<bfLen - 1) << shift
+		val = mem.readUint(order, addr, size)&^mask | val<<shift&mask
+	}
+	buf := make([]byte, 8)
+	switch size {
+	case 1:
+		buf[0] = byte(val)
+	case 2:
+		order.PutUint16(buf, uint16(val))
+	case 4:
+		order.PutUint32(buf, uint32(val))
+	case 8:
+		order.PutUint64(buf, val)
+	default:
+		return fmt.Errorf("bad int size %v", size)
+	}
+	mem.write(addr, buf[:size])
+	return nil
+}
+
+func (mem *memory) readUint(order binary.ByteOrder, addr, size uint64) uint64 {
+	buf := mem.read(addr, size)
+	switch size {
+	case 1:
+		return uint64(buf[0])
+	case 2:
+		return uint64(order.Uint16(buf))
+	case 4:
+		return uint64(order.Uint32(buf))
+	default:
+		return order.Uint64(buf)
+	}
+}
+
+// csumInet computes the checksum the same way csum_inet_update/csum_inet_digest in the executor do.
+func (mem *memory) csumInet(arg prog.ExecArgCsum) uint16 {
+	var acc uint32
+	update := func(data []byte) {
+		for i := 0; i+1 < len(data); i += 2 {
+			acc += uint32(mem.order.Uint16(data[i:]))
+		}
+		if len(data)%2 != 0 {
+			last := uint32(data[len(data)-1])
+			if mem.bigEndian {
+				last <<= 8
+			}
+			acc += last
+		}
+		for acc > 0xffff {
+			acc = acc&0xffff + acc>>16
+		}
+	}
+	for _, chunk := range arg.Chunks {
+		switch chunk.Kind {
+		case prog.ExecArgCsumChunkData:
+			update(mem.read(chunk.Value, chunk.Size))
+		case prog.ExecArgCsumChunkConst:
+			buf := make([]byte, 8)
+			mem.order.PutUint64(buf, chunk.Value)
+			update(buf[:chunk.Size])
+		}
+	}
+	return ^uint16(acc)
+}
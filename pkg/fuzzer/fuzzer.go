@@ -36,7 +36,9 @@ type Fuzzer struct {
 
 	ct           *prog.ChoiceTable
 	ctProgs      int
-	ctMu         sync.Mutex // TODO: use RWLock.
+	ctCalls      map[*prog.Syscall]bool // nil means all of Config.EnabledCalls
+	ctGen        int                    // incremented on each ctCalls change
+	ctMu         sync.Mutex             // TODO: use RWLock.
 	ctRegenerate chan struct{}
 
 	execQueues
@@ -214,6 +216,10 @@ type Config struct {
 	FetchRawCover  bool
 	NewInputFilter func(call string) bool
 	PatchTest      bool
+	// If set, the fuzzer generates and mutates programs only within the holes of these templates.
+	Templates []*prog.Template
+	// If set, generation inserts whole call sequences sampled from the model.
+	Sequences *prog.SequenceModel
 }
 
 func (fuzzer *Fuzzer) triageProgCall(p *prog.Prog, info *flatrpc.CallInfo, call int, triage *map[int]*triageCall) {
@@ -286,7 +292,7 @@ func (fuzzer *Fuzzer) genFuzz() *queue.Request {
 	if req == nil {
 		req = genProgRequest(fuzzer, rnd)
 	}
-	if fuzzer.Config.Collide && rnd.Intn(3) == 0 {
+	if fuzzer.Config.Collide && len(fuzzer.Config.Templates) == 0 && rnd.Intn(3) == 0 {
 		req = &queue.Request{
 			Prog: randomCollide(req.Prog, rnd),
 			Stat: fuzzer.statExecCollide,
@@ -374,16 +380,37 @@ func (fuzzer *Fuzzer) rand() *rand.Rand {
 }
 
 func (fuzzer *Fuzzer) updateChoiceTable(programs []*prog.Prog) {
-	newCt := fuzzer.target.BuildChoiceTable(programs, fuzzer.Config.EnabledCalls)
+	fuzzer.ctMu.Lock()
+	calls, gen := fuzzer.ctCalls, fuzzer.ctGen
+	fuzzer.ctMu.Unlock()
+	if calls == nil {
+		calls = fuzzer.Config.EnabledCalls
+	}
+
+	newCt := fuzzer.target.BuildChoiceTable(programs, calls)
+	if fuzzer.Config.Sequences != nil {
+		newCt.UseSequences(fuzzer.Config.Sequences)
+	}
 
 	fuzzer.ctMu.Lock()
 	defer fuzzer.ctMu.Unlock()
-	if len(programs) >= fuzzer.ctProgs {
+	if gen == fuzzer.ctGen && len(programs) >= fuzzer.ctProgs {
 		fuzzer.ctProgs = len(programs)
 		fuzzer.ct = newCt
 	}
 }
 
+// RotateCalls restricts generation of new calls to the given subset of the enabled calls
+// (e.g. one selected by prog.Rotator). Passing nil restores the full set of enabled calls.
+func (fuzzer *Fuzzer) RotateCalls(calls map[*prog.Syscall]bool) {
+	fuzzer.ctMu.Lock()
+	fuzzer.ctCalls = calls
+	fuzzer.ctGen++
+	fuzzer.ctProgs = 0
+	fuzzer.ctMu.Unlock()
+	fuzzer.updateChoiceTable(fuzzer.Config.Corpus.Programs())
+}
+
 func (fuzzer *Fuzzer) choiceTableUpdater() {
 	for {
 		select {
@@ -92,6 +92,80 @@ func TestFuzz(t *testing.T) {
 	}
 }
 
+func TestRotateCalls(t *testing.T) {
+	target, err := prog.GetTarget(targets.TestOS, targets.TestArch64Fuzz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := map[*prog.Syscall]bool{}
+	for _, c := range target.Syscalls {
+		if !c.Attrs.Disabled && !c.Attrs.NoGenerate {
+			calls[c] = true
+		}
+	}
+	rnd := rand.New(testutil.RandSource(t))
+	fuzzer := NewFuzzer(ctx, &Config{
+		Corpus:       corpus.NewCorpus(ctx),
+		Coverage:     true,
+		EnabledCalls: calls,
+	}, rnd, target)
+
+	rotated := prog.MakeRotator(target, calls, rnd).Select()
+	assert.Less(t, len(rotated), len(calls))
+	fuzzer.RotateCalls(rotated)
+	for i := 0; i < 100; i++ {
+		p := target.Generate(rnd, 10, fuzzer.ChoiceTable())
+		for _, c := range p.Calls {
+			if !rotated[c.Meta] {
+				t.Fatalf("generated a call outside of the rotated set: %v", c.Meta.Name)
+			}
+		}
+	}
+
+	fuzzer.RotateCalls(nil)
+	for c := range calls {
+		assert.True(t, fuzzer.ChoiceTable().Generatable(c.ID), c.Name)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	target, err := prog.GetTarget(targets.TestOS, targets.TestArch64Fuzz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := target.ParseTemplate([]byte(`
+test$int(0x1, ?0x2, 0x3, ?0x4, 0x5)
+?test$blob0(&(0x7f0000000000)=?"0102")
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rnd := rand.New(testutil.RandSource(t))
+	fuzzer := NewFuzzer(ctx, &Config{
+		Corpus:    corpus.NewCorpus(ctx),
+		Coverage:  true,
+		Templates: []*prog.Template{tmpl},
+	}, rnd, target)
+	for i := 0; i < 100; i++ {
+		req := genProgRequest(fuzzer, rnd)
+		assert.True(t, tmpl.Match(req.Prog), "%s", req.Prog.Serialize())
+		newP := req.Prog.Clone()
+		if fuzzer.mutate(newP, rnd) {
+			assert.True(t, tmpl.Match(newP), "%s", newP.Serialize())
+		}
+	}
+	// Programs that don't match the templates are not mutated.
+	p, err := target.Deserialize([]byte("test$int(0x7, 0x2, 0x3, 0x4, 0x5)\n"), prog.Strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, fuzzer.mutate(p, rnd))
+}
+
 func BenchmarkFuzzer(b *testing.B) {
 	b.ReportAllocs()
 	target, err := prog.GetTarget(targets.TestOS, targets.TestArch64Fuzz)
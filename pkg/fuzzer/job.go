@@ -42,9 +42,14 @@ func (ji *JobInfo) ID() string {
 }
 
 func genProgRequest(fuzzer *Fuzzer, rnd *rand.Rand) *queue.Request {
-	p := fuzzer.target.Generate(rnd,
-		prog.RecommendedCalls,
-		fuzzer.ChoiceTable())
+	var p *prog.Prog
+	if templates := fuzzer.Config.Templates; len(templates) != 0 {
+		p = templates[rnd.Intn(len(templates))].Generate(rnd, fuzzer.ChoiceTable())
+	} else {
+		p = fuzzer.target.Generate(rnd,
+			prog.RecommendedCalls,
+			fuzzer.ChoiceTable())
+	}
 	return &queue.Request{
 		Prog:     p,
 		ExecOpts: setFlags(flatrpc.ExecFlagCollectSignal),
@@ -58,12 +63,9 @@ func mutateProgRequest(fuzzer *Fuzzer, rnd *rand.Rand) *queue.Request {
 		return nil
 	}
 	newP := p.Clone()
-	newP.Mutate(rnd,
-		prog.RecommendedCalls,
-		fuzzer.ChoiceTable(),
-		fuzzer.Config.NoMutateCalls,
-		fuzzer.Config.Corpus.Programs(),
-	)
+	if !fuzzer.mutate(newP, rnd) {
+		return nil
+	}
 	return &queue.Request{
 		Prog:     newP,
 		ExecOpts: setFlags(flatrpc.ExecFlagCollectSignal),
@@ -71,6 +73,38 @@ func mutateProgRequest(fuzzer *Fuzzer, rnd *rand.Rand) *queue.Request {
 	}
 }
 
+// mutate mutates p, or only the holes of p if the fuzzer runs with templates.
+// Returns false if p can't be mutated (it does not match any of the templates).
+func (fuzzer *Fuzzer) mutate(p *prog.Prog, rnd *rand.Rand) bool {
+	templates := fuzzer.Config.Templates
+	if len(templates) == 0 {
+		p.Mutate(rnd,
+			prog.RecommendedCalls,
+			fuzzer.ChoiceTable(),
+			fuzzer.Config.NoMutateCalls,
+			fuzzer.Config.Corpus.Programs(),
+		)
+		return true
+	}
+	for _, i := range rnd.Perm(len(templates)) {
+		if templates[i].Mutate(p, rnd, fuzzer.ChoiceTable(), fuzzer.Config.Corpus.Programs()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTemplates returns whether p matches one of the templates (if any).
+func (fuzzer *Fuzzer) matchesTemplates(p *prog.Prog) bool {
+	templates := fuzzer.Config.Templates
+	for _, t := range templates {
+		if t.Match(p) {
+			return true
+		}
+	}
+	return len(templates) == 0
+}
+
 // triageJob are programs for which we noticed potential new coverage during
 // first execution. But we are not sure yet if the coverage is real or not.
 // During triage we understand if these programs in fact give new coverage,
@@ -167,7 +201,8 @@ func (job *triageJob) handleCall(call int, info *triageCall) {
 	}
 
 	p := job.p
-	if job.flags&ProgMinimized == 0 {
+	// Minimization would break the template skeleton.
+	if job.flags&ProgMinimized == 0 && len(job.fuzzer.Config.Templates) == 0 {
 		p, call = job.minimize(call, info)
 		if p == nil {
 			return
@@ -452,10 +487,9 @@ func (job *smashJob) run(fuzzer *Fuzzer) {
 	rnd := fuzzer.rand()
 	for i := 0; i < iters; i++ {
 		p := job.p.Clone()
-		p.Mutate(rnd, prog.RecommendedCalls,
-			fuzzer.ChoiceTable(),
-			fuzzer.Config.NoMutateCalls,
-			fuzzer.Config.Corpus.Programs())
+		if !fuzzer.mutate(p, rnd) {
+			return
+		}
 		result := fuzzer.execute(job.exec, &queue.Request{
 			Prog:     p,
 			ExecOpts: setFlags(flatrpc.ExecFlagCollectSignal),
@@ -568,6 +602,9 @@ func (job *hintsJob) run(fuzzer *Fuzzer) {
 	// Execute each of such mutants to check if it gives new coverage.
 	p.MutateWithHints(job.call, comps,
 		func(p *prog.Prog) bool {
+			if !fuzzer.matchesTemplates(p) {
+				return true
+			}
 			defer job.info.Execs.Add(1)
 			result := fuzzer.execute(job.exec, &queue.Request{
 				Prog:     p,
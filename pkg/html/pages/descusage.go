@@ -0,0 +1,49 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package pages
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/google/syzkaller/prog"
+)
+
+// DescUsageHTML renders the description usage statistics as an HTML fragment.
+func DescUsageHTML(u *prog.DescUsage) (template.HTML, error) {
+	buf := new(bytes.Buffer)
+	if err := descUsageTemplate.Execute(buf, u); err != nil {
+		return "", fmt.Errorf("failed to execute descusage template: %w", err)
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// WriteDescUsagePage writes a standalone HTML page with the description usage statistics.
+func WriteDescUsagePage(w io.Writer, u *prog.DescUsage) error {
+	body, err := DescUsageHTML(u)
+	if err != nil {
+		return err
+	}
+	return descUsagePageTemplate.Execute(w, body)
+}
+
+var (
+	descUsageTemplate     = Create(descUsageHTML)
+	descUsagePageTemplate = Create(`<!doctype html>
+<html>
+<head>
+	<title>syzkaller description usage</title>
+	{{HEAD}}
+</head>
+<body>
+{{.}}
+</body>
+</html>`)
+)
+
+//go:embed descusage.html
+var descUsageHTML string
@@ -0,0 +1,28 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package pages
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescUsageHTML(t *testing.T) {
+	target, err := prog.GetTarget(targets.TestOS, targets.TestArch64)
+	require.NoError(t, err)
+	p, err := target.Deserialize([]byte("test$union0(&(0x7f0000000000)={0x1, @f2})\n"), prog.Strict)
+	require.NoError(t, err)
+	u := target.CalculateDescUsage([]prog.UsageInput{{Prog: p, Credited: []int{0}}}, nil)
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteDescUsagePage(buf, u))
+	assert.Contains(t, buf.String(), "<td>test$union0</td>\n\t\t<td>1</td>\n\t\t<td>1</td>")
+	assert.Contains(t, buf.String(), `<span class="descusage_unused">f1: 0/0</span>`)
+	assert.Contains(t, buf.String(), "<span>f2: 1/1</span>")
+}
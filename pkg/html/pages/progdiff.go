@@ -0,0 +1,49 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package pages
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/google/syzkaller/prog"
+)
+
+// ProgDiffHTML renders the structural diff of two programs as an HTML fragment.
+func ProgDiffHTML(d *prog.ProgDiff) (template.HTML, error) {
+	buf := new(bytes.Buffer)
+	if err := progDiffTemplate.Execute(buf, d); err != nil {
+		return "", fmt.Errorf("failed to execute progdiff template: %w", err)
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// WriteProgDiffPage writes a standalone HTML page with the structural diff of two programs.
+func WriteProgDiffPage(w io.Writer, d *prog.ProgDiff) error {
+	body, err := ProgDiffHTML(d)
+	if err != nil {
+		return err
+	}
+	return progDiffPageTemplate.Execute(w, body)
+}
+
+var (
+	progDiffTemplate     = Create(progDiffHTML)
+	progDiffPageTemplate = Create(`<!doctype html>
+<html>
+<head>
+	<title>syzkaller program diff</title>
+	{{HEAD}}
+</head>
+<body>
+{{.}}
+</body>
+</html>`)
+)
+
+//go:embed progdiff.html
+var progDiffHTML string
@@ -0,0 +1,28 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package pages
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgDiffHTML(t *testing.T) {
+	target, err := prog.GetTarget(targets.TestOS, targets.TestArch64)
+	require.NoError(t, err)
+	a, err := target.Deserialize([]byte("r0 = test$res0()\ntest$res1(r0)\n"), prog.Strict)
+	require.NoError(t, err)
+	b, err := target.Deserialize([]byte("test$res0()\ntest$res1(0xffffffffffffffff)\ntest$res0()\n"), prog.Strict)
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteProgDiffPage(buf, prog.Diff(a, b)))
+	assert.Contains(t, buf.String(), `class="progdiff_inserted"`)
+	assert.Contains(t, buf.String(), "call 1 arg 0: r0 -&gt; 0xffffffffffffffff")
+}
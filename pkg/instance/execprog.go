@@ -164,6 +164,12 @@ type ExecParams struct {
 	// Only one of these will be used, depending on the function.
 	CProg   *prog.Prog
 	SyzProg []byte
+	// If set, RunCProg() builds a C program that runs these programs concurrently,
+	// each in its own proc, instead of CProg.
+	CProgs []*prog.Prog
+	// Execute each program of SyzProg in the proc specified in the log.
+	// This is best-effort: the executor may occasionally use another proc (see syz-execprog -pin_procs).
+	PinProcs bool
 
 	Opts     csource.Options
 	Duration time.Duration
@@ -173,6 +179,15 @@ type ExecParams struct {
 }
 
 func (inst *ExecProgInstance) RunCProg(params ExecParams) (*RunResult, error) {
+	if len(params.CProgs) != 0 {
+		src, err := csource.WriteConcurrent(params.CProgs, params.Opts)
+		if err != nil {
+			return nil, err
+		}
+		inst.Logf(2, "testing compiled concurrent C program (duration=%v, %+v): %s",
+			params.Duration, params.Opts, params.CProgs)
+		return inst.RunCProgRaw(src, params.CProgs[0].Target, params.Duration)
+	}
 	src, err := csource.Write(params.CProg, params.Opts)
 	if err != nil {
 		return nil, err
@@ -194,13 +209,18 @@ func (inst *ExecProgInstance) RunCProgRaw(src []byte, target *prog.Target,
 
 func (inst *ExecProgInstance) RunSyzProgFile(progFile string, duration time.Duration,
 	opts csource.Options, exitCondition vm.ExitCondition) (*RunResult, error) {
+	return inst.runSyzProgFile(progFile, duration, opts, exitCondition, false)
+}
+
+func (inst *ExecProgInstance) runSyzProgFile(progFile string, duration time.Duration,
+	opts csource.Options, exitCondition vm.ExitCondition, pinProcs bool) (*RunResult, error) {
 	vmProgFile, err := inst.VMInstance.Copy(progFile)
 	if err != nil {
 		return nil, &TestError{Title: fmt.Sprintf("failed to copy prog to VM: %v", err)}
 	}
 	target := inst.mgrCfg.SysTarget
 	command := ExecprogCmd(inst.execprogBin, inst.executorBin, target.OS, target.Arch, inst.mgrCfg.Type, opts,
-		!inst.OldFlagsCompatMode, inst.mgrCfg.Timeouts.Slowdown, vmProgFile)
+		!inst.OldFlagsCompatMode, inst.mgrCfg.Timeouts.Slowdown, pinProcs, vmProgFile)
 	return inst.runCommand(command, duration, exitCondition)
 }
 
@@ -214,5 +234,5 @@ func (inst *ExecProgInstance) RunSyzProg(params ExecParams) (*RunResult, error)
 	if params.ExitConditions == 0 {
 		params.ExitConditions = SyzExitConditions
 	}
-	return inst.RunSyzProgFile(progFile, params.Duration, params.Opts, params.ExitConditions)
+	return inst.runSyzProgFile(progFile, params.Duration, params.Opts, params.ExitConditions, params.PinProcs)
 }
@@ -461,7 +461,7 @@ func (inst *inst) csourceOptions() (csource.Options, error) {
 
 // nolint:revive
 func ExecprogCmd(execprog, executor, OS, arch, vmType string, opts csource.Options,
-	optionalFlags bool, slowdown int, progFile string) string {
+	optionalFlags bool, slowdown int, pinProcs bool, progFile string) string {
 	repeatCount := 1
 	if opts.Repeat {
 		repeatCount = 0
@@ -481,11 +481,15 @@ func ExecprogCmd(execprog, executor, OS, arch, vmType string, opts csource.Optio
 			opts.FaultCall, opts.FaultNth)
 	}
 	if optionalFlags {
-		optionalArg += " " + tool.OptionalFlags([]tool.Flag{
+		flags := []tool.Flag{
 			{Name: "slowdown", Value: fmt.Sprint(slowdown)},
 			{Name: "sandbox_arg", Value: fmt.Sprint(opts.SandboxArg)},
 			{Name: "type", Value: fmt.Sprint(vmType)},
-		})
+		}
+		if pinProcs {
+			flags = append(flags, tool.Flag{Name: "pin_procs", Value: "true"})
+		}
+		optionalArg += " " + tool.OptionalFlags(flags)
 	}
 	return fmt.Sprintf("%v -executor=%v -arch=%v%v -sandbox=%v"+
 		" -procs=%v -repeat=%v -threaded=%v -collide=%v -cover=0%v %v",
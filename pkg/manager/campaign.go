@@ -0,0 +1,272 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CampaignConfig describes the limits of a time-boxed fuzzing campaign.
+// Zero values mean no limit, but at least one of Duration and Execs must be set.
+type CampaignConfig struct {
+	// Fuzzing is stopped once this much time has passed since the machine check.
+	Duration time.Duration
+	// Fuzzing is stopped once this many programs have been executed.
+	Execs int
+	// How long to wait for the pending reproductions after fuzzing is stopped.
+	ReproTimeout time.Duration
+}
+
+func (cfg CampaignConfig) Validate() error {
+	if cfg.Duration < 0 || cfg.Execs < 0 || cfg.ReproTimeout < 0 {
+		return fmt.Errorf("campaign limits must not be negative")
+	}
+	if cfg.Duration == 0 && cfg.Execs == 0 {
+		return fmt.Errorf("either campaign duration or execution budget must be set")
+	}
+	return nil
+}
+
+// Campaign tracks the progress of a time-boxed fuzzing session and the bugs found during it.
+type Campaign struct {
+	cfg CampaignConfig
+	// Titles of the bugs that were already present in the crash store.
+	known map[string]bool
+
+	mu         sync.Mutex
+	start      time.Time
+	startExecs int
+	// The size of the corpus after the initial corpus triage, set by CorpusTriaged.
+	startCorpus   int
+	corpusTriaged bool
+	crashes       map[string]*CampaignCrash
+}
+
+type CampaignCrash struct {
+	Title     string
+	Count     int
+	New       bool // the bug was not present in the crash store before the campaign
+	Corrupted bool
+	// The fields below are set if the bug was reproduced during the campaign.
+	Repro      bool
+	CRepro     bool
+	ReproTitle string `json:",omitempty"` // set if the reproducer triggers a different title
+	// The number of finished, but unsuccessful reproduction attempts.
+	FailedRepros int
+}
+
+type CampaignSummary struct {
+	StopReason string
+	Duration   time.Duration
+	Execs      int
+	Coverage   int // the number of covered PCs
+	Signal     int
+	// CorpusStart and CorpusDelta are only set if the initial corpus triage has finished.
+	CorpusTriaged bool
+	CorpusStart   int
+	CorpusEnd     int
+	CorpusDelta   int
+	Crashes       []*CampaignCrash
+	NewBugs       int
+	Repros        int
+	PendingRepros []string
+}
+
+func NewCampaign(cfg CampaignConfig, store *CrashStore) (*Campaign, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	bugs, err := store.BugList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the known bugs: %w", err)
+	}
+	ret := &Campaign{
+		cfg:     cfg,
+		known:   make(map[string]bool),
+		crashes: make(map[string]*CampaignCrash),
+	}
+	for _, bug := range bugs {
+		ret.known[bug.Title] = true
+	}
+	return ret, nil
+}
+
+// Start begins counting the campaign limits. It's expected to be called once the machine check is done.
+func (c *Campaign) Start(now time.Time, execs int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.start = now
+	c.startExecs = execs
+}
+
+// CorpusTriaged records the size of the corpus once all inputs of the initial corpus are triaged.
+// The corpus growth during the campaign is counted from this size.
+func (c *Campaign) CorpusTriaged(corpus int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startCorpus = corpus
+	c.corpusTriaged = true
+}
+
+// Finished returns the reason to stop fuzzing or an empty string if the campaign should go on.
+func (c *Campaign) Finished(now time.Time, execs int) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.start.IsZero() {
+		return ""
+	}
+	if c.cfg.Duration != 0 && now.Sub(c.start) >= c.cfg.Duration {
+		return fmt.Sprintf("fuzzed for %v", c.cfg.Duration)
+	}
+	if c.cfg.Execs != 0 && execs-c.startExecs >= c.cfg.Execs {
+		return fmt.Sprintf("executed %v programs", c.cfg.Execs)
+	}
+	return ""
+}
+
+func (c *Campaign) SaveCrash(crash *Crash) {
+	if crash.Suppressed {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info := c.crashLocked(crash.Title)
+	info.Count++
+	info.Corrupted = info.Corrupted || crash.Corrupted
+}
+
+func (c *Campaign) SaveRepro(res *ReproResult) {
+	if res.Crash.Title == "" {
+		// The crash did not come from our VMs.
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info := c.crashLocked(res.Crash.Title)
+	if res.Repro == nil {
+		info.FailedRepros++
+		return
+	}
+	info.Repro = true
+	info.CRepro = info.CRepro || res.Repro.CRepro
+	if title := res.Repro.Report.Title; title != res.Crash.Title {
+		info.ReproTitle = title
+	}
+}
+
+func (c *Campaign) crashLocked(title string) *CampaignCrash {
+	info := c.crashes[title]
+	if info == nil {
+		info = &CampaignCrash{
+			Title: title,
+			New:   !c.known[title],
+		}
+		c.crashes[title] = info
+	}
+	return info
+}
+
+// WaitRepros blocks until the repro loop has no more work or until the repro timeout expires.
+// Returns the list of the reproductions that did not finish in time.
+func (c *Campaign) WaitRepros(ctx context.Context, loop *ReproLoop) []string {
+	if loop == nil {
+		return nil
+	}
+	if !loop.Started() {
+		// Reproductions start only after the corpus triage, they won't make any progress.
+		return loop.Pending()
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.ReproTimeout)
+	defer cancel()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for !loop.Empty() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return loop.Pending()
+		}
+	}
+	return nil
+}
+
+// Summary builds the final campaign report given the current manager statistics.
+func (c *Campaign) Summary(now time.Time, reason string, execs, coverage, signal, corpus int,
+	pending []string) *CampaignSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ret := &CampaignSummary{
+		StopReason:    reason,
+		Duration:      now.Sub(c.start),
+		Execs:         execs - c.startExecs,
+		Coverage:      coverage,
+		Signal:        signal,
+		CorpusEnd:     corpus,
+		PendingRepros: pending,
+	}
+	if c.corpusTriaged {
+		ret.CorpusTriaged = true
+		ret.CorpusStart = c.startCorpus
+		ret.CorpusDelta = corpus - c.startCorpus
+	}
+	for _, info := range c.crashes {
+		crash := *info
+		ret.Crashes = append(ret.Crashes, &crash)
+		if crash.New {
+			ret.NewBugs++
+		}
+		if crash.Repro {
+			ret.Repros++
+		}
+	}
+	sort.Slice(ret.Crashes, func(i, j int) bool {
+		a, b := ret.Crashes[i], ret.Crashes[j]
+		if a.New != b.New {
+			return a.New
+		}
+		return a.Title < b.Title
+	})
+	return ret
+}
+
+func (s *CampaignSummary) FoundNewBugs() bool {
+	return s.NewBugs != 0
+}
+
+func (s *CampaignSummary) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "campaign finished: %v\n", s.StopReason)
+	fmt.Fprintf(buf, "duration: %v, executions: %v\n", s.Duration.Round(time.Second), s.Execs)
+	fmt.Fprintf(buf, "coverage: %v PCs, signal: %v\n", s.Coverage, s.Signal)
+	if s.CorpusTriaged {
+		fmt.Fprintf(buf, "corpus: %v -> %v (%+d)\n", s.CorpusStart, s.CorpusEnd, s.CorpusDelta)
+	} else {
+		fmt.Fprintf(buf, "corpus: %v (the initial corpus triage has not finished)\n", s.CorpusEnd)
+	}
+	fmt.Fprintf(buf, "crashes: %v, new bugs: %v, reproduced: %v\n", len(s.Crashes), s.NewBugs, s.Repros)
+	for _, crash := range s.Crashes {
+		var flags []string
+		if crash.New {
+			flags = append(flags, "new")
+		}
+		if crash.Corrupted {
+			flags = append(flags, "corrupted")
+		}
+		if crash.CRepro {
+			flags = append(flags, "C repro")
+		} else if crash.Repro {
+			flags = append(flags, "syz repro")
+		}
+		fmt.Fprintf(buf, "\t%v (%v times) %v\n", crash.Title, crash.Count, flags)
+	}
+	for _, title := range s.PendingRepros {
+		fmt.Fprintf(buf, "\tunfinished reproduction: %v\n", title)
+	}
+	return buf.String()
+}
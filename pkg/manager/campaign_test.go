@@ -0,0 +1,143 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/syzkaller/pkg/report"
+	"github.com/google/syzkaller/pkg/repro"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaignConfig(t *testing.T) {
+	assert.Error(t, (&CampaignConfig{}).Validate())
+	assert.Error(t, (&CampaignConfig{Duration: -time.Hour}).Validate())
+	assert.NoError(t, (&CampaignConfig{Duration: time.Hour}).Validate())
+	assert.NoError(t, (&CampaignConfig{Execs: 1000}).Validate())
+}
+
+func TestCampaignLimits(t *testing.T) {
+	store := &CrashStore{BaseDir: t.TempDir(), MaxCrashLogs: 10}
+	c, err := NewCampaign(CampaignConfig{Duration: time.Hour, Execs: 1000}, store)
+	require.NoError(t, err)
+
+	start := time.Now()
+	// Not started yet.
+	assert.Empty(t, c.Finished(start.Add(2*time.Hour), 5000))
+
+	c.Start(start, 100)
+	assert.Empty(t, c.Finished(start.Add(time.Minute), 500))
+	assert.Equal(t, "executed 1000 programs", c.Finished(start.Add(time.Minute), 1100))
+	assert.Equal(t, "fuzzed for 1h0m0s", c.Finished(start.Add(time.Hour), 200))
+}
+
+func TestCampaignSummary(t *testing.T) {
+	store := &CrashStore{BaseDir: t.TempDir(), MaxCrashLogs: 10}
+	_, err := store.SaveCrash(&Crash{Report: &report.Report{
+		Title:  "known bug",
+		Output: []byte("ABCD"),
+	}})
+	require.NoError(t, err)
+
+	c, err := NewCampaign(CampaignConfig{Duration: time.Hour}, store)
+	require.NoError(t, err)
+	start := time.Now()
+	c.Start(start, 0)
+	c.CorpusTriaged(100)
+
+	newBug := &Crash{Report: &report.Report{Title: "new bug"}}
+	c.SaveCrash(&Crash{Report: &report.Report{Title: "known bug"}})
+	c.SaveCrash(newBug)
+	c.SaveCrash(newBug)
+	c.SaveCrash(&Crash{Report: &report.Report{Title: "suppressed report", Suppressed: true}})
+	c.SaveRepro(&ReproResult{
+		Crash: newBug,
+		Repro: &repro.Result{
+			Report: &report.Report{Title: "new bug"},
+			CRepro: true,
+		},
+	})
+	c.SaveRepro(&ReproResult{
+		Crash: &Crash{Report: &report.Report{Title: "known bug"}},
+	})
+	// Repros not originating from the local crashes are ignored.
+	c.SaveRepro(&ReproResult{Crash: &Crash{FromHub: true, Report: &report.Report{}}})
+
+	summary := c.Summary(start.Add(time.Hour), "fuzzed for 1h0m0s", 5000, 300, 400, 120,
+		[]string{"pending bug"})
+	assert.Equal(t, &CampaignSummary{
+		StopReason:    "fuzzed for 1h0m0s",
+		Duration:      time.Hour,
+		Execs:         5000,
+		Coverage:      300,
+		Signal:        400,
+		CorpusTriaged: true,
+		CorpusStart:   100,
+		CorpusEnd:     120,
+		CorpusDelta:   20,
+		Crashes: []*CampaignCrash{
+			{
+				Title:  "new bug",
+				Count:  2,
+				New:    true,
+				Repro:  true,
+				CRepro: true,
+			},
+			{
+				Title:        "known bug",
+				Count:        1,
+				FailedRepros: 1,
+			},
+		},
+		NewBugs:       1,
+		Repros:        1,
+		PendingRepros: []string{"pending bug"},
+	}, summary)
+	assert.True(t, summary.FoundNewBugs())
+	assert.Contains(t, summary.String(), "corpus: 100 -> 120 (+20)\n")
+}
+
+func TestCampaignSummaryNoTriage(t *testing.T) {
+	store := &CrashStore{BaseDir: t.TempDir()}
+	c, err := NewCampaign(CampaignConfig{Duration: time.Hour}, store)
+	require.NoError(t, err)
+	start := time.Now()
+	c.Start(start, 0)
+	summary := c.Summary(start.Add(time.Hour), "fuzzed for 1h0m0s", 5000, 300, 400, 120, nil)
+	assert.False(t, summary.CorpusTriaged)
+	assert.Zero(t, summary.CorpusDelta)
+	assert.Contains(t, summary.String(), "corpus: 120 (the initial corpus triage has not finished)\n")
+}
+
+func TestCampaignWaitRepros(t *testing.T) {
+	store := &CrashStore{BaseDir: t.TempDir()}
+	c, err := NewCampaign(CampaignConfig{Execs: 1, ReproTimeout: time.Hour}, store)
+	require.NoError(t, err)
+
+	mock := &reproMgrMock{run: make(chan runCallback)}
+	loop := NewReproLoop(mock, 1, false)
+	assert.Empty(t, c.WaitRepros(context.Background(), loop))
+
+	// The loop has not started, so we don't wait for the repro timeout.
+	loop.Enqueue(&Crash{Report: &report.Report{Title: "A"}})
+	assert.Equal(t, []string{"A"}, c.WaitRepros(context.Background(), loop))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loop.Loop(ctx)
+	called := <-mock.run
+	assert.Equal(t, "A", called.crash.Title)
+
+	// The reproduction does not finish in time.
+	c.cfg.ReproTimeout = time.Millisecond
+	assert.Equal(t, []string{"A"}, c.WaitRepros(context.Background(), loop))
+
+	called.ret <- &ReproResult{Crash: called.crash}
+	c.cfg.ReproTimeout = time.Minute
+	assert.Empty(t, c.WaitRepros(context.Background(), loop))
+}
@@ -4,6 +4,7 @@
 package manager
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/syzkaller/pkg/hash"
@@ -18,6 +20,7 @@ import (
 	"github.com/google/syzkaller/pkg/mgrconfig"
 	"github.com/google/syzkaller/pkg/osutil"
 	"github.com/google/syzkaller/pkg/report"
+	"github.com/google/syzkaller/pkg/repro"
 	"github.com/google/syzkaller/prog"
 )
 
@@ -26,11 +29,18 @@ type CrashStore struct {
 	BaseDir      string
 	MaxCrashLogs int
 	MaxReproLogs int
+	Retention    CrashRetention
+
+	// Serializes all modifications of the stored crashes (including deletion of the old ones)
+	// and protects the index.
+	mu    sync.Mutex
+	index crashIndex
 }
 
 const reproFileName = "repro.prog"
 const cReproFileName = "repro.cprog"
 const straceFileName = "strace.log"
+const reliabilityFileName = "repro.reliability"
 
 const MaxReproAttempts = 3
 
@@ -40,6 +50,11 @@ func NewCrashStore(cfg *mgrconfig.Config) *CrashStore {
 		BaseDir:      cfg.Workdir,
 		MaxCrashLogs: cfg.MaxCrashLogs,
 		MaxReproLogs: MaxReproAttempts,
+		Retention: CrashRetention{
+			MaxAge:   time.Duration(cfg.CrashRetention.MaxAgeDays) * 24 * time.Hour,
+			MaxCount: cfg.CrashRetention.MaxLogs,
+			MaxBytes: int64(cfg.CrashRetention.MaxMB) << 20,
+		},
 	}
 }
 
@@ -51,6 +66,8 @@ func ReadCrashStore(workdir string) *CrashStore {
 
 // Returns whether it was the first crash of a kind.
 func (cs *CrashStore) SaveCrash(crash *Crash) (bool, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
 	dir := cs.path(crash.Title)
 	osutil.MkdirAll(dir)
 
@@ -88,9 +105,19 @@ func (cs *CrashStore) SaveCrash(crash *Crash) (bool, error) {
 	}
 	reps := append([]*report.Report{crash.Report}, crash.TailReports...)
 	writeOrRemove("log", crash.Output)
+	var logTime time.Time
+	if stat, err := os.Stat(filepath.Join(dir, fmt.Sprintf("log%v", oldestI))); err == nil {
+		logTime = stat.ModTime()
+	}
+	cs.index.saveCrash(crashHash(crash.Title), crash.Title, oldestI, logTime, crash.Output)
 	writeOrRemove("tag", []byte(cs.Tag))
 	writeOrRemove("report", report.MergeReportBytes(reps))
 	writeOrRemove("machineInfo", crash.MachineInfo)
+	var locks []byte
+	if crash.Report.Locks != nil {
+		locks, _ = json.Marshal(crash.Report.Locks)
+	}
+	writeOrRemove("locks", locks)
 	if err := report.AddTitleStat(filepath.Join(dir, "title-stat"), reps); err != nil {
 		return false, fmt.Errorf("report.AddTitleStat: %w", err)
 	}
@@ -113,6 +140,8 @@ func (cs *CrashStore) MoreReproAttempts(title string) bool {
 }
 
 func (cs *CrashStore) SaveFailedRepro(title string, log []byte) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
 	dir := cs.path(title)
 	osutil.MkdirAll(dir)
 	for i := 0; i < cs.MaxReproLogs; i++ {
@@ -129,6 +158,8 @@ func (cs *CrashStore) SaveFailedRepro(title string, log []byte) error {
 }
 
 func (cs *CrashStore) SaveRepro(res *ReproResult, progText, cProgText []byte) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
 	repro := res.Repro
 	rep := repro.Report
 	dir := cs.path(rep.Title)
@@ -140,6 +171,7 @@ func (cs *CrashStore) SaveRepro(res *ReproResult, progText, cProgText []byte) er
 	}
 	// TODO: detect and handle errors below as well.
 	osutil.WriteFile(filepath.Join(dir, reproFileName), progText)
+	cs.index.saveRepro(crashHash(rep.Title), rep.Title, len(cProgText) > 0)
 	if cs.Tag != "" {
 		osutil.WriteFile(filepath.Join(dir, "repro.tag"), []byte(cs.Tag))
 	}
@@ -175,9 +207,25 @@ func (cs *CrashStore) SaveRepro(res *ReproResult, progText, cProgText []byte) er
 	if reproLog := res.Stats.FullLog(); len(reproLog) > 0 {
 		osutil.WriteFile(filepath.Join(dir, "repro.stats"), reproLog)
 	}
+	if repro.SyzReliability != nil {
+		data, err := json.Marshal(&ReproReliability{
+			Syz: repro.SyzReliability,
+			C:   repro.CReliability,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to serialize reliability: %w", err)
+		}
+		osutil.WriteFile(filepath.Join(dir, reliabilityFileName), data)
+	}
 	return nil
 }
 
+// ReproReliability is the result of the validation runs of the stored reproducers.
+type ReproReliability struct {
+	Syz *repro.Reliability
+	C   *repro.Reliability `json:",omitempty"`
+}
+
 type BugReport struct {
 	Title  string
 	Tag    string
@@ -225,6 +273,10 @@ type BugInfo struct {
 	ReproAttempts int
 	Crashes       []*CrashInfo
 	Rank          int
+	// Lock dependencies from the most recent crash (only set if full=true).
+	Locks *report.LockInfo
+	// Reliability of the reproducers (only set if full=true).
+	Reliability *ReproReliability
 }
 
 func (cs *CrashStore) BugInfo(id string, full bool) (*BugInfo, error) {
@@ -271,6 +323,8 @@ func (cs *CrashStore) BugInfo(id string, full bool) (*BugInfo, error) {
 			ret.HasCRepro = true
 		} else if f == straceFileName {
 			ret.StraceFile = filepath.Join(dir, f)
+		} else if f == reliabilityFileName {
+			continue
 		} else if strings.HasPrefix(f, "repro") {
 			ret.ReproAttempts++
 		}
@@ -292,6 +346,21 @@ func (cs *CrashStore) BugInfo(id string, full bool) (*BugInfo, error) {
 	sort.Slice(ret.Crashes, func(i, j int) bool {
 		return ret.Crashes[i].Time.After(ret.Crashes[j].Time)
 	})
+	if data, err := os.ReadFile(filepath.Join(dir, reliabilityFileName)); err == nil {
+		ret.Reliability = new(ReproReliability)
+		if err := json.Unmarshal(data, ret.Reliability); err != nil {
+			ret.Reliability = nil
+		}
+	}
+	if len(ret.Crashes) != 0 {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("locks%d", ret.Crashes[0].Index)))
+		if err == nil {
+			ret.Locks = new(report.LockInfo)
+			if err := json.Unmarshal(data, ret.Locks); err != nil {
+				ret.Locks = nil
+			}
+		}
+	}
 	return ret, nil
 }
 
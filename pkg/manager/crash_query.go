@@ -0,0 +1,370 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/pkg/report"
+	"github.com/google/syzkaller/pkg/report/crash"
+)
+
+// CrashQuery selects the stored crashes. Zero values of the fields match everything.
+type CrashQuery struct {
+	Title *regexp.Regexp
+	Type  crash.Type
+	Repro ReproStatus
+	// Since and Until restrict the time of the individual crashes.
+	Since time.Time
+	Until time.Time
+	// Syscall must appear in one of the programs executed before the crash.
+	Syscall string
+}
+
+type ReproStatus string
+
+const (
+	ReproStatusAny  ReproStatus = ""
+	ReproStatusNone ReproStatus = "none"
+	ReproStatusSyz  ReproStatus = "syz"
+	ReproStatusC    ReproStatus = "c"
+)
+
+func ParseReproStatus(str string) (ReproStatus, error) {
+	switch status := ReproStatus(str); status {
+	case ReproStatusAny, ReproStatusNone, ReproStatusSyz, ReproStatusC:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown repro status %q, expected none/syz/c", str)
+}
+
+// ParseCrashQuery constructs a query from the textual representation of its fields
+// (e.g. from command line flags or HTTP form values). Empty strings match everything.
+func ParseCrashQuery(title, typ, repro, since, until, syscall string) (CrashQuery, error) {
+	query := CrashQuery{
+		Type:    crash.Type(typ),
+		Syscall: syscall,
+	}
+	var err error
+	if title != "" {
+		if query.Title, err = regexp.Compile(title); err != nil {
+			return query, fmt.Errorf("bad title regexp: %w", err)
+		}
+	}
+	if query.Repro, err = ParseReproStatus(repro); err != nil {
+		return query, err
+	}
+	if query.Since, err = parseQueryTime(since); err != nil {
+		return query, err
+	}
+	if query.Until, err = parseQueryTime(until); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+var queryTimeFormats = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"}
+
+func parseQueryTime(str string) (time.Time, error) {
+	if str == "" {
+		return time.Time{}, nil
+	}
+	for _, format := range queryTimeFormats {
+		if t, err := time.ParseInLocation(format, str, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad time %q, expected one of the formats %q", str, queryTimeFormats)
+}
+
+// Query returns the bugs that match the query.
+// Crashes of the returned bugs are limited to the crashes that match the time range and the syscall.
+// The bugs are selected using the crash index, only the matching bugs are read from the workdir.
+func (cs *CrashStore) Query(query CrashQuery) ([]*BugInfo, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err := cs.loadIndexLocked(); err != nil {
+		return nil, err
+	}
+	var ret []*BugInfo
+	for id, bug := range cs.index.bugs {
+		if query.Title != nil && !query.Title.MatchString(bug.title) ||
+			query.Type != crash.UnknownType && report.TitleToCrashType(bug.title) != query.Type ||
+			!query.matchRepro(bug) {
+			continue
+		}
+		matching := make(map[int]bool)
+		for index, crash := range bug.crashes {
+			if !query.Since.IsZero() && crash.time.Before(query.Since) ||
+				!query.Until.IsZero() && crash.time.After(query.Until) ||
+				query.Syscall != "" && !crash.syscalls[query.Syscall] {
+				continue
+			}
+			matching[index] = true
+		}
+		if len(matching) == 0 && (len(bug.crashes) != 0 || !query.Since.IsZero() ||
+			!query.Until.IsZero() || query.Syscall != "") {
+			continue
+		}
+		info, err := cs.BugInfo(id, true)
+		if err != nil {
+			return nil, err
+		}
+		var crashes []*CrashInfo
+		for _, crash := range info.Crashes {
+			if matching[crash.Index] {
+				crashes = append(crashes, crash)
+			}
+		}
+		info.Crashes = crashes
+		ret = append(ret, info)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return strings.ToLower(ret[i].Title) < strings.ToLower(ret[j].Title)
+	})
+	return ret, nil
+}
+
+func (query *CrashQuery) matchRepro(bug *indexedBug) bool {
+	switch query.Repro {
+	case ReproStatusNone:
+		return !bug.hasRepro
+	case ReproStatusSyz:
+		return bug.hasRepro
+	case ReproStatusC:
+		return bug.hasCRepro
+	}
+	return true
+}
+
+// crashIndex keeps the properties of the stored bugs and crashes that are used by queries.
+// It's loaded from the workdir on the first query and is then updated by the CrashStore methods
+// that modify the workdir. All accesses are protected by CrashStore.mu.
+type crashIndex struct {
+	// Keyed by bug ID, nil if the index is not loaded yet.
+	bugs map[string]*indexedBug
+}
+
+type indexedBug struct {
+	title     string
+	hasRepro  bool
+	hasCRepro bool
+	crashes   map[int]*indexedCrash
+}
+
+type indexedCrash struct {
+	time time.Time
+	// Syscalls executed before the crash.
+	syscalls map[string]bool
+}
+
+func (cs *CrashStore) loadIndexLocked() error {
+	if cs.index.bugs != nil {
+		return nil
+	}
+	list, err := cs.BugList()
+	if err != nil {
+		return err
+	}
+	bugs := make(map[string]*indexedBug)
+	for _, bug := range list {
+		info, err := cs.BugInfo(bug.ID, true)
+		if err != nil {
+			return err
+		}
+		ent := &indexedBug{
+			title:     info.Title,
+			hasRepro:  info.HasRepro,
+			hasCRepro: info.HasCRepro,
+			crashes:   make(map[int]*indexedCrash),
+		}
+		for _, crash := range info.Crashes {
+			data, err := os.ReadFile(filepath.Join(cs.BaseDir, crash.Log))
+			if err != nil {
+				return err
+			}
+			ent.crashes[crash.Index] = &indexedCrash{
+				time:     crash.Time,
+				syscalls: logSyscalls(data),
+			}
+		}
+		bugs[bug.ID] = ent
+	}
+	cs.index.bugs = bugs
+	return nil
+}
+
+func (idx *crashIndex) bug(id, title string) *indexedBug {
+	bug := idx.bugs[id]
+	if bug == nil {
+		bug = &indexedBug{
+			title:   title,
+			crashes: make(map[int]*indexedCrash),
+		}
+		idx.bugs[id] = bug
+	}
+	return bug
+}
+
+func (idx *crashIndex) saveCrash(id, title string, index int, when time.Time, log []byte) {
+	if idx.bugs == nil {
+		return
+	}
+	bug := idx.bug(id, title)
+	if len(log) == 0 {
+		delete(bug.crashes, index)
+		return
+	}
+	bug.crashes[index] = &indexedCrash{
+		time:     when,
+		syscalls: logSyscalls(log),
+	}
+}
+
+func (idx *crashIndex) saveRepro(id, title string, hasCRepro bool) {
+	if idx.bugs == nil {
+		return
+	}
+	bug := idx.bug(id, title)
+	bug.hasRepro = true
+	bug.hasCRepro = bug.hasCRepro || hasCRepro
+}
+
+func (idx *crashIndex) removeCrash(id string, index int) {
+	if bug := idx.bugs[id]; bug != nil {
+		delete(bug.crashes, index)
+	}
+}
+
+func (idx *crashIndex) removeBug(id string) {
+	delete(idx.bugs, id)
+}
+
+var (
+	logProgramRe = regexp.MustCompile(`executing program [0-9]+`)
+	logCallRe    = regexp.MustCompile(`^(?:r[0-9]+ = )?([a-zA-Z0-9_]+(?:\$[a-zA-Z0-9_]+)?)\(`)
+)
+
+// logSyscalls returns names of the syscalls used in the programs in the crash log.
+// The log is not deserialized since the crash store does not know the target.
+func logSyscalls(data []byte) map[string]bool {
+	ret := make(map[string]bool)
+	inProgram := false
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, 64<<20)
+	for s.Scan() {
+		line := s.Bytes()
+		if logProgramRe.Match(line) {
+			inProgram = true
+			continue
+		}
+		if !inProgram {
+			continue
+		}
+		match := logCallRe.FindSubmatch(line)
+		if match == nil {
+			inProgram = false
+			continue
+		}
+		ret[string(match[1])] = true
+	}
+	return ret
+}
+
+// CrashRetention limits the amount of the stored crash logs. Zero values mean no limit.
+type CrashRetention struct {
+	MaxAge   time.Duration
+	MaxCount int
+	MaxBytes int64
+}
+
+type RetentionStats struct {
+	Crashes int
+	Bytes   int64
+	// Bugs whose directories were deleted completely.
+	Bugs int
+}
+
+// Files that are stored for every crash with the crash index suffix.
+var crashFiles = []string{"log", "tag", "report", "machineInfo", "locks"}
+
+// ApplyRetention deletes the oldest crash logs that violate the retention policy.
+// Bugs that are left without crash logs are deleted as well, unless they have a reproducer.
+func (cs *CrashStore) ApplyRetention(policy CrashRetention, now time.Time) (RetentionStats, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var stats RetentionStats
+	list, err := cs.BugList()
+	if err != nil {
+		return stats, err
+	}
+	type storedCrash struct {
+		bug   *BugInfo
+		index int
+		time  time.Time
+		size  int64
+	}
+	var all []*storedCrash
+	var total int64
+	for _, bug := range list {
+		info, err := cs.BugInfo(bug.ID, true)
+		if err != nil {
+			return stats, err
+		}
+		for _, crash := range info.Crashes {
+			sc := &storedCrash{bug: info, index: crash.Index, time: crash.Time}
+			for _, name := range crashFiles {
+				if stat, err := os.Stat(cs.crashFile(info.ID, name, crash.Index)); err == nil {
+					sc.size += stat.Size()
+				}
+			}
+			total += sc.size
+			all = append(all, sc)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].time.Before(all[j].time)
+	})
+	count := len(all)
+	remaining := make(map[string]int)
+	for _, sc := range all {
+		remaining[sc.bug.ID]++
+	}
+	for _, sc := range all {
+		if (policy.MaxAge == 0 || now.Sub(sc.time) <= policy.MaxAge) &&
+			(policy.MaxCount == 0 || count <= policy.MaxCount) &&
+			(policy.MaxBytes == 0 || total <= policy.MaxBytes) {
+			break
+		}
+		for _, name := range crashFiles {
+			os.Remove(cs.crashFile(sc.bug.ID, name, sc.index))
+		}
+		cs.index.removeCrash(sc.bug.ID, sc.index)
+		count--
+		total -= sc.size
+		stats.Crashes++
+		stats.Bytes += sc.size
+		remaining[sc.bug.ID]--
+		if remaining[sc.bug.ID] == 0 && !sc.bug.HasRepro {
+			if err := os.RemoveAll(filepath.Join(cs.BaseDir, "crashes", sc.bug.ID)); err != nil {
+				return stats, err
+			}
+			cs.index.removeBug(sc.bug.ID)
+			stats.Bugs++
+		}
+	}
+	return stats, nil
+}
+
+func (cs *CrashStore) crashFile(id, name string, index int) string {
+	return filepath.Join(cs.BaseDir, "crashes", id, fmt.Sprintf("%v%v", name, index))
+}
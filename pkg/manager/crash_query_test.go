@@ -0,0 +1,206 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/syzkaller/pkg/report"
+	"github.com/google/syzkaller/pkg/report/crash"
+	"github.com/google/syzkaller/pkg/repro"
+	"github.com/google/syzkaller/prog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrashQuery(t *testing.T) {
+	cs := &CrashStore{
+		BaseDir:      t.TempDir(),
+		MaxCrashLogs: 10,
+	}
+	now := time.Now()
+	saveTestCrash(t, cs, "KASAN: use-after-free Read in foo", now.Add(-48*time.Hour),
+		"executing program 0:\nr0 = openat(0xffffffffffffff9c, &(0x7f0000000000)='./file0\\x00', 0x0, 0x0)\n"+
+			"read(r0, 0x0, 0x0)\nBUG: KASAN: use-after-free in foo\n")
+	saveTestCrash(t, cs, "KASAN: use-after-free Read in foo", now.Add(-time.Hour),
+		"executing program 1:\nioctl$KVM_RUN(0xffffffffffffffff, 0xae80, 0x0)\n")
+	saveTestCrash(t, cs, "WARNING in bar", now.Add(-time.Hour),
+		"executing program 3:\nwrite(0xffffffffffffffff, 0x0, 0x0)\nnot a call\nread(0x1, 0x0, 0x0)\n")
+
+	query := func(title, typ, repro, since, until, syscall string) map[string]int {
+		q, err := ParseCrashQuery(title, typ, repro, since, until, syscall)
+		require.NoError(t, err)
+		list, err := cs.Query(q)
+		require.NoError(t, err)
+		ret := make(map[string]int)
+		for _, bug := range list {
+			ret[bug.Title] = len(bug.Crashes)
+		}
+		return ret
+	}
+	all := map[string]int{"KASAN: use-after-free Read in foo": 2, "WARNING in bar": 1}
+	assert.Equal(t, all, query("", "", "", "", "", ""))
+	assert.Equal(t, map[string]int{"WARNING in bar": 1}, query("^WARNING", "", "", "", "", ""))
+	assert.Equal(t, map[string]int{"KASAN: use-after-free Read in foo": 2},
+		query("", string(crash.KASANUseAfterFreeRead), "", "", "", ""))
+	assert.Equal(t, all, query("", "", "none", "", "", ""))
+	assert.Empty(t, query("", "", "syz", "", "", ""))
+	since := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	assert.Equal(t, map[string]int{"KASAN: use-after-free Read in foo": 1, "WARNING in bar": 1},
+		query("", "", "", since, "", ""))
+	assert.Equal(t, map[string]int{"KASAN: use-after-free Read in foo": 1}, query("", "", "", "", since, ""))
+	assert.Equal(t, map[string]int{"KASAN: use-after-free Read in foo": 1}, query("", "", "", "", "", "openat"))
+	assert.Equal(t, map[string]int{"KASAN: use-after-free Read in foo": 1},
+		query("", "", "", "", "", "ioctl$KVM_RUN"))
+	// The call after a non-program line does not belong to a program.
+	assert.Equal(t, map[string]int{"KASAN: use-after-free Read in foo": 1}, query("", "", "", "", "", "read"))
+	assert.Empty(t, query("", "", "", "", "", "mmap"))
+
+	_, err := ParseCrashQuery("(", "", "", "", "", "")
+	assert.Error(t, err)
+	_, err = ParseCrashQuery("", "", "maybe", "", "", "")
+	assert.Error(t, err)
+	_, err = ParseCrashQuery("", "", "", "yesterday", "", "")
+	assert.Error(t, err)
+}
+
+func TestCrashIndexUpdates(t *testing.T) {
+	cs := &CrashStore{
+		BaseDir:      t.TempDir(),
+		MaxCrashLogs: 2,
+	}
+	saveTestCrash(t, cs, "title A", time.Now().Add(-48*time.Hour), "executing program 0:\nopen(0x0, 0x0, 0x0)\n")
+	saveTestCrash(t, cs, "title B", time.Now().Add(-48*time.Hour), "executing program 0:\nread(0x0, 0x0, 0x0)\n")
+	// Load the index.
+	q, err := ParseCrashQuery("", "", "", "", "", "")
+	require.NoError(t, err)
+	_, err = cs.Query(q)
+	require.NoError(t, err)
+	require.NotNil(t, cs.index.bugs)
+
+	// Modify the store after the index is loaded.
+	for i := 0; i < 3; i++ {
+		_, err := cs.SaveCrash(&Crash{Report: &report.Report{
+			Title:  "title A",
+			Output: []byte(fmt.Sprintf("executing program 0:\nwrite$%v(0x0, 0x0, 0x0)\n", i)),
+		}})
+		require.NoError(t, err)
+	}
+	_, err = cs.SaveCrash(&Crash{Report: &report.Report{
+		Title:  "title C",
+		Output: []byte("executing program 0:\nmmap(0x0, 0x0, 0x0)\n"),
+	}})
+	require.NoError(t, err)
+	require.NoError(t, cs.SaveRepro(&ReproResult{
+		Repro: &repro.Result{
+			Report: &report.Report{Title: "title C"},
+			Prog:   &prog.Prog{},
+		},
+	}, []byte("prog"), []byte("cprog")))
+	_, err = cs.ApplyRetention(CrashRetention{MaxAge: 24 * time.Hour}, time.Now())
+	require.NoError(t, err)
+
+	// The updated index must give the same results as the one loaded from scratch.
+	fresh := &CrashStore{BaseDir: cs.BaseDir}
+	for _, args := range [][6]string{
+		{"", "", "", "", "", ""},
+		{"", "", "c", "", "", ""},
+		{"", "", "none", "", "", ""},
+		{"", "", "", "", "", "write$0"},
+		{"", "", "", "", "", "write$2"},
+		{"", "", "", "", "", "read"},
+		{"", "", "", "", "", "mmap"},
+	} {
+		q, err := ParseCrashQuery(args[0], args[1], args[2], args[3], args[4], args[5])
+		require.NoError(t, err)
+		got, err := cs.Query(q)
+		require.NoError(t, err)
+		want, err := fresh.Query(q)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "query %q", args)
+	}
+	assert.Equal(t, fresh.index.bugs, cs.index.bugs)
+	assert.Len(t, cs.index.bugs, 2)
+}
+
+func TestCrashRetention(t *testing.T) {
+	cs := &CrashStore{
+		BaseDir:      t.TempDir(),
+		MaxCrashLogs: 10,
+	}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		saveTestCrash(t, cs, "title A", now.Add(-time.Duration(10+i)*24*time.Hour), "AAAA")
+	}
+	for i := 0; i < 3; i++ {
+		saveTestCrash(t, cs, "title B", now.Add(-time.Duration(i)*time.Hour), "BBBB")
+	}
+	saveTestCrash(t, cs, "title C", now.Add(-20*24*time.Hour), "CCCC")
+	require.NoError(t, os.WriteFile(filepath.Join(cs.path("title C"), reproFileName), []byte("prog"), 0644))
+
+	// All crashes have the same size.
+	var crashSize int64
+	for _, name := range crashFiles {
+		if stat, err := os.Stat(cs.crashFile(crashHash("title A"), name, 0)); err == nil {
+			crashSize += stat.Size()
+		}
+	}
+
+	// Nothing to delete.
+	stats, err := cs.ApplyRetention(CrashRetention{MaxAge: 30 * 24 * time.Hour, MaxCount: 10}, now)
+	require.NoError(t, err)
+	assert.Equal(t, RetentionStats{}, stats)
+
+	// Age: all crashes of A and C go away, C is kept since it has a reproducer.
+	stats, err = cs.ApplyRetention(CrashRetention{MaxAge: 7 * 24 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, RetentionStats{Crashes: 4, Bytes: 4 * crashSize, Bugs: 1}, stats)
+	list, err := cs.BugList()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "title B", list[0].Title)
+	assert.Len(t, list[0].Crashes, 3)
+	assert.Equal(t, "title C", list[1].Title)
+	assert.Empty(t, list[1].Crashes)
+
+	// Count: the oldest crashes are deleted first.
+	stats, err = cs.ApplyRetention(CrashRetention{MaxCount: 2}, now)
+	require.NoError(t, err)
+	assert.Equal(t, RetentionStats{Crashes: 1, Bytes: crashSize}, stats)
+	info, err := cs.BugInfo(crashHash("title B"), true)
+	require.NoError(t, err)
+	require.Len(t, info.Crashes, 2)
+	assert.True(t, info.Crashes[1].Time.After(now.Add(-90*time.Minute)))
+
+	// Size.
+	stats, err = cs.ApplyRetention(CrashRetention{MaxBytes: 1}, now)
+	require.NoError(t, err)
+	assert.Equal(t, RetentionStats{Crashes: 2, Bytes: 2 * crashSize, Bugs: 1}, stats)
+	list, err = cs.BugList()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "title C", list[0].Title)
+}
+
+func saveTestCrash(t *testing.T, cs *CrashStore, title string, when time.Time, output string) {
+	_, err := cs.SaveCrash(&Crash{Report: &report.Report{
+		Title:  title,
+		Output: []byte(output),
+	}})
+	require.NoError(t, err)
+	// SaveCrash overwrites the oldest log, so the just saved log is the newest one.
+	info, err := cs.BugInfo(crashHash(title), false)
+	require.NoError(t, err)
+	newest := info.Crashes[0]
+	for _, crash := range info.Crashes {
+		if crash.Time.After(newest.Time) {
+			newest = crash
+		}
+	}
+	require.NoError(t, os.Chtimes(cs.crashFile(crashHash(title), "log", newest.Index), when, when))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/google/syzkaller/pkg/repro"
 	"github.com/google/syzkaller/prog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCrashList(t *testing.T) {
@@ -53,6 +54,34 @@ func TestCrashList(t *testing.T) {
 	assert.Len(t, list[2].Crashes, 3)
 }
 
+func TestCrashLocks(t *testing.T) {
+	crashStore := &CrashStore{
+		BaseDir:      t.TempDir(),
+		MaxCrashLogs: 10,
+	}
+	locks := &report.LockInfo{
+		Chain: []string{"rtnl_mutex", "&xt[i].mutex"},
+		Holders: []report.LockHolder{{
+			Task:  "syz-executor/5807",
+			Locks: []report.HeldLock{{Class: "rtnl_mutex", Func: "rtnl_lock"}},
+		}},
+	}
+	_, err := crashStore.SaveCrash(&Crash{Report: &report.Report{
+		Title:  "possible deadlock in rtnl_lock",
+		Output: []byte("ABCD"),
+		Locks:  locks,
+	}})
+	assert.NoError(t, err)
+
+	list, err := crashStore.BugList()
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Nil(t, list[0].Locks)
+	info, err := crashStore.BugInfo(list[0].ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, locks, info.Locks)
+}
+
 func TestEmptyCrashList(t *testing.T) {
 	crashStore := &CrashStore{
 		BaseDir:      t.TempDir(),
@@ -100,7 +129,9 @@ func TestCrashRepro(t *testing.T) {
 				Title:  "Some title",
 				Report: []byte("Some report"),
 			},
-			Prog: &prog.Prog{},
+			Prog:           &prog.Prog{},
+			SyzReliability: &repro.Reliability{Runs: 10, Crashes: 3, Low: 0.11, High: 0.6},
+			CReliability:   &repro.Reliability{Runs: 10, Crashes: 10, Low: 0.72, High: 1},
 		},
 	}, []byte("prog text"), []byte("c prog text"))
 	assert.NoError(t, err)
@@ -112,4 +143,10 @@ func TestCrashRepro(t *testing.T) {
 	assert.Equal(t, []byte("prog text"), report.Prog)
 	assert.Equal(t, []byte("c prog text"), report.CProg)
 	assert.Equal(t, []byte("Some report"), report.Report)
+
+	info, err := crashStore.BugInfo(crashHash("Some title"), true)
+	require.NoError(t, err)
+	require.NotNil(t, info.Reliability)
+	assert.Equal(t, 3, info.Reliability.Syz.Crashes)
+	assert.Equal(t, 1.0, info.Reliability.C.Rate())
 }
@@ -0,0 +1,242 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/pkg/stat"
+)
+
+// HealthMonitor periodically samples the fuzzing statistics and detects the situations
+// when fuzzing does not make progress: coverage plateaus, falling execution rate,
+// VMs that restart too often or fail to boot.
+type HealthMonitor struct {
+	inputs     HealthInputs
+	thresholds HealthThresholds
+	remedies   map[HealthProblem][]*healthRemedy
+
+	mu      sync.Mutex
+	samples []healthSample
+	alerts  map[HealthProblem]*HealthAlert
+}
+
+type HealthProblem string
+
+const (
+	HealthCoveragePlateau HealthProblem = "coverage plateau"
+	HealthExecRateDrop    HealthProblem = "falling exec rate"
+	HealthVMRestarts      HealthProblem = "frequent VM restarts"
+	HealthBootFailures    HealthProblem = "boot failures"
+)
+
+// HealthInputs are the statistics the monitor is based on.
+type HealthInputs struct {
+	Signal     *stat.Val
+	Execs      *stat.Val
+	VMRestarts *stat.Val
+	BootErrors *stat.Val
+	VMs        int
+}
+
+type HealthThresholds struct {
+	// Coverage has plateaued if the signal has not grown during this period.
+	PlateauPeriod time.Duration
+	// The execution rate and VM restarts are calculated over windows of this size.
+	Window time.Duration
+	// The execution rate is falling if it's below this fraction of the best rate seen so far.
+	MinExecRateRatio float64
+	// VMs restart too often if they restart more than this many times per VM per window.
+	MaxRestartsPerVM float64
+	// Too many boots fail if the ratio of boot failures exceeds this value.
+	// The check is only done if there were at least MinBoots boot attempts in the window.
+	MaxBootFailureRatio float64
+	MinBoots            int
+	// The same remedy is not applied more often than this.
+	RemedyCooldown time.Duration
+}
+
+func DefaultHealthThresholds() HealthThresholds {
+	return HealthThresholds{
+		PlateauPeriod:       6 * time.Hour,
+		Window:              time.Hour,
+		MinExecRateRatio:    0.5,
+		MaxRestartsPerVM:    10,
+		MaxBootFailureRatio: 0.5,
+		MinBoots:            10,
+		RemedyCooldown:      6 * time.Hour,
+	}
+}
+
+type HealthAlert struct {
+	Problem HealthProblem
+	Message string
+	Since   time.Time
+	// The names of the remedies applied since the problem was detected.
+	Remedies []string
+}
+
+type healthSample struct {
+	time       time.Time
+	signal     int
+	execs      int
+	restarts   int
+	bootErrors int
+}
+
+type healthRemedy struct {
+	name    string
+	fn      func()
+	lastRun time.Time
+}
+
+func NewHealthMonitor(inputs HealthInputs, thresholds HealthThresholds) *HealthMonitor {
+	return &HealthMonitor{
+		inputs:     inputs,
+		thresholds: thresholds,
+		remedies:   make(map[HealthProblem][]*healthRemedy),
+		alerts:     make(map[HealthProblem]*HealthAlert),
+	}
+}
+
+// AddRemedy registers an action that is run when the problem is detected.
+// Must be called before Loop.
+func (hm *HealthMonitor) AddRemedy(problem HealthProblem, name string, fn func()) {
+	hm.remedies[problem] = append(hm.remedies[problem], &healthRemedy{
+		name: name,
+		fn:   fn,
+	})
+}
+
+func (hm *HealthMonitor) Loop(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			hm.Tick(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Tick takes a new sample of the statistics, updates the alerts and runs the necessary remedies.
+func (hm *HealthMonitor) Tick(now time.Time) {
+	sample := healthSample{
+		time:       now,
+		signal:     hm.inputs.Signal.Val(),
+		execs:      hm.inputs.Execs.Val(),
+		restarts:   hm.inputs.VMRestarts.Val(),
+		bootErrors: hm.inputs.BootErrors.Val(),
+	}
+	hm.mu.Lock()
+	hm.samples = append(hm.samples, sample)
+	// Keep enough history to notice the plateau, and a day to remember the best exec rate.
+	keep := max(hm.thresholds.PlateauPeriod, 24*time.Hour) + hm.thresholds.Window
+	for len(hm.samples) > 1 && now.Sub(hm.samples[0].time) > keep {
+		hm.samples = hm.samples[1:]
+	}
+	problems := hm.detectLocked()
+	for problem, alert := range hm.alerts {
+		if _, ok := problems[problem]; !ok {
+			log.Logf(0, "health: %v is resolved", problem)
+			delete(hm.alerts, alert.Problem)
+		}
+	}
+	var remedies []*healthRemedy
+	for problem, msg := range problems {
+		alert := hm.alerts[problem]
+		if alert == nil {
+			log.Logf(0, "health: %v: %v", problem, msg)
+			alert = &HealthAlert{
+				Problem: problem,
+				Since:   now,
+			}
+			hm.alerts[problem] = alert
+		}
+		alert.Message = msg
+		for _, remedy := range hm.remedies[problem] {
+			if !remedy.lastRun.IsZero() && now.Sub(remedy.lastRun) < hm.thresholds.RemedyCooldown {
+				continue
+			}
+			remedy.lastRun = now
+			alert.Remedies = append(alert.Remedies, remedy.name)
+			remedies = append(remedies, remedy)
+		}
+	}
+	hm.mu.Unlock()
+	for _, remedy := range remedies {
+		log.Logf(0, "health: applying %v", remedy.name)
+		remedy.fn()
+	}
+}
+
+func (hm *HealthMonitor) detectLocked() map[HealthProblem]string {
+	ret := make(map[HealthProblem]string)
+	cur := hm.samples[len(hm.samples)-1]
+	if old := hm.sampleAtLocked(cur.time.Add(-hm.thresholds.PlateauPeriod)); old != nil &&
+		cur.signal <= old.signal {
+		ret[HealthCoveragePlateau] = fmt.Sprintf("no new signal for %v (signal %v)",
+			hm.thresholds.PlateauPeriod, cur.signal)
+	}
+	prev := hm.sampleAtLocked(cur.time.Add(-hm.thresholds.Window))
+	if prev == nil {
+		return ret
+	}
+	window := hm.thresholds.Window
+	execs, best := cur.execs-prev.execs, 0
+	for i := range hm.samples {
+		if base := hm.sampleAtLocked(hm.samples[i].time.Add(-window)); base != nil {
+			best = max(best, hm.samples[i].execs-base.execs)
+		}
+	}
+	if best > 0 && float64(execs) < float64(best)*hm.thresholds.MinExecRateRatio {
+		ret[HealthExecRateDrop] = fmt.Sprintf("%v execs in the last %v (best %v)", execs, window, best)
+	}
+	restarts := cur.restarts - prev.restarts
+	if hm.inputs.VMs > 0 && float64(restarts) > hm.thresholds.MaxRestartsPerVM*float64(hm.inputs.VMs) {
+		ret[HealthVMRestarts] = fmt.Sprintf("%v VM restarts in the last %v (%v VMs)",
+			restarts, window, hm.inputs.VMs)
+	}
+	bootErrors := cur.bootErrors - prev.bootErrors
+	boots := restarts + bootErrors
+	if boots >= hm.thresholds.MinBoots &&
+		float64(bootErrors) > float64(boots)*hm.thresholds.MaxBootFailureRatio {
+		ret[HealthBootFailures] = fmt.Sprintf("%v out of %v boots failed in the last %v",
+			bootErrors, boots, window)
+	}
+	return ret
+}
+
+// sampleAtLocked returns the latest sample taken not after the given time.
+func (hm *HealthMonitor) sampleAtLocked(t time.Time) *healthSample {
+	idx := sort.Search(len(hm.samples), func(i int) bool {
+		return hm.samples[i].time.After(t)
+	})
+	if idx == 0 {
+		return nil
+	}
+	return &hm.samples[idx-1]
+}
+
+// Alerts returns the currently active alerts.
+func (hm *HealthMonitor) Alerts() []HealthAlert {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	var ret []HealthAlert
+	for _, alert := range hm.alerts {
+		ret = append(ret, *alert)
+		ret[len(ret)-1].Remedies = append([]string(nil), alert.Remedies...)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Problem < ret[j].Problem
+	})
+	return ret
+}
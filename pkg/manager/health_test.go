@@ -0,0 +1,106 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/syzkaller/pkg/stat"
+	"github.com/stretchr/testify/assert"
+)
+
+type healthTest struct {
+	signal, execs, restarts, bootErrors *stat.Val
+	monitor                             *HealthMonitor
+	now                                 time.Time
+}
+
+func newHealthTest(t *testing.T) *healthTest {
+	ht := &healthTest{
+		signal:     stat.New(t.Name()+" signal", "", stat.NoGraph),
+		execs:      stat.New(t.Name()+" execs", "", stat.NoGraph),
+		restarts:   stat.New(t.Name()+" restarts", "", stat.NoGraph),
+		bootErrors: stat.New(t.Name()+" boot errors", "", stat.NoGraph),
+		now:        time.Now(),
+	}
+	ht.monitor = NewHealthMonitor(HealthInputs{
+		Signal:     ht.signal,
+		Execs:      ht.execs,
+		VMRestarts: ht.restarts,
+		BootErrors: ht.bootErrors,
+		VMs:        2,
+	}, DefaultHealthThresholds())
+	return ht
+}
+
+// run emulates the given number of minutes with the per-minute stat increments.
+func (ht *healthTest) run(minutes, signal, execs, restarts, bootErrors int) {
+	for i := 0; i < minutes; i++ {
+		ht.signal.Add(signal)
+		ht.execs.Add(execs)
+		ht.restarts.Add(restarts)
+		ht.bootErrors.Add(bootErrors)
+		ht.now = ht.now.Add(time.Minute)
+		ht.monitor.Tick(ht.now)
+	}
+}
+
+func (ht *healthTest) problems() []HealthProblem {
+	var ret []HealthProblem
+	for _, alert := range ht.monitor.Alerts() {
+		ret = append(ret, alert.Problem)
+	}
+	return ret
+}
+
+func TestHealthPlateau(t *testing.T) {
+	ht := newHealthTest(t)
+	rotations := 0
+	ht.monitor.AddRemedy(HealthCoveragePlateau, "rotation", func() { rotations++ })
+
+	ht.run(60, 10, 1000, 0, 0)
+	assert.Empty(t, ht.problems())
+	// The signal no longer grows, but it's not yet a plateau.
+	ht.run(5*60, 0, 1000, 0, 0)
+	assert.Empty(t, ht.problems())
+	ht.run(60, 0, 1000, 0, 0)
+	assert.Equal(t, []HealthProblem{HealthCoveragePlateau}, ht.problems())
+	assert.Equal(t, 1, rotations)
+	// The remedy is not re-applied until the cooldown period passes.
+	ht.run(60, 0, 1000, 0, 0)
+	assert.Equal(t, 1, rotations)
+	alerts := ht.monitor.Alerts()
+	assert.Equal(t, []string{"rotation"}, alerts[0].Remedies)
+	assert.Equal(t, "no new signal for 6h0m0s (signal 600)", alerts[0].Message)
+	// The signal grows again.
+	ht.run(1, 1, 1000, 0, 0)
+	assert.Empty(t, ht.problems())
+}
+
+func TestHealthExecRate(t *testing.T) {
+	ht := newHealthTest(t)
+	ht.run(120, 1, 1000, 0, 0)
+	assert.Empty(t, ht.problems())
+	ht.run(30, 1, 700, 0, 0)
+	assert.Empty(t, ht.problems())
+	ht.run(30, 1, 100, 0, 0)
+	assert.Equal(t, []HealthProblem{HealthExecRateDrop}, ht.problems())
+	ht.run(60, 1, 1000, 0, 0)
+	assert.Empty(t, ht.problems())
+}
+
+func TestHealthVMs(t *testing.T) {
+	ht := newHealthTest(t)
+	ht.run(60, 1, 1000, 0, 0)
+	assert.Empty(t, ht.problems())
+	// A VM restarts every minute -- that's too much for 2 VMs.
+	ht.run(60, 1, 1000, 1, 0)
+	assert.Equal(t, []HealthProblem{HealthVMRestarts}, ht.problems())
+	// Most of the boots fail.
+	ht.run(60, 1, 1000, 0, 1)
+	assert.Equal(t, []HealthProblem{HealthBootFailures}, ht.problems())
+	ht.run(60, 1, 1000, 0, 0)
+	assert.Empty(t, ht.problems())
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"math"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -30,6 +31,7 @@ import (
 	"github.com/google/syzkaller/pkg/log"
 	"github.com/google/syzkaller/pkg/mgrconfig"
 	"github.com/google/syzkaller/pkg/report"
+	"github.com/google/syzkaller/pkg/repro"
 	"github.com/google/syzkaller/pkg/stat"
 	"github.com/google/syzkaller/pkg/vcs"
 	"github.com/google/syzkaller/pkg/vminfo"
@@ -63,6 +65,7 @@ type HTTPServer struct {
 	Fuzzer          atomic.Pointer[fuzzer.Fuzzer]
 	Cover           atomic.Pointer[CoverageInfo]
 	EnabledSyscalls atomic.Value // map[*prog.Syscall]bool
+	Health          atomic.Pointer[HealthMonitor]
 
 	// Internal state.
 	expertMode bool
@@ -89,6 +92,7 @@ func (serv *HTTPServer) Serve(ctx context.Context) error {
 	handle("/cover", serv.httpCover)
 	handle("/coverprogs", serv.httpPrograms)
 	handle("/debuginput", serv.httpDebugInput)
+	handle("/descusage", serv.httpDescUsage)
 	handle("/file", serv.httpFile)
 	handle("/filecover", serv.httpFileCover)
 	handle("/filterpcs", serv.httpFilterPCs)
@@ -109,6 +113,7 @@ func (serv *HTTPServer) Serve(ctx context.Context) error {
 	// keep-sorted end
 	if serv.CrashStore != nil {
 		handle("/crash", serv.httpCrash)
+		handle("/crashes", serv.httpCrashes)
 		handle("/report", serv.httpReport)
 	}
 	// Browsers like to request this, without special handler this goes to / handler.
@@ -150,6 +155,9 @@ func (serv *HTTPServer) httpMain(w http.ResponseWriter, r *http.Request) {
 		UIPageHeader: serv.pageHeader(r, "syzkaller"),
 		Log:          log.CachedLogOutput(),
 	}
+	if health := serv.Health.Load(); health != nil {
+		data.Alerts = health.Alerts()
+	}
 
 	level := stat.Simple
 	if serv.expertMode {
@@ -402,9 +410,112 @@ func (serv *HTTPServer) httpCrash(w http.ResponseWriter, r *http.Request) {
 		UIPageHeader: serv.pageHeader(r, info.Title),
 		UICrashType:  makeUICrashType(info, serv.StartTime, nil),
 	}
+	if info.Locks != nil && len(info.Locks.Chain) > 1 {
+		data.LockGraph = makeUILockGraph(info.Locks.Chain)
+	}
+	if info.Reliability != nil {
+		data.ReliabilityConfidence = int(repro.ReliabilityConfidence * 100)
+		data.ReliabilityRows = append(data.ReliabilityRows, makeUIReliability("syz", info.Reliability.Syz))
+		if info.Reliability.C != nil {
+			data.ReliabilityRows = append(data.ReliabilityRows, makeUIReliability("C", info.Reliability.C))
+		}
+	}
 	executeTemplate(w, crashTemplate, data)
 }
 
+func makeUIReliability(name string, r *repro.Reliability) UIReliability {
+	percent := func(v float64) int {
+		return int(math.Round(v * 100))
+	}
+	return UIReliability{
+		Name:     name,
+		Runs:     r.Runs,
+		Crashes:  r.Crashes,
+		Rate:     percent(r.Rate()),
+		Interval: r.HasInterval(),
+		Low:      percent(r.Low),
+		High:     percent(r.High),
+	}
+}
+
+// makeUILockGraph places the locks of the dependency cycle on a circle.
+func makeUILockGraph(chain []string) *UILockGraph {
+	const (
+		width, height = 800, 360
+		radius        = 130
+		// Space between the node and the start/end of the edge.
+		margin = 10
+	)
+	graph := &UILockGraph{
+		Width:  width,
+		Height: height,
+	}
+	for i, lock := range chain {
+		angle := 2*math.Pi*float64(i)/float64(len(chain)) - math.Pi/2
+		cos, sin := math.Cos(angle), math.Sin(angle)
+		node := UILockNode{
+			Name:   lock,
+			X:      width/2 + int(radius*cos),
+			Y:      height/2 + int(radius*sin),
+			LabelX: width/2 + int((radius+margin)*cos),
+			LabelY: height/2 + int((radius+margin)*sin) + 4,
+			Anchor: "middle",
+		}
+		switch {
+		case cos > 0.1:
+			node.Anchor = "start"
+		case cos < -0.1:
+			node.Anchor = "end"
+		case sin < 0:
+			node.LabelY -= margin
+		default:
+			node.LabelY += margin
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	for i, from := range graph.Nodes {
+		to := graph.Nodes[(i+1)%len(graph.Nodes)]
+		dx, dy := float64(to.X-from.X), float64(to.Y-from.Y)
+		dist := math.Sqrt(dx*dx + dy*dy)
+		graph.Edges = append(graph.Edges, UILockEdge{
+			X1: from.X + int(dx*margin/dist),
+			Y1: from.Y + int(dy*margin/dist),
+			X2: to.X - int(dx*margin/dist),
+			Y2: to.Y - int(dy*margin/dist),
+			// The task tries to acquire the first lock while holding the last one.
+			Acquiring: i == len(graph.Nodes)-1,
+		})
+	}
+	return graph
+}
+
+func (serv *HTTPServer) httpCrashes(w http.ResponseWriter, r *http.Request) {
+	data := UICrashesPage{
+		UIPageHeader: serv.pageHeader(r, "crashes"),
+		Title:        r.FormValue("title"),
+		Type:         r.FormValue("type"),
+		Repro:        r.FormValue("repro"),
+		Since:        r.FormValue("since"),
+		Until:        r.FormValue("until"),
+		Syscall:      r.FormValue("syscall"),
+	}
+	query, err := ParseCrashQuery(data.Title, data.Type, data.Repro, data.Since, data.Until, data.Syscall)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	list, err := serv.CrashStore.Query(query)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to query crashes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	repros := serv.ReproLoop.Reproducing()
+	for _, info := range list {
+		data.Crashes = append(data.Crashes, makeUICrashType(info, serv.StartTime, repros))
+	}
+	executeTemplate(w, crashesTemplate, data)
+}
+
 func (serv *HTTPServer) httpCorpus(w http.ResponseWriter, r *http.Request) {
 	corpus := serv.Corpus.Load()
 	if corpus == nil {
@@ -416,6 +527,7 @@ func (serv *HTTPServer) httpCorpus(w http.ResponseWriter, r *http.Request) {
 		Call:         r.FormValue("call"),
 		RawCover:     serv.Cfg.RawCover,
 	}
+	calls := make(map[string]string)
 	for _, inp := range corpus.Items() {
 		if data.Call != "" && data.Call != inp.StringCall() {
 			continue
@@ -425,6 +537,7 @@ func (serv *HTTPServer) httpCorpus(w http.ResponseWriter, r *http.Request) {
 			Short: inp.Prog.String(),
 			Cover: len(inp.Cover),
 		})
+		calls[inp.Sig] = inp.StringCall()
 	}
 	sort.Slice(data.Inputs, func(i, j int) bool {
 		a, b := data.Inputs[i], data.Inputs[j]
@@ -433,6 +546,14 @@ func (serv *HTTPServer) httpCorpus(w http.ResponseWriter, r *http.Request) {
 		}
 		return a.Short < b.Short
 	})
+	// Offer to diff each input with the previous one in the list for the same call.
+	prev := make(map[string]string)
+	for i := range data.Inputs {
+		inp := &data.Inputs[i]
+		call := calls[inp.Sig]
+		inp.DiffSig = prev[call]
+		prev[call] = inp.Sig
+	}
 	executeTemplate(w, corpusTemplate, data)
 }
 
@@ -707,6 +828,41 @@ func (serv *HTTPServer) httpPrio(w http.ResponseWriter, r *http.Request) {
 	executeTemplate(w, prioTemplate, data)
 }
 
+func (serv *HTTPServer) httpDescUsage(w http.ResponseWriter, r *http.Request) {
+	corpus := serv.Corpus.Load()
+	if corpus == nil {
+		http.Error(w, "the corpus information is not yet available", http.StatusInternalServerError)
+		return
+	}
+	var inputs []prog.UsageInput
+	for _, inp := range corpus.Items() {
+		credited := []int{inp.Call}
+		for _, update := range inp.Updates {
+			credited = append(credited, update.Call)
+		}
+		inputs = append(inputs, prog.UsageInput{Prog: inp.Prog, Credited: credited})
+	}
+	var enabled map[*prog.Syscall]bool
+	if obj := serv.EnabledSyscalls.Load(); obj != nil {
+		enabled = obj.(map[*prog.Syscall]bool)
+	}
+	usage := serv.Cfg.Target.CalculateDescUsage(inputs, enabled)
+	if r.FormValue("format") == "json" {
+		serv.jsonPage(w, r, "description usage", usage)
+		return
+	}
+	html, err := pages.DescUsageHTML(usage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := &UITextPage{
+		UIPageHeader: serv.pageHeader(r, "description usage"),
+		HTML:         html,
+	}
+	executeTemplate(w, textTemplate, data)
+}
+
 func (serv *HTTPServer) httpFile(w http.ResponseWriter, r *http.Request) {
 	file := filepath.Clean(r.FormValue("name"))
 	if !strings.HasPrefix(file, "crashes/") && !strings.HasPrefix(file, "corpus/") {
@@ -732,7 +888,36 @@ func (serv *HTTPServer) httpInput(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "can't find the input", http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if sig := r.FormValue("diff"); sig != "" {
+		// Structural diff of the program against the input with the given signature.
+		other := corpus.Item(sig)
+		if other == nil {
+			http.Error(w, "can't find the input to diff with", http.StatusInternalServerError)
+			return
+		}
+		html, err := pages.ProgDiffHTML(prog.Diff(other.Prog, inp.Prog))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data := &UITextPage{
+			UIPageHeader: serv.pageHeader(r, "program diff"),
+			HTML:         html,
+		}
+		executeTemplate(w, textTemplate, data)
+		return
+	}
+	if r.FormValue("format") == "json" {
+		data, err := json.MarshalIndent(inp.Prog.ToJSON(), "", "\t")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ctApplicationJSON)
+		w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Type", ctTextPlain)
 	w.Write(inp.Prog.Serialize())
 }
 
@@ -1008,6 +1193,7 @@ func executeTemplate(w http.ResponseWriter, templ *template.Template, data inter
 
 type UISummaryData struct {
 	UIPageHeader
+	Alerts      []HealthAlert
 	Stats       []UIStat
 	Crashes     []UICrashType
 	PatchedOnly *UIDiffTable
@@ -1042,6 +1228,57 @@ type UISyscallsData struct {
 type UICrashPage struct {
 	UIPageHeader
 	UICrashType
+	LockGraph             *UILockGraph
+	ReliabilityConfidence int
+	ReliabilityRows       []UIReliability
+}
+
+type UIReliability struct {
+	Name    string
+	Runs    int
+	Crashes int
+	// In percents.
+	Rate int
+	// Low and High are only set if Interval is set.
+	Interval bool
+	Low      int
+	High     int
+}
+
+type UICrashesPage struct {
+	UIPageHeader
+	// The query as entered in the form.
+	Title   string
+	Type    string
+	Repro   string
+	Since   string
+	Until   string
+	Syscall string
+	Crashes []UICrashType
+}
+
+type UILockGraph struct {
+	Width  int
+	Height int
+	Nodes  []UILockNode
+	Edges  []UILockEdge
+}
+
+type UILockNode struct {
+	Name   string
+	X      int
+	Y      int
+	LabelX int
+	LabelY int
+	Anchor string
+}
+
+type UILockEdge struct {
+	X1        int
+	Y1        int
+	X2        int
+	Y2        int
+	Acquiring bool
 }
 
 type UICrashType struct {
@@ -1091,6 +1328,8 @@ type UIInput struct {
 	Sig   string
 	Short string
 	Cover int
+	// Signature of the input to diff this one with (optional).
+	DiffSig string
 }
 
 type UIPageHeader struct {
@@ -1201,6 +1440,7 @@ var (
 	syscallsTemplate      = createPage("syscalls", UISyscallsData{})
 	vmsTemplate           = createPage("vms", UIVMData{})
 	crashTemplate         = createPage("crash", UICrashPage{})
+	crashesTemplate       = createPage("crashes", UICrashesPage{})
 	corpusTemplate        = createPage("corpus", UICorpusPage{})
 	prioTemplate          = createPage("prio", UIPrioData{})
 	fallbackCoverTemplate = createPage("fallback_cover", UIFallbackCoverData{})
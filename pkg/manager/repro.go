@@ -68,6 +68,8 @@ type ReproLoop struct {
 	reproVMs  int
 
 	mu          sync.Mutex
+	started     bool
+	poolSize    int
 	queue       []*Crash
 	reproducing map[string]bool
 	enqueued    map[string]bool
@@ -110,6 +112,13 @@ func (r *ReproLoop) Reproducing() map[string]bool {
 	return maps.Clone(r.reproducing)
 }
 
+// Started returns true if Loop was called, i.e. the enqueued reproductions are being processed.
+func (r *ReproLoop) Started() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.started
+}
+
 // Empty returns true if there are neither running nor planned bug reproductions.
 func (r *ReproLoop) Empty() bool {
 	r.mu.Lock()
@@ -117,6 +126,17 @@ func (r *ReproLoop) Empty() bool {
 	return len(r.reproducing) == 0 && len(r.queue) == 0
 }
 
+// Pending returns the sorted titles of the running and planned bug reproductions.
+func (r *ReproLoop) Pending() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	titles := maps.Clone(r.reproducing)
+	for _, crash := range r.queue {
+		titles[crash.FullTitle()] = true
+	}
+	return slices.Sorted(maps.Keys(titles))
+}
+
 func (r *ReproLoop) Enqueue(crash *Crash) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -184,6 +204,9 @@ func (r *ReproLoop) popCrash() *Crash {
 }
 
 func (r *ReproLoop) Loop(ctx context.Context) {
+	r.mu.Lock()
+	r.started = true
+	r.mu.Unlock()
 	count := 0
 	for ; r.calculateReproVMs(count+1) <= r.reproVMs; count++ {
 		r.parallel <- struct{}{}
@@ -289,5 +312,14 @@ func (r *ReproLoop) adjustPoolSizeLocked() {
 
 	needRepros := len(uniqueTitles)
 	VMs := min(r.reproVMs, r.calculateReproVMs(needRepros))
+	r.poolSize = VMs
 	r.mgr.ResizeReproPool(VMs)
 }
+
+// ParallelVMs returns the number of VMs a single reproduction may use at the same time,
+// the repro pool is split evenly between the ongoing reproductions.
+func (r *ReproLoop) ParallelVMs() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return max(1, r.poolSize/max(1, len(r.reproducing)))
+}
@@ -40,6 +40,7 @@ func TestReproManager(t *testing.T) {
 	// One reproducer is running -- we can take one more.
 	assert.True(t, obj.CanReproMore())
 	assert.EqualValues(t, 2, mock.reserved.Load())
+	assert.Equal(t, 2, obj.ParallelVMs())
 	obj.Enqueue(&Crash{Report: &report.Report{Title: "B"}})
 	called2 := <-mock.run
 	assert.Equal(t, "B", called2.crash.Title)
@@ -47,6 +48,8 @@ func TestReproManager(t *testing.T) {
 	assert.False(t, obj.CanReproMore())
 	assert.Len(t, obj.Reproducing(), 2)
 	assert.EqualValues(t, 3, mock.reserved.Load())
+	// 3 VMs are split between 2 reproductions.
+	assert.Equal(t, 1, obj.ParallelVMs())
 
 	// Pretend that reproducers have finished.
 	called.ret <- &ReproResult{Crash: &Crash{FromHub: true}}
@@ -0,0 +1,42 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/syzkaller/pkg/mgrconfig"
+	"github.com/google/syzkaller/prog"
+)
+
+// LoadTemplates loads the program templates listed in the config and the templates
+// from the workdir/templates directory.
+func LoadTemplates(cfg *mgrconfig.Config) ([]*prog.Template, error) {
+	patterns := append([]string{filepath.Join(cfg.Workdir, "templates", "*")}, cfg.Templates...)
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad template pattern %q: %w", pattern, err)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	var templates []*prog.Template
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		t, err := cfg.Target.ParseTemplate(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %v: %w", file, err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
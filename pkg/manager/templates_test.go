@@ -0,0 +1,42 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/syzkaller/pkg/mgrconfig"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	target, err := prog.GetTarget(targets.TestOS, targets.TestArch64)
+	require.NoError(t, err)
+	workdir := t.TempDir()
+	extra := t.TempDir()
+	osutil.MkdirAll(filepath.Join(workdir, "templates"))
+	require.NoError(t, osutil.WriteFile(filepath.Join(workdir, "templates", "a"),
+		[]byte("test$int(?, 0x0, 0x0, 0x0, 0x0)\n")))
+	require.NoError(t, osutil.WriteFile(filepath.Join(extra, "b.tmpl"), []byte("?test$res0()\ntest$res0()\n")))
+	cfg := &mgrconfig.Config{
+		Workdir:   workdir,
+		Templates: []string{filepath.Join(extra, "*.tmpl")},
+	}
+	cfg.Target = target
+	templates, err := LoadTemplates(cfg)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, 1, templates[0].Holes())
+	assert.Equal(t, 0, templates[1].Holes())
+
+	require.NoError(t, osutil.WriteFile(filepath.Join(extra, "c.tmpl"), []byte("test$res0()\n")))
+	_, err = LoadTemplates(cfg)
+	assert.ErrorContains(t, err, "c.tmpl")
+}
@@ -103,6 +103,12 @@ type Config struct {
 	// If set, only consult dashboard if it needs reproducers for crashes,
 	// but otherwise don't send any info to dashboard (default: false).
 	DashboardOnlyRepro bool `json:"dashboard_only_repro,omitempty"`
+	// Reproducers that crash the kernel less often than this (0..1, as estimated by the validation runs)
+	// are considered unreliable (optional). The estimate is only precise if repro_reliability_runs is set.
+	DashboardReproReliability float64 `json:"dashboard_repro_reliability,omitempty"`
+	// If set, unreliable reproducers (see dashboard_repro_reliability) are not reported to dashboard
+	// and are only saved in the workdir (default: false).
+	DashboardSkipUnreliableRepros bool `json:"dashboard_skip_unreliable_repros,omitempty"`
 
 	// Location of the syzkaller checkout, syz-manager will look
 	// for binaries in bin subdir (does not have to be syzkaller checkout as
@@ -118,6 +124,11 @@ type Config struct {
 	// Maximum number of logs to store per crash (default: 100).
 	MaxCrashLogs int `json:"max_crash_logs"`
 
+	// Limits for the crash logs stored across all crashes in workdir/crashes (optional).
+	// The oldest logs are deleted first. Crashes that have reproducers are never deleted completely.
+	// E.g. "crash_retention": {"max_age_days": 30, "max_mb": 10240}.
+	CrashRetention CrashRetentionCfg `json:"crash_retention,omitempty"`
+
 	// Type of sandbox to use during fuzzing:
 	// "none": test under root;
 	//      don't do anything special beyond resource sandboxing,
@@ -156,6 +167,11 @@ type Config struct {
 
 	// Reproduce, localize and minimize crashers (default: true).
 	Reproduce bool `json:"reproduce"`
+	// The number of validation runs of the final syz and C reproducers that are used to estimate
+	// their reliability with confidence intervals (optional). Note that the runs take VM time
+	// from fuzzing and reproduction of other bugs. By default, only the syz reproducer is run
+	// until it crashes the kernel 3 times, but at most 10 times.
+	ReproReliabilityRuns int `json:"repro_reliability_runs,omitempty"`
 
 	// The number of VMs that are reserved to only perform fuzzing and nothing else.
 	// Can be helpful e.g. to ensure that the pool of fuzzing VMs is never exhausted and
@@ -163,6 +179,15 @@ type Config struct {
 	// By default the value is 0, i.e. all VMs can be used for all purposes.
 	FuzzingVMs int `json:"fuzzing_vms,omitempty"`
 
+	// The manager monitors fuzzing health (coverage plateaus, falling exec rate, frequent VM restarts
+	// and boot failures) and shows the detected problems on the main page. This option lists
+	// the remedies it may apply automatically (optional, none by default):
+	// "rotate": on coverage plateau, fuzz a random subset of the enabled syscalls for a while;
+	// "reseed": on coverage plateau, re-fetch the whole corpus from syz-hub;
+	// "recycle": on falling exec rate, restart all fuzzing VMs.
+	// E.g. "health_remedies": ["rotate", "recycle"].
+	HealthRemedies []string `json:"health_remedies,omitempty"`
+
 	// Keep existing programs in the corpus even if they no longer pass syscall filters.
 	// By default it is true, as this is the desired behavior when executing syzkaller
 	// locally.
@@ -175,6 +200,15 @@ type Config struct {
 	DisabledSyscalls []string `json:"disable_syscalls,omitempty"`
 	// List of syscalls that should not be mutated by the fuzzer (optional).
 	NoMutateSyscalls []string `json:"no_mutate_syscalls,omitempty"`
+	// List of program template files (globs are allowed), optional.
+	// Templates are also loaded from the workdir/templates directory.
+	// If there are any templates, the fuzzer generates and mutates programs only
+	// within the template holes, see docs/program_syntax.md for the template syntax.
+	Templates []string `json:"templates,omitempty"`
+	// File with a call sequence model mined from a corpus with tools/syz-showseq (optional).
+	// If set, the fuzzer occasionally generates whole call sequences from the model
+	// (e.g. socket -> bind -> listen -> accept) in addition to individual calls.
+	SequenceModel string `json:"sequence_model,omitempty"`
 	// List of regexps for known bugs.
 	// Don't save reports matching these regexps, but reboot VM after them,
 	// matched against whole report output.
@@ -282,6 +316,15 @@ type Subsystem struct {
 	Paths []string `json:"path"`
 }
 
+type CrashRetentionCfg struct {
+	// Delete crash logs older than this number of days.
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+	// Maximum total number of the stored crash logs.
+	MaxLogs int `json:"max_logs,omitempty"`
+	// Maximum total size of the stored crash logs in megabytes.
+	MaxMB int `json:"max_mb,omitempty"`
+}
+
 type CovFilterCfg struct {
 	Files     []string `json:"files,omitempty"`
 	Functions []string `json:"functions,omitempty"`
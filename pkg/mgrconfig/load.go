@@ -193,6 +193,23 @@ func Complete(cfg *Config) error {
 	if cfg.FuzzingVMs < 0 {
 		return fmt.Errorf("fuzzing_vms cannot be less than 0")
 	}
+	for _, remedy := range cfg.HealthRemedies {
+		switch remedy {
+		case "rotate", "reseed", "recycle":
+		default:
+			return fmt.Errorf("unknown health remedy %q, must be one of rotate/reseed/recycle", remedy)
+		}
+	}
+	if cfg.CrashRetention.MaxAgeDays < 0 || cfg.CrashRetention.MaxLogs < 0 || cfg.CrashRetention.MaxMB < 0 {
+		return fmt.Errorf("crash_retention limits cannot be negative")
+	}
+	if cfg.ReproReliabilityRuns < 0 {
+		return fmt.Errorf("repro_reliability_runs cannot be less than 0")
+	}
+	if cfg.DashboardReproReliability < 0 || cfg.DashboardReproReliability > 1 {
+		return fmt.Errorf("bad config param dashboard_repro_reliability: %v, want [0, 1]",
+			cfg.DashboardReproReliability)
+	}
 
 	var err error
 	cfg.Syscalls, err = ParseEnabledSyscalls(cfg.Target, cfg.EnabledSyscalls, cfg.DisabledSyscalls,
@@ -112,6 +112,10 @@ func ctorLinux(cfg *config) (reporterImpl, []string, error) {
 		regexp.MustCompile(`^drivers/usb/core/urb.c`), // WARNING in urb.c usually means a bug in a driver
 		// Crashes in these files are almost always caused by the calling code.
 		regexp.MustCompile(`^arch/.*/lib/crc.*`),
+		// Rust kernel crate and helpers are thin abstractions over the C code (similar to lib/),
+		// and panics in the Rust core library are caused by the calling code.
+		regexp.MustCompile(`^rust/(?:kernel|helpers|bindings|macros|pin-init)/`),
+		regexp.MustCompile(`(?:^|/)library/(?:core|alloc)/src/`),
 	}
 	ctx.guiltyLineIgnore = regexp.MustCompile(`(hardirqs|softirqs)\s+last\s+(enabled|disabled)|^Register r\d+ information`)
 	// These pattern do _not_ start a new report, i.e. can be in a middle of another report.
@@ -192,6 +196,12 @@ func (ctx *linux) Parse(output []byte) *Report {
 		rep.Report = append(rep.Report, report...)
 		rep.Type = TitleToCrashType(rep.Title)
 		setExecutorInfo(rep)
+		rep.Locks = ParseLockInfo(report)
+		if cycle := rep.Locks.CycleKey(); cycle != "" {
+			// The same deadlock can be detected in different functions depending on which
+			// of the locks is acquired last, the lock cycle is the same in all cases.
+			rep.AltTitles = append(rep.AltTitles, "possible deadlock on "+cycle)
+		}
 		if !rep.Corrupted {
 			rep.Corrupted, rep.CorruptedReason = isCorrupted(title, report, format)
 		}
@@ -822,7 +832,7 @@ func (ctx *linux) extractGuiltyFileImpl(report []byte) string {
 		if match == nil {
 			continue
 		}
-		file := match[1]
+		file := ctx.trimBuildSrc(match[1])
 		if guilty == "" {
 			// Avoid producing no guilty file at all, otherwise we mail the report to nobody.
 			// It's unclear if it's better to return the first one or the last one.
@@ -845,7 +855,7 @@ func (ctx *linux) extractGuiltyFileImpl(report []byte) string {
 		if match == nil {
 			continue
 		}
-		file := match[1]
+		file := ctx.trimBuildSrc(match[1])
 		if matchesAny(file, ctx.guiltyFileIgnores) || ctx.guiltyLineIgnore.Match(line) {
 			continue
 		}
@@ -864,6 +874,16 @@ func (ctx *linux) extractGuiltyFileImpl(report []byte) string {
 	return guilty
 }
 
+// trimBuildSrc converts absolute file paths that point into the kernel build directory
+// (e.g. Rust panic locations) into paths relative to the kernel source root.
+func (ctx *linux) trimBuildSrc(file []byte) []byte {
+	buildSrc := ctx.kernelDirs.BuildSrc
+	if buildSrc == "" || !bytes.HasPrefix(file, []byte(buildSrc)) {
+		return file
+	}
+	return bytes.TrimLeft(file[len(buildSrc):], "/")
+}
+
 func (ctx *linux) getMaintainers(file string) (vcs.Recipients, error) {
 	if ctx.kernelDirs.Src == "" {
 		return nil, nil
@@ -1174,6 +1194,13 @@ var linuxStackParams = &stackParams{
 		"(read|write)_once_.*nocheck",
 		"print_address_description",
 		"panic",
+		"rust_begin_unwind",
+		"^rust_helper_",
+		`^core::(?:result|option)::.*_failed`,
+		"^core::slice::index::",
+		"^core::fmt::",
+		// Rust abstractions from rust/kernel, the calling driver frame is more useful.
+		`^<?kernel::`,
 		"invalid_op",
 		"report_bug",
 		"fixup_bug",
@@ -2270,6 +2297,18 @@ var linuxOopses = append([]*oops{
 	{
 		[]byte("rust_kernel: panicked"),
 		[]oopsFormat{
+			{
+				// Rust before 1.73 printed the message before the location.
+				title:  compile("rust_kernel: panicked at '"),
+				report: compile(`rust_kernel: panicked at '(.+?)', [^\n]*?\.rs:[0-9]+`),
+				fmt:    "%[1]v in %[2]v",
+				stack: &stackFmt{
+					parts: []*regexp.Regexp{
+						linuxCallTrace,
+						parseStackTrace,
+					},
+				},
+			},
 			{
 				title:  compile("rust_kernel: panicked"),
 				report: compile("rust_kernel: panicked at [^\n]*?\n(.+?)\n"),
@@ -2279,9 +2318,6 @@ var linuxOopses = append([]*oops{
 						linuxCallTrace,
 						parseStackTrace,
 					},
-					skip: []string{
-						regexp.QuoteMeta(`__rustc::rust_begin_unwind`),
-					},
 				},
 			},
 		},
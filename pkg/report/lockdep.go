@@ -0,0 +1,101 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LockInfo is the lock dependency information extracted from lockdep and hung task reports.
+type LockInfo struct {
+	// Chain contains lock classes from the lockdep "-> #N" sections ordered by N.
+	// Each lock in the chain was acquired while holding the previous one,
+	// and the task that triggered the report tries to acquire the first lock while holding the last one.
+	Chain []string `json:",omitempty"`
+	// Holders lists the tasks that hold locks ("N locks held by task/pid:" sections).
+	Holders []LockHolder `json:",omitempty"`
+}
+
+type LockHolder struct {
+	Task  string
+	Locks []HeldLock
+}
+
+type HeldLock struct {
+	Class string
+	// The function that acquired the lock.
+	Func string
+}
+
+var (
+	lockdepChainRe  = regexp.MustCompile(`^-> #([0-9]+) \((.+)\)\{[^}]*\}(?:-\{[^}]*\})?:`)
+	lockdepHolderRe = regexp.MustCompile(`^([0-9]+) locks? held by (.+):$`)
+	lockdepHeldRe   = regexp.MustCompile(`^#[0-9]+: +(?:[0-9a-f]+ +)?\((.+)\)\{[^}]*\}(?:-\{[^}]*\})?` +
+		`, at: (?:\[<[0-9a-f]+>\] )?([a-zA-Z0-9_.]+)`)
+	// Lock subclass annotations (e.g. "&rq->lock/1" or "&type->s_umount_key#32") differ between
+	// instances of the same deadlock.
+	lockSubclassRe = regexp.MustCompile(`(?:#[0-9]+|/[0-9]+)$`)
+)
+
+// ParseLockInfo extracts lock dependencies from the (already extracted) report text.
+// Returns nil if the report does not contain any lock information.
+func ParseLockInfo(report []byte) *LockInfo {
+	info := new(LockInfo)
+	chain := make(map[int]string)
+	holder := -1
+	for _, line := range lines(report) {
+		line = bytes.TrimSpace(line)
+		if match := lockdepChainRe.FindSubmatch(line); match != nil {
+			n, _ := strconv.Atoi(string(match[1]))
+			chain[n] = string(match[2])
+			continue
+		}
+		if match := lockdepHolderRe.FindSubmatch(line); match != nil {
+			info.Holders = append(info.Holders, LockHolder{Task: string(match[2])})
+			holder = len(info.Holders) - 1
+			continue
+		}
+		if match := lockdepHeldRe.FindSubmatch(line); match != nil && holder != -1 {
+			info.Holders[holder].Locks = append(info.Holders[holder].Locks, HeldLock{
+				Class: string(match[1]),
+				Func:  string(match[2]),
+			})
+		}
+	}
+	for i := 0; i < len(chain); i++ {
+		class, ok := chain[i]
+		if !ok {
+			// Part of the chain was lost (e.g. the report is truncated).
+			info.Chain = nil
+			break
+		}
+		info.Chain = append(info.Chain, class)
+	}
+	if len(info.Chain) == 0 && len(info.Holders) == 0 {
+		return nil
+	}
+	return info
+}
+
+// CycleKey returns a canonical representation of the lock dependency cycle
+// that does not depend on the lock the cycle was detected on.
+// Returns an empty string if there is no cycle.
+func (info *LockInfo) CycleKey() string {
+	if info == nil || len(info.Chain) < 2 {
+		return ""
+	}
+	classes := make([]string, len(info.Chain))
+	start := 0
+	for i, class := range info.Chain {
+		classes[i] = lockSubclassRe.ReplaceAllString(class, "")
+		if classes[i] < classes[start] {
+			start = i
+		}
+	}
+	classes = append(classes[start:], classes[:start]...)
+	return strings.Join(classes, " -> ")
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLockInfo(t *testing.T) {
+	reporter, _ := prepareLinuxReporter(t, "amd64")
+	test := parseReport(t, reporter, "testdata/linux/report/747")
+	rep := reporter.Parse(test.Log)
+	require.NotNil(t, rep)
+	require.NotNil(t, rep.Locks)
+	assert.Equal(t, []string{"&q->elevator_lock", "fs_reclaim", "&q->q_usage_counter(io)#55"}, rep.Locks.Chain)
+	require.Len(t, rep.Locks.Holders, 1)
+	assert.Equal(t, "syz.5.7376/24950", rep.Locks.Holders[0].Task)
+	require.Len(t, rep.Locks.Holders[0].Locks, 6)
+	assert.Equal(t, HeldLock{Class: "cb_lock", Func: "genl_rcv"}, rep.Locks.Holders[0].Locks[0])
+	assert.Equal(t, HeldLock{Class: "&q->q_usage_counter(queue)#7", Func: "nbd_start_device"},
+		rep.Locks.Holders[0].Locks[5])
+
+	// Hung task reports list the locks held by all tasks.
+	test = parseReport(t, reporter, "testdata/linux/report/235")
+	rep = reporter.Parse(test.Log)
+	require.NotNil(t, rep)
+	require.NotNil(t, rep.Locks)
+	assert.Empty(t, rep.Locks.Chain)
+	assert.Equal(t, "khungtaskd/876", rep.Locks.Holders[0].Task)
+	assert.Equal(t, []HeldLock{
+		{Class: "rcu_read_lock", Func: "watchdog"},
+		{Class: "tasklist_lock", Func: "debug_show_all_locks"},
+	}, rep.Locks.Holders[0].Locks)
+
+	assert.Nil(t, ParseLockInfo([]byte("BUG: KASAN: use-after-free in foo\n")))
+}
+
+func TestLockCycleKey(t *testing.T) {
+	assert.Equal(t, "", (*LockInfo)(nil).CycleKey())
+	assert.Equal(t, "", (&LockInfo{Chain: []string{"a"}}).CycleKey())
+	// The same cycle detected on different locks.
+	key := "&rq->lock -> &sb->s_type->i_mutex_key -> rtnl_mutex"
+	assert.Equal(t, key, (&LockInfo{Chain: []string{
+		"&sb->s_type->i_mutex_key#10", "rtnl_mutex", "&rq->lock/1",
+	}}).CycleKey())
+	assert.Equal(t, key, (&LockInfo{Chain: []string{
+		"rtnl_mutex", "&rq->lock", "&sb->s_type->i_mutex_key#12",
+	}}).CycleKey())
+}
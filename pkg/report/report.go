@@ -72,6 +72,8 @@ type Report struct {
 	MachineInfo []byte
 	// If the crash happened in the context of the syz-executor process, Executor will hold more info.
 	Executor *ExecutorInfo
+	// Locks contains the lock dependencies for lockdep and hung task reports.
+	Locks *LockInfo
 	// reportPrefixLen is length of additional prefix lines that we added before actual crash report.
 	reportPrefixLen int
 	// symbolized is set if the report is symbolized. It prevents double symbolization.
@@ -707,7 +709,7 @@ func appendStackFrame(frames []string, match [][]byte, skipRe *regexp.Regexp) []
 		if frame == nil {
 			continue
 		}
-		frame := demangle.Filter(string(frame), demangle.NoParams)
+		frame := demangleFrame(string(frame))
 		if skipRe == nil || !skipRe.MatchString(frame) {
 			frames = append(frames, frame)
 		}
@@ -715,6 +717,51 @@ func appendStackFrame(frames []string, match [][]byte, skipRe *regexp.Regexp) []
 	return frames
 }
 
+// demangleFrame demangles C++ and Rust symbols for use in titles.
+// Generic arguments of Rust symbols are dropped since they make titles long and unstable,
+// e.g. "<kernel::list::List<T, 0>>::remove" becomes "<kernel::list::List>::remove".
+func demangleFrame(frame string) string {
+	demangled := demangle.Filter(frame, demangle.NoParams)
+	if demangled == frame || !strings.HasPrefix(frame, "_R") {
+		return demangled
+	}
+	var res []byte
+	for i := 0; i < len(demangled); i++ {
+		c := demangled[i]
+		if c == '<' && len(res) > 0 && isRustIdentChar(res[len(res)-1]) {
+			i = skipRustGenerics(demangled, i)
+			continue
+		}
+		if c == ':' && strings.HasPrefix(demangled[i:], "::<") && len(res) > 0 && isRustIdentChar(res[len(res)-1]) {
+			i = skipRustGenerics(demangled, i+2)
+			continue
+		}
+		res = append(res, c)
+	}
+	return string(res)
+}
+
+// skipRustGenerics returns the index of the '>' that closes the '<' at the given position.
+func skipRustGenerics(name string, start int) int {
+	depth := 0
+	for i := start; i < len(name); i++ {
+		switch name[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(name)
+}
+
+func isRustIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
 func canonicalArgs(prefix []any, frames []extractedFrame) []any {
 	ret := append([]any{}, prefix...)
 	for _, frame := range frames {
@@ -858,10 +905,10 @@ func Truncate(log []byte, begin, end int) []byte {
 }
 
 var (
-	filenameRe    = regexp.MustCompile(`([a-zA-Z0-9_\-\./]*[a-zA-Z0-9_\-]+\.(c|h)):[0-9]+`)
+	filenameRe    = regexp.MustCompile(`([a-zA-Z0-9_\-\./]*[a-zA-Z0-9_\-]+\.(c|h|rs)):[0-9]+`)
 	reportFrameRe = regexp.MustCompile(`.* in ((?:<[a-zA-Z0-9_: ]+>)?[a-zA-Z0-9_:]+)`)
-	// Matches a slash followed by at least one directory nesting before .c/.h file.
-	deeperPathRe = regexp.MustCompile(`^/[a-zA-Z0-9_\-\./]+/[a-zA-Z0-9_\-]+\.(c|h)$`)
+	// Matches a slash followed by at least one directory nesting before .c/.h/.rs file.
+	deeperPathRe = regexp.MustCompile(`^/[a-zA-Z0-9_\-\./]+/[a-zA-Z0-9_\-]+\.(c|h|rs)$`)
 )
 
 // These are produced by syzkaller itself.
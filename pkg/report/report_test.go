@@ -482,6 +482,30 @@ func TestReplace(t *testing.T) {
 	}
 }
 
+func TestDemangleFrame(t *testing.T) {
+	tests := []struct {
+		frame  string
+		result string
+	}{
+		{"__schedule", "__schedule"},
+		{
+			"_RNvMs3_NtCshgDM7dBCdno_11rust_binder7processNtB5_7Process10update_ref",
+			"<rust_binder::process::Process>::update_ref",
+		},
+		{
+			"_RNvXCsktjF9JQNZ8U_5rnullNtB2_13NullBlkModuleNtCs43vyB533jt3_6kernel13InPlaceModule4init",
+			"<rnull::NullBlkModule as kernel::InPlaceModule>::init",
+		},
+		{
+			"_RNvMNtCs2OFc1tBbM2P_6kernel4listINtB2_4ListNtNtCsfsOklgdLQvg_11rust_binder4node9NodeDeathKy0_E6removeBJ_",
+			"<kernel::list::List>::remove",
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.result, demangleFrame(test.frame), test.frame)
+	}
+}
+
 func TestFuzz(t *testing.T) {
 	for _, data := range []string{
 		"kernel panicType 'help' for a list of commands",
@@ -0,0 +1,155 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package repro
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/syzkaller/pkg/csource"
+	"github.com/google/syzkaller/pkg/instance"
+	"github.com/google/syzkaller/prog"
+)
+
+// extractProgConcurrent tries to reproduce crashes caused by races between programs that were
+// running at the same time in different procs. The last programs of all procs are executed
+// concurrently, each pinned to its own proc, then the set is bisected and minimized jointly.
+func (ctx *reproContext) extractProgConcurrent(entries []*prog.LogEntry, duration time.Duration) (*Result, error) {
+	last := lastEntries(entries)
+	if len(last) < 2 || !ctx.startOpts.Repeat {
+		return nil, nil
+	}
+	ctx.reproLogf(3, "concurrent: executing last programs of %d procs concurrently with timeout %s",
+		len(last), duration)
+	opts := ctx.startOpts
+	pred := func(entries []*prog.LogEntry) (bool, error) {
+		ret, err := ctx.testConcurrent(entryProgs(entries), duration, opts, false)
+		return ret.Crashed, err
+	}
+	crashed, err := pred(last)
+	if err != nil {
+		return nil, err
+	}
+	if !crashed {
+		ctx.reproLogf(3, "concurrent: programs did not crash the kernel")
+		return nil, nil
+	}
+	guilty, err := ctx.bisectProgs(last, pred)
+	if err != nil {
+		return nil, err
+	}
+	if len(guilty) < 2 {
+		// Either the crash is flaky, or a single program is enough to trigger it.
+		// The latter case is covered by the other strategies.
+		ctx.reproLogf(3, "concurrent: %d guilty programs left", len(guilty))
+		return nil, nil
+	}
+	progs, err := ctx.minimizeConcurrent(entryProgs(guilty), duration, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.Procs = len(progs)
+	res := &Result{
+		Prog:            joinProgs(progs),
+		Duration:        duration * 3 / 2,
+		Opts:            opts,
+		ConcurrentProgs: progs,
+	}
+	ctx.reproLogf(3, "concurrent: successfully extracted %d programs", len(progs))
+	return res, nil
+}
+
+// minimizeConcurrent minimizes every program while the rest of the programs stay the same.
+func (ctx *reproContext) minimizeConcurrent(progs []*prog.Prog, duration time.Duration,
+	opts csource.Options) ([]*prog.Prog, error) {
+	mode := prog.MinimizeCrash
+	if ctx.fast {
+		mode = prog.MinimizeCallsOnly
+	}
+	for i := range progs {
+		ctx.reproLogf(2, "concurrent: minimizing program #%d", i)
+		var testErr error
+		progs[i], _ = prog.Minimize(progs[i], -1, mode, func(p1 *prog.Prog, _ int) bool {
+			if testErr != nil || len(p1.Calls) == 0 {
+				return false
+			}
+			candidate := slices.Clone(progs)
+			candidate[i] = p1
+			ret, err := ctx.testConcurrent(candidate, duration, opts, false)
+			if err != nil {
+				ctx.reproLogf(2, "minimization failed with %v", err)
+				testErr = err
+				return false
+			}
+			return ret.Crashed
+		})
+		if testErr != nil {
+			return nil, testErr
+		}
+	}
+	return progs, nil
+}
+
+// testConcurrent executes the programs concurrently, the i-th program is pinned to proc i.
+func (ctx *reproContext) testConcurrent(progs []*prog.Prog, duration time.Duration, opts csource.Options,
+	strict bool) (verdict, error) {
+	var entries []*prog.LogEntry
+	for i, p := range progs {
+		entries = append(entries, &prog.LogEntry{P: p, Proc: i})
+	}
+	opts.Procs = len(progs)
+	ctx.reproLogf(2, "testing %d concurrent programs (duration=%v, %+v)", len(progs), duration, opts)
+	ctx.reproLogf(3, "detailed listing:\n%s", encodeEntries(entries))
+	return ctx.testParams(instance.ExecParams{
+		SyzProg:  encodeEntries(entries),
+		PinProcs: true,
+		Opts:     opts,
+		Duration: duration,
+	}, strict)
+}
+
+// testResult executes the syz reproducer with the specified options.
+func (ctx *reproContext) testResult(res *Result, opts csource.Options, strict bool) (verdict, error) {
+	if len(res.ConcurrentProgs) != 0 {
+		return ctx.testConcurrent(res.ConcurrentProgs, res.Duration, opts, strict)
+	}
+	return ctx.testProg(res.Prog, res.Duration, opts, strict)
+}
+
+// testCResult executes the C reproducer with the specified options.
+func (ctx *reproContext) testCResult(res *Result, opts csource.Options, strict bool) (verdict, error) {
+	if len(res.ConcurrentProgs) == 0 {
+		return ctx.testCProg(res.Prog, res.Duration, opts, strict)
+	}
+	opts.Procs = len(res.ConcurrentProgs)
+	ctx.reproLogf(2, "testing %d concurrent C programs (duration=%v, %+v)",
+		len(res.ConcurrentProgs), res.Duration, opts)
+	return ctx.testParams(instance.ExecParams{
+		CProgs:   res.ConcurrentProgs,
+		Opts:     opts,
+		Duration: res.Duration,
+	}, strict)
+}
+
+// supportsOpts returns whether the reproducer may run with the options.
+// Concurrent programs need a separate proc each.
+func (res *Result) supportsOpts(opts csource.Options) bool {
+	return len(res.ConcurrentProgs) == 0 || opts.Repeat && opts.Procs >= len(res.ConcurrentProgs)
+}
+
+func entryProgs(entries []*prog.LogEntry) []*prog.Prog {
+	var progs []*prog.Prog
+	for _, entry := range entries {
+		progs = append(progs, entry.P)
+	}
+	return progs
+}
+
+func joinProgs(progs []*prog.Prog) *prog.Prog {
+	p := &prog.Prog{Target: progs[0].Target}
+	for _, p1 := range progs {
+		p.Calls = append(p.Calls, p1.Clone().Calls...)
+	}
+	return p
+}
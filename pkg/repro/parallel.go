@@ -0,0 +1,274 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package repro
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/pkg/hash"
+	"github.com/google/syzkaller/pkg/instance"
+	"github.com/google/syzkaller/pkg/report"
+	"github.com/google/syzkaller/prog"
+)
+
+// strategy is one of the independent ways to get a reproducer (e.g. testing programs one by one
+// or bisecting the whole log).
+type strategy func(ctx *reproContext) (*Result, error)
+
+// race runs the strategies concurrently if the reproduction may use several VMs,
+// and one after another otherwise.
+// The strategies are listed in the order of preference: the result of a strategy that satisfies done
+// is only accepted after all the preceding strategies have finished without such a result,
+// so the outcome does not depend on which strategy finishes first.
+// Once a result is accepted, the remaining (less preferred) strategies are canceled.
+// If no result satisfies done, it returns the result of the last strategy that returned one.
+// The first strategy gets all the VMs that are not used by the other ones, the rest get one VM each.
+func (ctx *reproContext) race(done func(*Result) bool, strategies ...strategy) (*Result, error) {
+	if ctx.parallel <= 1 || len(strategies) == 1 {
+		var last *Result
+		for _, run := range strategies {
+			res, err := run(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if res == nil {
+				continue
+			}
+			if done(res) {
+				return res, nil
+			}
+			last = res
+		}
+		return last, nil
+	}
+	raceCtx, cancel := context.WithCancel(ctx.ctx)
+	defer cancel()
+	type outcome struct {
+		index int
+		res   *Result
+		err   error
+	}
+	outcomes := make(chan outcome, len(strategies))
+	subs := make([]*reproContext, len(strategies))
+	for i, run := range strategies {
+		parallel := 1
+		if i == 0 {
+			parallel = max(1, ctx.parallel-len(strategies)+1)
+		}
+		subs[i] = ctx.withContext(raceCtx, parallel)
+		go func() {
+			res, err := run(subs[i])
+			outcomes <- outcome{i, res, err}
+		}()
+	}
+	results := make([]*Result, len(strategies))
+	finished := make([]bool, len(strategies))
+	// All strategies before next have finished without a result that satisfies done.
+	next := 0
+	winner := -1
+	var firstErr error
+	for range strategies {
+		out := <-outcomes
+		if winner != -1 || firstErr != nil {
+			// The strategy was canceled, its outcome does not matter.
+			continue
+		}
+		if out.err != nil {
+			firstErr = out.err
+			cancel()
+			continue
+		}
+		results[out.index] = out.res
+		finished[out.index] = true
+		for ; next < len(strategies) && finished[next]; next++ {
+			if results[next] != nil && done(results[next]) {
+				ctx.reproLogf(3, "strategy #%v won the race", next)
+				winner = next
+				cancel()
+				break
+			}
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if winner == -1 {
+		for i := len(results) - 1; i >= 0; i-- {
+			if results[i] != nil {
+				winner = i
+				break
+			}
+		}
+	}
+	if winner == -1 {
+		return nil, nil
+	}
+	// The canceled strategies may have seen other crashes, so take the report of the returned result.
+	if rep := subs[winner].report.get(); rep != nil {
+		ctx.report.set(rep)
+	}
+	return results[winner], nil
+}
+
+// withContext returns a copy of the reproduction context that uses a separate Go context
+// (e.g. to be canceled independently) and may use the specified number of VMs.
+// The copy has its own crash report, see race.
+func (ctx *reproContext) withContext(goCtx context.Context, parallel int) *reproContext {
+	ret := *ctx
+	ret.ctx = goCtx
+	ret.parallel = parallel
+	ret.report = &reportHolder{rep: ctx.report.get()}
+	return &ret
+}
+
+// withoutCache returns a copy of the reproduction context that always runs the tests on VMs.
+// It's needed when a test is repeated on purpose (e.g. to estimate the reliability).
+// The copy shares the crash report with ctx.
+func (ctx *reproContext) withoutCache() *reproContext {
+	ret := *ctx
+	ret.cache = nil
+	return &ret
+}
+
+// testEach tests the programs separately on up to ctx.parallel VMs at once.
+// It returns the index of the first program (in the order of entries) that crashed the kernel, or -1.
+func (ctx *reproContext) testEach(entries []*prog.LogEntry, duration time.Duration) (int, verdict, error) {
+	batch := max(1, ctx.parallel)
+	for start := 0; start < len(entries); start += batch {
+		end := min(start+batch, len(entries))
+		verdicts := make([]verdict, end-start)
+		errs := make([]error, end-start)
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				verdicts[i-start], errs[i-start] = ctx.testProg(entries[i].P, duration, ctx.startOpts, false)
+			}()
+		}
+		wg.Wait()
+		for i := range verdicts {
+			if errs[i] != nil {
+				return -1, verdict{}, errs[i]
+			}
+			if verdicts[i].Crashed {
+				return start + i, verdicts[i], nil
+			}
+		}
+	}
+	return -1, verdict{}, nil
+}
+
+// reportHolder keeps the report of the last reproduced crash.
+// It's shared by copies of the reproduction context that should see each other's crashes.
+type reportHolder struct {
+	mu  sync.Mutex
+	rep *report.Report
+}
+
+func (holder *reportHolder) get() *report.Report {
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	return holder.rep
+}
+
+func (holder *reportHolder) set(rep *report.Report) {
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	holder.rep = rep
+}
+
+// verdictCache remembers the crashes observed in the VM runs, so that the concurrent strategies
+// (and the subsequent reproduction steps) do not test the same program with the same options twice.
+// Runs that did not crash the kernel are not remembered: crashes may be flaky, so a later step
+// that tests the same program again should get another chance to see the crash.
+// Concurrent requests for the same test wait for the first one to finish.
+type verdictCache struct {
+	mu      sync.Mutex
+	entries map[hash.Sig]*cacheEntry
+	hits    int
+}
+
+type cacheEntry struct {
+	ready  chan struct{}
+	result *instance.RunResult
+	err    error
+}
+
+func newVerdictCache() *verdictCache {
+	return &verdictCache{
+		entries: make(map[hash.Sig]*cacheEntry),
+	}
+}
+
+func cacheKey(params instance.ExecParams) hash.Sig {
+	kind, data := []byte("syz"), params.SyzProg
+	if params.PinProcs {
+		kind = []byte("pinned syz")
+	}
+	if params.CProg != nil {
+		kind, data = []byte("C"), params.CProg.Serialize()
+	}
+	if len(params.CProgs) != 0 {
+		kind, data = []byte("concurrent C"), nil
+		for _, p := range params.CProgs {
+			data = append(data, p.Serialize()...)
+			data = append(data, 0)
+		}
+	}
+	return hash.Hash(kind, data, params.Opts.Serialize(), int64(params.Duration), int64(params.ExitConditions))
+}
+
+// run returns the cached result of the test or executes it with the run callback.
+// Only the runs that crashed the kernel are cached.
+func (cache *verdictCache) run(ctx context.Context, params instance.ExecParams,
+	run func() (*instance.RunResult, error)) (*instance.RunResult, error) {
+	if cache == nil {
+		return run()
+	}
+	key := cacheKey(params)
+	for {
+		cache.mu.Lock()
+		ent := cache.entries[key]
+		if ent == nil {
+			break
+		}
+		cache.mu.Unlock()
+		select {
+		case <-ent.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if ent.err == nil && ent.result.Report != nil {
+			cache.mu.Lock()
+			cache.hits++
+			cache.mu.Unlock()
+			return ent.result, nil
+		}
+		// The run has failed or has not crashed the kernel, let's run it again.
+	}
+	ent := &cacheEntry{ready: make(chan struct{})}
+	cache.entries[key] = ent
+	cache.mu.Unlock()
+
+	ent.result, ent.err = run()
+	if ent.err != nil || ent.result.Report == nil {
+		cache.mu.Lock()
+		delete(cache.entries, key)
+		cache.mu.Unlock()
+	}
+	close(ent.ready)
+	return ent.result, ent.err
+}
+
+func (cache *verdictCache) hitCount() int {
+	if cache == nil {
+		return 0
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.hits
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package repro
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/syzkaller/pkg/stat/sample"
+)
+
+// ReliabilityConfidence is the confidence level of the reliability intervals.
+const ReliabilityConfidence = 0.95
+
+// Reliability summarizes the validation runs of a reproducer.
+type Reliability struct {
+	Runs    int
+	Crashes int
+	// Low and High are the bounds of the confidence interval of the probability
+	// with which the reproducer crashes the kernel.
+	// They are only calculated if the number of runs was fixed in advance (otherwise both are 0),
+	// stopping the runs after a few crashes biases the estimate and invalidates the interval.
+	Low  float64
+	High float64
+}
+
+func newReliability(runs, crashes int) *Reliability {
+	low, high := sample.BinomialInterval(crashes, runs, ReliabilityConfidence)
+	return &Reliability{
+		Runs:    runs,
+		Crashes: crashes,
+		Low:     low,
+		High:    high,
+	}
+}
+
+// HasInterval says if the confidence interval was calculated.
+func (r *Reliability) HasInterval() bool {
+	return r != nil && r.High != 0
+}
+
+// Rate returns the point estimate of the crash probability.
+func (r *Reliability) Rate() float64 {
+	if r == nil || r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Crashes) / float64(r.Runs)
+}
+
+func (r *Reliability) String() string {
+	if r == nil {
+		return "unknown"
+	}
+	if !r.HasInterval() {
+		return fmt.Sprintf("%.2f (%v/%v)", r.Rate(), r.Crashes, r.Runs)
+	}
+	return fmt.Sprintf("%.2f [%.2f-%.2f] (%v/%v)", r.Rate(), r.Low, r.High, r.Crashes, r.Runs)
+}
+
+// calculateReliability invokes cb runs times (up to parallel invocations at once) and counts the crashes.
+// If runs is 0, it invokes cb one by one and stops as soon as the reproducer has crashed the kernel
+// several times, which is enough to tell reliable reproducers from the random crashes,
+// but does not give a confidence interval.
+func calculateReliability(runs, parallel int, cb func() (bool, error)) (*Reliability, error) {
+	const (
+		maxRuns  = 10
+		enoughOK = 3
+	)
+	adaptive := runs <= 0
+	if adaptive {
+		runs, parallel = maxRuns, 1
+	}
+	total, okCount := 0, 0
+	for total < runs && (!adaptive || okCount < enoughOK) {
+		batch := min(max(1, parallel), runs-total)
+		oks := make([]bool, batch)
+		errs := make([]error, batch)
+		var wg sync.WaitGroup
+		for i := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				oks[i], errs[i] = cb()
+			}()
+		}
+		wg.Wait()
+		for i := range batch {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			total++
+			if oks[i] {
+				okCount++
+			}
+		}
+	}
+	if adaptive {
+		return &Reliability{Runs: total, Crashes: okCount}, nil
+	}
+	return newReliability(total, okCount), nil
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/syzkaller/pkg/bisect/minimize"
@@ -34,8 +35,18 @@ type Result struct {
 	// Can be different from what we started reproducing.
 	Report *report.Report
 	// A very rough estimate of the probability with which the resulting syz
-	// reproducer crashes the kernel.
+	// reproducer crashes the kernel (the same as SyzReliability.Rate()).
 	Reliability float64
+	// Results of the validation runs of the syz and the C reproducers.
+	// If Environment.ReliabilityRuns is 0, SyzReliability is only a point estimate
+	// without a confidence interval (see Reliability.HasInterval), since the validation
+	// stops after a few crashes. CReliability is only set if CRepro is set
+	// and Environment.ReliabilityRuns is not 0.
+	SyzReliability *Reliability
+	CReliability   *Reliability
+	// If set, the crash is only reproduced when these programs run concurrently,
+	// each pinned to its own proc. Prog is their concatenation then.
+	ConcurrentProgs []*prog.Prog
 }
 
 type Stats struct {
@@ -61,10 +72,16 @@ type reproContext struct {
 	testTimeouts   []time.Duration
 	startOpts      csource.Options
 	stats          *Stats
-	report         *report.Report
+	report         *reportHolder
 	timeouts       targets.Timeouts
 	observedTitles map[string]bool
 	fast           bool
+	// The maximum number of tests that may run concurrently.
+	parallel        int
+	reliabilityRuns int
+	cache           *verdictCache
+	// Protects observedTitles and stats, which are shared between the concurrent strategies.
+	mu *sync.Mutex
 }
 
 // execInterface describes the interfaces needed by pkg/repro.
@@ -81,6 +98,14 @@ type Environment struct {
 	// The Fast repro mode restricts the repro log bisection,
 	// it skips multiple simpifications and C repro generation.
 	Fast bool
+	// Parallel is the number of VMs the reproduction may use at the same time.
+	// If it's more than 1, independent extraction strategies are run concurrently.
+	Parallel int
+	// ReliabilityRuns is the number of validation runs of the final syz and C reproducers
+	// that are used to estimate their reliability with confidence intervals.
+	// If it's 0, only the syz reproducer is validated: it's run until it crashes the kernel
+	// a few times (at most 10 runs).
+	ReliabilityRuns int
 
 	logf func(string, ...interface{})
 }
@@ -141,14 +166,19 @@ func runInner(ctx context.Context, crashLog []byte, env Environment, exec execIn
 		crashStart:    crashStart,
 		crashExecutor: crashExecutor,
 
-		entries:        entries,
-		testTimeouts:   testTimeouts,
-		startOpts:      createStartOptions(cfg, env.Features, crashType),
-		stats:          new(Stats),
-		timeouts:       cfg.Timeouts,
-		observedTitles: map[string]bool{},
-		fast:           env.Fast,
-		logf:           env.logf,
+		entries:         entries,
+		testTimeouts:    testTimeouts,
+		startOpts:       createStartOptions(cfg, env.Features, crashType),
+		stats:           new(Stats),
+		report:          new(reportHolder),
+		timeouts:        cfg.Timeouts,
+		observedTitles:  map[string]bool{},
+		fast:            env.Fast,
+		parallel:        env.Parallel,
+		reliabilityRuns: env.ReliabilityRuns,
+		cache:           newVerdictCache(),
+		mu:              new(sync.Mutex),
+		logf:            env.logf,
 	}
 	return reproCtx.run()
 }
@@ -159,23 +189,25 @@ func (ctx *reproContext) run() (*Result, *Stats, error) {
 		return nil, nil, err
 	}
 	if res != nil {
-		ctx.reproLogf(3, "repro crashed as (corrupted=%v):\n%s",
-			ctx.report.Corrupted, ctx.report.Report)
+		rep := ctx.report.get()
+		ctx.reproLogf(3, "repro crashed as (corrupted=%v):\n%s", rep.Corrupted, rep.Report)
 		// Try to rerun the repro if the report is corrupted.
-		for attempts := 0; ctx.report.Corrupted && attempts < 3; attempts++ {
+		// The uncached context shares the report with ctx, so the reruns update it.
+		uncached := ctx.withoutCache()
+		for attempts := 0; ctx.report.get().Corrupted && attempts < 3; attempts++ {
 			ctx.reproLogf(3, "report is corrupted, running repro again")
 			if res.CRepro {
-				_, err = ctx.testCProg(res.Prog, res.Duration, res.Opts, false)
+				_, err = uncached.testCResult(res, res.Opts, false)
 			} else {
-				_, err = ctx.testProg(res.Prog, res.Duration, res.Opts, false)
+				_, err = uncached.testResult(res, res.Opts, false)
 			}
 			if err != nil {
 				return nil, nil, err
 			}
 		}
-		ctx.reproLogf(3, "final repro crashed as (corrupted=%v):\n%s",
-			ctx.report.Corrupted, ctx.report.Report)
-		res.Report = ctx.report
+		rep = ctx.report.get()
+		ctx.reproLogf(3, "final repro crashed as (corrupted=%v):\n%s", rep.Corrupted, rep.Report)
+		res.Report = rep
 	}
 	return res, ctx.stats, nil
 }
@@ -227,7 +259,8 @@ func (ctx *reproContext) repro() (*Result, error) {
 
 	reproStart := time.Now()
 	defer func() {
-		ctx.reproLogf(3, "reproducing took %s", time.Since(reproStart))
+		ctx.reproLogf(3, "reproducing took %s, %d tests were served from the cache",
+			time.Since(reproStart), ctx.cache.hitCount())
 		ctx.stats.TotalTime = time.Since(reproStart)
 	}()
 
@@ -243,21 +276,22 @@ func (ctx *reproContext) repro() (*Result, error) {
 		return nil, err
 	}
 
-	// Try extracting C repro without simplifying options first.
 	if !ctx.fast {
-		res, err = ctx.extractC(res)
+		// Try extracting C repro without simplifying options first.
+		// Simplify options and try extracting C repro in the meantime.
+		res, err = ctx.race(func(res *Result) bool {
+			return res.CRepro
+		}, func(ctx *reproContext) (*Result, error) {
+			resCopy := *res
+			return ctx.extractC(&resCopy)
+		}, func(ctx *reproContext) (*Result, error) {
+			resCopy := *res
+			return ctx.simplifyProg(&resCopy)
+		})
 		if err != nil {
 			return nil, err
 		}
 
-		// Simplify options and try extracting C repro.
-		if !res.CRepro {
-			res, err = ctx.simplifyProg(res)
-			if err != nil {
-				return nil, err
-			}
-		}
-
 		// Simplify C related options.
 		if res.CRepro {
 			res, err = ctx.simplifyC(res)
@@ -267,8 +301,9 @@ func (ctx *reproContext) repro() (*Result, error) {
 		}
 	}
 	// Validate the resulting reproducer - a random rare kernel crash might have diverted the process.
-	res.Reliability, err = calculateReliability(func() (bool, error) {
-		ret, err := ctx.testProg(res.Prog, res.Duration, res.Opts, false)
+	uncached := ctx.withoutCache()
+	res.SyzReliability, err = calculateReliability(ctx.reliabilityRuns, ctx.parallel, func() (bool, error) {
+		ret, err := uncached.testResult(res, res.Opts, false)
 		if err != nil {
 			return false, err
 		}
@@ -279,42 +314,37 @@ func (ctx *reproContext) repro() (*Result, error) {
 		ctx.reproLogf(2, "could not calculate reliability, err=%v", err)
 		return nil, err
 	}
+	res.Reliability = res.SyzReliability.Rate()
+	ctx.reproLogf(1, "syz reproducer reliability: %v", res.SyzReliability)
 
 	const minReliability = 0.15
 	if res.Reliability < minReliability {
 		ctx.reproLogf(1, "reproducer is too unreliable: %.2f", res.Reliability)
 		return nil, err
 	}
-
-	return res, nil
-}
-
-func calculateReliability(cb func() (bool, error)) (float64, error) {
-	const (
-		maxRuns  = 10
-		enoughOK = 3
-	)
-	total := 0
-	okCount := 0
-	for i := 0; i < maxRuns && okCount < enoughOK; i++ {
-		total++
-		ok, err := cb()
+	if res.CRepro && ctx.reliabilityRuns > 0 {
+		res.CReliability, err = calculateReliability(ctx.reliabilityRuns, ctx.parallel, func() (bool, error) {
+			ret, err := uncached.testCResult(res, res.Opts, false)
+			if err != nil {
+				return false, err
+			}
+			ctx.reproLogf(2, "C validation run: crashed=%v", ret.Crashed)
+			return ret.Crashed, nil
+		})
 		if err != nil {
-			return 0, err
-		}
-		if ok {
-			okCount++
+			ctx.reproLogf(2, "could not calculate C reliability, err=%v", err)
+			return nil, err
 		}
+		ctx.reproLogf(1, "C reproducer reliability: %v", res.CReliability)
 	}
-	return float64(okCount) / float64(total), nil
+
+	return res, nil
 }
 
 func (ctx *reproContext) extractProg(entries []*prog.LogEntry) (*Result, error) {
 	ctx.reproLogf(2, "extracting reproducer from %v programs", len(entries))
 	start := time.Now()
-	defer func() {
-		ctx.stats.ExtractProgTime = time.Since(start)
-	}()
+	defer ctx.recordTime(&ctx.stats.ExtractProgTime, start)
 
 	var toTest []*prog.LogEntry
 	if ctx.crashExecutor != nil {
@@ -337,27 +367,22 @@ func (ctx *reproContext) extractProg(entries []*prog.LogEntry) (*Result, error)
 	for i, timeout := range ctx.testTimeouts {
 		// Execute each program separately to detect simple crashes caused by a single program.
 		// Programs are executed in reverse order, usually the last program is the guilty one.
-		res, err := ctx.extractProgSingle(toTest, timeout)
-		if err != nil {
-			return nil, err
-		}
-		if res != nil {
-			ctx.reproLogf(3, "found reproducer with %d syscalls", len(res.Prog.Calls))
-			return res, nil
-		}
-
+		strategies := []strategy{func(ctx *reproContext) (*Result, error) {
+			return ctx.extractProgSingle(toTest, timeout)
+		}}
 		// Don't try bisecting if there's only one entry.
-		if len(entries) == 1 {
-			continue
-		}
-
-		if ctx.fast && i+1 < len(ctx.testTimeouts) {
-			// Bisect only under the biggest timeout.
-			continue
+		// In the fast mode, bisect only under the biggest timeout.
+		if len(entries) > 1 && (!ctx.fast || i+1 == len(ctx.testTimeouts)) {
+			// Execute all programs and bisect the log to find multiple guilty programs.
+			strategies = append(strategies, func(ctx *reproContext) (*Result, error) {
+				return ctx.extractProgBisect(entries, timeout)
+			})
+			// The crash may also be caused by a race between programs executed in different procs.
+			strategies = append(strategies, func(ctx *reproContext) (*Result, error) {
+				return ctx.extractProgConcurrent(entries, timeout)
+			})
 		}
-
-		// Execute all programs and bisect the log to find multiple guilty programs.
-		res, err = ctx.extractProgBisect(entries, timeout)
+		res, err := ctx.race(func(res *Result) bool { return true }, strategies...)
 		if err != nil {
 			return nil, err
 		}
@@ -371,6 +396,15 @@ func (ctx *reproContext) extractProg(entries []*prog.LogEntry) (*Result, error)
 	return nil, nil
 }
 
+func copyEntries(entries []*prog.LogEntry) []*prog.LogEntry {
+	ret := make([]*prog.LogEntry, len(entries))
+	for i, ent := range entries {
+		ent1 := *ent
+		ret[i] = &ent1
+	}
+	return ret
+}
+
 // Extract last program on every proc.
 func lastEntries(entries []*prog.LogEntry) []*prog.LogEntry {
 	procs := make(map[int]int)
@@ -392,21 +426,18 @@ func lastEntries(entries []*prog.LogEntry) []*prog.LogEntry {
 func (ctx *reproContext) extractProgSingle(entries []*prog.LogEntry, duration time.Duration) (*Result, error) {
 	ctx.reproLogf(3, "single: executing %d programs separately with timeout %s", len(entries), duration)
 
-	opts := ctx.startOpts
-	for _, ent := range entries {
-		ret, err := ctx.testProg(ent.P, duration, opts, false)
-		if err != nil {
-			return nil, err
-		}
-		if ret.Crashed {
-			res := &Result{
-				Prog:     ent.P,
-				Duration: max(duration, ret.Duration*3/2),
-				Opts:     opts,
-			}
-			ctx.reproLogf(3, "single: successfully extracted reproducer")
-			return res, nil
+	idx, ret, err := ctx.testEach(entries, duration)
+	if err != nil {
+		return nil, err
+	}
+	if idx != -1 {
+		res := &Result{
+			Prog:     entries[idx].P,
+			Duration: max(duration, ret.Duration*3/2),
+			Opts:     ctx.startOpts,
 		}
+		ctx.reproLogf(3, "single: successfully extracted reproducer")
+		return res, nil
 	}
 
 	ctx.reproLogf(3, "single: failed to extract reproducer")
@@ -459,6 +490,8 @@ func (ctx *reproContext) extractProgBisect(entries []*prog.LogEntry, baseDuratio
 func (ctx *reproContext) concatenateProgs(entries []*prog.LogEntry, dur time.Duration) (*Result, error) {
 	ctx.reproLogf(3, "bisect: concatenate %d entries", len(entries))
 	if len(entries) > 1 {
+		// The entries are shared with the concurrently running strategies, so don't minimize them in place.
+		entries = copyEntries(entries)
 		// There's a risk of exceeding prog.MaxCalls, so let's first minimize
 		// all entries separately.
 		for i := 0; i < len(entries); i++ {
@@ -524,11 +557,13 @@ func (ctx *reproContext) concatenateProgs(entries []*prog.LogEntry, dur time.Dur
 
 // Minimize calls and arguments.
 func (ctx *reproContext) minimizeProg(res *Result) (*Result, error) {
+	if len(res.ConcurrentProgs) != 0 {
+		// Concurrent programs are minimized jointly during extraction.
+		return res, nil
+	}
 	ctx.reproLogf(2, "minimizing guilty program")
 	start := time.Now()
-	defer func() {
-		ctx.stats.MinimizeProgTime = time.Since(start)
-	}()
+	defer ctx.recordTime(&ctx.stats.MinimizeProgTime, start)
 
 	mode := prog.MinimizeCrash
 	if ctx.fast {
@@ -562,17 +597,15 @@ func (ctx *reproContext) minimizeProg(res *Result) (*Result, error) {
 func (ctx *reproContext) simplifyProg(res *Result) (*Result, error) {
 	ctx.reproLogf(2, "simplifying guilty program options")
 	start := time.Now()
-	defer func() {
-		ctx.stats.SimplifyProgTime = time.Since(start)
-	}()
+	defer ctx.recordTime(&ctx.stats.SimplifyProgTime, start)
 
 	// Do further simplifications.
 	for _, simplify := range progSimplifies {
 		opts := res.Opts
-		if !simplify(&opts) || !checkOpts(&opts, ctx.timeouts, res.Duration) {
+		if !simplify(&opts) || !checkOpts(&opts, ctx.timeouts, res.Duration) || !res.supportsOpts(opts) {
 			continue
 		}
-		ret, err := ctx.testProg(res.Prog, res.Duration, opts, true)
+		ret, err := ctx.testResult(res, opts, true)
 		if err != nil {
 			return nil, err
 		}
@@ -600,11 +633,9 @@ func (ctx *reproContext) simplifyProg(res *Result) (*Result, error) {
 func (ctx *reproContext) extractC(res *Result) (*Result, error) {
 	ctx.reproLogf(2, "extracting C reproducer")
 	start := time.Now()
-	defer func() {
-		ctx.stats.ExtractCTime = time.Since(start)
-	}()
+	defer ctx.recordTime(&ctx.stats.ExtractCTime, start)
 
-	ret, err := ctx.testCProg(res.Prog, res.Duration, res.Opts, true)
+	ret, err := ctx.testCResult(res, res.Opts, true)
 	if err != nil {
 		return nil, err
 	}
@@ -616,16 +647,14 @@ func (ctx *reproContext) extractC(res *Result) (*Result, error) {
 func (ctx *reproContext) simplifyC(res *Result) (*Result, error) {
 	ctx.reproLogf(2, "simplifying C reproducer")
 	start := time.Now()
-	defer func() {
-		ctx.stats.SimplifyCTime = time.Since(start)
-	}()
+	defer ctx.recordTime(&ctx.stats.SimplifyCTime, start)
 
 	for _, simplify := range cSimplifies {
 		opts := res.Opts
-		if !simplify(&opts) || !checkOpts(&opts, ctx.timeouts, res.Duration) {
+		if !simplify(&opts) || !checkOpts(&opts, ctx.timeouts, res.Duration) || !res.supportsOpts(opts) {
 			continue
 		}
-		ret, err := ctx.testCProg(res.Prog, res.Duration, opts, true)
+		ret, err := ctx.testCResult(res, opts, true)
 		if err != nil {
 			return nil, err
 		}
@@ -702,15 +731,18 @@ func (ctx *reproContext) getVerdict(callback func() (rep *instance.RunResult, er
 		ctx.reproLogf(2, "not a leak crash: %v", rep.Title)
 		return verdict{false, result.Duration}, nil
 	}
+	ctx.mu.Lock()
 	if strict && len(ctx.observedTitles) > 0 {
 		if !ctx.observedTitles[rep.Title] {
+			ctx.mu.Unlock()
 			ctx.reproLogf(2, "a never seen crash title: %v, ignore", rep.Title)
 			return verdict{false, result.Duration}, nil
 		}
 	} else {
 		ctx.observedTitles[rep.Title] = true
 	}
-	ctx.report = rep
+	ctx.mu.Unlock()
+	ctx.report.set(rep)
 	return verdict{true, result.Duration}, nil
 }
 
@@ -746,23 +778,27 @@ func (ctx *reproContext) testProgs(entries []*prog.LogEntry, duration time.Durat
 	}
 	ctx.reproLogf(2, "testing program (duration=%v, %+v): %s", duration, opts, program)
 	ctx.reproLogf(3, "detailed listing:\n%s", pstr)
-	return ctx.getVerdict(func() (*instance.RunResult, error) {
-		return ctx.exec.Run(ctx.ctx, instance.ExecParams{
-			SyzProg:  pstr,
-			Opts:     opts,
-			Duration: duration,
-		}, ctx.reproLogf)
+	return ctx.testParams(instance.ExecParams{
+		SyzProg:  pstr,
+		Opts:     opts,
+		Duration: duration,
 	}, strict)
 }
 
 func (ctx *reproContext) testCProg(p *prog.Prog, duration time.Duration, opts csource.Options,
 	strict bool) (ret verdict, err error) {
+	return ctx.testParams(instance.ExecParams{
+		CProg:    p,
+		Opts:     opts,
+		Duration: duration,
+	}, strict)
+}
+
+func (ctx *reproContext) testParams(params instance.ExecParams, strict bool) (verdict, error) {
 	return ctx.getVerdict(func() (*instance.RunResult, error) {
-		return ctx.exec.Run(ctx.ctx, instance.ExecParams{
-			CProg:    p,
-			Opts:     opts,
-			Duration: duration,
-		}, ctx.reproLogf)
+		return ctx.cache.run(ctx.ctx, params, func() (*instance.RunResult, error) {
+			return ctx.exec.Run(ctx.ctx, params, ctx.reproLogf)
+		})
 	}, strict)
 }
 
@@ -772,7 +808,15 @@ func (ctx *reproContext) reproLogf(level int, format string, args ...interface{}
 	}
 	prefix := fmt.Sprintf("reproducing crash '%v': ", ctx.crashTitle)
 	log.Logf(level, prefix+format, args...)
+	ctx.mu.Lock()
 	ctx.stats.Log = append(ctx.stats.Log, []byte(fmt.Sprintf(format, args...)+"\n")...)
+	ctx.mu.Unlock()
+}
+
+func (ctx *reproContext) recordTime(stat *time.Duration, start time.Time) {
+	ctx.mu.Lock()
+	*stat = time.Since(start)
+	ctx.mu.Unlock()
 }
 
 func (ctx *reproContext) bisectProgs(progs []*prog.LogEntry, pred func([]*prog.LogEntry) (bool, error)) (
@@ -1037,7 +1081,13 @@ func (stats *Stats) FullLog() []byte {
 }
 
 func (repro *Result) CProgram() ([]byte, error) {
-	cprog, err := csource.Write(repro.Prog, repro.Opts)
+	var cprog []byte
+	var err error
+	if len(repro.ConcurrentProgs) != 0 {
+		cprog, err = csource.WriteConcurrent(repro.ConcurrentProgs, repro.Opts)
+	} else {
+		cprog, err = csource.Write(repro.Prog, repro.Opts)
+	}
 	if err == nil {
 		formatted, err := csource.Format(cprog)
 		if err == nil {
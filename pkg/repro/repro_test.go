@@ -4,11 +4,14 @@
 package repro
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/rand"
 	"regexp"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -37,6 +40,7 @@ func TestBisect(t *testing.T) {
 	ctx := &reproContext{
 		stats: new(Stats),
 		logf:  t.Logf,
+		mu:    new(sync.Mutex),
 	}
 
 	rd, iters := initTest(t)
@@ -114,18 +118,39 @@ func TestSimplifies(t *testing.T) {
 type testExecInterface struct {
 	// For now only do the simplest imitation.
 	run func([]byte) (*instance.RunResult, error)
+	// If set, used instead of run.
+	runParams func(instance.ExecParams) (*instance.RunResult, error)
 }
 
-func (tei *testExecInterface) Run(_ context.Context, params instance.ExecParams,
+func (tei *testExecInterface) Run(ctx context.Context, params instance.ExecParams,
 	_ instance.ExecutorLogger) (*instance.RunResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if tei.runParams != nil {
+		return tei.runParams(params)
+	}
 	syzProg := params.SyzProg
 	if params.CProg != nil {
 		syzProg = params.CProg.Serialize()
 	}
+	for _, p := range params.CProgs {
+		syzProg = append(syzProg, p.Serialize()...)
+	}
 	return tei.run(syzProg)
 }
 
 func runTestRepro(t *testing.T, log string, exec execInterface) (*Result, *Stats, error) {
+	return runTestReproParallel(t, log, exec, 0)
+}
+
+func runTestReproParallel(t *testing.T, log string, exec execInterface, parallel int) (*Result, *Stats, error) {
+	return runTestReproEnv(t, log, exec, func(env *Environment) {
+		env.Parallel = parallel
+	})
+}
+
+func runTestReproEnv(t *testing.T, log string, exec execInterface, setup func(*Environment)) (*Result, *Stats, error) {
 	mgrConfig := &mgrconfig.Config{
 		Derived: mgrconfig.Derived{
 			TargetOS:     targets.Linux,
@@ -149,6 +174,7 @@ func runTestRepro(t *testing.T, log string, exec execInterface) (*Result, *Stats
 		Reporter: reporter,
 		logf:     t.Logf,
 	}
+	setup(&env)
 	return runInner(context.Background(), []byte(log), env, exec)
 }
 
@@ -314,6 +340,325 @@ func TestFlakyCrashes(t *testing.T) {
 	assert.Greater(t, success, iters/3*2, "must succeed >2/3 of cases")
 }
 
+func TestParallelRepro(t *testing.T) {
+	// The guilty program is the last one to be tested separately.
+	execLog := "2015/12/21 12:18:05 executing program 1:\npause()\nalarm(0xa)\n"
+	for proc := 2; proc <= 12; proc++ {
+		execLog += fmt.Sprintf("2015/12/21 12:18:10 executing program %v:\ngetpid()\nalarm(0x%x)\n", proc, 100+proc)
+	}
+	// The critical path is measured in runs rather than in time: each run is one step deeper
+	// than the deepest run finished before it started. The sleep only gives concurrent runs
+	// a chance to overlap, the result does not depend on timing otherwise.
+	run := func(parallel int) (runs, peak, depth int) {
+		var mu sync.Mutex
+		active, done := 0, 0
+		result, _, err := runTestReproParallel(t, execLog, &testExecInterface{
+			run: func(log []byte) (*instance.RunResult, error) {
+				mu.Lock()
+				runs++
+				active++
+				peak = max(peak, active)
+				runDepth := done + 1
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				active--
+				done = max(done, runDepth)
+				mu.Unlock()
+				return testExecRunner(log)
+			},
+		}, parallel)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, expectedReproducer, string(result.Prog.Serialize()))
+		return runs, peak, done
+	}
+	seqRuns, seqPeak, seqDepth := run(1)
+	parRuns, parPeak, parDepth := run(4)
+	t.Logf("sequential: %v runs, %v peak concurrent runs, %v runs on the critical path", seqRuns, seqPeak, seqDepth)
+	t.Logf("parallel: %v runs, %v peak concurrent runs, %v runs on the critical path", parRuns, parPeak, parDepth)
+	assert.Equal(t, 1, seqPeak)
+	assert.Equal(t, seqRuns, seqDepth)
+	assert.Greater(t, parPeak, 1)
+	assert.LessOrEqual(t, parPeak, 4)
+	assert.Less(t, parDepth, seqDepth)
+}
+
+func TestConcurrentRepro(t *testing.T) {
+	execLog := `
+2015/12/21 12:18:05 executing program 1:
+getpid()
+pause()
+2015/12/21 12:18:05 executing program 2:
+getuid()
+alarm(0xb)
+2015/12/21 12:18:05 executing program 3:
+getpid()
+`
+	// The crash happens only if pause() and alarm(0xb) run concurrently in different procs.
+	crashes := func(progs [][]byte) bool {
+		pause, alarm := -1, -1
+		for i, p := range progs {
+			if bytes.Contains(p, []byte("pause()")) {
+				pause = i
+			}
+			if bytes.Contains(p, []byte("alarm(0xb)")) {
+				alarm = i
+			}
+		}
+		return pause != -1 && alarm != -1 && pause != alarm
+	}
+	for _, parallel := range []int{1, 4} {
+		t.Run(fmt.Sprint(parallel), func(t *testing.T) {
+			result, _, err := runTestReproParallel(t, execLog, &testExecInterface{
+				runParams: func(params instance.ExecParams) (*instance.RunResult, error) {
+					var progs [][]byte
+					if params.PinProcs {
+						for _, p := range regexp.MustCompile(`executing program [0-9]+:\n`).Split(
+							string(params.SyzProg), -1) {
+							progs = append(progs, []byte(p))
+						}
+					}
+					for _, p := range params.CProgs {
+						progs = append(progs, p.Serialize())
+					}
+					if crashes(progs) {
+						return fakeCrashResult("crashed"), nil
+					}
+					return fakeCrashResult(""), nil
+				},
+			}, parallel)
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			require.Len(t, result.ConcurrentProgs, 2)
+			var progs []string
+			for _, p := range result.ConcurrentProgs {
+				progs = append(progs, string(p.Serialize()))
+			}
+			sort.Strings(progs)
+			assert.Equal(t, []string{"alarm(0xb)\n", "pause()\n"}, progs)
+			assert.True(t, result.CRepro)
+			assert.Equal(t, 1.0, result.SyzReliability.Rate())
+			// C reproducers are only validated if the reliability runs are requested.
+			assert.Nil(t, result.CReliability)
+			assert.Equal(t, 2, result.Opts.Procs)
+			// The test manager config does not set the slowdown.
+			result.Opts.Slowdown = 1
+			cprog, err := result.CProgram()
+			require.NoError(t, err)
+			assert.Contains(t, string(cprog), "procid")
+		})
+	}
+}
+
+func TestCorruptedReportRerun(t *testing.T) {
+	// All crashes are corrupted until the final repro is rerun.
+	var rerun atomic.Bool
+	var reruns atomic.Int32
+	result, _, err := runTestReproEnv(t, testReproLog, &testExecInterface{
+		run: func(log []byte) (*instance.RunResult, error) {
+			res, err := testExecRunner(log)
+			if res != nil && res.Report != nil {
+				res.Report.Corrupted = !rerun.Load()
+				res.Report.Report = []byte(fmt.Sprintf("corrupted=%v", res.Report.Corrupted))
+			}
+			return res, err
+		},
+	}, func(env *Environment) {
+		env.Parallel = 4
+		env.logf = func(format string, args ...interface{}) {
+			if format == "report is corrupted, running repro again" {
+				reruns.Add(1)
+				rerun.Store(true)
+			}
+			t.Logf(format, args...)
+		}
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.EqualValues(t, 1, reruns.Load())
+	assert.False(t, result.Report.Corrupted)
+	assert.Equal(t, "corrupted=false", string(result.Report.Report))
+}
+
+func TestRaceReport(t *testing.T) {
+	ctx := &reproContext{
+		ctx:      context.Background(),
+		stats:    new(Stats),
+		logf:     t.Logf,
+		mu:       new(sync.Mutex),
+		parallel: 2,
+		report:   new(reportHolder),
+	}
+	loserDone := make(chan bool)
+	res, err := ctx.race(func(res *Result) bool { return res.Prog != nil },
+		func(ctx *reproContext) (*Result, error) {
+			ctx.report.set(&report.Report{Title: "winner"})
+			return &Result{Prog: new(prog.Prog)}, nil
+		},
+		func(ctx *reproContext) (*Result, error) {
+			// The loser sees a crash after the race is over.
+			<-ctx.ctx.Done()
+			ctx.report.set(&report.Report{Title: "loser"})
+			close(loserDone)
+			return nil, nil
+		},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	<-loserDone
+	assert.Equal(t, "winner", ctx.report.get().Title)
+}
+
+func TestRacePreference(t *testing.T) {
+	ctx := &reproContext{
+		ctx:      context.Background(),
+		stats:    new(Stats),
+		logf:     t.Logf,
+		mu:       new(sync.Mutex),
+		parallel: 3,
+		report:   new(reportHolder),
+	}
+	preferredDone := make(chan bool)
+	fallbackDone := make(chan bool)
+	canceled := make(chan bool)
+	res, err := ctx.race(func(res *Result) bool { return res.CRepro },
+		func(ctx *reproContext) (*Result, error) {
+			// The preferred strategy finishes last, but its result is taken.
+			<-fallbackDone
+			close(preferredDone)
+			return &Result{CRepro: true, Duration: 1}, nil
+		},
+		func(ctx *reproContext) (*Result, error) {
+			close(fallbackDone)
+			return &Result{CRepro: true, Duration: 2}, nil
+		},
+		func(ctx *reproContext) (*Result, error) {
+			// The least preferred strategy is canceled once the race is decided.
+			<-preferredDone
+			<-ctx.ctx.Done()
+			close(canceled)
+			return nil, nil
+		},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	assert.EqualValues(t, 1, res.Duration)
+	<-canceled
+
+	// If the preferred strategies do not find anything, the next one wins.
+	res, err = ctx.race(func(res *Result) bool { return res.CRepro },
+		func(ctx *reproContext) (*Result, error) {
+			return nil, nil
+		},
+		func(ctx *reproContext) (*Result, error) {
+			return &Result{CRepro: false, Duration: 2}, nil
+		},
+		func(ctx *reproContext) (*Result, error) {
+			return &Result{CRepro: true, Duration: 3}, nil
+		},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	assert.EqualValues(t, 3, res.Duration)
+}
+
+func TestVerdictCache(t *testing.T) {
+	cache := newVerdictCache()
+	var runs atomic.Int32
+	run := func() (*instance.RunResult, error) {
+		runs.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return fakeCrashResult("crashed"), nil
+	}
+	params := instance.ExecParams{
+		SyzProg:  []byte("getpid()\n"),
+		Duration: time.Minute,
+	}
+	// Concurrent requests for the same test are served by a single run.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := cache.run(context.Background(), params, run)
+			assert.NoError(t, err)
+			assert.Equal(t, "crashed", res.Report.Title)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, runs.Load())
+	assert.Equal(t, 3, cache.hitCount())
+
+	// Different duration is a different test.
+	params.Duration = 2 * time.Minute
+	_, err := cache.run(context.Background(), params, run)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, runs.Load())
+
+	// Failed runs are not cached.
+	params.Duration = 3 * time.Minute
+	_, err = cache.run(context.Background(), params, func() (*instance.RunResult, error) {
+		return nil, fmt.Errorf("VM failure")
+	})
+	require.Error(t, err)
+	_, err = cache.run(context.Background(), params, run)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, runs.Load())
+
+	// Runs that did not crash the kernel are not cached either.
+	params.Duration = 4 * time.Minute
+	noCrash := func() (*instance.RunResult, error) {
+		runs.Add(1)
+		return fakeCrashResult(""), nil
+	}
+	for i := 0; i < 2; i++ {
+		res, err := cache.run(context.Background(), params, noCrash)
+		require.NoError(t, err)
+		assert.Nil(t, res.Report)
+	}
+	assert.EqualValues(t, 5, runs.Load())
+	assert.Equal(t, 3, cache.hitCount())
+}
+
+func TestCalculateReliability(t *testing.T) {
+	var runs atomic.Int32
+	flaky := func() (bool, error) {
+		return runs.Add(1)%2 == 0, nil
+	}
+	// By default, we stop after enough crashes, and don't calculate the interval.
+	ret, err := calculateReliability(0, 4, flaky)
+	require.NoError(t, err)
+	assert.Equal(t, 6, ret.Runs)
+	assert.Equal(t, 3, ret.Crashes)
+	assert.EqualValues(t, 6, runs.Load())
+	assert.Equal(t, 0.5, ret.Rate())
+	assert.False(t, ret.HasInterval())
+	assert.Equal(t, "0.50 (3/6)", ret.String())
+
+	// The requested number of runs is always done, but several at once.
+	runs.Store(0)
+	ret, err = calculateReliability(20, 3, flaky)
+	require.NoError(t, err)
+	assert.Equal(t, 20, ret.Runs)
+	assert.Equal(t, 10, ret.Crashes)
+	assert.EqualValues(t, 20, runs.Load())
+	assert.True(t, ret.HasInterval())
+	assert.Less(t, ret.Low, 0.5)
+	assert.Greater(t, ret.High, 0.5)
+
+	// More runs give a narrower interval.
+	narrow := newReliability(100, 50)
+	assert.Greater(t, narrow.Low, ret.Low)
+	assert.Less(t, narrow.High, ret.High)
+	assert.Equal(t, "0.50 [0.40-0.60] (50/100)", narrow.String())
+
+	_, err = calculateReliability(5, 2, func() (bool, error) {
+		return false, fmt.Errorf("VM failure")
+	})
+	assert.Error(t, err)
+}
+
 func BenchmarkCalculateReliability(b *testing.B) {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
@@ -328,13 +673,13 @@ func BenchmarkCalculateReliability(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
 				runs := 0
-				ret, err := calculateReliability(func() (bool, error) {
+				ret, err := calculateReliability(0, 1, func() (bool, error) {
 					runs++
 					return r.Float64() < base, nil
 				})
 				require.NoError(b, err)
 				neededRuns = append(neededRuns, runs)
-				reliability = append(reliability, ret)
+				reliability = append(reliability, ret.Rate())
 			}
 			b.StopTimer()
 
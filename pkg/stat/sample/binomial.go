@@ -0,0 +1,23 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sample
+
+import "math"
+
+// BinomialInterval returns the Wilson score confidence interval for the success probability
+// of a Bernoulli trial given the number of successes out of total independent trials.
+// Unlike the normal approximation, the interval is well-behaved for small totals and
+// for success rates close to 0 or 1. Confidence is the confidence level (e.g. 0.95).
+func BinomialInterval(successes, total int, confidence float64) (low, high float64) {
+	if total <= 0 {
+		return 0, 1
+	}
+	n := float64(total)
+	p := float64(successes) / n
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	denom := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denom
+	delta := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denom
+	return math.Max(0, center-delta), math.Min(1, center+delta)
+}
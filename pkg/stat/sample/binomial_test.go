@@ -0,0 +1,36 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sample
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinomialInterval(t *testing.T) {
+	tests := []struct {
+		successes int
+		total     int
+		low       float64
+		high      float64
+	}{
+		{0, 0, 0, 1},
+		{0, 10, 0, 0.2775},
+		{10, 10, 0.7225, 1},
+		{3, 10, 0.1078, 0.6032},
+		{50, 100, 0.4038, 0.5962},
+		{1, 1000, 0.0002, 0.0056},
+	}
+	for _, test := range tests {
+		low, high := BinomialInterval(test.successes, test.total, 0.95)
+		assert.InDelta(t, test.low, low, 0.0001, "%v/%v", test.successes, test.total)
+		assert.InDelta(t, test.high, high, 0.0001, "%v/%v", test.successes, test.total)
+	}
+	// The interval shrinks with the confidence level.
+	low90, high90 := BinomialInterval(3, 10, 0.9)
+	low99, high99 := BinomialInterval(3, 10, 0.99)
+	assert.Less(t, low99, low90)
+	assert.Greater(t, high99, high90)
+}
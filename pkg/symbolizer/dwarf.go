@@ -0,0 +1,627 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package symbolizer
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/sys/targets"
+)
+
+// dwarfSymbolizer symbolizes PCs in-process using DWARF debug info.
+// It produces the same frames as addr2line -afi and falls back to addr2line
+// for binaries it can't handle (e.g. relocatable kernel modules).
+type dwarfSymbolizer struct {
+	target   *targets.Target
+	fallback *addr2Line
+	// Binaries referenced by the symbolizer, they are released on Close.
+	bins map[string]*dwarfBinary
+}
+
+func (s *dwarfSymbolizer) Symbolize(bin string, pcs ...uint64) ([]Frame, error) {
+	if s.bins == nil {
+		s.bins = make(map[string]*dwarfBinary)
+	}
+	b, err := acquireDWARFBinary(bin, s.bins[bin])
+	if b != nil {
+		s.bins[bin] = b
+	}
+	if errors.Is(err, errNoDWARF) {
+		if s.fallback == nil {
+			s.fallback = &addr2Line{target: s.target}
+		}
+		return s.fallback.Symbolize(bin, pcs...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var frames []Frame
+	for _, pc := range pcs {
+		frames1, err := b.symbolize(pc)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frames1...)
+	}
+	return frames, nil
+}
+
+func (s *dwarfSymbolizer) Close() {
+	for bin, b := range s.bins {
+		releaseDWARFBinary(bin, b)
+	}
+	s.bins = nil
+	if s.fallback != nil {
+		s.fallback.Close()
+	}
+}
+
+var errNoDWARF = errors.New("no usable DWARF")
+
+// dwarfBinaries caches indexes of binaries, so that they are shared between all symbolizers
+// (e.g. between goroutines that symbolize coverage in parallel) and are not re-read for each report.
+// The indexes are reference counted, an index is dropped when the last symbolizer that uses it is closed.
+var dwarfBinaries = struct {
+	mu   sync.Mutex
+	bins map[string]*dwarfBinary
+}{bins: make(map[string]*dwarfBinary)}
+
+// dwarfBinary is an index of the DWARF info of a binary. Compilation units are indexed
+// eagerly, while their line tables and function trees are parsed on first use.
+type dwarfBinary struct {
+	size  int64
+	mtime time.Time
+	// The number of symbolizers that use the binary, protected by dwarfBinaries.mu.
+	refs  int
+	once  sync.Once
+	err   error
+	data  *dwarf.Data
+	units []unitRange
+	// Function symbols sorted by address, used for code without DWARF info.
+	symbols []funcSymbol
+	// All units of a binary include the same headers, so we intern strings to save memory.
+	interner Interner
+}
+
+type funcSymbol struct {
+	addr uint64
+	size uint64
+	name string
+	// Name of the STT_FILE symbol the local symbol belongs to.
+	file string
+}
+
+type unitRange struct {
+	start, end uint64
+	unit       *dwarfUnit
+}
+
+type dwarfUnit struct {
+	offset dwarf.Offset
+	once   sync.Once
+	err    error
+	lines  []lineRow
+	files  []string
+	funcs  []funcRange
+	// callFiles is the file table of the line program, it's indexed by call file attributes.
+	callFiles []string
+}
+
+// lineRow is a row of the line table, it covers PCs up to the next row.
+type lineRow struct {
+	pc   uint64
+	file int32
+	line int32
+	// Marks the end of a sequence, there is no line info for PCs past it.
+	end bool
+}
+
+type funcRange struct {
+	start, end uint64
+	fn         *funcNode
+}
+
+// funcNode is a subprogram or an inlined subroutine.
+type funcNode struct {
+	ranges [][2]uint64
+	name   string
+	// The name is a linkage name, such names are not replaced with symbol names.
+	linkage bool
+	// Position of the call of an inlined subroutine.
+	callFile string
+	callLine int
+	inlined  []*funcNode
+	origin   dwarf.Offset
+}
+
+// acquireDWARFBinary returns the index of the binary bin and takes a reference to it.
+// old is the index of the binary the caller already holds (if any), it's released
+// if the binary has changed since then.
+func acquireDWARFBinary(bin string, old *dwarfBinary) (*dwarfBinary, error) {
+	stat, err := os.Stat(bin)
+	if err != nil {
+		return nil, err
+	}
+	dwarfBinaries.mu.Lock()
+	b := dwarfBinaries.bins[bin]
+	if b == nil || b.size != stat.Size() || !b.mtime.Equal(stat.ModTime()) {
+		// Note: the binary may be rebuilt in place, e.g. by syz-ci.
+		b = &dwarfBinary{size: stat.Size(), mtime: stat.ModTime()}
+		dwarfBinaries.bins[bin] = b
+	}
+	if b != old {
+		b.refs++
+		if old != nil {
+			releaseDWARFBinaryLocked(bin, old)
+		}
+	}
+	dwarfBinaries.mu.Unlock()
+	b.once.Do(func() {
+		b.err = b.load(bin)
+	})
+	return b, b.err
+}
+
+func releaseDWARFBinary(bin string, b *dwarfBinary) {
+	dwarfBinaries.mu.Lock()
+	defer dwarfBinaries.mu.Unlock()
+	releaseDWARFBinaryLocked(bin, b)
+}
+
+func releaseDWARFBinaryLocked(bin string, b *dwarfBinary) {
+	b.refs--
+	if b.refs == 0 && dwarfBinaries.bins[bin] == b {
+		delete(dwarfBinaries.bins, bin)
+	}
+}
+
+func (b *dwarfBinary) load(bin string) error {
+	file, err := elf.Open(bin)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNoDWARF, err)
+	}
+	defer file.Close()
+	if file.Type != elf.ET_EXEC && file.Type != elf.ET_DYN {
+		return fmt.Errorf("%w: unsupported ELF type %v", errNoDWARF, file.Type)
+	}
+	if file.Section(".debug_info") == nil && file.Section(".zdebug_info") == nil {
+		return fmt.Errorf("%w: no .debug_info section", errNoDWARF)
+	}
+	b.data, err = file.DWARF()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNoDWARF, err)
+	}
+	r := b.data.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return fmt.Errorf("failed to read DWARF in %v: %w", bin, err)
+		}
+		if e == nil {
+			break
+		}
+		if e.Tag != dwarf.TagCompileUnit {
+			r.SkipChildren()
+			continue
+		}
+		ranges, err := b.data.Ranges(e)
+		if err != nil {
+			return fmt.Errorf("failed to read DWARF in %v: %w", bin, err)
+		}
+		unit := &dwarfUnit{offset: e.Offset}
+		for _, rng := range ranges {
+			b.units = append(b.units, unitRange{rng[0], rng[1], unit})
+		}
+		r.SkipChildren()
+	}
+	sort.Slice(b.units, func(i, j int) bool {
+		return b.units[i].start < b.units[j].start
+	})
+	return b.loadSymbols(file)
+}
+
+func (b *dwarfBinary) loadSymbols(file *elf.File) error {
+	symbols, err := file.Symbols()
+	if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
+		return err
+	}
+	srcFile := ""
+	for _, sym := range symbols {
+		switch elf.ST_TYPE(sym.Info) {
+		case elf.STT_FILE:
+			srcFile = sym.Name
+		case elf.STT_FUNC:
+			if sym.Section == elf.SHN_UNDEF || sym.Section >= elf.SHN_LORESERVE {
+				continue
+			}
+			fn := funcSymbol{
+				addr: sym.Value,
+				size: sym.Size,
+				name: b.interner.Do(sym.Name),
+			}
+			// Like addr2line, we attribute only local symbols to source files.
+			if elf.ST_BIND(sym.Info) == elf.STB_LOCAL {
+				fn.file = b.interner.Do(srcFile)
+			}
+			b.symbols = append(b.symbols, fn)
+		}
+	}
+	// Like llvm-addr2line, out of aliases take the largest one, and the last one in the symbol table
+	// out of the same-sized ones (local symbols go first, so this prefers global symbols).
+	sort.SliceStable(b.symbols, func(i, j int) bool {
+		a, b := b.symbols[i], b.symbols[j]
+		return a.addr < b.addr || a.addr == b.addr && a.size < b.size
+	})
+	n := 0
+	for i, sym := range b.symbols {
+		if i+1 < len(b.symbols) && b.symbols[i+1].addr == sym.addr {
+			continue
+		}
+		b.symbols[n] = sym
+		n++
+	}
+	b.symbols = b.symbols[:n]
+	return nil
+}
+
+// lookupSymbol returns the function symbol that contains pc.
+func (b *dwarfBinary) lookupSymbol(pc uint64) *funcSymbol {
+	idx := sort.Search(len(b.symbols), func(i int) bool {
+		return b.symbols[i].addr > pc
+	}) - 1
+	if idx < 0 {
+		return nil
+	}
+	sym := &b.symbols[idx]
+	if sym.size != 0 && pc >= sym.addr+sym.size {
+		return nil
+	}
+	return sym
+}
+
+func (b *dwarfBinary) symbolize(pc uint64) ([]Frame, error) {
+	idx := sort.Search(len(b.units), func(i int) bool {
+		return b.units[i].start > pc
+	}) - 1
+	var unit *dwarfUnit
+	if idx >= 0 && pc < b.units[idx].end {
+		unit = b.units[idx].unit
+		unit.once.Do(func() {
+			unit.err = unit.parse(b.data, &b.interner)
+		})
+		if unit.err != nil {
+			return nil, unit.err
+		}
+		if frames, fn := unit.symbolize(pc); frames != nil {
+			// Like addr2line, prefer the symbol name if the function starts at the symbol,
+			// it's more precise for clones (e.g. foo.constprop.0).
+			if sym := b.lookupSymbol(pc); sym != nil && !fn.linkage && sym.addr == fn.ranges[0][0] {
+				frames[0].Func = sym.name
+			}
+			return frames, nil
+		}
+	}
+	// Like addr2line, fall back to the symbol table for code without DWARF functions
+	// (e.g. assembly or objects compiled without debug info).
+	sym := b.lookupSymbol(pc)
+	if sym == nil {
+		return nil, nil
+	}
+	// Source files of local symbols are used only if there is no DWARF for pc at all.
+	file, line := sym.file, 0
+	if unit != nil {
+		file, line = unit.lookupLine(pc)
+	}
+	if file == "" {
+		return nil, nil
+	}
+	if line == 0 {
+		line = -1
+	}
+	return []Frame{{
+		PC:   pc,
+		Func: sym.name,
+		File: file,
+		Line: line,
+	}}, nil
+}
+
+// symbolize returns frames for pc starting from the innermost one, and the function of the innermost frame.
+func (u *dwarfUnit) symbolize(pc uint64) ([]Frame, *funcNode) {
+	idx := sort.Search(len(u.funcs), func(i int) bool {
+		return u.funcs[i].start > pc
+	}) - 1
+	if idx < 0 || pc >= u.funcs[idx].end {
+		return nil, nil
+	}
+	chain := []*funcNode{u.funcs[idx].fn}
+	for fn := chain[0]; fn != nil; {
+		next := fn
+		fn = nil
+		for _, inl := range next.inlined {
+			if inl.contains(pc) {
+				chain = append(chain, inl)
+				fn = inl
+				break
+			}
+		}
+	}
+	// Like addr2line, the innermost frame gets the position from the line table,
+	// and the outer frames get positions of the calls of the inlined subroutines.
+	file, line := u.lookupLine(pc)
+	var frames []Frame
+	var innermost *funcNode
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i].name != "" && file != "" {
+			if frames == nil {
+				innermost = chain[i]
+			}
+			if line == 0 {
+				line = -1
+			}
+			frames = append(frames, Frame{
+				PC:     pc,
+				Func:   chain[i].name,
+				File:   file,
+				Line:   line,
+				Inline: true,
+			})
+		}
+		file, line = chain[i].callFile, chain[i].callLine
+	}
+	if len(frames) != 0 {
+		frames[len(frames)-1].Inline = false
+	}
+	return frames, innermost
+}
+
+func (fn *funcNode) contains(pc uint64) bool {
+	for _, rng := range fn.ranges {
+		if pc >= rng[0] && pc < rng[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *dwarfUnit) lookupLine(pc uint64) (string, int) {
+	idx := sort.Search(len(u.lines), func(i int) bool {
+		return u.lines[i].pc > pc
+	}) - 1
+	if idx < 0 || u.lines[idx].end {
+		return "", 0
+	}
+	return u.files[u.lines[idx].file], int(u.lines[idx].line)
+}
+
+func (u *dwarfUnit) parse(data *dwarf.Data, interner *Interner) error {
+	r := data.Reader()
+	r.Seek(u.offset)
+	cu, err := r.Next()
+	if err != nil {
+		return err
+	}
+	if err := u.parseLines(data, cu, interner); err != nil {
+		return err
+	}
+	if !cu.Children {
+		return nil
+	}
+	names := make(map[dwarf.Offset]funcName)
+	var all []*funcNode
+	// stack contains the current function for each level of nesting (nil if not within a function).
+	stack := []*funcNode{nil}
+	for len(stack) != 0 {
+		e, err := r.Next()
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			break
+		}
+		if e.Tag == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		parent := stack[len(stack)-1]
+		var fn *funcNode
+		switch e.Tag {
+		case dwarf.TagSubprogram, dwarf.TagInlinedSubroutine:
+			fn, err = u.parseFunc(data, e, names, interner)
+			if err != nil {
+				return err
+			}
+			if fn != nil {
+				all = append(all, fn)
+				if e.Tag == dwarf.TagInlinedSubroutine && parent != nil {
+					parent.inlined = append(parent.inlined, fn)
+				} else if e.Tag == dwarf.TagSubprogram {
+					for _, rng := range fn.ranges {
+						u.funcs = append(u.funcs, funcRange{rng[0], rng[1], fn})
+					}
+				}
+			}
+		case dwarf.TagLexDwarfBlock, dwarf.TagNamespace, dwarf.TagModule:
+			// These may contain functions and inlined subroutines.
+			fn = parent
+		default:
+			if e.Children {
+				r.SkipChildren()
+			}
+			continue
+		}
+		if e.Children {
+			stack = append(stack, fn)
+		}
+	}
+	for _, fn := range all {
+		if fn.name == "" && fn.origin != 0 {
+			name := resolveName(data, fn.origin, names)
+			fn.name, fn.linkage = interner.Do(name.name), name.linkage
+		}
+	}
+	sort.Slice(u.funcs, func(i, j int) bool {
+		return u.funcs[i].start < u.funcs[j].start
+	})
+	return nil
+}
+
+func (u *dwarfUnit) parseFunc(data *dwarf.Data, e *dwarf.Entry, names map[dwarf.Offset]funcName,
+	interner *Interner) (*funcNode, error) {
+	name, ok := entryName(e)
+	if ok {
+		name.name = interner.Do(name.name)
+		names[e.Offset] = name
+	}
+	ranges, err := data.Ranges(e)
+	if err != nil {
+		return nil, err
+	}
+	// Drop empty ranges and ranges of functions discarded by the linker.
+	n := 0
+	for _, rng := range ranges {
+		if rng[0] < rng[1] && rng[0] != 0 {
+			ranges[n] = rng
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	fn := &funcNode{
+		ranges:  ranges[:n],
+		name:    name.name,
+		linkage: name.linkage,
+		origin:  originOf(e),
+	}
+	if e.Tag == dwarf.TagInlinedSubroutine {
+		if idx, ok := e.Val(dwarf.AttrCallFile).(int64); ok && idx >= 0 && int(idx) < len(u.callFiles) {
+			fn.callFile = u.callFiles[idx]
+		}
+		if line, ok := e.Val(dwarf.AttrCallLine).(int64); ok {
+			fn.callLine = int(line)
+		}
+	}
+	return fn, nil
+}
+
+func originOf(e *dwarf.Entry) dwarf.Offset {
+	if off, ok := e.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset); ok {
+		return off
+	}
+	if off, ok := e.Val(dwarf.AttrSpecification).(dwarf.Offset); ok {
+		return off
+	}
+	return 0
+}
+
+type funcName struct {
+	name    string
+	linkage bool
+}
+
+// entryName returns the name of the function entry, like addr2line it prefers linkage names.
+func entryName(e *dwarf.Entry) (funcName, bool) {
+	if name, ok := e.Val(dwarf.AttrLinkageName).(string); ok && name != "" {
+		return funcName{name, true}, true
+	}
+	if name, ok := e.Val(dwarf.AttrName).(string); ok && name != "" {
+		return funcName{name: name}, true
+	}
+	return funcName{}, false
+}
+
+// resolveName returns the name of the function at the offset following abstract origins
+// and specifications, which may point to other compilation units.
+func resolveName(data *dwarf.Data, off dwarf.Offset, names map[dwarf.Offset]funcName) funcName {
+	r := data.Reader()
+	for i := 0; i < 10 && off != 0; i++ {
+		if name, ok := names[off]; ok {
+			return name
+		}
+		r.Seek(off)
+		e, err := r.Next()
+		if err != nil || e == nil {
+			break
+		}
+		if name, ok := entryName(e); ok {
+			names[off] = name
+			return name
+		}
+		off = originOf(e)
+	}
+	return funcName{}
+}
+
+func (u *dwarfUnit) parseLines(data *dwarf.Data, cu *dwarf.Entry, interner *Interner) error {
+	lr, err := data.LineReader(cu)
+	if err != nil || lr == nil {
+		return err
+	}
+	compDir, _ := cu.Val(dwarf.AttrCompDir).(string)
+	fileIdx := make(map[*dwarf.LineFile]int32)
+	var entry dwarf.LineEntry
+	for {
+		if err := lr.Next(&entry); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		row := lineRow{
+			pc:   entry.Address,
+			line: int32(entry.Line),
+			end:  entry.EndSequence,
+		}
+		if !entry.EndSequence {
+			if entry.File == nil {
+				continue
+			}
+			idx, ok := fileIdx[entry.File]
+			if !ok {
+				idx = int32(len(u.files))
+				fileIdx[entry.File] = idx
+				u.files = append(u.files, interner.Do(fileName(compDir, entry.File.Name)))
+			}
+			row.file = idx
+		}
+		u.lines = append(u.lines, row)
+	}
+	// Sequences may go in any order. If a sequence starts where another ends,
+	// the end marker must go first.
+	sort.SliceStable(u.lines, func(i, j int) bool {
+		if u.lines[i].pc != u.lines[j].pc {
+			return u.lines[i].pc < u.lines[j].pc
+		}
+		return u.lines[i].end && !u.lines[j].end
+	})
+	// Call file attributes index the file table of the line program.
+	files := lr.Files()
+	u.callFiles = make([]string, len(files))
+	for i, f := range files {
+		if f != nil {
+			u.callFiles[i] = interner.Do(fileName(compDir, f.Name))
+		}
+	}
+	return nil
+}
+
+// fileName returns the file name similar to addr2line: relative names are prefixed with the compilation directory.
+// Unlike addr2line, the names are cleaned (debug/dwarf does this for directories),
+// so they may miss redundant "./" elements.
+func fileName(compDir, name string) string {
+	if compDir == "" || path.IsAbs(name) {
+		return name
+	}
+	return path.Join(compDir, name)
+}
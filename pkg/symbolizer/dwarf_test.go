@@ -0,0 +1,253 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package symbolizer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The sources are built similarly to the kernel: from the root of the source tree with relative paths,
+// so that the line table contains relative directories.
+var dwarfTestFiles = map[string]string{
+	"include/test.h": `
+extern int counter;
+
+__attribute__((noinline)) static void sink(int x) { counter += x; }
+
+static inline __attribute__((always_inline)) void inner(int x)
+{
+	if (x > 10)
+		sink(x * 3);
+	sink(x);
+}
+`,
+	"src/test.c": `
+#include <stdio.h>
+#include "test.h"
+
+int counter;
+
+static inline __attribute__((always_inline)) void middle(int x)
+{
+	for (int i = 0; i < x; i++)
+		inner(i);
+	sink(x + 1);
+}
+
+__attribute__((noinline)) static void outer(int x)
+{
+	middle(x);
+	inner(x + 2);
+}
+
+__attribute__((noinline)) void outer2(int x)
+{
+	outer(x);
+	if (x > 1)
+		outer(x - 1);
+}
+
+int main(int argc, char** argv)
+{
+	outer(argc);
+	outer2(argc);
+	printf("%d\n", counter);
+	return 0;
+}
+`,
+}
+
+func buildDWARFTestBinary(t testing.TB, cflags ...string) (*targets.Target, string) {
+	target := targets.Get(runtime.GOOS, runtime.GOARCH)
+	if target == nil || target.BrokenCompiler != "" {
+		t.Skipf("no compiler for %v/%v", runtime.GOOS, runtime.GOARCH)
+	}
+	if _, err := exec.LookPath(target.CCompiler); err != nil {
+		t.Skipf("no C compiler: %v", err)
+	}
+	if _, err := target.Addr2Line(); err != nil {
+		t.Skipf("no addr2line: %v", err)
+	}
+	dir := t.TempDir()
+	for file, data := range dwarfTestFiles {
+		file = filepath.Join(dir, filepath.FromSlash(file))
+		require.NoError(t, osutil.MkdirAll(filepath.Dir(file)))
+		require.NoError(t, osutil.WriteFile(file, []byte(data)))
+	}
+	bin := filepath.Join(dir, "test")
+	args := append([]string{"-g", "-O2", "-Iinclude", "-o", bin, "src/test.c"}, cflags...)
+	if _, err := osutil.RunCmd(time.Minute, dir, target.CCompiler, args...); err != nil {
+		t.Skipf("failed to build test binary: %v", err)
+	}
+	return target, bin
+}
+
+func textPCs(t testing.TB, bin string) []uint64 {
+	symbols, err := ReadTextSymbols(bin)
+	require.NoError(t, err)
+	var pcs []uint64
+	for _, list := range symbols {
+		for _, sym := range list {
+			for pc := sym.Addr; pc < sym.Addr+uint64(sym.Size); pc++ {
+				pcs = append(pcs, pc)
+			}
+		}
+	}
+	require.NotEmpty(t, pcs)
+	return pcs
+}
+
+func TestDWARFSymbolizer(t *testing.T) {
+	for _, cflags := range [][]string{nil, {"-gdwarf-4"}, {"-O0"}} {
+		t.Run(fmt.Sprint(cflags), func(t *testing.T) {
+			target, bin := buildDWARFTestBinary(t, cflags...)
+			pcs := append(textPCs(t, bin), 0, 0x1, 0xffffffffffffffff)
+			native := Make(target)
+			defer native.Close()
+			frames, err := native.Symbolize(bin, pcs...)
+			require.NoError(t, err)
+			subproc := &addr2Line{target: target}
+			defer subproc.Close()
+			want, err := subproc.Symbolize(bin, pcs...)
+			require.NoError(t, err)
+			wantPC, gotPC := framesByPC(want), framesByPC(frames)
+			mismatches := 0
+			for _, pc := range pcs {
+				if !assert.Equal(t, wantPC[pc], gotPC[pc], "pc 0x%x", pc) {
+					if mismatches++; mismatches == 10 {
+						t.FailNow()
+					}
+				}
+			}
+			require.Equal(t, want, frames)
+			inlined := 0
+			for _, frame := range frames {
+				if frame.Inline {
+					inlined++
+				}
+			}
+			if len(cflags) == 0 {
+				assert.NotZero(t, inlined)
+			}
+		})
+	}
+}
+
+func framesByPC(frames []Frame) map[uint64][]Frame {
+	res := make(map[uint64][]Frame)
+	for _, frame := range frames {
+		res[frame.PC] = append(res[frame.PC], frame)
+	}
+	return res
+}
+
+func TestDWARFSymbolizerConcurrent(t *testing.T) {
+	target, bin := buildDWARFTestBinary(t)
+	pcs := textPCs(t, bin)
+	symb := Make(target)
+	want, err := symb.Symbolize(bin, pcs...)
+	symb.Close()
+	require.NoError(t, err)
+	errs := make(chan error)
+	const procs = 4
+	for p := 0; p < procs; p++ {
+		go func() {
+			symb := Make(target)
+			defer symb.Close()
+			frames, err := symb.Symbolize(bin, pcs...)
+			if err == nil && !assert.Equal(t, want, frames) {
+				err = fmt.Errorf("frames differ")
+			}
+			errs <- err
+		}()
+	}
+	for p := 0; p < procs; p++ {
+		assert.NoError(t, <-errs)
+	}
+}
+
+func TestDWARFSymbolizerRelease(t *testing.T) {
+	target, bin := buildDWARFTestBinary(t)
+	pcs := textPCs(t, bin)[:1]
+	cached := func() *dwarfBinary {
+		dwarfBinaries.mu.Lock()
+		defer dwarfBinaries.mu.Unlock()
+		return dwarfBinaries.bins[bin]
+	}
+	symb1, symb2 := Make(target), Make(target)
+	_, err := symb1.Symbolize(bin, pcs...)
+	require.NoError(t, err)
+	_, err = symb1.Symbolize(bin, pcs...)
+	require.NoError(t, err)
+	_, err = symb2.Symbolize(bin, pcs...)
+	require.NoError(t, err)
+	b := cached()
+	require.NotNil(t, b)
+	assert.Equal(t, 2, b.refs)
+	symb1.Close()
+	assert.Same(t, b, cached())
+	symb2.Close()
+	assert.Nil(t, cached())
+
+	// The binary is rebuilt while the symbolizer holds the old index.
+	_, err = symb1.Symbolize(bin, pcs...)
+	require.NoError(t, err)
+	b = cached()
+	require.NoError(t, os.Chtimes(bin, time.Now(), time.Now().Add(time.Hour)))
+	_, err = symb1.Symbolize(bin, pcs...)
+	require.NoError(t, err)
+	assert.NotSame(t, b, cached())
+	assert.Equal(t, 0, b.refs)
+	symb1.Close()
+	assert.Nil(t, cached())
+}
+
+func TestDWARFSymbolizerFallback(t *testing.T) {
+	// The binary has no DWARF, so addr2line is used.
+	target := targets.Get(targets.Linux, targets.AMD64)
+	if _, err := target.Addr2Line(); err != nil {
+		t.Skipf("no addr2line: %v", err)
+	}
+	symb := Make(target)
+	defer symb.Close()
+	frames, err := symb.Symbolize("testdata/nm.test.out", 0x400507, 0x4004fa)
+	require.NoError(t, err)
+	assert.Equal(t, []Frame{{PC: 0x4004fa, Func: "foobar", File: "test.c", Line: -1}}, frames)
+	assert.NotNil(t, symb.(*dwarfSymbolizer).fallback)
+}
+
+func BenchmarkSymbolize(b *testing.B) {
+	target, bin := buildDWARFTestBinary(b)
+	pcs := textPCs(b, bin)
+	b.Run("addr2line", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			symb := &addr2Line{target: target}
+			if _, err := symb.Symbolize(bin, pcs...); err != nil {
+				b.Fatal(err)
+			}
+			symb.Close()
+		}
+	})
+	b.Run("dwarf", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			// Close drops the shared index, so we measure the parsing as well.
+			symb := Make(target)
+			if _, err := symb.Symbolize(bin, pcs...); err != nil {
+				b.Fatal(err)
+			}
+			symb.Close()
+		}
+	})
+}
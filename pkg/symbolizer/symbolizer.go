@@ -18,6 +18,8 @@ type Symbolizer interface {
 	Close()
 }
 
+// Make returns the default in-process DWARF symbolizer.
+// Binaries that it can't handle are symbolized with addr2line.
 func Make(target *targets.Target) Symbolizer {
-	return &addr2Line{target: target}
+	return &dwarfSymbolizer{target: target}
 }
@@ -0,0 +1,131 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"fmt"
+)
+
+// patchConstraints adjusts values of fields that have value constraints (eq/le/lt/ge/gt attributes).
+// Most of the time the constraints are satisfied, but sometimes they are deliberately left violated
+// since the kernel must handle such inputs as well. Must be called after sizes are assigned
+// since constraints may refer to len fields.
+func (r *randGen) patchConstraints(c *Call) {
+	r.patchConstraintsFiltered(c, nil)
+}
+
+// patchConstraintsFiltered is like patchConstraints, but patches only args for which filter returns true
+// (if filter is not nil).
+func (r *randGen) patchConstraintsFiltered(c *Call, filter func(Arg) bool) {
+	// Fields are patched in the order they are visited, but constraints may refer to fields
+	// that are visited later (e.g. declared later in the struct). Patching of such a field may
+	// violate constraints that were already satisfied, so we re-check them in subsequent passes.
+	// Passes are limited since circular references may not have a solution.
+	const maxPasses = 4
+	violate := make(map[*ConstArg]bool)
+	for pass := 0; pass < maxPasses; pass++ {
+		patched := false
+		r.target.foreachConstrainedArg(c, func(arg *ConstArg, field *Field, finder ArgFinder) {
+			if filter != nil && !filter(arg) {
+				return
+			}
+			if pass == 0 && r.oneOf(10) {
+				violate[arg] = true
+			}
+			if violate[arg] {
+				return
+			}
+			lo, hi, ok := constraintRange(arg.Type(), field.Constraints, finder)
+			if !ok || arg.Val >= lo && arg.Val <= hi {
+				return
+			}
+			patched = true
+			switch {
+			case r.bin():
+				// Values on the boundary are the most interesting ones.
+				arg.Val = lo
+				if r.bin() {
+					arg.Val = hi
+				}
+			default:
+				arg.Val = lo + r.Uint64()%(hi-lo+1)
+			}
+		})
+		if !patched {
+			break
+		}
+	}
+}
+
+// constraintRange returns the range of field values that satisfy all constraints.
+// ok is false if the range can't be calculated or is empty.
+func constraintRange(typ Type, constraints []Constraint, finder ArgFinder) (lo, hi uint64, ok bool) {
+	hi = ^uint64(0)
+	if bits := typ.TypeBitSize(); bits < 64 {
+		hi = 1<<bits - 1
+	}
+	for _, cons := range constraints {
+		val, ok := cons.Value.Evaluate(finder)
+		if !ok {
+			return 0, 0, false
+		}
+		switch cons.Op {
+		case ConstraintEq:
+			lo, hi = max(lo, val), min(hi, val)
+		case ConstraintLe:
+			hi = min(hi, val)
+		case ConstraintLt:
+			if val == 0 {
+				return 0, 0, false
+			}
+			hi = min(hi, val-1)
+		case ConstraintGe:
+			lo = max(lo, val)
+		case ConstraintGt:
+			if val == ^uint64(0) {
+				return 0, 0, false
+			}
+			lo = max(lo, val+1)
+		default:
+			panic(fmt.Sprintf("unknown constraint op %v", cons.Op))
+		}
+	}
+	return lo, hi, lo <= hi
+}
+
+func (target *Target) foreachConstrainedArg(c *Call, cb func(*ConstArg, *Field, ArgFinder)) {
+	visit := func(args []Arg, fields []Field, parents parentStack, overlayField int) {
+		for i, arg := range args {
+			field := &fields[i]
+			if len(field.Constraints) == 0 || arg.Dir() == DirOut {
+				continue
+			}
+			finder := func(path []string) Arg {
+				var found *foundArg
+				if path[0] == SyscallRef {
+					found = target.findArg(nil, path[1:], c.Args, c.Meta.Args, parents, 0)
+				} else {
+					found = target.findArg(arg, path, args, fields, parents, overlayField)
+				}
+				if found == nil || found.isAnyPtr || found.arg == nil {
+					// The target is squashed or is behind a NULL pointer.
+					return SquashedArgFound
+				}
+				return found.arg
+			}
+			cb(arg.(*ConstArg), field, finder)
+		}
+	}
+	for _, arg := range c.Args {
+		foreachSubArgWithStack(arg, func(arg Arg, ctx *ArgCtx) {
+			if target.isAnyPtr(arg.Type()) {
+				ctx.Stop = true
+				return
+			}
+			if typ, ok := arg.Type().(*StructType); ok {
+				visit(arg.(*GroupArg).Inner, typ.Fields, ctx.parentStack, typ.OverlayField)
+			}
+		})
+	}
+}
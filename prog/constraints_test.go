@@ -0,0 +1,164 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintRange(t *testing.T) {
+	val := func(v uint64) Expression { return &Value{Value: v} }
+	int8Type := &IntType{IntTypeCommon: IntTypeCommon{TypeCommon: TypeCommon{TypeSize: 1}}}
+	int64Type := &IntType{IntTypeCommon: IntTypeCommon{TypeCommon: TypeCommon{TypeSize: 8}}}
+	tests := []struct {
+		typ         Type
+		constraints []Constraint
+		lo, hi      uint64
+		ok          bool
+	}{
+		{int8Type, nil, 0, 255, true},
+		{int64Type, nil, 0, ^uint64(0), true},
+		{int8Type, []Constraint{{ConstraintEq, val(10)}}, 10, 10, true},
+		{int8Type, []Constraint{{ConstraintLe, val(10)}, {ConstraintGe, val(3)}}, 3, 10, true},
+		{int8Type, []Constraint{{ConstraintLt, val(10)}, {ConstraintGt, val(3)}}, 4, 9, true},
+		{int8Type, []Constraint{{ConstraintGe, val(300)}}, 0, 0, false},
+		{int8Type, []Constraint{{ConstraintLt, val(0)}}, 0, 0, false},
+		{int64Type, []Constraint{{ConstraintGt, val(^uint64(0))}}, 0, 0, false},
+		{int8Type, []Constraint{{ConstraintLe, val(3)}, {ConstraintGe, val(10)}}, 0, 0, false},
+		{int8Type, []Constraint{{ConstraintLe, &BinaryExpression{OperatorSub, val(3), val(10)}}}, 0, 0, false},
+		{int8Type, []Constraint{{ConstraintLe, &BinaryExpression{OperatorAdd, val(3), val(10)}}}, 0, 13, true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			lo, hi, ok := constraintRange(test.typ, test.constraints, nil)
+			assert.Equal(t, test.ok, ok)
+			if ok {
+				assert.Equal(t, test.lo, lo)
+				assert.Equal(t, test.hi, hi)
+			}
+		})
+	}
+}
+
+func TestGenerateConstraints(t *testing.T) {
+	target, rs, _ := initRandomTargetTest(t, "test", "64")
+	ct := target.DefaultChoiceTable()
+	r := newRand(target, rs)
+	const iters = 1000
+	violated := make(map[string]int)
+	for i := 0; i < iters; i++ {
+		s := newState(target, ct, nil)
+		calls := r.generateParticularCall(s, target.SyscallMap["constraints"])
+		for field := range violatedConstraints(target, calls[len(calls)-1]) {
+			violated[field]++
+		}
+	}
+	// Constraints must be satisfied most of the time, but not always.
+	for _, field := range []string{"off", "len", "cmd", "echo", "limit"} {
+		assert.Greater(t, violated[field], 0, field)
+		assert.Less(t, violated[field], iters/4, field)
+	}
+	assert.Less(t, violated["f0"], iters/4)
+}
+
+func TestGenerateForwardConstraints(t *testing.T) {
+	target, rs, _ := initRandomTargetTest(t, "test", "64")
+	ct := target.DefaultChoiceTable()
+	r := newRand(target, rs)
+	const iters = 1000
+	violated := make(map[string]int)
+	for i := 0; i < iters; i++ {
+		s := newState(target, ct, nil)
+		calls := r.generateParticularCall(s, target.SyscallMap["constraints$forward"])
+		for field := range violatedConstraints(target, calls[len(calls)-1]) {
+			violated[field]++
+		}
+	}
+	// lo is checked before hi is patched, so it's violated all the time without re-checking.
+	assert.Less(t, violated["lo"], iters/4)
+	assert.Less(t, violated["hi"], iters/4)
+}
+
+func TestGenerateCallRefConstraints(t *testing.T) {
+	target, rs, _ := initRandomTargetTest(t, "test", "64")
+	ct := target.DefaultChoiceTable()
+	r := newRand(target, rs)
+	// The constraint is lowered to a resource produced by the output field of the other call.
+	res := target.SyscallMap["constraints$create"].Args[0].Type.(*PtrType).Elem.(*StructType).Fields[1].Type
+	require.Equal(t, "constraint_out$id", res.Name())
+	const iters = 1000
+	linked := 0
+	for i := 0; i < iters; i++ {
+		s := newState(target, ct, nil)
+		calls := r.generateParticularCall(s, target.SyscallMap["constraints$use"])
+		ptr := calls[len(calls)-1].Args[0].(*PointerArg)
+		if ptr.Res == nil {
+			continue
+		}
+		id := ptr.Res.(*GroupArg).Inner[0].(*ResultArg)
+		if id.Res == nil {
+			continue
+		}
+		linked++
+		require.Equal(t, res, id.Res.Type())
+		require.Equal(t, "constraints$create", calls[0].Meta.Name)
+	}
+	// The field must be equal to the returned value most of the time, but not always.
+	assert.Greater(t, linked, iters/2)
+	assert.Less(t, linked, iters)
+}
+
+func TestMutateConstraints(t *testing.T) {
+	target, rs, _ := initRandomTargetTest(t, "test", "64")
+	ct := target.BuildChoiceTable(nil, map[*Syscall]bool{target.SyscallMap["constraints"]: true})
+	p, err := target.Deserialize([]byte(
+		"constraints(&(0x7f0000000000)={0x4, 0x1, 0x2, &(0x7f0000001000)=\"01020304\","+
+			" 0x3, 0x4, 0x2, {0x4}}, 0x2)\n"), Strict)
+	require.NoError(t, err)
+	require.Empty(t, violatedConstraints(target, p.Calls[0]))
+	opts := MutateOpts{
+		ExpectedIterations: 1,
+		MutateArgCount:     1,
+		MutateArgWeight:    1,
+	}
+	const iters = 1000
+	violated := 0
+	for i := 0; i < iters; i++ {
+		p1 := p.Clone()
+		p1.MutateWithOpts(rs, 1, ct, nil, nil, opts)
+		if len(violatedConstraints(target, p1.Calls[0])) != 0 {
+			violated++
+		}
+	}
+	assert.Greater(t, violated, 0)
+	assert.Less(t, violated, iters/4)
+}
+
+func TestDeserializeViolatedConstraints(t *testing.T) {
+	// Programs that violate constraints are valid and must be preserved as is.
+	target := initTargetTest(t, "test", "64")
+	const text = "constraints(&(0x7f0000000000)={0x4, 0x10, 0x20, &(0x7f0000001000)=\"01020304\"," +
+		" 0x0, 0x0, 0x2, {0x1}}, 0x1)\n"
+	p, err := target.Deserialize([]byte(text), Strict)
+	require.NoError(t, err)
+	// The len constraint can't be calculated since off is larger than size.
+	assert.Equal(t, map[string]bool{"off": true, "cmd": true, "echo": true, "limit": true},
+		violatedConstraints(target, p.Calls[0]))
+	assert.Equal(t, text, string(p.Serialize()))
+}
+
+func violatedConstraints(target *Target, c *Call) map[string]bool {
+	violated := make(map[string]bool)
+	target.foreachConstrainedArg(c, func(arg *ConstArg, field *Field, finder ArgFinder) {
+		lo, hi, ok := constraintRange(arg.Type(), field.Constraints, finder)
+		if ok && (arg.Val < lo || arg.Val > hi) {
+			violated[field.Name] = true
+		}
+	})
+	return violated
+}
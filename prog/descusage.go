@@ -0,0 +1,261 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"sort"
+)
+
+// DescUsage describes how a set of programs (normally the corpus) uses the syscall descriptions:
+// how often each syscall, struct field, union option and flag value appears in the programs,
+// and how often it appears in calls that got coverage credit during triage.
+// Elements that never appear are included as well, this allows to find unreachable descriptions
+// and union options/flag values that are never generated.
+type DescUsage struct {
+	Programs int             `json:"programs"`
+	Syscalls []*SyscallUsage `json:"syscalls"`
+	Structs  []*StructUsage  `json:"structs"`
+	Unions   []*UnionUsage   `json:"unions"`
+	Flags    []*FlagsUsage   `json:"flags"`
+}
+
+type UsageCount struct {
+	// Number of occurrences in all programs.
+	Count int `json:"count"`
+	// Number of occurrences in calls that got coverage credit.
+	Credited int `json:"credited"`
+}
+
+type SyscallUsage struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	UsageCount
+}
+
+type StructUsage struct {
+	Name string `json:"name"`
+	UsageCount
+	Fields []*FieldUsage `json:"fields"`
+}
+
+type UnionUsage struct {
+	Name string `json:"name"`
+	UsageCount
+	Options []*FieldUsage `json:"options"`
+}
+
+type FieldUsage struct {
+	Name string `json:"name"`
+	UsageCount
+	// Number of occurrences with a non-default value (non-zero integer, non-NULL pointer, etc).
+	NonDefault int `json:"non_default"`
+}
+
+type FlagsUsage struct {
+	Name    string `json:"name"`
+	BitMask bool   `json:"bitmask"`
+	UsageCount
+	Values []*FlagValueUsage `json:"values"`
+	// Number of occurrences of values that don't belong to the flags
+	// (for bitmasks: values with bits not covered by any of the flags).
+	Other int `json:"other"`
+}
+
+type FlagValueUsage struct {
+	Name  string `json:"name,omitempty"`
+	Value uint64 `json:"value"`
+	UsageCount
+}
+
+// UsageInput is a program along with indices of its calls that got coverage credit
+// (i.e. the calls the program was added to the corpus for).
+type UsageInput struct {
+	Prog     *Prog
+	Credited []int
+}
+
+// CalculateDescUsage collects description usage statistics over the programs.
+// Only types reachable from the enabled syscalls are reported (all syscalls if enabled is nil),
+// but all syscalls are reported with the Enabled flag set accordingly.
+func (target *Target) CalculateDescUsage(inputs []UsageInput, enabled map[*Syscall]bool) *DescUsage {
+	u := &descUsageCtx{
+		target:  target,
+		usage:   &DescUsage{Programs: len(inputs)},
+		calls:   make(map[*Syscall]*SyscallUsage),
+		structs: make(map[string]*StructUsage),
+		unions:  make(map[string]*UnionUsage),
+		flags:   make(map[string]*flagsUsage),
+	}
+	var reachable []*Syscall
+	for _, meta := range target.Syscalls {
+		cu := &SyscallUsage{
+			Name:    meta.Name,
+			Enabled: enabled == nil || enabled[meta],
+		}
+		u.calls[meta] = cu
+		u.usage.Syscalls = append(u.usage.Syscalls, cu)
+		if cu.Enabled {
+			reachable = append(reachable, meta)
+		}
+	}
+	ForeachType(reachable, func(typ Type, _ *TypeCtx) {
+		u.addType(typ)
+	})
+	for _, inp := range inputs {
+		credited := make(map[int]bool)
+		for _, idx := range inp.Credited {
+			credited[idx] = true
+		}
+		for i, c := range inp.Prog.Calls {
+			u.addCall(c, credited[i])
+		}
+	}
+	return u.finish()
+}
+
+type descUsageCtx struct {
+	target  *Target
+	usage   *DescUsage
+	calls   map[*Syscall]*SyscallUsage
+	structs map[string]*StructUsage
+	unions  map[string]*UnionUsage
+	flags   map[string]*flagsUsage
+}
+
+type flagsUsage struct {
+	*FlagsUsage
+	all uint64 // union of all flag values
+}
+
+func (u *descUsageCtx) addType(typ Type) {
+	switch t := typ.(type) {
+	case *StructType:
+		if u.structs[t.Name()] != nil {
+			return
+		}
+		su := &StructUsage{Name: t.Name()}
+		for _, f := range t.Fields {
+			if !IsPad(f.Type) {
+				su.Fields = append(su.Fields, &FieldUsage{Name: f.Name})
+			}
+		}
+		u.structs[t.Name()] = su
+	case *UnionType:
+		if u.unions[t.Name()] != nil {
+			return
+		}
+		uu := &UnionUsage{Name: t.Name()}
+		for _, f := range t.Fields {
+			uu.Options = append(uu.Options, &FieldUsage{Name: f.Name})
+		}
+		u.unions[t.Name()] = uu
+	case *FlagsType:
+		if u.flags[t.Name()] != nil {
+			return
+		}
+		fu := &flagsUsage{
+			FlagsUsage: &FlagsUsage{Name: t.Name(), BitMask: t.BitMask},
+		}
+		names := make(map[uint64]string)
+		for _, name := range u.target.FlagsMap[t.Name()] {
+			if v, ok := u.target.ConstMap[name]; ok && names[v] == "" {
+				names[v] = name
+			}
+		}
+		for _, v := range t.Vals {
+			fu.Values = append(fu.Values, &FlagValueUsage{Name: names[v], Value: v})
+			fu.all |= v
+		}
+		u.flags[t.Name()] = fu
+	}
+}
+
+func (u *descUsageCtx) addCall(c *Call, credited bool) {
+	inc := func(cnt *UsageCount) {
+		cnt.Count++
+		if credited {
+			cnt.Credited++
+		}
+	}
+	if cu := u.calls[c.Meta]; cu != nil {
+		inc(&cu.UsageCount)
+	}
+	ForeachArg(c, func(arg Arg, _ *ArgCtx) {
+		switch a := arg.(type) {
+		case *GroupArg:
+			t, ok := a.Type().(*StructType)
+			if !ok {
+				return
+			}
+			su := u.structs[t.Name()]
+			if su == nil {
+				// The struct is used only by disabled syscalls.
+				return
+			}
+			inc(&su.UsageCount)
+			fields := su.Fields
+			for _, inner := range a.Inner {
+				if IsPad(inner.Type()) {
+					continue
+				}
+				fu := fields[0]
+				fields = fields[1:]
+				inc(&fu.UsageCount)
+				if !isDefault(inner) {
+					fu.NonDefault++
+				}
+			}
+		case *UnionArg:
+			uu := u.unions[a.Type().Name()]
+			if uu == nil {
+				return
+			}
+			inc(&uu.UsageCount)
+			fu := uu.Options[a.Index]
+			inc(&fu.UsageCount)
+			if !isDefault(a.Option) {
+				fu.NonDefault++
+			}
+		case *ConstArg:
+			t, ok := a.Type().(*FlagsType)
+			if !ok || a.Dir() == DirOut {
+				return
+			}
+			fu := u.flags[t.Name()]
+			if fu == nil {
+				return
+			}
+			inc(&fu.UsageCount)
+			val := a.Val
+			known := false
+			for _, v := range fu.Values {
+				if t.BitMask && v.Value != 0 && val&v.Value == v.Value || v.Value == val {
+					inc(&v.UsageCount)
+					known = true
+				}
+			}
+			if t.BitMask && val&^fu.all != 0 || !t.BitMask && !known {
+				fu.Other++
+			}
+		}
+	})
+}
+
+func (u *descUsageCtx) finish() *DescUsage {
+	res := u.usage
+	for _, su := range u.structs {
+		res.Structs = append(res.Structs, su)
+	}
+	for _, uu := range u.unions {
+		res.Unions = append(res.Unions, uu)
+	}
+	for _, fu := range u.flags {
+		res.Flags = append(res.Flags, fu.FlagsUsage)
+	}
+	sort.Slice(res.Syscalls, func(i, j int) bool { return res.Syscalls[i].Name < res.Syscalls[j].Name })
+	sort.Slice(res.Structs, func(i, j int) bool { return res.Structs[i].Name < res.Structs[j].Name })
+	sort.Slice(res.Unions, func(i, j int) bool { return res.Unions[i].Name < res.Unions[j].Name })
+	sort.Slice(res.Flags, func(i, j int) bool { return res.Flags[i].Name < res.Flags[j].Name })
+	return res
+}
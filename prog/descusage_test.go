@@ -0,0 +1,80 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescUsage(t *testing.T) {
+	target := initTargetTest(t, "test", "64")
+	var inputs []UsageInput
+	for _, test := range []struct {
+		prog     string
+		credited []int
+	}{
+		{`
+test$union0(&(0x7f0000000000)={0x1, @f0=0x2})
+mutate_flags(&(0x7f0000000000)='./file0\x00', 0x0, 0x0, 0x9)
+`, []int{1}},
+		{`
+test$union0(&(0x7f0000000000)={0x0, @f2})
+test$union0(&(0x7f0000000000)={0x0, @f0})
+mutate_flags(&(0x7f0000000000)='./file0\x00', 0x0, 0x0, 0x21)
+mutate5(&(0x7f0000000000)='./file0\x00', 0x1)
+`, []int{0}},
+	} {
+		p, err := target.Deserialize([]byte(test.prog), Strict)
+		require.NoError(t, err)
+		inputs = append(inputs, UsageInput{Prog: p, Credited: test.credited})
+	}
+	enabled := map[*Syscall]bool{
+		target.SyscallMap["test$union0"]:  true,
+		target.SyscallMap["mutate_flags"]: true,
+	}
+	u := target.CalculateDescUsage(inputs, enabled)
+	assert.Equal(t, 2, u.Programs)
+	assert.Len(t, u.Syscalls, len(target.Syscalls))
+	calls := make(map[string]*SyscallUsage)
+	for _, c := range u.Syscalls {
+		calls[c.Name] = c
+	}
+	assert.Equal(t, &SyscallUsage{"test$union0", true, UsageCount{3, 1}}, calls["test$union0"])
+	assert.Equal(t, &SyscallUsage{"mutate_flags", true, UsageCount{2, 1}}, calls["mutate_flags"])
+	assert.Equal(t, &SyscallUsage{"mutate5", false, UsageCount{1, 0}}, calls["mutate5"])
+	assert.Equal(t, &SyscallUsage{"test$union1", false, UsageCount{}}, calls["test$union1"])
+
+	assert.Equal(t, []*StructUsage{{
+		Name:       "syz_union0_struct",
+		UsageCount: UsageCount{3, 1},
+		Fields: []*FieldUsage{
+			{Name: "f", UsageCount: UsageCount{3, 1}, NonDefault: 1},
+			{Name: "u", UsageCount: UsageCount{3, 1}, NonDefault: 2},
+		},
+	}}, u.Structs)
+	assert.Equal(t, []*UnionUsage{{
+		Name:       "syz_union0",
+		UsageCount: UsageCount{3, 1},
+		Options: []*FieldUsage{
+			{Name: "f0", UsageCount: UsageCount{2, 0}, NonDefault: 1},
+			{Name: "f1", UsageCount: UsageCount{0, 0}},
+			{Name: "f2", UsageCount: UsageCount{1, 1}},
+		},
+	}}, u.Unions)
+	// Types used only by disabled syscalls (open_flags of mutate5) are not reported.
+	assert.Equal(t, []*FlagsUsage{{
+		Name:       "bitmask_flags",
+		BitMask:    true,
+		UsageCount: UsageCount{2, 1},
+		Values: []*FlagValueUsage{
+			{Value: 0x1, UsageCount: UsageCount{2, 1}},
+			{Value: 0x8, UsageCount: UsageCount{1, 1}},
+			{Value: 0x10},
+		},
+		Other: 1,
+	}}, u.Flags)
+}
@@ -0,0 +1,375 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ProgDiff is a structural difference between two programs.
+// Calls are aligned by syscall, resources are matched by the calls that produce them,
+// so resource renumbering and shifted data addresses don't show up as changes.
+type ProgDiff struct {
+	A, B  *Prog
+	Calls []*CallDiff
+}
+
+type CallDiffKind int
+
+const (
+	CallSame CallDiffKind = iota
+	// The call is present only in B.
+	CallInserted
+	// The call is present only in A.
+	CallRemoved
+	// The call is present in both programs, but its arguments differ.
+	CallChanged
+)
+
+func (kind CallDiffKind) String() string {
+	return [...]string{"same", "inserted", "removed", "changed"}[kind]
+}
+
+type CallDiff struct {
+	Kind CallDiffKind
+	// Indexes of the call in A and B, -1 if the call is not present in the program.
+	A, B int
+	// Serialized call in A and B.
+	TextA, TextB string
+	Changes      []*ArgDiff
+}
+
+type ArgDiff struct {
+	// Call is the index of the call in B.
+	Call int
+	// Arg is the index of the syscall argument, -1 for the call properties.
+	Arg int
+	// Path is the path within the argument, e.g. ".field.flags" (empty for the argument itself).
+	Path     string
+	Old, New string
+}
+
+func (d *ArgDiff) String() string {
+	what := "props"
+	if d.Arg != -1 {
+		what = fmt.Sprintf("arg %v", d.Arg)
+		if d.Path != "" {
+			what += " " + d.Path
+		}
+	}
+	return fmt.Sprintf("call %v %v: %v -> %v", d.Call, what, d.Old, d.New)
+}
+
+// Equal returns whether the programs are structurally equal.
+func (d *ProgDiff) Equal() bool {
+	for _, c := range d.Calls {
+		if c.Kind != CallSame {
+			return false
+		}
+	}
+	return true
+}
+
+// Changes returns all argument changes of all calls.
+func (d *ProgDiff) Changes() []*ArgDiff {
+	var res []*ArgDiff
+	for _, c := range d.Calls {
+		res = append(res, c.Changes...)
+	}
+	return res
+}
+
+// String returns a human-readable diff, one call per line, followed by the argument changes.
+func (d *ProgDiff) String() string {
+	buf := new(bytes.Buffer)
+	for _, c := range d.Calls {
+		switch c.Kind {
+		case CallSame:
+			fmt.Fprintf(buf, "  call %v: %v\n", c.B, c.TextB)
+		case CallInserted:
+			fmt.Fprintf(buf, "+ call %v: %v\n", c.B, c.TextB)
+		case CallRemoved:
+			fmt.Fprintf(buf, "- call %v: %v\n", c.A, c.TextA)
+		case CallChanged:
+			fmt.Fprintf(buf, "~ call %v: %v\n", c.B, c.TextB)
+			for _, change := range c.Changes {
+				fmt.Fprintf(buf, "\t%v\n", change)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// Diff returns the structural difference between programs a and b.
+func Diff(a, b *Prog) *ProgDiff {
+	if a.Target != b.Target {
+		panic("diffing programs of different targets")
+	}
+	ctx := &differ{
+		resMap: make(map[*ResultArg]*ResultArg),
+		namesA: resultNames(a),
+		namesB: resultNames(b),
+	}
+	textA, textB := callTexts(a), callTexts(b)
+	d := &ProgDiff{A: a, B: b}
+	for _, pair := range alignCalls(a, b) {
+		c := &CallDiff{
+			Kind: CallSame,
+			A:    pair[0],
+			B:    pair[1],
+		}
+		switch {
+		case c.A == -1:
+			c.Kind = CallInserted
+			c.TextB = textB[c.B]
+		case c.B == -1:
+			c.Kind = CallRemoved
+			c.TextA = textA[c.A]
+		default:
+			c.TextA, c.TextB = textA[c.A], textB[c.B]
+			c.Changes = ctx.diffCall(c.B, a.Calls[c.A], b.Calls[c.B])
+			if len(c.Changes) != 0 {
+				c.Kind = CallChanged
+			}
+		}
+		d.Calls = append(d.Calls, c)
+	}
+	return d
+}
+
+// alignCalls matches calls with the same syscall using the longest common subsequence.
+// It returns pairs of indexes in a and b, -1 denotes a missing call.
+func alignCalls(a, b *Prog) [][2]int {
+	n, m := len(a.Calls), len(b.Calls)
+	// lcs[i][j] is the length of the LCS of a.Calls[i:] and b.Calls[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a.Calls[i].Meta == b.Calls[j].Meta {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var res [][2]int
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a.Calls[i].Meta == b.Calls[j].Meta:
+			res = append(res, [2]int{i, j})
+			i++
+			j++
+		case j == m || i < n && lcs[i+1][j] >= lcs[i][j+1]:
+			res = append(res, [2]int{i, -1})
+			i++
+		default:
+			res = append(res, [2]int{-1, j})
+			j++
+		}
+	}
+	return res
+}
+
+// resultNames assigns variable names to used results the same way the serializer does.
+func resultNames(p *Prog) map[*ResultArg]string {
+	names := make(map[*ResultArg]string)
+	for _, c := range p.Calls {
+		ForeachArg(c, func(arg Arg, _ *ArgCtx) {
+			if a, ok := arg.(*ResultArg); ok && len(a.uses) != 0 {
+				names[a] = fmt.Sprintf("r%v", len(names))
+			}
+		})
+	}
+	return names
+}
+
+func callTexts(p *Prog) []string {
+	var res []string
+	for _, line := range strings.Split(string(p.Serialize()), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+type differ struct {
+	// resMap maps results of A to the matching results of B.
+	resMap  map[*ResultArg]*ResultArg
+	namesA  map[*ResultArg]string
+	namesB  map[*ResultArg]string
+	call    int
+	arg     int
+	changes []*ArgDiff
+}
+
+func (ctx *differ) diffCall(idx int, a, b *Call) []*ArgDiff {
+	ctx.call = idx
+	ctx.changes = nil
+	for i := range a.Args {
+		ctx.arg = i
+		ctx.diffArg("", a.Args[i], b.Args[i])
+	}
+	if a.Props != b.Props {
+		ctx.arg = -1
+		ctx.change("", formatProps(a.Props), formatProps(b.Props))
+	}
+	if a.Ret != nil && b.Ret != nil {
+		ctx.resMap[a.Ret] = b.Ret
+	}
+	return ctx.changes
+}
+
+func (ctx *differ) change(path, oldVal, newVal string) {
+	ctx.changes = append(ctx.changes, &ArgDiff{
+		Call: ctx.call,
+		Arg:  ctx.arg,
+		Path: path,
+		Old:  oldVal,
+		New:  newVal,
+	})
+}
+
+func (ctx *differ) diffArg(path string, a, b Arg) {
+	if a == nil || b == nil || a.Type() != b.Type() {
+		if a != nil || b != nil {
+			ctx.change(path, ctx.format(a, ctx.namesA), ctx.format(b, ctx.namesB))
+		}
+		return
+	}
+	switch a1 := a.(type) {
+	case *ConstArg:
+		b1 := b.(*ConstArg)
+		if a1.Val != b1.Val {
+			ctx.change(path, ctx.format(a, ctx.namesA), ctx.format(b, ctx.namesB))
+		}
+	case *ResultArg:
+		b1 := b.(*ResultArg)
+		same := a1.Val == b1.Val
+		if a1.Res != nil || b1.Res != nil {
+			same = a1.Res != nil && b1.Res != nil && ctx.resMap[a1.Res] == b1.Res &&
+				a1.OpDiv == b1.OpDiv && a1.OpAdd == b1.OpAdd
+		}
+		if !same {
+			ctx.change(path, ctx.format(a, ctx.namesA), ctx.format(b, ctx.namesB))
+		}
+		ctx.resMap[a1] = b1
+	case *PointerArg:
+		b1 := b.(*PointerArg)
+		if a1.IsSpecial() || b1.IsSpecial() || a1.VmaSize != b1.VmaSize {
+			// Data addresses are not compared, they are not important and shift a lot.
+			if a1.IsSpecial() != b1.IsSpecial() || a1.IsSpecial() && a1.Address != b1.Address ||
+				a1.VmaSize != b1.VmaSize {
+				ctx.change(path, ctx.format(a, ctx.namesA), ctx.format(b, ctx.namesB))
+			}
+			return
+		}
+		ctx.diffArg(path, a1.Res, b1.Res)
+	case *DataArg:
+		b1 := b.(*DataArg)
+		if a1.Dir() == DirOut && a1.Size() != b1.Size() ||
+			a1.Dir() != DirOut && !bytes.Equal(a1.Data(), b1.Data()) {
+			ctx.change(path, ctx.format(a, ctx.namesA), ctx.format(b, ctx.namesB))
+		}
+	case *GroupArg:
+		b1 := b.(*GroupArg)
+		if typ, ok := a.Type().(*StructType); ok {
+			for i, field := range typ.Fields {
+				if !IsPad(field.Type) {
+					ctx.diffArg(path+"."+field.Name, a1.Inner[i], b1.Inner[i])
+				}
+			}
+			return
+		}
+		if len(a1.Inner) != len(b1.Inner) {
+			ctx.change(path, fmt.Sprintf("%v elements", len(a1.Inner)), fmt.Sprintf("%v elements", len(b1.Inner)))
+		}
+		for i := 0; i < min(len(a1.Inner), len(b1.Inner)); i++ {
+			ctx.diffArg(fmt.Sprintf("%v[%v]", path, i), a1.Inner[i], b1.Inner[i])
+		}
+	case *UnionArg:
+		b1 := b.(*UnionArg)
+		if a1.Index != b1.Index {
+			ctx.change(path, ctx.format(a, ctx.namesA), ctx.format(b, ctx.namesB))
+			return
+		}
+		ctx.diffArg(path+"."+a1.Type().(*UnionType).Fields[a1.Index].Name, a1.Option, b1.Option)
+	default:
+		panic(fmt.Sprintf("unknown arg type %T", a))
+	}
+}
+
+// format returns a short description of the arg for the diff.
+func (ctx *differ) format(arg Arg, names map[*ResultArg]string) string {
+	const maxData = 32
+	switch a := arg.(type) {
+	case nil:
+		return "nil"
+	case *ConstArg:
+		return fmt.Sprintf("0x%x", a.Val)
+	case *ResultArg:
+		if a.Res == nil {
+			return fmt.Sprintf("0x%x", a.Val)
+		}
+		res := names[a.Res]
+		if a.OpDiv != 0 {
+			res += fmt.Sprintf("/%v", a.OpDiv)
+		}
+		if a.OpAdd != 0 {
+			res += fmt.Sprintf("+%v", a.OpAdd)
+		}
+		return res
+	case *PointerArg:
+		if a.IsSpecial() {
+			return fmt.Sprintf("0x%x", a.Address)
+		}
+		if a.VmaSize != 0 {
+			return fmt.Sprintf("vma/0x%x", a.VmaSize)
+		}
+		return "&" + ctx.format(a.Res, names)
+	case *DataArg:
+		if a.Dir() == DirOut {
+			return fmt.Sprintf(`""/%v`, a.Size())
+		}
+		data := a.Data()
+		if len(data) > maxData {
+			return fmt.Sprintf("%q.../%v", data[:maxData], len(data))
+		}
+		return fmt.Sprintf("%q", data)
+	case *GroupArg:
+		if _, ok := a.Type().(*StructType); ok {
+			return a.Type().Name() + "{...}"
+		}
+		return fmt.Sprintf("%v elements", len(a.Inner))
+	case *UnionArg:
+		return "@" + a.Type().(*UnionType).Fields[a.Index].Name
+	default:
+		panic(fmt.Sprintf("unknown arg type %T", arg))
+	}
+}
+
+func formatProps(props CallProps) string {
+	var res []string
+	props.ForeachProp(func(_, key string, value reflect.Value) {
+		if value.IsZero() {
+			return
+		}
+		if value.Kind() == reflect.Int {
+			res = append(res, fmt.Sprintf("%v: %v", key, value.Int()))
+		} else {
+			res = append(res, key)
+		}
+	})
+	if len(res) == 0 {
+		return "none"
+	}
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	target := initTargetTest(t, "linux", "amd64")
+	a, err := target.Deserialize([]byte(`
+r0 = open(&(0x7f0000000000)='./file0\x00', 0x0, 0x0)
+write(r0, &(0x7f0000000040)="0102", 0x2)
+nanosleep(&(0x7f0000000080)={0x0, 0x1}, 0x0)
+getpid()
+close(r0)
+`), Strict)
+	require.NoError(t, err)
+	b, err := target.Deserialize([]byte(`
+r0 = open(&(0x7f0000001000)='./file0\x00', 0x0, 0x0)
+r1 = open(&(0x7f0000000000)='./file1\x00', 0x0, 0x0)
+write(r1, &(0x7f0000000040)="0103", 0x2)
+nanosleep(&(0x7f0000000100)={0x0, 0x2}, 0x0) (fail_nth: 1)
+close(r0)
+`), Strict)
+	require.NoError(t, err)
+	d := Diff(a, b)
+	assert.False(t, d.Equal())
+	var kinds []CallDiffKind
+	for _, c := range d.Calls {
+		kinds = append(kinds, c.Kind)
+	}
+	assert.Equal(t, []CallDiffKind{CallSame, CallInserted, CallChanged, CallChanged, CallRemoved, CallSame}, kinds)
+	var changes []string
+	for _, change := range d.Changes() {
+		changes = append(changes, change.String())
+	}
+	assert.Equal(t, []string{
+		`call 2 arg 0: r0 -> r1`,
+		`call 2 arg 1: "\x01\x02" -> "\x01\x03"`,
+		`call 3 arg 0 .nsec: 0x1 -> 0x2`,
+		`call 3 props: none -> fail_nth: 1`,
+	}, changes)
+	assert.Equal(t, `  call 0: r0 = open(&(0x7f0000001000)='./file0\x00', 0x0, 0x0)
++ call 1: r1 = open(&(0x7f0000000000)='./file1\x00', 0x0, 0x0)
+~ call 2: write(r1, &(0x7f0000000040)="0103", 0x2)
+	call 2 arg 0: r0 -> r1
+	call 2 arg 1: "\x01\x02" -> "\x01\x03"
+~ call 3: nanosleep(&(0x7f0000000100)={0x0, 0x2}, 0x0) (fail_nth: 1)
+	call 3 arg 0 .nsec: 0x1 -> 0x2
+	call 3 props: none -> fail_nth: 1
+- call 3: getpid()
+  call 4: close(r0)
+`, d.String())
+}
+
+func TestDiffEqual(t *testing.T) {
+	target, rs, iters := initTest(t)
+	ct := target.DefaultChoiceTable()
+	for i := 0; i < iters; i++ {
+		p := target.Generate(rs, 10, ct)
+		d := Diff(p, p.Clone())
+		if !d.Equal() {
+			t.Fatalf("program is not equal to its clone:\n%v", d)
+		}
+	}
+}
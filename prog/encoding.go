@@ -253,6 +253,11 @@ const (
 )
 
 func (target *Target) Deserialize(data []byte, mode DeserializeMode) (*Prog, error) {
+	prog, _, err := target.deserialize(data, mode, false)
+	return prog, err
+}
+
+func (target *Target) deserialize(data []byte, mode DeserializeMode, template bool) (*Prog, *parser, error) {
 	defer func() {
 		if err := recover(); err != nil {
 			panic(fmt.Errorf("%v\ntarget: %v/%v, rev: %v, mode=%v, prog:\n%q",
@@ -262,12 +267,17 @@ func (target *Target) Deserialize(data []byte, mode DeserializeMode) (*Prog, err
 	strict := mode == Strict || mode == StrictUnsafe
 	unsafe := mode == StrictUnsafe || mode == NonStrictUnsafe
 	p := newParser(target, data, strict, unsafe)
+	if template {
+		p.template = true
+		p.holes = make(map[Arg]bool)
+		p.optional = make(map[*Call]bool)
+	}
 	prog, err := p.parseProg()
 	if err := p.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// This validation is done even in non-debug mode because deserialization
 	// procedure does not catch all bugs (e.g. mismatched types).
@@ -276,7 +286,7 @@ func (target *Target) Deserialize(data []byte, mode DeserializeMode) (*Prog, err
 		// Don't validate auto-set conditional fields. We'll patch them later.
 		ignoreTransient: true,
 	}); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	p.fixupConditionals(prog)
 	if p.autos != nil {
@@ -284,10 +294,10 @@ func (target *Target) Deserialize(data []byte, mode DeserializeMode) (*Prog, err
 	}
 	if !unsafe {
 		if err := prog.sanitize(!strict); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	return prog, nil
+	return prog, p, nil
 }
 
 func (p *parser) parseProg() (*Prog, error) {
@@ -310,6 +320,11 @@ func (p *parser) parseProg() (*Prog, error) {
 			p.comment = strings.TrimSpace(p.s[p.i+1:])
 			continue
 		}
+		optional := false
+		if p.template && p.Char() == '?' {
+			p.Parse('?')
+			optional = true
+		}
 		name := p.Ident()
 		r := ""
 		if p.Char() == '=' {
@@ -324,6 +339,9 @@ func (p *parser) parseProg() (*Prog, error) {
 		c := MakeCall(meta, nil)
 		c.Comment = p.comment
 		prog.Calls = append(prog.Calls, c)
+		if optional {
+			p.optional[c] = true
+		}
 		p.Parse('(')
 		for i := 0; p.Char() != ')'; i++ {
 			if i >= len(meta.Args) {
@@ -418,6 +436,9 @@ func (p *parser) parseCallProps() CallProps {
 }
 
 func (p *parser) parseArg(typ Type, dir Dir) (Arg, error) {
+	if p.template && p.Char() == '?' {
+		return p.parseHole(typ, dir)
+	}
 	r := ""
 	if p.Char() == '<' {
 		p.Parse('<')
@@ -1104,6 +1125,10 @@ type parser struct {
 	vars    map[string]*ResultArg
 	autos   map[Arg]bool
 	comment string
+	// Set when parsing program templates, see template.go.
+	template bool
+	holes    map[Arg]bool
+	optional map[*Call]bool
 
 	data []byte
 	s    string
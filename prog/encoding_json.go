@@ -0,0 +1,395 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/image"
+)
+
+// JSONProgVersion is the version of the JSON program schema described in docs/program_syntax.md.
+// It's incremented on incompatible schema changes.
+const JSONProgVersion = 1
+
+// JSONProg is the JSON representation of a program.
+// Unlike the text format, it's self-describing: every argument carries its kind, type and direction.
+type JSONProg struct {
+	Version  int
+	Target   string // OS/arch
+	Calls    []*JSONCall
+	Comments []string `json:",omitempty"`
+}
+
+type JSONCall struct {
+	// Name is the full syscall name (e.g. "openat$dir").
+	Name string
+	// CallName and NR identify the kernel syscall (e.g. "openat").
+	CallName string
+	NR       uint64
+	Args     []*JSONArg
+	// Ret is the name of the variable that holds the call result, if other calls use the result.
+	Ret     string     `json:",omitempty"`
+	Props   *CallProps `json:",omitempty"`
+	Comment string     `json:",omitempty"`
+}
+
+type JSONArgKind string
+
+const (
+	JSONConst   JSONArgKind = "const"
+	JSONResult  JSONArgKind = "result"
+	JSONPointer JSONArgKind = "pointer"
+	JSONData    JSONArgKind = "data"
+	JSONStruct  JSONArgKind = "struct"
+	JSONArray   JSONArgKind = "array"
+	JSONUnion   JSONArgKind = "union"
+)
+
+type JSONArg struct {
+	Kind JSONArgKind
+	// Name is the syscall argument, struct field or union option name (empty for array elements).
+	Name string `json:",omitempty"`
+	// Type is the syzlang type name.
+	Type string
+	// Dir is one of "in", "out", "inout".
+	Dir string
+	// Value is the value of const args and of result args that don't refer to other results.
+	Value uint64 `json:",omitempty"`
+	// Var is the name of the variable that holds the result arg, if other args use it.
+	Var string `json:",omitempty"`
+	// Ref is the name of the variable the result arg refers to. The value is Ref/OpDiv+OpAdd.
+	Ref   string `json:",omitempty"`
+	OpDiv uint64 `json:",omitempty"`
+	OpAdd uint64 `json:",omitempty"`
+	// Address is the pointer value. For special pointers (e.g. NULL) it's the raw value,
+	// otherwise it's an address in the data area. VmaSize is the size of the vma pointers.
+	Address uint64 `json:",omitempty"`
+	VmaSize uint64 `json:",omitempty"`
+	Special bool   `json:",omitempty"`
+	// Any is set for pointers to squashed (type-erased) data.
+	Any     bool     `json:",omitempty"`
+	Pointee *JSONArg `json:",omitempty"`
+	// Data is hex-encoded contents of input data args, Compressed data stays compressed.
+	// Size is the size of output data args.
+	Data       string `json:",omitempty"`
+	Compressed bool   `json:",omitempty"`
+	Size       uint64 `json:",omitempty"`
+	// Inner contains struct fields and array elements (padding is omitted).
+	Inner []*JSONArg `json:",omitempty"`
+	// Option is the selected option of the union.
+	Option *JSONArg `json:",omitempty"`
+}
+
+// SerializeJSON returns the JSON representation of the program.
+func (p *Prog) SerializeJSON() []byte {
+	data, err := json.Marshal(p.ToJSON())
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// ToJSON converts the program to its JSON representation.
+func (p *Prog) ToJSON() *JSONProg {
+	p.debugValidate()
+	ctx := &serializer{
+		target: p.Target,
+		vars:   make(map[*ResultArg]int),
+	}
+	ret := &JSONProg{
+		Version:  JSONProgVersion,
+		Target:   p.Target.OS + "/" + p.Target.Arch,
+		Comments: p.Comments,
+	}
+	for _, c := range p.Calls {
+		jc := &JSONCall{
+			Name:     c.Meta.Name,
+			CallName: c.Meta.CallName,
+			NR:       c.Meta.NR,
+			Comment:  c.Comment,
+		}
+		if c.Props != (CallProps{}) {
+			props := c.Props
+			jc.Props = &props
+		}
+		if c.Ret != nil && len(c.Ret.uses) != 0 {
+			jc.Ret = fmt.Sprintf("r%v", ctx.allocVarID(c.Ret))
+		}
+		for i, arg := range c.Args {
+			if IsPad(arg.Type()) {
+				continue
+			}
+			jc.Args = append(jc.Args, ctx.jsonArg(arg, c.Meta.Args[i].Name))
+		}
+		ret.Calls = append(ret.Calls, jc)
+	}
+	return ret
+}
+
+func (ctx *serializer) jsonArg(arg Arg, name string) *JSONArg {
+	if arg == nil {
+		return nil
+	}
+	ja := &JSONArg{
+		Name: name,
+		Type: arg.Type().Name(),
+		Dir:  arg.Dir().String(),
+	}
+	switch a := arg.(type) {
+	case *ConstArg:
+		ja.Kind = JSONConst
+		ja.Value = a.Val
+	case *ResultArg:
+		ja.Kind = JSONResult
+		if len(a.uses) != 0 {
+			ja.Var = fmt.Sprintf("r%v", ctx.allocVarID(a))
+		}
+		if a.Res == nil {
+			ja.Value = a.Val
+			break
+		}
+		id, ok := ctx.vars[a.Res]
+		if !ok {
+			panic("no result")
+		}
+		ja.Ref = fmt.Sprintf("r%v", id)
+		ja.OpDiv = a.OpDiv
+		ja.OpAdd = a.OpAdd
+	case *PointerArg:
+		ja.Kind = JSONPointer
+		if a.IsSpecial() {
+			ja.Special = true
+			ja.Address = a.Address
+			break
+		}
+		ja.Address = encodingAddrBase + a.Address
+		ja.VmaSize = a.VmaSize
+		ja.Any = ctx.target.isAnyPtr(a.Type())
+		ja.Pointee = ctx.jsonArg(a.Res, "")
+	case *DataArg:
+		ja.Kind = JSONData
+		if a.Dir() == DirOut {
+			ja.Size = a.Size()
+			break
+		}
+		ja.Data = hex.EncodeToString(a.Data())
+		ja.Compressed = a.Type().(*BufferType).IsCompressed()
+	case *GroupArg:
+		ja.Kind = JSONArray
+		if typ, ok := a.Type().(*StructType); ok {
+			ja.Kind = JSONStruct
+			// Struct args may be created without inner args when decoding, but never with nil entries.
+			ja.Inner = []*JSONArg{}
+			for i, inner := range a.Inner {
+				if !IsPad(inner.Type()) {
+					ja.Inner = append(ja.Inner, ctx.jsonArg(inner, typ.Fields[i].Name))
+				}
+			}
+			break
+		}
+		ja.Inner = []*JSONArg{}
+		for _, inner := range a.Inner {
+			ja.Inner = append(ja.Inner, ctx.jsonArg(inner, ""))
+		}
+	case *UnionArg:
+		ja.Kind = JSONUnion
+		ja.Option = ctx.jsonArg(a.Option, a.Type().(*UnionType).Fields[a.Index].Name)
+	default:
+		panic(fmt.Sprintf("unknown arg type %T", arg))
+	}
+	return ja
+}
+
+// DeserializeJSON restores the program from its JSON representation.
+// The program is validated the same way Deserialize validates programs in the text format.
+func (target *Target) DeserializeJSON(data []byte, mode DeserializeMode) (*Prog, error) {
+	jp := new(JSONProg)
+	if err := json.Unmarshal(data, jp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON program: %w", err)
+	}
+	return target.FromJSON(jp, mode)
+}
+
+// FromJSON converts the JSON representation back to a program.
+func (target *Target) FromJSON(jp *JSONProg, mode DeserializeMode) (*Prog, error) {
+	if jp.Version != JSONProgVersion {
+		return nil, fmt.Errorf("unsupported JSON program version %v, want %v", jp.Version, JSONProgVersion)
+	}
+	if want := target.OS + "/" + target.Arch; jp.Target != want {
+		return nil, fmt.Errorf("JSON program is for target %v, want %v", jp.Target, want)
+	}
+	// The JSON representation is converted to the text format, which gives us all the checks
+	// and fixups of the text parser for free.
+	buf := new(bytes.Buffer)
+	for i, jc := range jp.Calls {
+		if err := jsonCall(buf, jc); err != nil {
+			return nil, fmt.Errorf("call #%v %v: %w", i, jc.Name, err)
+		}
+	}
+	for _, comment := range jp.Comments {
+		if err := jsonComment(buf, comment); err != nil {
+			return nil, err
+		}
+	}
+	return target.Deserialize(buf.Bytes(), mode)
+}
+
+var jsonIdentRe = regexp.MustCompile(`^[a-zA-Z0-9_$]+$`)
+
+func jsonIdent(kind, name string) error {
+	if !jsonIdentRe.MatchString(name) {
+		return fmt.Errorf("bad %v name %q", kind, name)
+	}
+	return nil
+}
+
+func jsonComment(buf *bytes.Buffer, comment string) error {
+	if strings.ContainsAny(comment, "\r\n") {
+		return fmt.Errorf("multi-line comment %q", comment)
+	}
+	fmt.Fprintf(buf, "# %v\n", comment)
+	return nil
+}
+
+func jsonCall(buf *bytes.Buffer, jc *JSONCall) error {
+	if jc == nil {
+		return fmt.Errorf("null call")
+	}
+	if jc.Comment != "" {
+		if err := jsonComment(buf, jc.Comment); err != nil {
+			return err
+		}
+	}
+	if jc.Ret != "" {
+		if err := jsonIdent("variable", jc.Ret); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%v = ", jc.Ret)
+	}
+	if err := jsonIdent("syscall", jc.Name); err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "%v(", jc.Name)
+	for i, arg := range jc.Args {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		if err := jsonArgText(buf, arg); err != nil {
+			return err
+		}
+	}
+	buf.WriteString(")")
+	if jc.Props != nil {
+		sep := " ("
+		jc.Props.ForeachProp(func(_, key string, value reflect.Value) {
+			if value.IsZero() {
+				return
+			}
+			fmt.Fprintf(buf, "%v%v", sep, key)
+			if value.Kind() == reflect.Int {
+				fmt.Fprintf(buf, ": %d", value.Int())
+			}
+			sep = ", "
+		})
+		if sep != " (" {
+			buf.WriteString(")")
+		}
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+func jsonArgText(buf *bytes.Buffer, arg *JSONArg) error {
+	if arg == nil {
+		buf.WriteString("nil")
+		return nil
+	}
+	switch arg.Kind {
+	case JSONConst:
+		fmt.Fprintf(buf, "0x%x", arg.Value)
+	case JSONResult:
+		if arg.Var != "" {
+			if err := jsonIdent("variable", arg.Var); err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, "<%v=>", arg.Var)
+		}
+		if arg.Ref == "" {
+			fmt.Fprintf(buf, "0x%x", arg.Value)
+			break
+		}
+		if err := jsonIdent("variable", arg.Ref); err != nil {
+			return err
+		}
+		buf.WriteString(arg.Ref)
+		if arg.OpDiv != 0 {
+			fmt.Fprintf(buf, "/%v", arg.OpDiv)
+		}
+		if arg.OpAdd != 0 {
+			fmt.Fprintf(buf, "+%v", arg.OpAdd)
+		}
+	case JSONPointer:
+		if arg.Special {
+			fmt.Fprintf(buf, "0x%x", arg.Address)
+			break
+		}
+		fmt.Fprintf(buf, "&(0x%x", arg.Address)
+		if arg.VmaSize != 0 {
+			fmt.Fprintf(buf, "/0x%x", arg.VmaSize)
+		}
+		buf.WriteString(")=")
+		if arg.Any {
+			buf.WriteString("ANY=")
+		}
+		return jsonArgText(buf, arg.Pointee)
+	case JSONData:
+		if arg.Dir == DirOut.String() {
+			fmt.Fprintf(buf, "\"\"/%v", arg.Size)
+			break
+		}
+		data, err := hex.DecodeString(arg.Data)
+		if err != nil {
+			return fmt.Errorf("bad data of %v: %w", arg.Name, err)
+		}
+		if arg.Compressed {
+			fmt.Fprintf(buf, "\"$%s\"", image.EncodeB64(data))
+		} else {
+			fmt.Fprintf(buf, "\"%v\"", arg.Data)
+		}
+	case JSONStruct, JSONArray:
+		delims := "{}"
+		if arg.Kind == JSONArray {
+			delims = "[]"
+		}
+		buf.WriteByte(delims[0])
+		for i, inner := range arg.Inner {
+			if i != 0 {
+				buf.WriteString(", ")
+			}
+			if err := jsonArgText(buf, inner); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(delims[1])
+	case JSONUnion:
+		if arg.Option == nil {
+			return fmt.Errorf("union %v has no option", arg.Name)
+		}
+		if err := jsonIdent("union option", arg.Option.Name); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "@%v=", arg.Option.Name)
+		return jsonArgText(buf, arg.Option)
+	default:
+		return fmt.Errorf("unknown arg kind %q", arg.Kind)
+	}
+	return nil
+}
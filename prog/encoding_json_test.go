@@ -0,0 +1,78 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeJSONRandom(t *testing.T) {
+	testEachTargetRandom(t, func(t *testing.T, target *Target, rs rand.Source, iters int) {
+		ct := target.DefaultChoiceTable()
+		for i := 0; i < iters; i++ {
+			p0 := target.Generate(rs, 10, ct)
+			p0.Calls[0].Comment = "first call"
+			p0.Comments = []string{"trailing comment"}
+			data := p0.SerializeJSON()
+			p1, err := target.DeserializeJSON(data, Strict)
+			if err != nil {
+				t.Fatalf("failed to deserialize: %v\nprogram:\n%s\njson:\n%s", err, p0.Serialize(), data)
+			}
+			require.Equal(t, string(p0.SerializeVerbose()), string(p1.SerializeVerbose()))
+			require.Equal(t, p0.Calls[0].Comment, p1.Calls[0].Comment)
+			require.Equal(t, p0.Comments, p1.Comments)
+			require.Equal(t, string(data), string(p1.SerializeJSON()))
+		}
+	})
+}
+
+func TestSerializeJSON(t *testing.T) {
+	target := initTargetTest(t, "test", "64")
+	p, err := target.Deserialize([]byte(`r0 = test$res0()
+test$res1(r0) (fail_nth: 2)
+`), Strict)
+	require.NoError(t, err)
+	jp := p.ToJSON()
+	require.Len(t, jp.Calls, 2)
+	assert.Equal(t, "test/64", jp.Target)
+	assert.Equal(t, "test$res0", jp.Calls[0].Name)
+	assert.Equal(t, "test", jp.Calls[0].CallName)
+	assert.Equal(t, "r0", jp.Calls[0].Ret)
+	assert.Equal(t, &CallProps{FailNth: 2}, jp.Calls[1].Props)
+	require.Len(t, jp.Calls[1].Args, 1)
+	assert.Equal(t, &JSONArg{
+		Kind: JSONResult,
+		Name: "a0",
+		Type: "syz_res",
+		Dir:  "in",
+		Ref:  "r0",
+	}, jp.Calls[1].Args[0])
+}
+
+func TestDeserializeJSONErrors(t *testing.T) {
+	target := initTargetTest(t, "test", "64")
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{`{`, "failed to parse JSON program"},
+		{`{"Version":100,"Target":"test/64"}`, "unsupported JSON program version"},
+		{`{"Version":1,"Target":"linux/amd64"}`, "JSON program is for target linux/amd64"},
+		{`{"Version":1,"Target":"test/64","Calls":[{"Name":"test()\nfoo"}]}`, "bad syscall name"},
+		{`{"Version":1,"Target":"test/64","Calls":[{"Name":"test","Comment":"a\nb"}]}`, "multi-line comment"},
+		{`{"Version":1,"Target":"test/64","Calls":[{"Name":"test$res1","Args":[{"Kind":"foo"}]}]}`,
+			"unknown arg kind"},
+		{`{"Version":1,"Target":"test/64","Calls":[{"Name":"test$res1","Args":[{"Kind":"result","Ref":"r0"}]}]}`,
+			"undeclared variable r0"},
+	}
+	for _, test := range tests {
+		_, err := target.DeserializeJSON([]byte(test.data), Strict)
+		require.Error(t, err, test.data)
+		assert.Contains(t, err.Error(), test.err, test.data)
+	}
+}
@@ -35,6 +35,15 @@ func (bo BinaryExpression) Evaluate(finder ArgFinder) (uint64, bool) {
 			return 1, true
 		}
 		return 0, true
+	case OperatorAdd:
+		return left + right, true
+	case OperatorSub:
+		if left < right {
+			// Treat underflows as not calculable, these are almost never intended
+			// (e.g. value[size] - value[offset] when offset is out of bounds).
+			return 0, false
+		}
+		return left - right, true
 	}
 	panic(fmt.Sprintf("unknown operator %q", bo.Operator))
 }
@@ -6,9 +6,11 @@ package prog
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"reflect"
 
 	"github.com/google/syzkaller/pkg/hash"
+	"github.com/google/syzkaller/pkg/image"
 	"github.com/google/syzkaller/pkg/stat"
 )
 
@@ -29,6 +31,8 @@ var (
 		"Total number of buffer minimization attempts", stat.StackedGraph("minimize"))
 	statMinFilename = stat.New("minimize: filename",
 		"Total number of filename minimization attempts", stat.StackedGraph("minimize"))
+	statMinImage = stat.New("minimize: image",
+		"Total number of compressed image minimization attempts", stat.StackedGraph("minimize"))
 )
 
 type MinimizeMode int
@@ -74,6 +78,8 @@ func Minimize(p0 *Prog, callIndex0 int, mode MinimizeMode, pred0 func(*Prog, int
 		name0 = p0.Calls[callIndex0].Meta.Name
 	}
 
+	budget := newDataBudget(mode)
+
 	// Try to remove all calls except the last one one-by-one.
 	p0, callIndex0 = removeCalls(p0, callIndex0, pred)
 
@@ -84,6 +90,9 @@ func Minimize(p0 *Prog, callIndex0 int, mode MinimizeMode, pred0 func(*Prog, int
 		// Try to minimize individual calls.
 		for i := 0; i < len(p0.Calls); i++ {
 			if p0.Calls[i].Meta.Attrs.NoMinimize {
+				// The arguments of such calls are usually interdependent (e.g. mount options and the image),
+				// so we only try to simplify contents of the images.
+				p0 = minimizeImages(p0, i, callIndex0, mode, budget, pred)
 				continue
 			}
 			ctx := &minimizeArgsCtx{
@@ -92,6 +101,7 @@ func Minimize(p0 *Prog, callIndex0 int, mode MinimizeMode, pred0 func(*Prog, int
 				callIndex0: callIndex0,
 				mode:       mode,
 				pred:       pred,
+				budget:     budget,
 				triedPaths: make(map[string]bool),
 			}
 		again:
@@ -292,6 +302,7 @@ type minimizeArgsCtx struct {
 	callIndex0 int
 	mode       MinimizeMode
 	pred       minimizePred
+	budget     *dataBudget
 	triedPaths map[string]bool
 }
 
@@ -475,25 +486,16 @@ func (typ *BufferType) minimize(ctx *minimizeArgsCtx, arg Arg, path string) bool
 	a := arg.(*DataArg)
 	switch typ.Kind {
 	case BufferBlobRand, BufferBlobRange:
-		len0 := len(a.Data())
-		minLen := int(typ.RangeBegin)
-		for step := len(a.Data()) - minLen; len(a.Data()) > minLen && step > 0; {
-			if len(a.Data())-step >= minLen {
-				a.data = a.Data()[:len(a.Data())-step]
-				ctx.target.assignSizesCall(ctx.call)
-				if ctx.pred(ctx.p, ctx.callIndex0, statMinBuffer, path) {
-					step /= 2
-					continue
-				}
-				a.data = a.Data()[:len(a.Data())+step]
-				ctx.target.assignSizesCall(ctx.call)
-			}
-			step /= 2
-			if ctx.mode == MinimizeCrash {
-				break
-			}
-		}
-		if len(a.Data()) != len0 {
+		data0 := a.Data()
+		budget, test := ctx.budget.take(ctx.budget.perArg, func(data []byte) bool {
+			a.data = data
+			ctx.target.assignSizesCall(ctx.call)
+			return ctx.pred(ctx.p, ctx.callIndex0, statMinBuffer, path)
+		})
+		data := minimizeData(data0, int(typ.RangeBegin), true, ctx.budget.zero, budget, test)
+		a.data = data
+		ctx.target.assignSizesCall(ctx.call)
+		if !bytes.Equal(data, data0) {
 			*ctx.p0 = ctx.p
 			ctx.triedPaths[path] = true
 			return true
@@ -524,3 +526,149 @@ func (typ *BufferType) minimize(ctx *minimizeArgsCtx, arg Arg, path string) bool
 	}
 	return false
 }
+
+// minimizeImages tries to simplify contents of compressed images of call callIndex.
+// Images are minimized in the decompressed form and only zeroed, but never shrunk,
+// since file system images usually have offsets baked in.
+func minimizeImages(p0 *Prog, callIndex, callIndex0 int, mode MinimizeMode, budget *dataBudget,
+	pred minimizePred) *Prog {
+	if mode != MinimizeCrash && mode != MinimizeCrashSnapshot {
+		return p0
+	}
+	p := p0.Clone()
+	call := p.Calls[callIndex]
+	changed := false
+	ForeachArg(call, func(arg Arg, _ *ArgCtx) {
+		typ, ok := arg.Type().(*BufferType)
+		if !ok || !typ.IsCompressed() || arg.Dir() == DirOut {
+			return
+		}
+		a := arg.(*DataArg)
+		compressed0 := a.Data()
+		data, dtor := image.MustDecompress(compressed0)
+		data0 := append([]byte{}, data...)
+		dtor()
+		path := fmt.Sprintf("call%v-image", callIndex)
+		n, test := budget.take(budget.perImage, func(data []byte) bool {
+			a.data = image.Compress(data)
+			p.Target.assignSizesCall(call)
+			return pred(p, callIndex0, statMinImage, path)
+		})
+		data = minimizeData(data0, len(data0), false, true, n, test)
+		if bytes.Equal(data, data0) {
+			a.data = compressed0
+		} else {
+			a.data = image.Compress(data)
+			changed = true
+		}
+		p.Target.assignSizesCall(call)
+	})
+	if !changed {
+		return p0
+	}
+	return p
+}
+
+// dataBudget limits the number of predicate invocations spent on minimization of data arguments
+// and compressed images of a single program.
+type dataBudget struct {
+	perArg   int
+	perImage int
+	// The number of invocations left for the whole program.
+	left int
+	// Whether data should be zeroed.
+	zero bool
+}
+
+func newDataBudget(mode MinimizeMode) *dataBudget {
+	switch mode {
+	case MinimizeCorpus:
+		// Zeroing does not make the program any simpler for mutation.
+		return &dataBudget{perArg: 16, left: math.MaxInt}
+	case MinimizeCrash:
+		// Each test needs a VM run, so the whole program gets only a few tests,
+		// but zeroed data makes reproducers much more readable.
+		return &dataBudget{perArg: 8, perImage: 4, left: 16, zero: true}
+	default:
+		return &dataBudget{perArg: 64, perImage: 64, left: math.MaxInt, zero: true}
+	}
+}
+
+// take returns the number of invocations available for a single argument with the given limit,
+// and the test wrapped to charge the invocations to the program budget.
+func (b *dataBudget) take(limit int, test func([]byte) bool) (int, func([]byte) bool) {
+	return min(limit, b.left), func(data []byte) bool {
+		b.left--
+		return test(data)
+	}
+}
+
+// minimizeData minimizes data using delta debugging: it removes chunks of data
+// while keeping at least minLen bytes (if shrink is set), and then replaces chunks
+// of data with zeros (if zero is set). Chunks start at half of the data and are halved
+// when no chunk can be removed. Test is invoked at most budget times,
+// it must return true if the candidate preserves the property of interest.
+// Data is not modified, the result is always a new slice.
+func minimizeData(data []byte, minLen int, shrink, zero bool, budget int, test func([]byte) bool) []byte {
+	try := func(data []byte) bool {
+		if budget <= 0 {
+			return false
+		}
+		budget--
+		return test(data)
+	}
+	if shrink && len(data) > minLen {
+		if try(append([]byte{}, data[:minLen]...)) {
+			return append([]byte{}, data[:minLen]...)
+		}
+		for n := 2; len(data) > minLen && budget > 0; {
+			chunk := (len(data) + n - 1) / n
+			removed := false
+			// Go from the end, trailing data is more likely to be unused.
+			for end := len(data); end > 0 && budget > 0; end -= chunk {
+				start := max(end-chunk, 0)
+				if len(data)-(end-start) < minLen {
+					continue
+				}
+				candidate := append(append([]byte{}, data[:start]...), data[end:]...)
+				if try(candidate) {
+					data, removed = candidate, true
+					break
+				}
+			}
+			if removed {
+				n = max(n-1, 2)
+				continue
+			}
+			if chunk == 1 {
+				break
+			}
+			n = min(n*2, len(data))
+		}
+	}
+	if zero {
+		for chunk := len(data); chunk > 0 && budget > 0; chunk /= 2 {
+			for start := 0; start < len(data) && budget > 0; start += chunk {
+				end := min(start+chunk, len(data))
+				if isZero(data[start:end]) {
+					continue
+				}
+				candidate := append([]byte{}, data...)
+				clear(candidate[start:end])
+				if try(candidate) {
+					data = candidate
+				}
+			}
+		}
+	}
+	return append([]byte{}, data...)
+}
+
+func isZero(data []byte) bool {
+	for _, v := range data {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
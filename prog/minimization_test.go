@@ -4,12 +4,14 @@
 package prog
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"strings"
 	"testing"
 
 	"github.com/google/syzkaller/pkg/hash"
+	"github.com/google/syzkaller/pkg/image"
 )
 
 // nolint:gocyclo
@@ -234,6 +236,46 @@ func TestMinimize(t *testing.T) {
 			"mutate9(&(0x7f0000000000)='./file0\\x00')\n",
 			0,
 		},
+		// Remove unneeded parts of a buffer.
+		{
+			"test", "64", MinimizeCrashSnapshot,
+			"mutate4(&(0x7f0000000000)=\"0102030405060708\", 0x8)\n",
+			0,
+			func(p *Prog, callIndex int) bool {
+				ptr := p.Calls[0].Args[0].(*PointerArg)
+				return ptr.Res != nil && bytes.IndexByte(ptr.Res.(*DataArg).Data(), 0x5) != -1
+			},
+			"mutate4(&(0x7f0000000000)=\"05\", 0x1)\n",
+			0,
+		},
+		// Shrink and zero a buffer within the crash mode budget.
+		{
+			"test", "64", MinimizeCrash,
+			"mutate4(&(0x7f0000000000)=\"0102030405060708\", 0x8)\n",
+			0,
+			func(p *Prog, callIndex int) bool {
+				ptr := p.Calls[0].Args[0].(*PointerArg)
+				if ptr.Res == nil {
+					return false
+				}
+				data := ptr.Res.(*DataArg).Data()
+				return len(data) >= 2 && data[1] != 0
+			},
+			"mutate4(&(0x7f0000000000)=\"0002\", 0x2)\n",
+			0,
+		},
+		// Buffers are not zeroed for corpus.
+		{
+			"test", "64", MinimizeCorpus,
+			"mutate4(&(0x7f0000000000)=\"0102030405060708\", 0x8)\n",
+			0,
+			func(p *Prog, callIndex int) bool {
+				ptr := p.Calls[0].Args[0].(*PointerArg)
+				return ptr.Res != nil && len(ptr.Res.(*DataArg).Data()) >= 3
+			},
+			"mutate4(&(0x7f0000000000)=\"010203\", 0x3)\n",
+			0,
+		},
 		// Ensure `no_minimize` calls are untouched.
 		{
 			"linux", "amd64", MinimizeCorpus,
@@ -403,3 +445,165 @@ func TestMinimizeCallIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestMinimizeData(t *testing.T) {
+	tests := []struct {
+		data   string
+		minLen int
+		shrink bool
+		zero   bool
+		budget int
+		pred   func([]byte) bool
+		result string
+	}{
+		// Nothing can be changed.
+		{
+			data:   "abcd",
+			shrink: true,
+			zero:   true,
+			budget: 100,
+			pred:   func(data []byte) bool { return string(data) == "abcd" },
+			result: "abcd",
+		},
+		// Remove everything at once.
+		{
+			data:   "abcd",
+			minLen: 1,
+			shrink: true,
+			budget: 100,
+			pred:   func(data []byte) bool { return true },
+			result: "a",
+		},
+		// Keep a byte in the middle.
+		{
+			data:   "abcdefghijklmnop",
+			shrink: true,
+			zero:   true,
+			budget: 100,
+			pred:   func(data []byte) bool { return bytes.IndexByte(data, 'k') != -1 },
+			result: "k",
+		},
+		// Keep non-adjacent bytes and zero the rest.
+		{
+			data:   "abcdefghijklmnop",
+			zero:   true,
+			budget: 100,
+			pred: func(data []byte) bool {
+				return len(data) == 16 && data[2] == 'c' && data[13] == 'n'
+			},
+			result: "\x00\x00c\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00n\x00\x00",
+		},
+		// Remove non-adjacent bytes.
+		{
+			data:   "abcdefghijklmnop",
+			shrink: true,
+			budget: 100,
+			pred: func(data []byte) bool {
+				return bytes.Contains(data, []byte("cd")) && bytes.Contains(data, []byte("mn"))
+			},
+			result: "cdmn",
+		},
+		// Budget is exhausted after the first attempt.
+		{
+			data:   "abcd",
+			shrink: true,
+			zero:   true,
+			budget: 1,
+			pred:   func(data []byte) bool { return len(data) != 0 },
+			result: "abcd",
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			data := []byte(test.data)
+			calls := 0
+			res := minimizeData(data, test.minLen, test.shrink, test.zero, test.budget, func(data []byte) bool {
+				calls++
+				return test.pred(data)
+			})
+			if string(res) != test.result {
+				t.Fatalf("got %q, want %q", res, test.result)
+			}
+			if string(data) != test.data {
+				t.Fatalf("input data was modified: %q", data)
+			}
+			if calls > test.budget {
+				t.Fatalf("test was called %v times with budget %v", calls, test.budget)
+			}
+		})
+	}
+}
+
+func TestMinimizeImage(t *testing.T) {
+	target, err := GetTarget("test", "64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := bytes.Repeat([]byte("syzkaller"), 8)
+	p, err := target.Deserialize([]byte(fmt.Sprintf("syz_compare_zlib(&(0x7f0000000000)=\"\", 0x0, "+
+		"&(0x7f0000001000)=\"$%s\", 0x%x)", image.EncodeB64(image.Compress(img)), len(img))), Strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompress := func(p *Prog) []byte {
+		data, dtor := image.MustDecompress(p.Calls[0].Args[2].(*PointerArg).Res.(*DataArg).Data())
+		defer dtor()
+		return append([]byte{}, data...)
+	}
+	pred := func(p *Prog, callIndex int) bool {
+		data := decompress(p)
+		return len(data) == len(img) && data[10] == 'y'
+	}
+	p1, _ := Minimize(p, 0, MinimizeCorpus, pred)
+	if got := decompress(p1); !bytes.Equal(got, img) {
+		t.Fatalf("image was changed in corpus mode: %q", got)
+	}
+	p1, _ = Minimize(p, 0, MinimizeCrashSnapshot, pred)
+	want := make([]byte, len(img))
+	want[10] = 'y'
+	if got := decompress(p1); !bytes.Equal(got, want) {
+		t.Fatalf("got image %q, want %q", got, want)
+	}
+	// Crash mode has a small budget, so only the biggest chunks are zeroed.
+	p1, _ = Minimize(p, 0, MinimizeCrash, pred)
+	want = append([]byte{}, img...)
+	clear(want[len(img)/2:])
+	if got := decompress(p1); !bytes.Equal(got, want) {
+		t.Fatalf("got image %q, want %q", got, want)
+	}
+}
+
+func TestMinimizeCrashDataBudget(t *testing.T) {
+	target, err := GetTarget("test", "64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const text = "mutate4(&(0x7f0000000000)=\"0102030405060708\", 0x8)\n"
+	p, err := target.Deserialize([]byte(strings.Repeat(text, 4)), Strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each test needs a VM run in crash mode, so the data budget is per program rather than per buffer.
+	// None of the data can be minimized, so each buffer would use up its own budget otherwise.
+	dataTests := 0
+	pred := func(p1 *Prog, callIndex int) bool {
+		if len(p1.Calls) != len(p.Calls) {
+			return false
+		}
+		for _, c := range p1.Calls {
+			ptr := c.Args[0].(*PointerArg)
+			if ptr.Res == nil {
+				return false
+			}
+			if !bytes.Equal(ptr.Res.(*DataArg).Data(), []byte{1, 2, 3, 4, 5, 6, 7, 8}) {
+				dataTests++
+				return false
+			}
+		}
+		return true
+	}
+	Minimize(p, len(p.Calls)-1, MinimizeCrash, pred)
+	if dataTests != 16 {
+		t.Fatalf("data was tested %v times, want 16", dataTests)
+	}
+}
@@ -47,6 +47,8 @@ type MutateOpts struct {
 	InsertWeight       int
 	MutateArgWeight    int
 	RemoveCallWeight   int
+	// KeepSizes disables mutation of len fields, which otherwise may be set to incorrect values on purpose.
+	KeepSizes bool
 }
 
 func (o MutateOpts) weight() int {
@@ -230,7 +232,7 @@ func (ctx *mutator) mutateArg() bool {
 	updateSizes := true
 	for stop, ok := false, false; !stop; stop = ok && r.oneOf(ctx.opts.MutateArgCount) {
 		ok = true
-		ma := &mutationArgs{target: p.Target}
+		ma := &mutationArgs{target: p.Target, keepSizes: ctx.opts.KeepSizes}
 		ForeachArg(c, ma.collectArg)
 		if len(ma.args) == 0 {
 			return false
@@ -257,6 +259,7 @@ func (ctx *mutator) mutateArg() bool {
 		if updateSizes || fieldsPatched {
 			p.Target.assignSizesCall(c)
 		}
+		r.patchConstraints(c)
 	}
 	return true
 }
@@ -562,6 +565,7 @@ func (t *ConstType) mutate(r *randGen, s *state, arg Arg, ctx ArgCtx) (calls []*
 type mutationArgs struct {
 	target        *Target
 	ignoreSpecial bool
+	keepSizes     bool
 	prioSum       float64
 	args          []mutationArg
 	argsBuffer    [16]mutationArg
@@ -590,6 +594,9 @@ func (ma *mutationArgs) collectArg(arg Arg, ctx *ArgCtx) {
 	if prio == dontMutate {
 		return
 	}
+	if _, isLenTyp := typ.(*LenType); isLenTyp && ma.keepSizes {
+		return
+	}
 
 	_, isArrayTyp := typ.(*ArrayType)
 	_, isBufferTyp := typ.(*BufferType)
@@ -242,6 +242,22 @@ func TestMutateRandom(t *testing.T) {
 	})
 }
 
+func TestMutateKeepSizes(t *testing.T) {
+	target, rs, iters := initRandomTargetTest(t, "test", "64")
+	ct := target.DefaultChoiceTable()
+	opts := DefaultMutateOpts
+	opts.KeepSizes = true
+	for i := 0; i < iters; i++ {
+		p := target.Generate(rs, 10, ct)
+		for it := 0; it < 10; it++ {
+			p.MutateWithOpts(rs, 10, ct, nil, nil, opts)
+			if err := p.CheckSizes(); err != nil {
+				t.Fatalf("%v\n%s", err, p.Serialize())
+			}
+		}
+	}
+}
+
 func TestMutateCorpus(t *testing.T) {
 	target, rs, iters := initTest(t)
 	ct := target.DefaultChoiceTable()
@@ -264,6 +264,9 @@ type ChoiceTable struct {
 	target *Target
 	runs   [][]int32
 	calls  []*Syscall
+	// Call sequences set with UseSequences and cumulative sums of their weights.
+	seqs    []*CallSequence
+	seqRuns []int
 }
 
 func (target *Target) BuildChoiceTable(corpus []*Prog, enabled map[*Syscall]bool) *ChoiceTable {
@@ -293,7 +296,7 @@ func (target *Target) BuildChoiceTable(corpus []*Prog, enabled map[*Syscall]bool
 			run[i][j] = sum
 		}
 	}
-	return &ChoiceTable{target, run, generatableCalls}
+	return &ChoiceTable{target: target, runs: run, calls: generatableCalls}
 }
 
 func (ct *ChoiceTable) Generatable(call int) bool {
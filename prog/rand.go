@@ -587,12 +587,16 @@ func (r *randGen) nOutOf(n, outOf int) bool {
 }
 
 func (r *randGen) generateCall(s *state, p *Prog, insertionPoint int) []*Call {
+	if len(s.ct.seqs) != 0 && r.oneOf(10) {
+		return r.generateSequence(s, p, insertionPoint, s.ct.chooseSequence(r.Rand))
+	}
 	biasCall := -1
 	if insertionPoint > 0 {
 		// Choosing the base call is based on the insertion point of the new calls sequence.
 		insertionCall := p.Calls[r.Intn(insertionPoint)].Meta
-		if !insertionCall.Attrs.NoGenerate {
-			// We must be careful not to bias towards a non-generatable call.
+		if s.ct.Generatable(insertionCall.ID) {
+			// We must be careful not to bias towards a non-generatable call
+			// (e.g. a no_generate call or a call outside of the current rotation).
 			biasCall = insertionCall.ID
 		}
 	}
@@ -612,6 +616,7 @@ func (r *randGen) generateParticularCall(s *state, meta *Syscall) (calls []*Call
 	c.Args, calls = r.generateArgs(s, meta.Args, DirIn)
 	moreCalls, _ := r.patchConditionalFields(c, s)
 	r.target.assignSizesCall(c)
+	r.patchConstraints(c)
 	return append(append(calls, moreCalls...), c)
 }
 
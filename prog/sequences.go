@@ -0,0 +1,357 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// SequenceModel is a probabilistic model of call sequences mined from a corpus.
+// Unlike ChoiceTable priorities, which capture only pairs of calls, the model captures
+// whole chains of calls connected by resources (e.g. socket -> bind -> listen -> accept),
+// which program generation can insert as a whole to create deep stateful setups.
+type SequenceModel struct {
+	// Programs is the number of programs the model was mined from.
+	Programs  int             `json:"programs"`
+	Sequences []*CallSequence `json:"sequences"`
+}
+
+// CallSequence is an ordered sequence of calls where each call except the first one
+// consumes a resource produced by one of the preceding calls of the sequence.
+// The calls are not necessarily adjacent in the programs the sequence was mined from.
+type CallSequence struct {
+	Calls []string `json:"calls"`
+	// Deps[i-1] is the index of the call in Calls that produces the resource consumed by Calls[i].
+	Deps []int `json:"deps"`
+	// Resources[i-1] is the kind of the resource consumed by Calls[i].
+	Resources []string `json:"resources"`
+	// Support is the number of programs that contain the sequence.
+	Support int `json:"support"`
+	// Prob is the probability of the last call given that the rest of the sequence is present.
+	Prob float64 `json:"prob"`
+
+	syscalls []*Syscall
+}
+
+type SequenceOpts struct {
+	// Max number of calls in a sequence.
+	MaxLen int
+	// Min number of programs that must contain a sequence.
+	MinSupport int
+	// Max number of sequences in the model, the most frequent ones are kept.
+	MaxSequences int
+}
+
+var DefaultSequenceOpts = SequenceOpts{
+	MaxLen:       4,
+	MinSupport:   3,
+	MaxSequences: 1000,
+}
+
+func (seq *CallSequence) String() string {
+	res := []string{seq.Calls[0]}
+	for i, call := range seq.Calls[1:] {
+		res = append(res, fmt.Sprintf("%v(#%v %v)", call, seq.Deps[i], seq.Resources[i]))
+	}
+	return strings.Join(res, " -> ")
+}
+
+func (seq *CallSequence) key() string {
+	res := seq.Calls[0]
+	for i, call := range seq.Calls[1:] {
+		res += fmt.Sprintf(" %v:%v:%v", call, seq.Deps[i], seq.Resources[i])
+	}
+	return res
+}
+
+// MineSequences builds a sequence model from the corpus.
+func (target *Target) MineSequences(corpus []*Prog, opts SequenceOpts) *SequenceModel {
+	seqs := make(map[string]*CallSequence)
+	for _, p := range corpus {
+		seen := make(map[string]bool)
+		newSequenceMiner(p, opts.MaxLen).mine(func(seq *CallSequence) {
+			key := seq.key()
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+			if seqs[key] == nil {
+				seqs[key] = seq
+			}
+			seqs[key].Support++
+		})
+	}
+	m := &SequenceModel{Programs: len(corpus)}
+	callSupport := make(map[string]int)
+	for _, p := range corpus {
+		seen := make(map[string]bool)
+		for _, c := range p.Calls {
+			if !seen[c.Meta.Name] {
+				seen[c.Meta.Name] = true
+				callSupport[c.Meta.Name]++
+			}
+		}
+	}
+	for _, seq := range seqs {
+		if seq.Support < opts.MinSupport {
+			continue
+		}
+		n := len(seq.Calls) - 1
+		prefixSupport := callSupport[seq.Calls[0]]
+		if n != 1 {
+			prefix := &CallSequence{
+				Calls:     seq.Calls[:n],
+				Deps:      seq.Deps[:n-1],
+				Resources: seq.Resources[:n-1],
+			}
+			prefixSupport = seqs[prefix.key()].Support
+		}
+		seq.Prob = min(float64(seq.Support)/float64(prefixSupport), 1)
+		m.Sequences = append(m.Sequences, seq)
+	}
+	sort.Slice(m.Sequences, func(i, j int) bool {
+		a, b := m.Sequences[i], m.Sequences[j]
+		if a.Support != b.Support {
+			return a.Support > b.Support
+		}
+		if len(a.Calls) != len(b.Calls) {
+			return len(a.Calls) > len(b.Calls)
+		}
+		return a.key() < b.key()
+	})
+	if opts.MaxSequences != 0 && len(m.Sequences) > opts.MaxSequences {
+		m.Sequences = m.Sequences[:opts.MaxSequences]
+	}
+	return m
+}
+
+type sequenceDep struct {
+	call int
+	kind string
+}
+
+type sequenceMiner struct {
+	p      *Prog
+	maxLen int
+	// deps[i] are the calls that produce resources consumed by call i.
+	deps  [][]sequenceDep
+	calls []int
+	found int
+}
+
+// maxSequencesPerProg bounds the combinatorial explosion for programs
+// that use the same resource in lots of calls.
+const maxSequencesPerProg = 10000
+
+func newSequenceMiner(p *Prog, maxLen int) *sequenceMiner {
+	producer := make(map[*ResultArg]int)
+	deps := make([][]sequenceDep, len(p.Calls))
+	for i, c := range p.Calls {
+		ForeachArg(c, func(arg Arg, _ *ArgCtx) {
+			a, ok := arg.(*ResultArg)
+			if !ok {
+				return
+			}
+			if a.Res != nil {
+				if call, ok := producer[a.Res]; ok && call != i {
+					deps[i] = append(deps[i], sequenceDep{call, a.Res.Type().(*ResourceType).Desc.Name})
+				}
+			}
+			if len(a.uses) != 0 {
+				producer[a] = i
+			}
+		})
+	}
+	return &sequenceMiner{
+		p:      p,
+		maxLen: maxLen,
+		deps:   deps,
+	}
+}
+
+func (sm *sequenceMiner) mine(cb func(*CallSequence)) {
+	for i := range sm.p.Calls {
+		sm.calls = append(sm.calls[:0], i)
+		sm.extend(nil, nil, cb)
+	}
+}
+
+func (sm *sequenceMiner) extend(deps []int, kinds []string, cb func(*CallSequence)) {
+	if len(sm.calls) == sm.maxLen {
+		return
+	}
+	for j := sm.calls[len(sm.calls)-1] + 1; j < len(sm.p.Calls); j++ {
+		if sm.found >= maxSequencesPerProg {
+			return
+		}
+		dep, kind := sm.dependency(j)
+		if dep == -1 {
+			continue
+		}
+		sm.calls = append(sm.calls, j)
+		deps1 := append(deps[:len(deps):len(deps)], dep)
+		kinds1 := append(kinds[:len(kinds):len(kinds)], kind)
+		seq := &CallSequence{
+			Deps:      deps1,
+			Resources: kinds1,
+		}
+		for _, idx := range sm.calls {
+			seq.Calls = append(seq.Calls, sm.p.Calls[idx].Meta.Name)
+			seq.syscalls = append(seq.syscalls, sm.p.Calls[idx].Meta)
+		}
+		sm.found++
+		cb(seq)
+		sm.extend(deps1, kinds1, cb)
+		sm.calls = sm.calls[:len(sm.calls)-1]
+	}
+}
+
+// dependency returns the index of the latest call in the current sequence
+// that produces a resource consumed by call j.
+func (sm *sequenceMiner) dependency(j int) (int, string) {
+	for i := len(sm.calls) - 1; i >= 0; i-- {
+		for _, dep := range sm.deps[j] {
+			if dep.call == sm.calls[i] {
+				return i, dep.kind
+			}
+		}
+	}
+	return -1, ""
+}
+
+// Serialize returns JSON representation of the model.
+func (m *SequenceModel) Serialize() []byte {
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// ParseSequenceModel parses a model serialized with Serialize.
+// Sequences that refer to unknown calls or resources are dropped,
+// since the model may be mined on a different kernel version.
+func (target *Target) ParseSequenceModel(data []byte) (*SequenceModel, error) {
+	m := new(SequenceModel)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse sequence model: %w", err)
+	}
+	seqs := m.Sequences
+	m.Sequences = nil
+	for i, seq := range seqs {
+		if len(seq.Calls) < 2 || len(seq.Deps) != len(seq.Calls)-1 || len(seq.Resources) != len(seq.Calls)-1 {
+			return nil, fmt.Errorf("sequence #%v is malformed", i)
+		}
+		valid := true
+		for j, name := range seq.Calls {
+			meta := target.SyscallMap[name]
+			if meta == nil {
+				valid = false
+				break
+			}
+			if j != 0 && (seq.Deps[j-1] < 0 || seq.Deps[j-1] >= j) {
+				return nil, fmt.Errorf("sequence #%v has bad dependency %v for call %v", i, seq.Deps[j-1], j)
+			}
+			seq.syscalls = append(seq.syscalls, meta)
+		}
+		for _, kind := range seq.Resources {
+			if target.resourceMap[kind] == nil {
+				valid = false
+			}
+		}
+		if valid {
+			m.Sequences = append(m.Sequences, seq)
+		}
+	}
+	return m, nil
+}
+
+// UseSequences makes program generation insert whole call sequences sampled from the model.
+// Sequences that contain non-generatable calls are ignored. The choice table must not be used concurrently
+// while the sequences are set.
+func (ct *ChoiceTable) UseSequences(m *SequenceModel) {
+	ct.seqs, ct.seqRuns = nil, nil
+	sum := 0
+	for _, seq := range m.Sequences {
+		enabled := true
+		for _, meta := range seq.syscalls {
+			if !ct.Generatable(meta.ID) {
+				enabled = false
+				break
+			}
+		}
+		if !enabled {
+			continue
+		}
+		// Prefer longer sequences, they are what is hard to get with pairwise priorities.
+		sum += seq.Support * (len(seq.Calls) - 1)
+		ct.seqs = append(ct.seqs, seq)
+		ct.seqRuns = append(ct.seqRuns, sum)
+	}
+}
+
+func (ct *ChoiceTable) chooseSequence(r *rand.Rand) *CallSequence {
+	x := r.Intn(ct.seqRuns[len(ct.seqRuns)-1])
+	idx := sort.Search(len(ct.seqRuns), func(i int) bool {
+		return ct.seqRuns[i] > x
+	})
+	return ct.seqs[idx]
+}
+
+// generateSequence generates calls for seq to be inserted at insertionPoint in p.
+// The resources are connected according to the sequence dependencies.
+func (r *randGen) generateSequence(s *state, p *Prog, insertionPoint int, seq *CallSequence) []*Call {
+	var res, chunk []*Call
+	for i, meta := range seq.syscalls {
+		if i != 0 {
+			// Let the generated calls see resources created by the preceding calls of the sequence.
+			prefix := append(append([]*Call{}, p.Calls[:insertionPoint]...), res...)
+			s = analyze(s.ct, s.corpus, &Prog{Target: p.Target, Calls: prefix}, nil)
+		}
+		calls := r.generateParticularCall(s, meta)
+		c := calls[len(calls)-1]
+		if i != 0 {
+			r.connectResource(chunk[seq.Deps[i-1]], c, seq.Resources[i-1])
+		}
+		res = append(res, calls...)
+		chunk = append(chunk, c)
+	}
+	return res
+}
+
+// connectResource makes consumer use a resource of the given kind produced by producer,
+// unless it already does so.
+func (r *randGen) connectResource(producer, consumer *Call, kind string) {
+	var produced []*ResultArg
+	ForeachArg(producer, func(arg Arg, _ *ArgCtx) {
+		if a, ok := arg.(*ResultArg); ok && a.Dir() != DirIn && a.Type().(*ResourceType).Desc.Name == kind {
+			produced = append(produced, a)
+		}
+	})
+	if len(produced) == 0 {
+		// E.g. the output pointer was not generated.
+		return
+	}
+	var consumers []*ResultArg
+	done := false
+	ForeachArg(consumer, func(arg Arg, _ *ArgCtx) {
+		a, ok := arg.(*ResultArg)
+		if !ok || a.Dir() == DirOut || !r.target.isCompatibleResource(a.Type().(*ResourceType).Desc.Name, kind) {
+			return
+		}
+		consumers = append(consumers, a)
+		for _, res := range produced {
+			done = done || a.Res == res
+		}
+	})
+	if done || len(consumers) == 0 {
+		return
+	}
+	a := consumers[r.Intn(len(consumers))]
+	replaceArg(a, MakeResultArg(a.Type(), a.Dir(), produced[r.Intn(len(produced))], 0))
+}
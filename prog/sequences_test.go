@@ -0,0 +1,127 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sequenceTestCorpus(t *testing.T, target *Target) []*Prog {
+	progs := []string{`
+r0 = socket$inet_tcp(0x2, 0x1, 0x0)
+bind$inet(r0, 0x0, 0x0)
+listen(r0, 0x5)
+r1 = accept$inet(r0, 0x0, 0x0)
+close(r1)
+`, `
+r0 = socket$inet_tcp(0x2, 0x1, 0x0)
+getpid()
+bind$inet(r0, 0x0, 0x0)
+listen(r0, 0x5)
+r1 = accept$inet(r0, 0x0, 0x0)
+`, `
+r0 = socket$inet_tcp(0x2, 0x1, 0x0)
+bind$inet(r0, 0x0, 0x0)
+close(r0)
+`}
+	var corpus []*Prog
+	for _, text := range progs {
+		p, err := target.Deserialize([]byte(strings.TrimSpace(text)), Strict)
+		require.NoError(t, err)
+		corpus = append(corpus, p)
+	}
+	return corpus
+}
+
+func TestMineSequences(t *testing.T) {
+	target, err := GetTarget("linux", "amd64")
+	require.NoError(t, err)
+	opts := DefaultSequenceOpts
+	opts.MinSupport = 2
+	m := target.MineSequences(sequenceTestCorpus(t, target), opts)
+	assert.Equal(t, 3, m.Programs)
+	var res []string
+	for _, seq := range m.Sequences {
+		res = append(res, seq.String())
+	}
+	assert.Equal(t, []string{
+		"socket$inet_tcp -> bind$inet(#0 sock_tcp)",
+		"socket$inet_tcp -> bind$inet(#0 sock_tcp) -> listen(#0 sock_tcp) -> accept$inet(#0 sock_tcp)",
+		"socket$inet_tcp -> bind$inet(#0 sock_tcp) -> accept$inet(#0 sock_tcp)",
+		"socket$inet_tcp -> bind$inet(#0 sock_tcp) -> listen(#0 sock_tcp)",
+		"socket$inet_tcp -> listen(#0 sock_tcp) -> accept$inet(#0 sock_tcp)",
+		"socket$inet_tcp -> accept$inet(#0 sock_tcp)",
+		"socket$inet_tcp -> listen(#0 sock_tcp)",
+	}, res)
+	assert.Equal(t, 3, m.Sequences[0].Support)
+	assert.Equal(t, 1.0, m.Sequences[0].Prob)
+	assert.Equal(t, 2, m.Sequences[3].Support)
+	assert.InDelta(t, 2.0/3, m.Sequences[3].Prob, 1e-6)
+
+	m1, err := target.ParseSequenceModel(m.Serialize())
+	require.NoError(t, err)
+	assert.Equal(t, m.Serialize(), m1.Serialize())
+}
+
+func TestParseSequenceModel(t *testing.T) {
+	target, err := GetTarget("linux", "amd64")
+	require.NoError(t, err)
+	m, err := target.ParseSequenceModel([]byte(`{"sequences": [
+		{"calls": ["socket$inet_tcp", "listen"], "deps": [0], "resources": ["sock_tcp"]},
+		{"calls": ["socket$inet_tcp", "non_existent"], "deps": [0], "resources": ["sock_tcp"]},
+		{"calls": ["socket$inet_tcp", "listen"], "deps": [0], "resources": ["non_existent"]}
+	]}`))
+	require.NoError(t, err)
+	assert.Len(t, m.Sequences, 1)
+	for _, data := range []string{
+		`{"sequences": [{"calls": ["socket$inet_tcp", "listen"], "deps": [1], "resources": ["sock_tcp"]}]}`,
+		`{"sequences": [{"calls": ["socket$inet_tcp", "listen"], "deps": [], "resources": []}]}`,
+		`{"sequences": [{"calls": ["socket$inet_tcp"], "deps": [], "resources": []}]}`,
+		`{"sequences": {}}`,
+	} {
+		_, err := target.ParseSequenceModel([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestGenerateSequence(t *testing.T) {
+	target, rs, iters := initRandomTargetTest(t, "linux", "amd64")
+	opts := DefaultSequenceOpts
+	opts.MinSupport = 2
+	m := target.MineSequences(sequenceTestCorpus(t, target), opts)
+	require.NotEmpty(t, m.Sequences)
+	seq := m.Sequences[1]
+	require.Len(t, seq.Calls, 4)
+	enabled := make(map[*Syscall]bool)
+	for _, name := range []string{"socket$inet_tcp", "bind$inet", "listen", "accept$inet", "close"} {
+		enabled[target.SyscallMap[name]] = true
+	}
+	ct := target.BuildChoiceTable(nil, enabled)
+	ct.UseSequences(m)
+	require.Len(t, ct.seqs, len(m.Sequences))
+	r := newRand(target, rs)
+	for i := 0; i < iters; i++ {
+		p := &Prog{Target: target}
+		p.Calls = r.generateSequence(newState(target, ct, nil), p, 0, seq)
+		require.NoError(t, p.validate())
+		// The first call of the sequence does not need any resources, so it goes first.
+		// Other calls may be preceded by calls that create resources for them (e.g. accept$inet),
+		// but the sequence calls must use the socket.
+		require.Equal(t, "socket$inet_tcp", p.Calls[0].Meta.Name)
+		sock := p.Calls[0].Ret
+		last := p.Calls[len(p.Calls)-1]
+		require.Equal(t, "accept$inet", last.Meta.Name)
+		for _, c := range p.Calls {
+			if c.Meta.Name == "bind$inet" || c.Meta.Name == "listen" || c == last {
+				assert.Equal(t, sock, c.Args[0].(*ResultArg).Res, "%s", p.Serialize())
+			}
+		}
+		p1 := target.Generate(rs, 10, ct)
+		require.NoError(t, p1.validate())
+	}
+}
@@ -181,6 +181,53 @@ func (target *Target) assignSizesCall(c *Call) {
 	target.assignSizesArray(c.Args, c.Meta.Args, nil)
 }
 
+// CheckSizes checks that values of all len fields match sizes of the args they refer to.
+func (p *Prog) CheckSizes() error {
+	fixed := p.Clone()
+	for i, c := range fixed.Calls {
+		p.Target.assignSizesCall(c)
+		got, want := lenArgs(p.Calls[i]), lenArgs(c)
+		for j, arg := range got {
+			if arg.Val == want[j].Val {
+				continue
+			}
+			name := arg.Type().Name()
+			if field := arg.field; field != "" {
+				name = field
+			}
+			return fmt.Errorf("call #%v %v: len field %v has value %#x, expected %#x",
+				i, c.Meta.Name, name, arg.Val, want[j].Val)
+		}
+	}
+	return nil
+}
+
+type lenArg struct {
+	*ConstArg
+	field string
+}
+
+func lenArgs(c *Call) []lenArg {
+	var args []lenArg
+	ForeachArg(c, func(arg Arg, ctx *ArgCtx) {
+		if _, ok := arg.Type().(*LenType); !ok {
+			return
+		}
+		field := ""
+		if ctx.Field != nil {
+			field = ctx.Field.Name
+		} else if ctx.Parent != nil {
+			for i, sibling := range *ctx.Parent {
+				if sibling == arg && i < len(ctx.Fields) {
+					field = ctx.Fields[i].Name
+				}
+			}
+		}
+		args = append(args, lenArg{arg.(*ConstArg), field})
+	})
+	return args
+}
+
 func (r *randGen) mutateSize(arg *ConstArg, parent []Arg, fields []Field) bool {
 	typ := arg.Type().(*LenType)
 	elemSize := typ.BitSize / 8
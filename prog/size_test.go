@@ -185,3 +185,39 @@ func TestAssignSize(t *testing.T) {
 		},
 	})
 }
+
+func TestCheckSizes(t *testing.T) {
+	target := initTargetTest(t, "test", "64")
+	tests := []struct {
+		prog string
+		err  string
+	}{
+		{
+			prog: "test$length0(&(0x7f0000000000)={0xff, 0x2})",
+		},
+		{
+			prog: "test$length3(&(0x7f0000005000)={0xff, 0x4, 0x2})",
+		},
+		{
+			prog: "test$length0(&(0x7f0000000000)={0xff, 0x3})",
+			err:  "call #0 test$length0: len field f1 has value 0x3, expected 0x2",
+		},
+		{
+			prog: "test$length3(&(0x7f0000005000)={0xff, 0x4, 0x2})\n" +
+				"test$length3(&(0x7f0000005000)={0xff, 0x4, 0x0})",
+			err: "call #1 test$length3: len field f2 has value 0x0, expected 0x2",
+		},
+	}
+	for i, test := range tests {
+		p, err := target.Deserialize([]byte(test.prog), NonStrict)
+		if err != nil {
+			t.Fatalf("#%v: failed to deserialize: %v", i, err)
+		}
+		err = p.CheckSizes()
+		if test.err == "" && err != nil {
+			t.Errorf("#%v: unexpected error: %v", i, err)
+		} else if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("#%v: got error %v, want %q", i, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Template is a program skeleton with fuzzable holes.
+// Programs generated from a template contain the same calls with the same arguments,
+// except for the holes, which are generated and mutated by the fuzzer, and optional calls,
+// which may be dropped.
+//
+// Templates use the program text format with the following extensions:
+//
+//	?0x10                  - argument hole with the initial value 0x10
+//	&(0x7f0000000000)=?{}  - hole for the pointee, the pointer itself is fixed
+//	?                      - argument hole with the default initial value
+//	?close(r0)             - the call is optional
+type Template struct {
+	// Prog is the program with the initial values of the holes.
+	Prog     *Prog
+	holes    map[Arg]bool
+	optional map[*Call]bool
+}
+
+// ParseTemplate parses a program template, see Template for the syntax.
+func (target *Target) ParseTemplate(data []byte) (*Template, error) {
+	p, parser, err := target.deserialize(data, Strict, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Calls) == 0 {
+		return nil, fmt.Errorf("template has no calls")
+	}
+	if len(parser.holes) == 0 && len(parser.optional) == 0 {
+		return nil, fmt.Errorf("template has no holes and no optional calls")
+	}
+	t := &Template{
+		Prog:     p,
+		holes:    parser.holes,
+		optional: parser.optional,
+	}
+	if _, _, ok := t.match(p); !ok {
+		return nil, fmt.Errorf("template does not match itself")
+	}
+	return t, nil
+}
+
+// Holes returns the number of argument holes in the template.
+func (t *Template) Holes() int {
+	return len(t.holes)
+}
+
+// Match returns whether p could be produced from the template.
+func (t *Template) Match(p *Prog) bool {
+	_, _, ok := t.match(p)
+	return ok
+}
+
+// Generate generates a new program from the template: optional calls are dropped
+// with 50% probability and all holes get random values.
+func (t *Template) Generate(rs rand.Source, ct *ChoiceTable) *Prog {
+	r := newRand(t.Prog.Target, rs)
+	p := t.Prog.Clone()
+	for i := len(p.Calls) - 1; i >= 0; i-- {
+		if t.optional[t.Prog.Calls[i]] && len(p.Calls) > 1 && r.oneOf(2) {
+			p.RemoveCall(i)
+		}
+	}
+	holes, _, ok := t.match(p)
+	if !ok {
+		panic("program generated from template does not match it")
+	}
+	for _, hole := range holes {
+		s := analyze(ct, nil, p, hole.call)
+		newArg, calls := r.generateArg(s, hole.arg.Type(), hole.arg.Dir())
+		if len(calls) != 0 {
+			// The calls are not part of the template, so we keep the old value.
+			discardArg(newArg, calls)
+			continue
+		}
+		if ptr, ok := hole.arg.(*PointerArg); ok && ptr.Res != nil {
+			removeArg(ptr.Res)
+		}
+		replaceArg(hole.arg, newArg)
+	}
+	for _, c := range p.Calls {
+		p.Target.assignSizesCall(c)
+	}
+	t.patchConstraints(r, holes)
+	p.sanitizeFix()
+	p.debugValidate()
+	return p
+}
+
+// Mutate mutates program p produced from the template, only the holes are mutated
+// and only optional calls are removed. Returns false if p does not match the template
+// or no mutation was found.
+func (t *Template) Mutate(p *Prog, rs rand.Source, ct *ChoiceTable, corpus []*Prog) bool {
+	if !t.Match(p) {
+		return false
+	}
+	r := newRand(p.Target, rs)
+	const attempts = 10
+	for i := 0; i < attempts; i++ {
+		// Mutations may need to insert new calls, we can't undo them in place.
+		p1 := p.Clone()
+		if t.mutate(r, p1, ct, corpus) {
+			*p = *p1
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Template) mutate(r *randGen, p *Prog, ct *ChoiceTable, corpus []*Prog) bool {
+	holes, optional, _ := t.match(p)
+	if len(optional) != 0 && (len(holes) == 0 || r.oneOf(10)) {
+		if len(p.Calls) == 1 {
+			return false
+		}
+		p.RemoveCall(optional[r.Intn(len(optional))])
+		return true
+	}
+	if len(holes) == 0 {
+		return false
+	}
+	const maxIters = 100
+	updateSizes := true
+	for iter := 0; ; iter++ {
+		if iter == maxIters {
+			// The holes may have nothing mutable.
+			return false
+		}
+		hole := holes[r.Intn(len(holes))]
+		inside := make(map[Arg]bool)
+		ForeachSubArg(hole.arg, func(arg Arg, _ *ArgCtx) {
+			inside[arg] = true
+		})
+		ma := &mutationArgs{target: p.Target}
+		ForeachArg(hole.call, func(arg Arg, ctx *ArgCtx) {
+			if inside[arg] {
+				ma.collectArg(arg, ctx)
+			}
+		})
+		if len(ma.args) == 0 {
+			continue
+		}
+		s := analyze(ct, corpus, p, hole.call)
+		arg, argCtx := ma.chooseArg(r.Rand)
+		calls, ok1 := p.Target.mutateArg(r, s, arg, argCtx, &updateSizes)
+		if !ok1 {
+			continue
+		}
+		moreCalls, fieldsPatched := r.patchConditionalFields(hole.call, s)
+		if len(calls) != 0 || len(moreCalls) != 0 {
+			return false
+		}
+		if updateSizes || fieldsPatched {
+			p.Target.assignSizesCall(hole.call)
+		}
+		t.patchConstraints(r, []templateHole{hole})
+		if r.oneOf(DefaultMutateOpts.MutateArgCount) {
+			break
+		}
+	}
+	p.sanitizeFix()
+	p.debugValidate()
+	return true
+}
+
+// patchConstraints patches value constraints of fields inside of the holes,
+// fixed parts of the template are never changed.
+func (t *Template) patchConstraints(r *randGen, holes []templateHole) {
+	var calls []*Call
+	inside := make(map[*Call]map[Arg]bool)
+	for _, hole := range holes {
+		if inside[hole.call] == nil {
+			inside[hole.call] = make(map[Arg]bool)
+			calls = append(calls, hole.call)
+		}
+		ForeachSubArg(hole.arg, func(arg Arg, _ *ArgCtx) {
+			inside[hole.call][arg] = true
+		})
+	}
+	for _, c := range calls {
+		r.patchConstraintsFiltered(c, func(arg Arg) bool {
+			return inside[c][arg]
+		})
+	}
+}
+
+type templateHole struct {
+	call *Call
+	arg  Arg
+}
+
+// match matches p against the template and returns args of p that correspond to the template holes
+// and indexes of the optional calls.
+func (t *Template) match(p *Prog) ([]templateHole, []int, bool) {
+	var holes []templateHole
+	var optional []int
+	ti := 0
+	for i, c := range p.Calls {
+		for ; ti < len(t.Prog.Calls); ti++ {
+			tc := t.Prog.Calls[ti]
+			if callHoles, ok := t.matchCall(tc, c); ok {
+				holes = append(holes, callHoles...)
+				if t.optional[tc] {
+					optional = append(optional, i)
+				}
+				break
+			}
+			if !t.optional[tc] {
+				return nil, nil, false
+			}
+		}
+		if ti == len(t.Prog.Calls) {
+			return nil, nil, false
+		}
+		ti++
+	}
+	for ; ti < len(t.Prog.Calls); ti++ {
+		if !t.optional[t.Prog.Calls[ti]] {
+			return nil, nil, false
+		}
+	}
+	return holes, optional, true
+}
+
+func (t *Template) matchCall(tc, c *Call) ([]templateHole, bool) {
+	if tc.Meta != c.Meta {
+		return nil, false
+	}
+	var holes []Arg
+	for i := range tc.Args {
+		if !t.matchArg(tc.Args[i], c.Args[i], &holes) {
+			return nil, false
+		}
+	}
+	var res []templateHole
+	for _, arg := range holes {
+		res = append(res, templateHole{c, arg})
+	}
+	return res, true
+}
+
+// matchArg checks that arg has the same value as the template arg outside of the holes.
+func (t *Template) matchArg(targ, arg Arg, holes *[]Arg) bool {
+	if targ == nil || arg == nil {
+		return targ == nil && arg == nil
+	}
+	if targ.Type() != arg.Type() {
+		return false
+	}
+	if t.holes[targ] {
+		*holes = append(*holes, arg)
+		return true
+	}
+	switch a := targ.(type) {
+	case *ConstArg:
+		switch a.Type().(type) {
+		case *LenType, *CsumType:
+			// These depend on the contents of the holes.
+			return true
+		}
+		return a.Val == arg.(*ConstArg).Val
+	case *ResultArg:
+		// The referenced call may be optional, and then the reference is replaced with the default value.
+		return true
+	case *PointerArg:
+		a1 := arg.(*PointerArg)
+		// Addresses are not compared: the data may need to be reallocated if a hole in it has grown.
+		if a.IsSpecial() || a1.IsSpecial() {
+			return a.IsSpecial() == a1.IsSpecial() && a.Address == a1.Address
+		}
+		return a.VmaSize == a1.VmaSize && t.matchArg(a.Res, a1.Res, holes)
+	case *DataArg:
+		a1 := arg.(*DataArg)
+		if a.Dir() == DirOut {
+			return a.Size() == a1.Size()
+		}
+		return bytes.Equal(a.Data(), a1.Data())
+	case *GroupArg:
+		a1 := arg.(*GroupArg)
+		if len(a.Inner) != len(a1.Inner) {
+			return false
+		}
+		for i := range a.Inner {
+			if !t.matchArg(a.Inner[i], a1.Inner[i], holes) {
+				return false
+			}
+		}
+		return true
+	case *UnionArg:
+		a1 := arg.(*UnionArg)
+		return a.Index == a1.Index && t.matchArg(a.Option, a1.Option, holes)
+	default:
+		panic(fmt.Sprintf("unknown arg type %T", targ))
+	}
+}
+
+// discardArg unlinks a generated arg and the calls generated for it from the program.
+func discardArg(arg Arg, calls []*Call) {
+	removeArg(arg)
+	for _, c := range calls {
+		for _, arg := range c.Args {
+			removeArg(arg)
+		}
+		if c.Ret != nil {
+			removeArg(c.Ret)
+		}
+	}
+}
+
+func (p *parser) parseHole(typ Type, dir Dir) (Arg, error) {
+	p.Parse('?')
+	if typ == nil {
+		return nil, fmt.Errorf("hole for an excessive argument (line #%v)", p.l)
+	}
+	var arg Arg
+	if strings.IndexByte(",)}]", p.Char()) != -1 {
+		arg = typ.DefaultArg(dir)
+	} else {
+		var err error
+		if arg, err = p.parseArg(typ, dir); err != nil {
+			return nil, err
+		}
+	}
+	p.holes[arg] = true
+	return arg, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplate = `
+r0 = open(&(0x7f0000000000)='./file0\x00', 0x42, ?0x0)
+write(r0, &(0x7f0000000040)=?"0102", 0x2)
+?nanosleep(&(0x7f0000000080)=?, 0x0)
+close(r0)
+`
+
+func TestTemplate(t *testing.T) {
+	target, rs, iters := initTest(t)
+	ct := target.DefaultChoiceTable()
+	tmpl, err := target.ParseTemplate([]byte(testTemplate))
+	require.NoError(t, err)
+	assert.Equal(t, 3, tmpl.Holes())
+	assert.True(t, tmpl.Match(tmpl.Prog))
+
+	checkSkeleton := func(p *Prog) {
+		t.Helper()
+		if !tmpl.Match(p) {
+			t.Fatalf("program does not match the template:\n%s", p.Serialize())
+		}
+		data := string(p.Serialize())
+		assert.Contains(t, data, `open(&(0x7f0000000000)='./file0\x00', 0x42, `)
+		assert.Contains(t, data, "\nclose(r0)\n")
+	}
+	var dropped, mutatedData bool
+	for i := 0; i < iters; i++ {
+		p := tmpl.Generate(rs, ct)
+		checkSkeleton(p)
+		dropped = dropped || len(p.Calls) == 3
+		for j := 0; j < 10; j++ {
+			if !tmpl.Mutate(p, rs, ct, nil) {
+				continue
+			}
+			checkSkeleton(p)
+			mutatedData = mutatedData || !strings.Contains(string(p.Serialize()), `="0102"`)
+		}
+	}
+	assert.True(t, dropped, "optional call was never dropped")
+	assert.True(t, mutatedData, "write data was never mutated")
+
+	other, err := target.Deserialize([]byte("getpid()\n"), Strict)
+	require.NoError(t, err)
+	assert.False(t, tmpl.Match(other))
+	assert.False(t, tmpl.Mutate(other, rs, ct, nil))
+}
+
+func TestTemplateErrors(t *testing.T) {
+	target := initTargetTest(t, "linux", "amd64")
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{"getpid()\n", "template has no holes"},
+		{"getpid(?)\n", "excessive syscall arguments"},
+		{"", "template has no calls"},
+	}
+	for _, test := range tests {
+		_, err := target.ParseTemplate([]byte(test.data))
+		require.Error(t, err, test.data)
+		assert.Contains(t, err.Error(), test.err, test.data)
+	}
+	// Holes are not allowed in normal programs.
+	_, err := target.Deserialize([]byte("close(?0x1)\n"), Strict)
+	assert.Error(t, err)
+}
+
+func TestTemplateConstraints(t *testing.T) {
+	target, rs, _ := initRandomTargetTest(t, "test", "64")
+	ct := target.DefaultChoiceTable()
+	// lo must be equal to hi, but it's a fixed part of the template, so it must not be patched.
+	tmpl, err := target.ParseTemplate([]byte("constraints$forward(&(0x7f0000000000)={0x5, ?0x0})\n"))
+	require.NoError(t, err)
+	hi := func(p *Prog) uint64 {
+		return p.Calls[0].Args[0].(*PointerArg).Res.(*GroupArg).Inner[1].(*ConstArg).Val
+	}
+	const iters = 1000
+	generated, mutated := 0, 0
+	for i := 0; i < iters; i++ {
+		p := tmpl.Generate(rs, ct)
+		require.True(t, tmpl.Match(p))
+		if v := hi(p); v >= 100 && v <= 200 {
+			generated++
+		}
+		if !tmpl.Mutate(p, rs, ct, nil) {
+			continue
+		}
+		require.True(t, tmpl.Match(p))
+		if v := hi(p); v >= 100 && v <= 200 {
+			mutated++
+		}
+	}
+	// Constraints must be satisfied most of the time, but not always.
+	assert.Greater(t, generated, iters/2)
+	assert.Less(t, generated, iters)
+	assert.Greater(t, mutated, iters/2)
+}
@@ -84,6 +84,8 @@ type Field struct {
 	HasDirection bool
 	Direction    Dir
 	Condition    Expression
+	// Constraints on the field value relative to other fields (eq/le/lt/ge/gt attributes).
+	Constraints []Constraint
 
 	// See Target.initRelatedFields.
 	relatedFields map[Type]struct{}
@@ -115,6 +117,8 @@ const (
 	OperatorCompareNeq
 	OperatorBinaryAnd
 	OperatorOr
+	OperatorAdd
+	OperatorSub
 )
 
 type BinaryExpression struct {
@@ -140,6 +144,23 @@ func (bo BinaryExpression) Clone() Expression {
 	}
 }
 
+type ConstraintOp int
+
+const (
+	ConstraintEq ConstraintOp = iota
+	ConstraintLe
+	ConstraintLt
+	ConstraintGe
+	ConstraintGt
+)
+
+// Constraint requires the field value to relate to the value of the expression as Op says,
+// e.g. {ConstraintLe, value[size] - value[offset]} means field <= size - offset.
+type Constraint struct {
+	Op    ConstraintOp
+	Value Expression
+}
+
 type Value struct {
 	// If Path is empty, Value is to be used.
 	Value uint64
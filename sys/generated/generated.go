@@ -9,6 +9,7 @@ import (
 	"embed"
 	"encoding/gob"
 	"fmt"
+	"io"
 	"path/filepath"
 
 	"github.com/google/syzkaller/prog"
@@ -102,4 +103,37 @@ func init() {
 	gob.Register(&prog.UnionType{})
 	gob.Register(&prog.BinaryExpression{})
 	gob.Register(&prog.Value{})
+
+	// Gob assigns ids to types when they are first encoded in the process, and types stored
+	// in interfaces are encoded in the order they are encountered in the data. Encode all types
+	// in a fixed order, so that the output does not depend on what was serialized before.
+	types := &Desc{
+		Types: []prog.Type{
+			prog.Ref(0),
+			&prog.ResourceType{},
+			&prog.ConstType{},
+			&prog.IntType{},
+			&prog.FlagsType{},
+			&prog.LenType{},
+			&prog.ProcType{},
+			&prog.CsumType{},
+			&prog.VmaType{},
+			&prog.BufferType{},
+			&prog.ArrayType{},
+			&prog.PtrType{},
+			&prog.StructType{
+				Fields: []prog.Field{{
+					Type: prog.Ref(0),
+					Condition: &prog.BinaryExpression{
+						Left:  &prog.Value{},
+						Right: &prog.Value{},
+					},
+				}},
+			},
+			&prog.UnionType{},
+		},
+	}
+	if err := gob.NewEncoder(io.Discard).Encode(types); err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,133 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/pkg/hash"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+// Cache allows to skip work for descriptions that did not change since the previous run.
+// Targets whose inputs did not change are not compiled at all and their outputs are reused.
+// Other targets are compiled with compiler.Cache that skips checks of files not affected by changes.
+type Cache struct {
+	dir      string
+	compiler *compiler.Cache
+	targets  targetsCache
+}
+
+type targetsCache struct {
+	Version string
+	Targets map[string]*CachedTarget
+}
+
+type CachedTarget struct {
+	// Hash of all inputs of the target.
+	Inputs      string
+	ArchData    ArchData
+	Unsupported []string
+}
+
+const (
+	compilerCacheFile = "compiler.json"
+	targetsCacheFile  = "targets.json"
+)
+
+// loadCache loads cache from the dir. If dir is empty, the cache is not persisted.
+func loadCache(dir string) *Cache {
+	version := binaryHash()
+	cache := &Cache{
+		dir: dir,
+		targets: targetsCache{
+			Version: version,
+			Targets: make(map[string]*CachedTarget),
+		},
+	}
+	if dir == "" || version == "" {
+		cache.dir = ""
+		cache.compiler = compiler.NewCache(version)
+		return cache
+	}
+	cache.compiler = compiler.LoadCache(filepath.Join(dir, compilerCacheFile), version)
+	var cached targetsCache
+	data, err := os.ReadFile(filepath.Join(dir, targetsCacheFile))
+	if err == nil && json.Unmarshal(data, &cached) == nil && cached.Version == version &&
+		cached.Targets != nil {
+		cache.targets = cached
+	}
+	return cache
+}
+
+func (cache *Cache) save() error {
+	if cache.dir == "" {
+		return nil
+	}
+	if err := cache.compiler.Save(filepath.Join(cache.dir, compilerCacheFile)); err != nil {
+		return err
+	}
+	return osutil.WriteJSON(filepath.Join(cache.dir, targetsCacheFile), cache.targets)
+}
+
+// lookup returns cached results for the target if its inputs did not change
+// and the previously generated file is still in place.
+func (cache *Cache) lookup(target *targets.Target, inputs, sysFile string) *CachedTarget {
+	cached := cache.targets.Targets[targetKey(target)]
+	if cached == nil || cached.Inputs != inputs {
+		return nil
+	}
+	data, err := os.ReadFile(sysFile)
+	if err != nil || hash.String(data) != cached.ArchData.Revision {
+		return nil
+	}
+	return cached
+}
+
+func (cache *Cache) update(target *targets.Target, cached *CachedTarget) {
+	cache.targets.Targets[targetKey(target)] = cached
+}
+
+func targetKey(target *targets.Target) string {
+	return target.OS + "/" + target.Arch
+}
+
+// inputsHash returns hash of all inputs of the OS targets: descriptions and consts.
+func (cache *Cache) inputsHash(glob string, constFile *compiler.ConstFile) (string, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(cache.targets.Version)
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(buf, "\n%v %v\n", file, len(data))
+		buf.Write(data)
+	}
+	buf.Write(constFile.Serialize())
+	return hash.String(buf.Bytes()), nil
+}
+
+// binaryHash returns hash of the current binary, results of other versions of the compiler can't be reused.
+func binaryHash() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		return ""
+	}
+	return hash.String(data)
+}
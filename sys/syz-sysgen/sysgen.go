@@ -8,9 +8,11 @@ import (
 	"flag"
 	"fmt"
 	"go/format"
+	"maps"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -69,19 +71,11 @@ type TemplateData struct {
 
 var srcDir = flag.String("src", "", "path to root of syzkaller source dir")
 var outDir = flag.String("out", "", "path to out dir")
+var useCache = flag.Bool("cache", true, "reuse results of the previous run for descriptions that did not change")
 
 func main() {
 	defer tool.Init()()
 
-	// Cleanup old files in the case set of architectures has chnaged.
-	allFiles, err := filepath.Glob(filepath.Join(*outDir, "sys", generated.Glob()))
-	if err != nil {
-		tool.Failf("failed to glob: %v", err)
-	}
-	for _, file := range allFiles {
-		os.Remove(file)
-	}
-
 	// Also remove old generated files since they will break build.
 	// TODO: remove this after some time after 2025-01-23.
 	oldFiles, err := filepath.Glob(filepath.Join(*outDir, "sys", "*", "gen", "*"))
@@ -98,11 +92,18 @@ func main() {
 	}
 	sort.Strings(OSList)
 
+	cacheDir := ""
+	if *useCache {
+		cacheDir = filepath.Join(*outDir, "sys", "gen", "cache")
+	}
+	cache := loadCache(cacheDir)
+	generatedFiles := make(map[string]bool)
 	data := &TemplateData{
 		Notice: "Automatically generated by syz-sysgen; DO NOT EDIT.",
 	}
 	for _, OS := range OSList {
-		descriptions := ast.ParseGlob(filepath.Join(*srcDir, "sys", OS, "*.txt"), nil)
+		descGlob := filepath.Join(*srcDir, "sys", OS, "*.txt")
+		descriptions := cache.compiler.ParseGlob(descGlob, nil)
 		if descriptions == nil {
 			os.Exit(1)
 		}
@@ -110,6 +111,10 @@ func main() {
 		if constFile == nil {
 			os.Exit(1)
 		}
+		inputs, err := cache.inputsHash(descGlob, constFile)
+		if err != nil {
+			tool.Fail(err)
+		}
 
 		var archs []string
 		for arch := range targets.List[OS] {
@@ -120,28 +125,41 @@ func main() {
 		var jobs []*Job
 		for _, arch := range archs {
 			target := targets.List[OS][arch]
-			constInfo := compiler.ExtractConsts(descriptions, target, nil)
+			job := &Job{
+				Target:      target,
+				Unsupported: make(map[string]bool),
+				SysFile:     filepath.Join(*outDir, "sys", generated.FileName(target.OS, target.Arch)),
+			}
+			jobs = append(jobs, job)
+			generatedFiles[job.SysFile] = true
+			if cached := cache.lookup(target, inputs, job.SysFile); cached != nil {
+				job.Cached = true
+				job.OK = true
+				job.ArchData = cached.ArchData
+				for _, what := range cached.Unsupported {
+					job.Unsupported[what] = true
+				}
+				continue
+			}
+			job.ConstInfo = compiler.ExtractConsts(descriptions, target, nil)
 			if OS == targets.TestOS {
 				// The ConstFile object provides no guarantees re concurrent read-write,
 				// so let's patch it before we start goroutines.
-				compiler.FabricateSyscallConsts(target, constInfo, constFile)
+				compiler.FabricateSyscallConsts(target, job.ConstInfo, constFile)
 			}
-			jobs = append(jobs, &Job{
-				Target:      target,
-				Unsupported: make(map[string]bool),
-				ConstInfo:   constInfo,
-			})
 		}
 		sort.Slice(jobs, func(i, j int) bool {
 			return jobs[i].Target.Arch < jobs[j].Target.Arch
 		})
 		var wg sync.WaitGroup
-		wg.Add(len(jobs))
-
 		for _, job := range jobs {
+			if job.Cached {
+				continue
+			}
+			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				processJob(job, descriptions, constFile)
+				processJob(job, descriptions, constFile, cache.compiler)
 			}()
 		}
 		wg.Wait()
@@ -160,6 +178,13 @@ func main() {
 			for u := range job.Unsupported {
 				unsupported[u]++
 			}
+			if !job.Cached {
+				cache.update(job.Target, &CachedTarget{
+					Inputs:      inputs,
+					ArchData:    job.ArchData,
+					Unsupported: slices.Sorted(maps.Keys(job.Unsupported)),
+				})
+			}
 		}
 		data.OSes = append(data.OSes, OSData{
 			GOOS:  OS,
@@ -193,10 +218,26 @@ func main() {
 	writeTemplate(filepath.Join(*outDir, "sys", "register.go"), registerTempl, data)
 	writeTemplate(filepath.Join(*outDir, "executor", "defs.h"), defsTempl, data)
 	writeTemplate(filepath.Join(*outDir, "executor", "syscalls.h"), syscallsTempl, data)
+
+	// Cleanup old files in the case set of architectures has changed.
+	allFiles, err := filepath.Glob(filepath.Join(*outDir, "sys", generated.Glob()))
+	if err != nil {
+		tool.Failf("failed to glob: %v", err)
+	}
+	for _, file := range allFiles {
+		if !generatedFiles[file] {
+			os.Remove(file)
+		}
+	}
+	if err := cache.save(); err != nil {
+		tool.Failf("failed to save cache: %v", err)
+	}
 }
 
 type Job struct {
 	Target      *targets.Target
+	SysFile     string
+	Cached      bool
 	OK          bool
 	Errors      []string
 	Unsupported map[string]bool
@@ -205,7 +246,7 @@ type Job struct {
 	Revision    string
 }
 
-func processJob(job *Job, descriptions *ast.Description, constFile *compiler.ConstFile) {
+func processJob(job *Job, descriptions *ast.Description, constFile *compiler.ConstFile, cache *compiler.Cache) {
 	var flags []prog.FlagDesc
 	for _, decl := range descriptions.Nodes {
 		switch n := decl.(type) {
@@ -231,7 +272,7 @@ func processJob(job *Job, descriptions *ast.Description, constFile *compiler.Con
 		return constArr[i].Name < constArr[j].Name
 	})
 
-	prg := compiler.Compile(descriptions, consts, job.Target, eh)
+	prg := cache.Compile(descriptions, consts, job.Target, eh)
 	if prg == nil {
 		return
 	}
@@ -250,8 +291,7 @@ func processJob(job *Job, descriptions *ast.Description, constFile *compiler.Con
 	if err != nil {
 		tool.Fail(err)
 	}
-	sysFile := filepath.Join(*outDir, "sys", generated.FileName(job.Target.OS, job.Target.Arch))
-	writeFile(sysFile, data)
+	writeFile(job.SysFile, data)
 
 	job.ArchData = generateExecutorSyscalls(job.Target, prg.Syscalls, hash.String(data))
 
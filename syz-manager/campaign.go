@@ -0,0 +1,65 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/pkg/manager"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/vm"
+	"github.com/google/syzkaller/vm/dispatcher"
+)
+
+// The exit status of the campaign mode if new bugs were found.
+const campaignNewBugsStatus = 3
+
+func campaignConfig() manager.CampaignConfig {
+	return manager.CampaignConfig{
+		Duration:     *flagDuration,
+		Execs:        *flagExecs,
+		ReproTimeout: *flagReproTimeout,
+	}
+}
+
+func (mgr *Manager) campaignLoop() {
+	var reason string
+	ticker := time.NewTicker(10 * time.Second)
+	for range ticker.C {
+		reason = mgr.campaign.Finished(time.Now(), mgr.servStats.StatExecs.Val())
+		if reason != "" {
+			break
+		}
+	}
+	ticker.Stop()
+	log.Logf(0, "campaign: %v, stopping fuzzing", reason)
+
+	// Keep the fuzzing VMs idle, but let the instances reserved for reproduction do their job.
+	mgr.pool.SetDefault(func(ctx context.Context, _ *vm.Instance, _ dispatcher.UpdateInfo) {
+		<-ctx.Done()
+	})
+	pending := mgr.campaign.WaitRepros(vm.ShutdownCtx(), mgr.reproLoop)
+	if len(pending) != 0 {
+		log.Logf(0, "campaign: %v reproductions did not finish in %v", len(pending), *flagReproTimeout)
+	}
+
+	mgr.mu.Lock()
+	corpus := mgr.corpus
+	mgr.mu.Unlock()
+	summary := mgr.campaign.Summary(time.Now(), reason, mgr.servStats.StatExecs.Val(),
+		corpus.StatCover.Val(), corpus.StatSignal.Val(), corpus.StatProgs.Val(), pending)
+	log.Logf(0, "%s", summary)
+	path := filepath.Join(mgr.cfg.Workdir, "campaign.json")
+	if err := osutil.WriteJSON(path, summary); err != nil {
+		log.Fatal(err)
+	}
+	status := 0
+	if summary.FoundNewBugs() {
+		status = campaignNewBugsStatus
+	}
+	mgr.exitStatus("campaign", status)
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/google/syzkaller/pkg/fuzzer"
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/pkg/manager"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/vm"
+)
+
+// For how long we fuzz a subset of syscalls once the coverage has plateaued.
+const rotationPeriod = time.Hour
+
+func (mgr *Manager) startHealthMonitor(fuzzerObj *fuzzer.Fuzzer, enabledSyscalls map[*prog.Syscall]bool) {
+	health := manager.NewHealthMonitor(manager.HealthInputs{
+		Signal:     mgr.corpus.StatSignal,
+		Execs:      mgr.servStats.StatExecs,
+		VMRestarts: mgr.servStats.StatVMRestarts,
+		BootErrors: mgr.statBootErrors,
+		VMs:        mgr.vmPool.Count(),
+	}, manager.DefaultHealthThresholds())
+	for _, remedy := range mgr.cfg.HealthRemedies {
+		switch remedy {
+		case "rotate":
+			rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+			rotator := prog.MakeRotator(mgr.target, enabledSyscalls, rnd)
+			health.AddRemedy(manager.HealthCoveragePlateau, "syscall rotation", func() {
+				calls := rotator.Select()
+				log.Logf(0, "fuzzing %v out of %v enabled syscalls for %v",
+					len(calls), len(enabledSyscalls), rotationPeriod)
+				fuzzerObj.RotateCalls(calls)
+				time.AfterFunc(rotationPeriod, func() {
+					fuzzerObj.RotateCalls(nil)
+				})
+			})
+		case "reseed":
+			if mgr.cfg.HubClient == "" {
+				log.Logf(0, "ignoring the reseed health remedy: syz-hub is not configured")
+				continue
+			}
+			health.AddRemedy(manager.HealthCoveragePlateau, "hub reseed", func() {
+				select {
+				case mgr.hubReseed <- struct{}{}:
+				default:
+				}
+			})
+		case "recycle":
+			health.AddRemedy(manager.HealthExecRateDrop, "VM recycle", mgr.pool.Restart)
+		}
+	}
+	mgr.http.Health.Store(health)
+	go health.Loop(vm.ShutdownCtx())
+}
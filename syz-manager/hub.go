@@ -49,6 +49,7 @@ func (mgr *Manager) hubSyncLoop(keyGet keyGetter, enabledSyscalls map[*prog.Sysc
 		leak:          mgr.enabledFeatures&flatrpc.FeatureLeak != 0,
 		fresh:         mgr.fresh,
 		hubReproQueue: mgr.externalReproQueue,
+		reseed:        mgr.hubReseed,
 		keyGet:        keyGet,
 
 		statRecvProg:      stat.New("hub recv prog", "", stat.Graph("hub progs")),
@@ -75,6 +76,7 @@ type HubConnector struct {
 	newRepros      [][]byte
 	hubReproQueue  chan *manager.Crash
 	needMoreRepros func() bool
+	reseed         <-chan struct{}
 	keyGet         keyGetter
 
 	statRecvProg      *stat.Val
@@ -97,7 +99,7 @@ func (hc *HubConnector) loop() {
 	var hub *rpctype.RPCClient
 	var doneOnce bool
 	var connectTime time.Time
-	for query := 0; ; time.Sleep(10 * time.Minute) {
+	for query := 0; ; hub = hc.wait(hub) {
 		if hub == nil {
 			var corpus []*corpus.Item
 			// If we are using fake coverage, don't send our corpus to the hub.
@@ -146,6 +148,22 @@ func (hc *HubConnector) loop() {
 	}
 }
 
+// wait sleeps until the next sync. On a reseed request it drops the hub connection,
+// so that we reconnect as a fresh manager and receive the whole hub corpus again.
+func (hc *HubConnector) wait(hub *rpctype.RPCClient) *rpctype.RPCClient {
+	select {
+	case <-time.After(10 * time.Minute):
+		return hub
+	case <-hc.reseed:
+	}
+	log.Logf(0, "reseeding from hub")
+	if hub != nil {
+		hub.Close()
+	}
+	hc.fresh = true
+	return nil
+}
+
 func (hc *HubConnector) connect(corpus []*corpus.Item) (*rpctype.RPCClient, error) {
 	key, err := hc.keyGet()
 	if err != nil {
@@ -55,6 +55,11 @@ var (
 	flagBench  = flag.String("bench", "", "write execution statistics into this file periodically")
 	flagMode   = flag.String("mode", ModeFuzzing.Name, modesDescription())
 	flagTests  = flag.String("tests", "", "prefix to match test file names (for -mode run-tests)")
+
+	flagDuration     = flag.Duration("duration", 0, "stop fuzzing after this time (for -mode campaign)")
+	flagExecs        = flag.Int("execs", 0, "stop fuzzing after this many executions (for -mode campaign)")
+	flagReproTimeout = flag.Duration("repro-timeout", time.Hour,
+		"max time to wait for the pending reproductions (for -mode campaign)")
 )
 
 type Manager struct {
@@ -64,6 +69,8 @@ type Manager struct {
 	pool            *vm.Dispatcher
 	target          *prog.Target
 	sysTarget       *targets.Target
+	templates       []*prog.Template
+	sequences       *prog.SequenceModel
 	reporter        *report.Reporter
 	crashStore      *manager.CrashStore
 	serv            rpcserver.Server
@@ -100,6 +107,7 @@ type Manager struct {
 
 	externalReproQueue chan *manager.Crash
 	crashes            chan *manager.Crash
+	hubReseed          chan struct{}
 
 	benchMu   sync.Mutex
 	benchFile *os.File
@@ -108,6 +116,7 @@ type Manager struct {
 	fsckChecker  image.FsckChecker
 
 	reproLoop *manager.ReproLoop
+	campaign  *manager.Campaign
 
 	Stats
 }
@@ -146,6 +155,17 @@ var (
 	This is useful mostly for benchmarking with testbed.`,
 		LoadCorpus: true,
 	}
+	ModeCampaign = &Mode{
+		Name: "campaign",
+		Description: `fuzz for the time/executions budget given by -duration/-execs and exit
+	After the budget is exhausted, manager waits for the pending reproductions (up to -repro-timeout),
+	writes the summary to workdir/campaign.json and exits. The exit status is 0 if no new bugs were found
+	and 3 otherwise.`,
+		LoadCorpus: true,
+		CheckConfig: func(cfg *mgrconfig.Config) error {
+			return campaignConfig().Validate()
+		},
+	}
 	ModeCorpusRun = &Mode{
 		Name:        "corpus-run",
 		Description: `continuously run the corpus programs`,
@@ -178,6 +198,7 @@ var (
 		ModeFuzzing,
 		ModeSmokeTest,
 		ModeCorpusTriage,
+		ModeCampaign,
 		ModeCorpusRun,
 		ModeRunTests,
 		ModeIfaceProbe,
@@ -278,12 +299,37 @@ func RunManager(mode *Mode, cfg *mgrconfig.Config) {
 		fresh:              true,
 		externalReproQueue: make(chan *manager.Crash, 10),
 		crashes:            make(chan *manager.Crash, 10),
+		hubReseed:          make(chan struct{}, 1),
 		saturatedCalls:     make(map[string]bool),
 		reportGenerator:    manager.ReportGeneratorCache(cfg),
 	}
 	if *flagDebug {
 		mgr.cfg.Procs = 1
 	}
+	mgr.templates, err = manager.LoadTemplates(cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if len(mgr.templates) != 0 {
+		log.Logf(0, "loaded %v program templates, fuzzing only within the template holes", len(mgr.templates))
+	}
+	if cfg.SequenceModel != "" {
+		data, err := os.ReadFile(cfg.SequenceModel)
+		if err != nil {
+			log.Fatalf("failed to read sequence model: %v", err)
+		}
+		mgr.sequences, err = cfg.Target.ParseSequenceModel(data)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		log.Logf(0, "loaded %v call sequences", len(mgr.sequences.Sequences))
+	}
+	if mode == ModeCampaign {
+		mgr.campaign, err = manager.NewCampaign(campaignConfig(), mgr.crashStore)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
 	mgr.http = &manager.HTTPServer{
 		// Note that if cfg.HTTP == "", we don't start the server.
 		Cfg:        cfg,
@@ -375,17 +421,22 @@ func RunManager(mode *Mode, cfg *mgrconfig.Config) {
 		}()
 	}
 	go mgr.trackUsedFiles()
+	go mgr.crashRetentionLoop()
 	go mgr.processFuzzingResults(ctx)
 	mgr.pool.Loop(ctx)
 }
 
 // Exit successfully in special operation modes.
 func (mgr *Manager) exit(reason string) {
+	mgr.exitStatus(reason, 0)
+}
+
+func (mgr *Manager) exitStatus(reason string, status int) {
 	log.Logf(0, "%v finished, shutting down...", reason)
 	mgr.writeBench()
 	close(vm.Shutdown)
 	time.Sleep(10 * time.Second)
-	os.Exit(0)
+	os.Exit(status)
 }
 
 func (mgr *Manager) heartbeatLoop() {
@@ -448,6 +499,7 @@ func (mgr *Manager) processFuzzingResults(ctx context.Context) {
 				mgr.reproLoop.Enqueue(crash)
 			}
 		case err := <-mgr.pool.BootErrors:
+			mgr.statBootErrors.Add(1)
 			crash := mgr.convertBootError(err)
 			if crash != nil {
 				mgr.saveCrash(crash)
@@ -510,6 +562,9 @@ func (mgr *Manager) RunRepro(ctx context.Context, crash *manager.Crash) *manager
 		Features: mgr.enabledFeatures,
 		Reporter: mgr.reporter,
 		Pool:     mgr.pool,
+		Parallel: mgr.reproLoop.ParallelVMs(),
+
+		ReliabilityRuns: mgr.cfg.ReproReliabilityRuns,
 	})
 	ret := &manager.ReproResult{
 		Crash: crash,
@@ -542,6 +597,9 @@ func (mgr *Manager) processRepro(res *manager.ReproResult) {
 	if res.Err != nil {
 		reportReproError(res.Err)
 	}
+	if mgr.campaign != nil {
+		mgr.campaign.SaveRepro(res)
+	}
 	if res.Repro == nil {
 		if res.Crash.Title == "" {
 			log.Logf(1, "repro '%v' not from dashboard, so not reporting the failure",
@@ -740,6 +798,9 @@ func (mgr *Manager) saveCrash(crash *manager.Crash) bool {
 		mgr.statCrashTypes.Add(1)
 	}
 	mgr.mu.Unlock()
+	if mgr.campaign != nil {
+		mgr.campaign.SaveCrash(crash)
+	}
 
 	if mgr.dash != nil {
 		if crash.Type == crash_pkg.MemoryLeak {
@@ -878,7 +939,10 @@ func (mgr *Manager) saveRepro(res *manager.ReproResult) {
 		}
 	}
 
-	if mgr.dash != nil {
+	unreliable := mgr.dash != nil && mgr.unreliableRepro(repro)
+	if unreliable && mgr.cfg.DashboardSkipUnreliableRepros {
+		log.Logf(0, "not reporting unreliable repro for %q to dashboard", repro.Report.Title)
+	} else if mgr.dash != nil {
 		// Note: we intentionally don't set Corrupted for reproducers:
 		// 1. This is reproducible so can be debugged even with corrupted report.
 		// 2. Repro re-tried 3 times and still got corrupted report at the end,
@@ -896,6 +960,9 @@ func (mgr *Manager) saveRepro(res *manager.ReproResult) {
 			output = res.Strace.Output
 			crashFlags = dashapi.CrashUnderStrace
 		}
+		if unreliable {
+			crashFlags |= dashapi.CrashUnreliableRepro
+		}
 
 		dc := &dashapi.Crash{
 			BuildID:       mgr.cfg.Tag,
@@ -928,6 +995,20 @@ func (mgr *Manager) saveRepro(res *manager.ReproResult) {
 	}
 }
 
+// unreliableRepro returns whether the reproducer that would be reported (C if possible)
+// crashes the kernel less often than the configured threshold.
+func (mgr *Manager) unreliableRepro(res *repro.Result) bool {
+	reliability := res.SyzReliability
+	if res.CRepro && res.CReliability != nil {
+		reliability = res.CReliability
+	}
+	if reliability == nil || reliability.Rate() >= mgr.cfg.DashboardReproReliability {
+		return false
+	}
+	log.Logf(0, "repro for %q is unreliable: %v", res.Report.Title, reliability)
+	return true
+}
+
 func (mgr *Manager) ResizeReproPool(size int) {
 	mgr.pool.ReserveForRun(size)
 }
@@ -1132,7 +1213,7 @@ func (mgr *Manager) MachineChecked(features flatrpc.Feature,
 	opts := fuzzer.DefaultExecOpts(mgr.cfg, features, *flagDebug)
 
 	switch mgr.mode {
-	case ModeFuzzing, ModeCorpusTriage:
+	case ModeFuzzing, ModeCorpusTriage, ModeCampaign:
 		corpusUpdates := make(chan corpus.NewItemEvent, 128)
 		mgr.corpus = corpus.NewFocusedCorpus(context.Background(),
 			corpusUpdates, mgr.coverFilters.Areas)
@@ -1149,6 +1230,8 @@ func (mgr *Manager) MachineChecked(features flatrpc.Feature,
 			EnabledCalls:   enabledSyscalls,
 			NoMutateCalls:  mgr.cfg.NoMutateCalls,
 			FetchRawCover:  mgr.cfg.RawCover,
+			Templates:      mgr.templates,
+			Sequences:      mgr.sequences,
 			Logf: func(level int, msg string, args ...interface{}) {
 				if level != 0 {
 					return
@@ -1168,6 +1251,13 @@ func (mgr *Manager) MachineChecked(features flatrpc.Feature,
 		go mgr.corpusInputHandler(corpusUpdates)
 		go mgr.corpusMinimization()
 		go mgr.fuzzerLoop(fuzzerObj)
+		if mgr.vmPool != nil {
+			mgr.startHealthMonitor(fuzzerObj, enabledSyscalls)
+		}
+		if mgr.campaign != nil {
+			mgr.campaign.Start(time.Now(), mgr.servStats.StatExecs.Val())
+			go mgr.campaignLoop()
+		}
 		if mgr.dash != nil {
 			go mgr.dashboardReporter()
 			if mgr.cfg.Reproduce {
@@ -1297,6 +1387,9 @@ func (mgr *Manager) fuzzerLoop(fuzzer *fuzzer.Fuzzer) {
 				if !mgr.cfg.Snapshot {
 					mgr.serv.TriagedCorpus()
 				}
+				if mgr.campaign != nil {
+					mgr.campaign.CorpusTriaged(mgr.corpus.StatProgs.Val())
+				}
 				if mgr.cfg.HubClient != "" {
 					mgr.setPhaseLocked(phaseTriagedCorpus)
 					go mgr.hubSyncLoop(pickGetter(mgr.cfg.HubKey),
@@ -1341,6 +1434,25 @@ func (mgr *Manager) hubIsUnreachable() {
 	}
 }
 
+// crashRetentionLoop periodically deletes old crashes according to the configured retention policy.
+func (mgr *Manager) crashRetentionLoop() {
+	policy := mgr.crashStore.Retention
+	if policy == (manager.CrashRetention{}) {
+		return
+	}
+	for ; ; time.Sleep(time.Hour) {
+		stats, err := mgr.crashStore.ApplyRetention(policy, time.Now())
+		if err != nil {
+			log.Errorf("failed to apply crash retention: %v", err)
+			continue
+		}
+		if stats.Crashes != 0 {
+			log.Logf(0, "crash retention: deleted %v crash logs (%v MB), %v crashes",
+				stats.Crashes, stats.Bytes>>20, stats.Bugs)
+		}
+	}
+}
+
 // trackUsedFiles() is checking that the files that syz-manager needs are not changed while it's running.
 func (mgr *Manager) trackUsedFiles() {
 	usedFiles := make(map[string]time.Time) // file name to modification time
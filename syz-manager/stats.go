@@ -20,6 +20,7 @@ type Stats struct {
 	statFuzzingTime   *stat.Val
 	statAvgBootTime   *stat.Val
 	statCoverFiltered *stat.Val
+	statBootErrors    *stat.Val
 }
 
 func (mgr *Manager) initStats() {
@@ -77,4 +78,5 @@ func (mgr *Manager) initStats() {
 			return int(image.StatImages.Load())
 		})
 	mgr.statCoverFiltered = stat.New("filtered coverage", "", stat.NoGraph)
+	mgr.statBootErrors = stat.New("boot errors", "Total number of VM boot errors", stat.Graph("crashes"))
 }
@@ -0,0 +1,104 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-btfextract generates syzlang struct, union and flags descriptions from kernel BTF.
+// Unlike syz-declextract it does not need a clang tool run over the kernel build,
+// it only needs a kernel built with CONFIG_DEBUG_INFO_BTF (vmlinux with .BTF section),
+// or /sys/kernel/btf/vmlinux of a running kernel. The output is in the same format as sys/linux/auto.txt.
+// Since BTF does not contain syscalls, macros and explicit alignment attributes,
+// only types and enum values are generated.
+//
+// The tool also compares the existing descriptions with the kernel types and prints
+// the inconsistencies (struct size, field offset/size mismatches, missing flag values) to stderr.
+// Usage:
+//
+//	syz-btfextract [-btf /sys/kernel/btf/vmlinux] [-types 'regexp'] [-arch amd64] > btf.txt
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/btf"
+	"github.com/google/syzkaller/pkg/declextract"
+	"github.com/google/syzkaller/pkg/ifaceprobe"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/pkg/tool"
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+func main() {
+	var (
+		flagBTF   = flag.String("btf", "/sys/kernel/btf/vmlinux", "vmlinux or raw BTF file")
+		flagBase  = flag.String("base", "", "base BTF file if -btf is split BTF (e.g. /sys/kernel/btf/MODULE)")
+		flagTypes = flag.String("types", "", "regexp for names of structs/unions/enums to extract (all if empty)")
+		flagArch  = flag.String("arch", targets.AMD64, "kernel arch")
+		flagOut   = flag.String("out", "", "output file (stdout if empty)")
+		flagCheck = flag.Bool("check", true, "check existing descriptions against the kernel types")
+	)
+	defer tool.Init()()
+	target, err := prog.GetTarget(targets.Linux, *flagArch)
+	if err != nil {
+		tool.Fail(err)
+	}
+	re, err := regexp.Compile(*flagTypes)
+	if err != nil {
+		tool.Failf("bad -types: %v", err)
+	}
+	spec, err := loadBTF(*flagBTF, *flagBase)
+	if err != nil {
+		tool.Fail(err)
+	}
+	res, warnings, err := run(target, spec, re.MatchString, *flagCheck)
+	if err != nil {
+		tool.Fail(err)
+	}
+	for _, warn := range warnings {
+		fmt.Fprintln(os.Stderr, warn)
+	}
+	if *flagOut == "" {
+		os.Stdout.Write(res.Descriptions)
+		return
+	}
+	if err := osutil.WriteFile(*flagOut, res.Descriptions); err != nil {
+		tool.Fail(err)
+	}
+}
+
+func loadBTF(file, baseFile string) (*btf.Spec, error) {
+	if baseFile == "" {
+		return btf.Load(file)
+	}
+	base, err := btf.Load(baseFile)
+	if err != nil {
+		return nil, err
+	}
+	return btf.LoadSplit(file, base)
+}
+
+func run(target *prog.Target, spec *btf.Spec, match func(string) bool, checkDescs bool) (
+	*declextract.Result, []Warn, error) {
+	out := convert(spec, int(target.PtrSize), match)
+	res, err := declextract.Run(out, new(ifaceprobe.Info), nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Canonicalize the descriptions, we need to re-parse them again b/c new lines are fixed up during parsing.
+	desc := ast.Parse(res.Descriptions, "auto.txt", ast.LoggingHandler)
+	if desc == nil {
+		return nil, nil, fmt.Errorf("failed to parse generated descriptions")
+	}
+	res.Descriptions = ast.Format(ast.Parse(ast.Format(desc), "auto.txt", nil))
+	res.Descriptions = bytes.Replace(res.Descriptions, []byte("syz-declextract"), []byte("syz-btfextract"), 1)
+	var warnings []Warn
+	if checkDescs {
+		warnings = check(target, spec, match)
+	}
+	return res, warnings, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/btf"
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+var flagUpdate = flag.Bool("update", false, "update golden files")
+
+func TestBTFExtract(t *testing.T) {
+	target, err := prog.GetTarget(targets.Linux, targets.AMD64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "*.c"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("found no source files: %v", err)
+	}
+	// Everything except for not_selected.
+	match := regexp.MustCompile("^(timespec|foo|nested|point_t|pid_type|foo_mode_t|packed)$").MatchString
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			spec, err := btf.Load(file + ".btf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, warnings, err := run(target, spec, match, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Check that descriptions compile and generated structs have the same size/align as in C.
+			errors := new(bytes.Buffer)
+			eh := func(pos ast.Pos, msg string) {
+				fmt.Fprintf(errors, "%v: %v\n", pos, msg)
+			}
+			desc := ast.ParseGlob(filepath.Join("testdata", "manual.txt"), eh)
+			if desc == nil {
+				t.Fatalf("failed to parse manual descriptions:\n%s", errors)
+			}
+			auto := ast.Parse(res.Descriptions, "auto.txt", eh)
+			if auto == nil {
+				t.Fatalf("failed to parse descriptions:\n%s", errors)
+			}
+			desc.Nodes = append(desc.Nodes, auto.Nodes...)
+			use := ast.Parse(useDescriptions(auto), "use.txt", eh)
+			if use == nil {
+				t.Fatalf("failed to parse use descriptions:\n%s", errors)
+			}
+			desc.Nodes = append(desc.Nodes, use.Nodes...)
+			sysTarget := targets.Get(targets.Linux, targets.AMD64)
+			constInfo := compiler.ExtractConsts(desc, sysTarget, eh)
+			if constInfo == nil {
+				t.Fatalf("failed to compile descriptions:\n%s", errors)
+			}
+			consts := make(map[string]uint64)
+			for _, info := range constInfo {
+				for i, c := range info.Consts {
+					consts[c.Name] = uint64(i + 1)
+				}
+			}
+			prg := compiler.Compile(desc, consts, sysTarget, eh)
+			if prg == nil {
+				t.Fatalf("failed to compile descriptions:\n%s", errors)
+			}
+			for _, typ := range prg.Types {
+				info := res.StructInfo[typ.Name()]
+				if info == nil {
+					continue
+				}
+				// Structs with flexible array members are varlen, only alignment can be checked for them.
+				if typ.Varlen() && typ.Alignment() != uint64(info.Align) {
+					t.Errorf("incorrect generated type %v: align %v/%v", typ.Name(), typ.Alignment(), info.Align)
+				}
+				if !typ.Varlen() && (typ.Size() != uint64(info.Size) || typ.Alignment() != uint64(info.Align)) {
+					t.Errorf("incorrect generated type %v: size %v/%v align %v/%v",
+						typ.Name(), typ.Size(), info.Size, typ.Alignment(), info.Align)
+				}
+			}
+
+			warnText := new(bytes.Buffer)
+			for _, warn := range warnings {
+				fmt.Fprintln(warnText, warn)
+			}
+			compareGolden(t, file+".txt", res.Descriptions)
+			compareGolden(t, file+".warn", warnText.Bytes())
+		})
+	}
+}
+
+// useDescriptions returns a syscall that uses all generated types,
+// the tool does not generate syscalls so the types would be unused otherwise.
+func useDescriptions(desc *ast.Description) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "use$btf(a ptr[in, use_btf])\nuse_btf {\n")
+	fmt.Fprintf(buf, "\tunion\tauto_union[int8, int8]\n\taligner\tauto_aligner[1]\n")
+	for i, node := range desc.Nodes {
+		switch n := node.(type) {
+		case *ast.Struct:
+			fmt.Fprintf(buf, "\tf%v\tptr[in, %v]\n", i, n.Name.Name)
+		case *ast.IntFlags:
+			fmt.Fprintf(buf, "\tf%v\tflags[%v, int32]\n", i, n.Name.Name)
+		}
+	}
+	fmt.Fprintf(buf, "}\n")
+	return buf.Bytes()
+}
+
+func compareGolden(t *testing.T, goldenFile string, got []byte) {
+	if *flagUpdate {
+		if err := osutil.WriteFile(goldenFile, got); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/btf"
+	"github.com/google/syzkaller/prog"
+)
+
+// Warning types are the same as in syz-check where they have the same meaning.
+const (
+	WarnBadStructKind    = "bad-struct-kind"
+	WarnBadStructSize    = "bad-struct-size"
+	WarnBadFieldNumber   = "bad-field-number"
+	WarnBadFieldSize     = "bad-field-size"
+	WarnBadFieldOffset   = "bad-field-offset"
+	WarnBadBitfield      = "bad-bitfield"
+	WarnMissingFlagValue = "missing-flag-value"
+)
+
+type Warn struct {
+	typ string
+	msg string
+}
+
+func (w Warn) String() string {
+	return fmt.Sprintf("%v: %v", w.typ, w.msg)
+}
+
+// check compares the existing descriptions with the kernel types.
+// Only descriptions that have a selected kernel type with the same name are checked.
+func check(target *prog.Target, spec *btf.Spec, match func(name string) bool) []Warn {
+	var warnings []Warn
+	lookup := func(name string, kinds ...btf.Kind) *btf.Type {
+		if !match(name) {
+			return nil
+		}
+		for _, t := range spec.Lookup(name) {
+			for _, kind := range kinds {
+				if t.Kind == kind {
+					return t
+				}
+			}
+		}
+		return nil
+	}
+	var types []prog.Type
+	seen := make(map[string]bool)
+	prog.ForeachType(target.Syscalls, func(typ prog.Type, ctx *prog.TypeCtx) {
+		switch typ.(type) {
+		case *prog.StructType, *prog.UnionType:
+			if !seen[typ.Name()] {
+				seen[typ.Name()] = true
+				types = append(types, typ)
+			}
+		}
+	})
+	for _, typ := range types {
+		// We frequently split a single struct into multiple ones (more precise description),
+		// so try to match our foo$bar with kernel foo.
+		name := typ.TemplateName()
+		kernelStruct := lookup(name, btf.KindStruct, btf.KindUnion)
+		if delim := strings.LastIndexByte(name, '$'); kernelStruct == nil && delim != -1 {
+			kernelStruct = lookup(name[:delim], btf.KindStruct, btf.KindUnion)
+		}
+		if kernelStruct != nil {
+			warnings = append(warnings, checkStruct(typ, kernelStruct, int(target.PtrSize))...)
+		}
+	}
+	for _, flags := range target.Flags {
+		enum := lookup(flags.Name, btf.KindEnum, btf.KindEnum64)
+		if enum == nil {
+			continue
+		}
+		have := make(map[string]bool)
+		for _, val := range flags.Values {
+			have[val] = true
+		}
+		var missing []string
+		for _, val := range enum.Values {
+			// Skip sentinel values like FOO_MAX and __FOO_MAX, they are not supposed to be used.
+			if !have[val.Name] && !strings.HasSuffix(val.Name, "_MAX") && !strings.HasPrefix(val.Name, "__") {
+				missing = append(missing, val.Name)
+			}
+		}
+		if len(missing) != 0 {
+			warnings = append(warnings, Warn{WarnMissingFlagValue,
+				fmt.Sprintf("%v: %v", flags.Name, strings.Join(missing, ", "))})
+		}
+	}
+	// Template instantiations produce the same warnings several times.
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].String() < warnings[j].String()
+	})
+	return slices.Compact(warnings)
+}
+
+func checkStruct(typ prog.Type, str *btf.Type, ptrSize int) []Warn {
+	var warnings []Warn
+	warn := func(typ, msg string, args ...any) {
+		warnings = append(warnings, Warn{typ, fmt.Sprintf(msg, args...)})
+	}
+	name := typ.TemplateName()
+	_, isUnion := typ.(*prog.UnionType)
+	if isUnion != (str.Kind == btf.KindUnion) {
+		warn(WarnBadStructKind, "%v: kernel type is %v", name, str.Kind)
+		return warnings
+	}
+	if !typ.Varlen() && typ.Size() != uint64(str.Size) {
+		warn(WarnBadStructSize, "%v: syz=%v kernel=%v", name, typ.Size(), str.Size)
+	}
+	// Union options are frequently described differently, and structs with out_overlay
+	// are never described in the kernel as a simple struct.
+	if isUnion || typ.(*prog.StructType).OverlayField != 0 {
+		return warnings
+	}
+	ai := 0
+	offset := uint64(0)
+	for _, field := range typ.(*prog.StructType).Fields {
+		if field.Type.Varlen() {
+			ai = len(str.Members)
+			break
+		}
+		if prog.IsPad(field.Type) {
+			offset += field.Type.Size()
+			continue
+		}
+		if ai < len(str.Members) {
+			m := str.Members[ai]
+			desc := fmt.Sprintf("%v.%v", name, field.Name)
+			if field.Name != m.Name {
+				desc += "/" + m.Name
+			}
+			if size := m.Type.ByteSize(ptrSize); field.Type.UnitSize() != uint64(size) {
+				warn(WarnBadFieldSize, "%v: syz=%v kernel=%v", desc, field.Type.UnitSize(), size)
+			}
+			byteOffset := offset - field.Type.UnitOffset()
+			if m.BitSize == 0 && byteOffset*8 != uint64(m.BitOffset) {
+				warn(WarnBadFieldOffset, "%v: syz=%v kernel=%v", desc, byteOffset, m.BitOffset/8)
+			}
+			// Unlike DWARF, BTF bitfield offsets are offsets from the beginning of the struct.
+			if m.BitSize != 0 || field.Type.BitfieldLength() != 0 {
+				bitOffset := byteOffset*8 + field.Type.BitfieldOffset()
+				if field.Type.BitfieldLength() != uint64(m.BitSize) || bitOffset != uint64(m.BitOffset) {
+					warn(WarnBadBitfield, "%v: size/offset: syz=%v/%v kernel=%v/%v",
+						desc, field.Type.BitfieldLength(), bitOffset, m.BitSize, m.BitOffset)
+				}
+			}
+		}
+		ai++
+		offset += field.Size()
+	}
+	if ai != len(str.Members) {
+		warn(WarnBadFieldNumber, "%v: syz=%v kernel=%v", name, ai, len(str.Members))
+	}
+	return warnings
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/btf"
+	"github.com/google/syzkaller/pkg/declextract"
+)
+
+// converter converts BTF types into declextract entities the same way the clang tool
+// converts C types, so that pkg/declextract can produce descriptions from them.
+type converter struct {
+	ptrSize int
+	match   func(name string) bool
+	out     *declextract.Output
+	names   map[*btf.Type]string
+	taken   map[string]*btf.Type
+}
+
+func convert(spec *btf.Spec, ptrSize int, match func(name string) bool) *declextract.Output {
+	conv := &converter{
+		ptrSize: ptrSize,
+		match:   match,
+		out:     new(declextract.Output),
+		names:   make(map[*btf.Type]string),
+		taken:   make(map[string]*btf.Type),
+	}
+	for _, t := range spec.Types[1:] {
+		if !conv.selected(t.Name) {
+			continue
+		}
+		switch t.Kind {
+		case btf.KindStruct, btf.KindUnion:
+			conv.genStruct(t, t.Name)
+		case btf.KindEnum, btf.KindEnum64:
+			conv.genEnum(t, t.Name)
+		case btf.KindTypedef:
+			// Typedefs of anonymous structs/enums: typedef struct {...} foo_t.
+			if u := t.Skip(); u != nil && u.Name == "" && u.Kind != btf.KindInt {
+				conv.genType(t, t.Name)
+			}
+		}
+	}
+	conv.out.SortAndDedup()
+	return conv.out
+}
+
+func (conv *converter) selected(name string) bool {
+	return name != "" && conv.match(name)
+}
+
+// genType converts the BTF type, backup is the name to use for anonymous structs.
+func (conv *converter) genType(typ *btf.Type, backup string) *declextract.Type {
+	// If it's a typedef, we want to use the typedef name for ints and anonymous structs/enums.
+	typedef := ""
+	if t := typ.SkipQualifiers(); t != nil && t.Kind == btf.KindTypedef {
+		typedef = t.Name
+	}
+	t := typ.Skip()
+	if t == nil {
+		return todoType()
+	}
+	switch t.Kind {
+	case btf.KindInt, btf.KindFloat:
+		name := t.Name
+		if typedef != "" {
+			name = typedef
+		}
+		return &declextract.Type{Int: &declextract.IntType{ByteSize: t.Size, Name: name, Base: t.Name}}
+	case btf.KindEnum, btf.KindEnum64:
+		name := t.Name
+		if name == "" {
+			name = typedef
+		}
+		res := &declextract.Type{Int: &declextract.IntType{ByteSize: t.Size, Name: name, Base: "int"}}
+		if name != "" && len(t.Values) != 0 {
+			res.Int.Enum = conv.genEnum(t, name)
+		}
+		return res
+	case btf.KindStruct, btf.KindUnion:
+		name := t.Name
+		if name == "" {
+			name = typedef
+		}
+		if name == "" {
+			name = backup
+		}
+		return &declextract.Type{Struct: conv.genStruct(t, name)}
+	case btf.KindArray:
+		return &declextract.Type{Array: &declextract.ArrayType{
+			Elem:        conv.genType(t.Ref, backup),
+			MinSize:     t.Len,
+			MaxSize:     t.Len,
+			Align:       t.Align(conv.ptrSize),
+			IsConstSize: true,
+		}}
+	case btf.KindPtr:
+		return conv.genPtr(t.Ref, backup)
+	case btf.KindFuncProto:
+		return &declextract.Type{Ptr: &declextract.PtrType{Elem: todoType(), IsConst: true}}
+	}
+	// Forward declarations: the definition is not present in BTF.
+	return todoType()
+}
+
+func (conv *converter) genPtr(pointee *btf.Type, backup string) *declextract.Type {
+	res := &declextract.Type{Ptr: &declextract.PtrType{IsConst: pointee.IsConst()}}
+	t := pointee.Skip()
+	switch {
+	case t == nil:
+		res.Ptr.Elem = &declextract.Type{Array: &declextract.ArrayType{Elem: todoType()}}
+	case t.Kind == btf.KindInt && (t.Encoding&btf.IntChar != 0 || strings.HasSuffix(t.Name, "char")):
+		res.Ptr.Elem = &declextract.Type{Buffer: &declextract.BufferType{IsString: true}}
+	case (t.Kind == btf.KindStruct || t.Kind == btf.KindUnion) && conv.names[t] == "" &&
+		!conv.selected(typeName(pointee)):
+		// Don't pull in all structs reachable over pointers (that would be most of the kernel),
+		// only the ones that are selected or are already used by value.
+		res.Ptr.Elem = &declextract.Type{Array: &declextract.ArrayType{Elem: todoType()}}
+	default:
+		res.Ptr.Elem = conv.genType(pointee, backup)
+	}
+	return res
+}
+
+func (conv *converter) genStruct(t *btf.Type, name string) string {
+	if existing := conv.names[t]; existing != "" {
+		return existing
+	}
+	name = conv.uniqueName(t, name)
+	var fields []*declextract.Field
+	for i, m := range t.Members {
+		fieldName, anonymous := m.Name, false
+		backup := name + "_" + fieldName
+		if fieldName == "" {
+			backup = fmt.Sprintf("%v_%v", name, i)
+			fieldName, anonymous = backup, true
+		}
+		typ := conv.genType(m.Type, backup)
+		if typ.Array != nil && typ.Array.MaxSize == 0 && i == len(t.Members)-1 {
+			// BTF does not distinguish flexible array members (foo[]) from zero-sized arrays (foo[0]),
+			// but the last member is a flexible array in either case.
+			typ.Array = &declextract.ArrayType{Elem: typ.Array.Elem}
+		}
+		fields = append(fields, &declextract.Field{
+			Name:        fieldName,
+			IsAnonymous: anonymous,
+			BitWidth:    m.BitSize,
+			CountedBy:   -1,
+			Type:        typ,
+		})
+	}
+	packed, align := conv.isPacked(t), t.Align(conv.ptrSize)
+	if packed {
+		align = 1
+	}
+	conv.out.Structs = append(conv.out.Structs, &declextract.Struct{
+		Name:     name,
+		ByteSize: t.Size,
+		Align:    align,
+		IsUnion:  t.Kind == btf.KindUnion,
+		IsPacked: packed,
+		Fields:   fields,
+	})
+	return name
+}
+
+// isPacked infers the packed attribute from the layout, since BTF does not contain attributes.
+func (conv *converter) isPacked(t *btf.Type) bool {
+	if t.Kind != btf.KindStruct {
+		return false
+	}
+	if t.Size%t.Align(conv.ptrSize) != 0 {
+		return true
+	}
+	for _, m := range t.Members {
+		if m.BitSize == 0 && m.BitOffset%(m.Type.Align(conv.ptrSize)*8) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (conv *converter) genEnum(t *btf.Type, name string) string {
+	if existing := conv.names[t]; existing != "" {
+		return existing
+	}
+	name = conv.uniqueName(t, name)
+	enum := &declextract.Enum{Name: name}
+	for _, val := range t.Values {
+		enum.Values = append(enum.Values, val.Name)
+		conv.out.Consts = append(conv.out.Consts, &declextract.ConstInfo{
+			Name:  val.Name,
+			Value: val.Value,
+		})
+	}
+	conv.out.Enums = append(conv.out.Enums, enum)
+	return name
+}
+
+// uniqueName assigns the name to the type. BTF may contain several different types
+// with the same name (e.g. static structs in different source files), later ones get the type ID suffix.
+func (conv *converter) uniqueName(t *btf.Type, name string) string {
+	if prev := conv.taken[name]; prev != nil && prev != t {
+		name = fmt.Sprintf("%v_%v", name, t.ID)
+	}
+	conv.taken[name] = t
+	conv.names[t] = name
+	return name
+}
+
+// typeName returns name of the type, or name of the typedef for anonymous types.
+func typeName(typ *btf.Type) string {
+	if t := typ.Skip(); t != nil && t.Name != "" {
+		return t.Name
+	}
+	if t := typ.SkipQualifiers(); t != nil && t.Kind == btf.KindTypedef {
+		return t.Name
+	}
+	return ""
+}
+
+func todoType() *declextract.Type {
+	return &declextract.Type{Int: &declextract.IntType{ByteSize: 1, Name: "TODO", Base: "long"}}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+typedef unsigned char __u8;
+typedef unsigned short __u16;
+typedef unsigned int __u32;
+typedef unsigned long long __u64;
+
+enum pid_type {
+	PIDTYPE_PID,
+	PIDTYPE_TGID,
+	PIDTYPE_PGID,
+	PIDTYPE_SID,
+	PIDTYPE_NEW,
+	PIDTYPE_MAX,
+};
+
+typedef enum {
+	MODE_A = 1,
+	MODE_B = 2,
+	MODE_C = 4,
+} foo_mode_t;
+
+// Mismatches the existing timespec description.
+struct timespec {
+	long tv_sec;
+	int tv_nsec;
+};
+
+struct not_selected {
+	int x;
+};
+
+struct nested {
+	__u16 a;
+	__u16 b;
+};
+
+typedef struct {
+	__u32 x;
+	__u32 y;
+} point_t;
+
+struct foo {
+	__u8 kind;
+	__u32 flags : 3;
+	__u32 mode : 5;
+	__u64 addr;
+	char name[16];
+	const char *path;
+	void *data;
+	int (*callback)(int);
+	struct nested by_value;
+	struct nested *by_ptr;
+	struct not_selected *other;
+	struct foo *next;
+	point_t point;
+	enum pid_type type;
+	foo_mode_t fmode;
+	union {
+		__u32 u32;
+		__u64 u64;
+	};
+	union {
+		__u16 port;
+		__u8 raw[2];
+	} addr_u;
+	__u32 fd;
+	__u32 unused_pad;
+	__u32 len;
+	__u8 payload[];
+};
+
+struct packed {
+	__u8 a;
+	__u32 b;
+} __attribute__((packed));
+
+struct foo foo;
+struct packed packed;
+struct timespec ts;
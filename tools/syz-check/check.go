@@ -25,7 +25,19 @@
 // E.g. -dwarf=0 greatly speeds up checking if you are only interested in netlink warnings
 // (but then again don't commit changes).
 //
-// The results are produced in sys/os/*.warn files.
+// Descriptions are compiled for all arches of the OS, even if there is no object file for the arch.
+// Besides compilation errors, this allows to find consts that are defined only on some arches,
+// while the descriptions that use them are not restricted to these arches with meta arches
+// (syscalls that use such consts are silently disabled on the rest of the arches).
+// This check does not require object files at all:
+//
+//	$ syz-check -report report.txt
+//
+// The results are produced in sys/os/*.warn files, or in a single report file across all files
+// and arches if -report flag is given (.warn files are not changed then).
+// With -os=all the report covers all OSes that have descriptions and consts
+// (object files are used only for linux). Compiling descriptions for all arches of all OSes
+// takes a minute or so, while DWARF parsing of each object file may take much longer.
 // On implementation level syz-check parses vmlinux dwarf, extracts struct descriptions
 // and compares them with what we have (size, fields, alignment, etc). Netlink checking extracts policy symbols
 // from the object files and parses them.
@@ -33,7 +45,6 @@ package main
 
 import (
 	"bytes"
-	"debug/dwarf"
 	"debug/elf"
 	"flag"
 	"fmt"
@@ -55,61 +66,114 @@ import (
 
 func main() {
 	var (
-		flagOS      = flag.String("os", runtime.GOOS, "OS")
+		flagOS      = flag.String("os", runtime.GOOS, "OS, or \"all\" to check all OSes (requires -report)")
 		flagDWARF   = flag.Bool("dwarf", true, "do checking based on DWARF")
 		flagNetlink = flag.Bool("netlink", true, "do checking of netlink policies")
+		flagConsts  = flag.Bool("consts", false, "check missing consts (default: true with -report)")
+		flagReport  = flag.String("report", "", "write all warnings into this file instead of .warn files")
 	)
-	arches := make(map[string]*string)
+	objs := make(map[string]string)
 	for arch := range targets.List[targets.Linux] {
-		arches[arch] = flag.String("obj-"+arch, "", arch+" kernel object file")
+		flag.Func("obj-"+arch, arch+" kernel object file", func(obj string) error {
+			objs[arch] = obj
+			return nil
+		})
 	}
 	defer tool.Init()()
-	var warnings []Warn
-	for arch, obj := range arches {
-		if *obj == "" {
-			delete(arches, arch)
+	// Missing consts are only checked by default for the report, otherwise they would
+	// change the checked-in .warn files on every run.
+	missingConsts := *flagReport != ""
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "consts" {
+			missingConsts = *flagConsts
+		}
+	})
+	if len(objs) == 0 && *flagReport == "" {
+		fmt.Fprintf(os.Stderr, "specify at least one -obj-arch flag, or -report flag\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	OSes := []string{*flagOS}
+	if *flagOS == "all" {
+		if *flagReport == "" {
+			tool.Failf("-os=all requires -report flag")
+		}
+		OSes = nil
+		for OS := range targets.List {
+			// Skip OSes that don't have descriptions or never had consts extracted.
+			txts, _ := filepath.Glob(filepath.Join("sys", OS, "*.txt"))
+			consts, _ := filepath.Glob(filepath.Join("sys", OS, "*.const"))
+			if len(txts) != 0 && len(consts) != 0 {
+				OSes = append(OSes, OS)
+			}
+		}
+		sort.Strings(OSes)
+	}
+	report := new(bytes.Buffer)
+	for _, OS := range OSes {
+		var arches []string
+		for arch := range targets.List[OS] {
+			arches = append(arches, arch)
+		}
+		sort.Strings(arches)
+		var warnings []Warn
+		nobjs := 0
+		for _, arch := range arches {
+			obj := ""
+			if OS == targets.Linux {
+				obj = objs[arch]
+			}
+			if obj != "" {
+				nobjs++
+			}
+			warnings1, err := check(OS, arch, obj, *flagDWARF, *flagNetlink)
+			if err != nil {
+				tool.Fail(err)
+			}
+			warnings = append(warnings, warnings1...)
+			runtime.GC()
+		}
+		if missingConsts {
+			warnings1, err := checkConsts(OS, arches)
+			if err != nil {
+				tool.Fail(err)
+			}
+			warnings = append(warnings, warnings1...)
+		}
+		if *flagReport != "" {
+			formatReport(report, OS, len(arches), nobjs, warnings)
 			continue
 		}
-		warnings1, err := check(*flagOS, arch, *obj, *flagDWARF, *flagNetlink)
-		if err != nil {
+		if err := writeWarnings(OS, len(arches), nobjs, warnings); err != nil {
 			tool.Fail(err)
 		}
-		warnings = append(warnings, warnings1...)
-		runtime.GC()
-	}
-	if len(arches) == 0 {
-		fmt.Fprintf(os.Stderr, "specify at least one -obj-arch flag\n")
-		flag.PrintDefaults()
-		os.Exit(1)
 	}
-	if err := writeWarnings(*flagOS, len(arches), warnings); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	if *flagReport != "" {
+		if err := osutil.WriteFile(*flagReport, report.Bytes()); err != nil {
+			tool.Fail(err)
+		}
 	}
 }
 
 func check(OS, arch, obj string, dwarf, netlink bool) ([]Warn, error) {
 	var warnings []Warn
-	if obj == "" {
-		return nil, fmt.Errorf("no object file in -obj-%v flag", arch)
-	}
 	structTypes, locs, warnings1, err := parseDescriptions(OS, arch)
 	if err != nil {
 		return nil, err
 	}
 	warnings = append(warnings, warnings1...)
-	if dwarf {
+	if dwarf && obj != "" {
 		structs, err := parseKernelObject(obj)
 		if err != nil {
 			return nil, err
 		}
-		warnings2, err := checkImpl(structs, structTypes, locs)
+		warnings2, err := checkImpl(structs, structTypes, locs, targets.Get(OS, arch))
 		if err != nil {
 			return nil, err
 		}
 		warnings = append(warnings, warnings2...)
 	}
-	if netlink {
+	if netlink && obj != "" {
 		warnings3, err := checkNetlink(arch, obj, structTypes, locs)
 		if err != nil {
 			return nil, err
@@ -126,6 +190,7 @@ const (
 	WarnCompiler           = "compiler"
 	WarnNoSuchStruct       = "no-such-struct"
 	WarnBadStructSize      = "bad-struct-size"
+	WarnBadStructAlign     = "bad-struct-align"
 	WarnBadFieldNumber     = "bad-field-number"
 	WarnBadFieldSize       = "bad-field-size"
 	WarnBadFieldOffset     = "bad-field-offset"
@@ -134,8 +199,18 @@ const (
 	WarnNetlinkBadSize     = "bad-kernel-netlink-policy-size"
 	WarnNetlinkBadAttrType = "bad-netlink-attr-type"
 	WarnNetlinkBadAttr     = "bad-netlink-attr"
+	WarnMissingConst       = "missing-const"
 )
 
+func isDWARFWarn(typ string) bool {
+	switch typ {
+	case WarnNoSuchStruct, WarnBadStructSize, WarnBadStructAlign, WarnBadFieldNumber,
+		WarnBadFieldSize, WarnBadFieldOffset, WarnBadBitfield:
+		return true
+	}
+	return false
+}
+
 type Warn struct {
 	pos  ast.Pos
 	arch string
@@ -143,7 +218,7 @@ type Warn struct {
 	msg  string
 }
 
-func writeWarnings(OS string, narches int, warnings []Warn) error {
+func writeWarnings(OS string, narches, nobjs int, warnings []Warn) error {
 	allFiles, err := filepath.Glob(filepath.Join("sys", OS, "*.warn"))
 	if err != nil {
 		return err
@@ -152,11 +227,42 @@ func writeWarnings(OS string, narches int, warnings []Warn) error {
 	for _, file := range allFiles {
 		toRemove[file] = true
 	}
+	for file, warns := range warningsByFile(warnings) {
+		buf := new(bytes.Buffer)
+		formatWarnings(buf, narches, nobjs, warns, false)
+		warnFile := file + ".warn"
+		if err := osutil.WriteFile(warnFile, buf.Bytes()); err != nil {
+			return err
+		}
+		delete(toRemove, warnFile)
+	}
+	for file := range toRemove {
+		os.Remove(file)
+	}
+	return nil
+}
+
+// formatReport formats warnings for a single OS for the report file grouped by description files.
+// Unlike .warn files, the report includes line numbers.
+func formatReport(buf *bytes.Buffer, OS string, narches, nobjs int, warnings []Warn) {
+	byFile := warningsByFile(warnings)
+	var files []string
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		formatWarnings(buf, narches, nobjs, byFile[file], true)
+	}
+	fmt.Fprintf(buf, "%v: %v warnings in %v files\n", OS, len(warnings), len(files))
+}
+
+func warningsByFile(warnings []Warn) map[string][]Warn {
 	byFile := make(map[string][]Warn)
 	for _, warn := range warnings {
 		byFile[warn.pos.File] = append(byFile[warn.pos.File], warn)
 	}
-	for file, warns := range byFile {
+	for _, warns := range byFile {
 		sort.Slice(warns, func(i, j int) bool {
 			w1, w2 := warns[i], warns[j]
 			if w1.pos.Line != w2.pos.Line {
@@ -170,39 +276,43 @@ func writeWarnings(OS string, narches int, warnings []Warn) error {
 			}
 			return w1.arch < w2.arch
 		})
-		buf := new(bytes.Buffer)
-		for i := 0; i < len(warns); i++ {
-			warn := warns[i]
-			arch := warn.arch
-			arches := []string{warn.arch}
-			for i < len(warns)-1 && warn.msg == warns[i+1].msg {
-				if arch != warns[i+1].arch {
-					arch = warns[i+1].arch
-					arches = append(arches, arch)
-				}
-				i++
-			}
-			archStr := ""
-			// We do netlink checking only on amd64, so don't add arch.
-			if len(arches) < narches && !strings.Contains(warn.typ, "netlink") {
-				archStr = fmt.Sprintf(" [%v]", strings.Join(arches, ","))
+	}
+	return byFile
+}
+
+// formatWarnings writes sorted warnings for a single file, the same warnings for different arches are merged.
+// Compiler and const warnings are checked on all narches arches, while DWARF warnings are checked
+// only on the nobjs arches that have object files. Warnings found on all checked arches are not suffixed.
+func formatWarnings(buf *bytes.Buffer, narches, nobjs int, warns []Warn, withPos bool) {
+	for i := 0; i < len(warns); i++ {
+		warn := warns[i]
+		arch := warn.arch
+		arches := []string{warn.arch}
+		for i < len(warns)-1 && warn.msg == warns[i+1].msg {
+			if arch != warns[i+1].arch {
+				arch = warns[i+1].arch
+				arches = append(arches, arch)
 			}
-			fmt.Fprintf(buf, "%v: %v%v\n", warn.typ, warn.msg, archStr)
+			i++
 		}
-		warnFile := file + ".warn"
-		if err := osutil.WriteFile(warnFile, buf.Bytes()); err != nil {
-			return err
+		checked := narches
+		if isDWARFWarn(warn.typ) {
+			checked = nobjs
 		}
-		delete(toRemove, warnFile)
-	}
-	for file := range toRemove {
-		os.Remove(file)
+		archStr := ""
+		// We do netlink checking only on amd64, so don't add arch.
+		if len(arches) < checked && !strings.Contains(warn.typ, "netlink") {
+			archStr = fmt.Sprintf(" [%v]", strings.Join(arches, ","))
+		}
+		if withPos {
+			fmt.Fprintf(buf, "%v:%v: ", warn.pos.File, warn.pos.Line)
+		}
+		fmt.Fprintf(buf, "%v: %v%v\n", warn.typ, warn.msg, archStr)
 	}
-	return nil
 }
 
-func checkImpl(structs map[string]*dwarf.StructType, structTypes []prog.Type,
-	locs map[string]*ast.Struct) ([]Warn, error) {
+func checkImpl(structs map[string]*KernelStruct, structTypes []prog.Type,
+	locs map[string]*ast.Struct, target *targets.Target) ([]Warn, error) {
 	var warnings []Warn
 	for _, typ := range structTypes {
 		name := typ.TemplateName()
@@ -219,7 +329,7 @@ func checkImpl(structs map[string]*dwarf.StructType, structTypes []prog.Type,
 		if delim := strings.LastIndexByte(name, '$'); kernelStruct == nil && delim != -1 {
 			kernelStruct = structs[name[:delim]]
 		}
-		warns, err := checkStruct(typ, astStruct, kernelStruct)
+		warns, err := checkStruct(typ, astStruct, kernelStruct, target)
 		if err != nil {
 			return nil, err
 		}
@@ -228,7 +338,7 @@ func checkImpl(structs map[string]*dwarf.StructType, structTypes []prog.Type,
 	return warnings, nil
 }
 
-func checkStruct(typ prog.Type, astStruct *ast.Struct, str *dwarf.StructType) ([]Warn, error) {
+func checkStruct(typ prog.Type, astStruct *ast.Struct, str *KernelStruct, target *targets.Target) ([]Warn, error) {
 	var warnings []Warn
 	warn := func(pos ast.Pos, typ, msg string, args ...interface{}) {
 		warnings = append(warnings, Warn{pos: pos, typ: typ, msg: fmt.Sprintf(msg, args...)})
@@ -244,6 +354,12 @@ func checkStruct(typ prog.Type, astStruct *ast.Struct, str *dwarf.StructType) ([
 	if !typ.Varlen() && typ.Size() != uint64(str.ByteSize) {
 		warn(astStruct.Pos, WarnBadStructSize, "%v: syz=%v kernel=%v", name, typ.Size(), str.ByteSize)
 	}
+	// Packed kernel structs with naturally aligned fields are indistinguishable from non-packed ones in DWARF,
+	// so if our struct is packed, we can't say if the kernel alignment is in fact larger.
+	kernelAlign := structAlign(str.StructType, str.Align, target.Int64Alignment)
+	if typ.Alignment() != kernelAlign && (typ.Alignment() != 1 || kernelAlign == 1) {
+		warn(astStruct.Pos, WarnBadStructAlign, "%v: syz=%v kernel=%v", name, typ.Alignment(), kernelAlign)
+	}
 	// TODO: handle unions, currently we should report some false errors.
 	if _, ok := typ.(*prog.UnionType); ok || str.Kind == "union" {
 		return warnings, nil
@@ -0,0 +1,135 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckStructAlign(t *testing.T) {
+	const desc = `
+foo(a ptr[in, natural], b ptr[in, packed], c ptr[in, aligned], d ptr[in, kernel_aligned])
+
+natural {
+	a	int32
+	b	int64
+}
+
+packed {
+	a	int32
+	b	int32
+} [packed]
+
+aligned {
+	a	int32
+} [align[8]]
+
+kernel_aligned {
+	a	int32
+}
+`
+	i32, i64 := dwarfInt(4), dwarfInt(8)
+	tests := []struct {
+		arch    string
+		structs map[string]*KernelStruct
+		want    []string
+	}{
+		{
+			arch: targets.AMD64,
+			structs: map[string]*KernelStruct{
+				"natural": {StructType: dwarfStruct(16, dwarfField(0, i32), dwarfField(8, i64))},
+				// The kernel struct may be packed as well, but we can't tell it from DWARF.
+				"packed":         {StructType: dwarfStruct(8, dwarfField(0, i32), dwarfField(4, i32))},
+				"aligned":        {StructType: dwarfStruct(8, dwarfField(0, i32))},
+				"kernel_aligned": {StructType: dwarfStruct(16, dwarfField(0, i32)), Align: 16},
+			},
+			want: []string{
+				"aligned: syz=8 kernel=4",
+				"kernel_aligned: syz=4 kernel=16",
+			},
+		},
+		{
+			arch: targets.I386,
+			structs: map[string]*KernelStruct{
+				"natural":        {StructType: dwarfStruct(12, dwarfField(0, i32), dwarfField(4, i64))},
+				"packed":         {StructType: dwarfStruct(8, dwarfField(0, i32), dwarfField(4, i32))},
+				"aligned":        {StructType: dwarfStruct(8, dwarfField(0, i32)), Align: 8},
+				"kernel_aligned": {StructType: dwarfStruct(4, dwarfField(0, i32))},
+			},
+		},
+		{
+			arch: targets.I386,
+			structs: map[string]*KernelStruct{
+				// The syz struct is not packed, but the kernel one is.
+				"natural": {StructType: dwarfStruct(12, dwarfField(0, i32), dwarfField(4, i64)), Align: 1},
+			},
+			want: []string{
+				"natural: syz=4 kernel=1",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.arch, func(t *testing.T) {
+			target := targets.Get(targets.Linux, test.arch)
+			eh := func(pos ast.Pos, msg string) {
+				t.Errorf("%v: %v", pos, msg)
+			}
+			top := ast.Parse([]byte(desc), "test.txt", eh)
+			require.NotNil(t, top)
+			prg := compiler.Compile(top, map[string]uint64{"__NR_foo": 1}, target, eh)
+			require.NotNil(t, prg)
+			prog.RestoreLinks(prg.Syscalls, prg.Resources, prg.Types)
+			locs := make(map[string]*ast.Struct)
+			for _, decl := range top.Nodes {
+				if n, ok := decl.(*ast.Struct); ok {
+					locs[n.Name.Name] = n
+				}
+			}
+			var got []string
+			for _, typ := range prg.Types {
+				str := test.structs[typ.Name()]
+				if _, ok := typ.(*prog.StructType); !ok || str == nil {
+					continue
+				}
+				warnings, err := checkStruct(typ, locs[typ.Name()], str, target)
+				require.NoError(t, err)
+				for _, warn := range warnings {
+					if warn.typ == WarnBadStructAlign {
+						got = append(got, warn.msg)
+					}
+				}
+			}
+			assert.ElementsMatch(t, test.want, got)
+		})
+	}
+}
+
+func TestFormatWarnings(t *testing.T) {
+	objArches := []string{targets.I386, targets.AMD64, targets.ARM, targets.ARM64}
+	pos := ast.Pos{File: "sys.txt", Line: 1}
+	var warnings []Warn
+	for _, arch := range objArches {
+		warnings = append(warnings,
+			Warn{pos: pos, arch: arch, typ: WarnNoSuchStruct, msg: "foo"},
+			Warn{pos: pos, arch: arch, typ: WarnCompiler, msg: "bar"})
+	}
+	for _, arch := range objArches[:2] {
+		warnings = append(warnings, Warn{pos: pos, arch: arch, typ: WarnBadStructSize, msg: "baz"})
+	}
+	// DWARF warnings are checked only on the 4 arches with object files,
+	// while the descriptions are compiled for all 8 linux arches.
+	buf := new(bytes.Buffer)
+	formatWarnings(buf, 8, len(objArches), warningsByFile(warnings)["sys.txt"], false)
+	assert.Equal(t, "bad-struct-size: baz [386,amd64]\n"+
+		"compiler: bar [386,amd64,arm,arm64]\n"+
+		"no-such-struct: foo\n", buf.String())
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+// checkConsts finds consts that are defined on some arches, but are missing on other arches
+// the description file is enabled for. Uses of such consts silently disable the corresponding syscalls
+// on these arches, which is usually unintended (the file is missing meta arches, or the const needs
+// to be extracted/defined on more arches).
+func checkConsts(OS string, arches []string) ([]Warn, error) {
+	errorBuf := new(bytes.Buffer)
+	eh := func(pos ast.Pos, msg string) {
+		fmt.Fprintf(errorBuf, "%v: %v\n", pos, msg)
+	}
+	top := ast.ParseGlob(filepath.Join("sys", OS, "*.txt"), eh)
+	if top == nil {
+		return nil, fmt.Errorf("failed to parse txt files:\n%s", errorBuf.Bytes())
+	}
+	constFile := compiler.DeserializeConstFile(filepath.Join("sys", OS, "*.const"), eh)
+	if constFile == nil {
+		return nil, fmt.Errorf("failed to parse const files:\n%s", errorBuf.Bytes())
+	}
+	warnings, err := checkConstsImpl(top, constFile, OS, arches, eh)
+	if err != nil {
+		return nil, fmt.Errorf("%w:\n%s", err, errorBuf.Bytes())
+	}
+	return warnings, nil
+}
+
+func checkConstsImpl(top *ast.Description, constFile *compiler.ConstFile, OS string, arches []string,
+	eh ast.ErrorHandler) ([]Warn, error) {
+	type constUse struct {
+		pos ast.Pos
+		// Arches the const is used on (i.e. the arches the file is enabled for).
+		arches []string
+	}
+	uses := make(map[string]*constUse)
+	for _, arch := range arches {
+		// The compiler drops descriptions that are not enabled for the arch,
+		// so extract consts for every arch separately.
+		target := targets.Get(OS, arch)
+		infos := compiler.ExtractConsts(top.Clone(), target, eh)
+		if infos == nil {
+			return nil, fmt.Errorf("failed to compile descriptions for %v", arch)
+		}
+		for file, info := range infos {
+			for _, c := range info.Consts {
+				if !c.Used || strings.HasPrefix(c.Name, target.SyscallPrefix) {
+					continue
+				}
+				key := file + ":" + c.Name
+				use := uses[key]
+				if use == nil {
+					use = &constUse{pos: c.Pos}
+					uses[key] = use
+				}
+				use.arches = append(use.arches, arch)
+			}
+		}
+	}
+	consts := make(map[string]map[string]uint64)
+	for _, arch := range arches {
+		consts[arch] = constFile.Arch(arch)
+	}
+	var warnings []Warn
+	for key, use := range uses {
+		name := key[strings.LastIndexByte(key, ':')+1:]
+		var missing []string
+		for _, arch := range use.arches {
+			if _, ok := consts[arch][name]; !ok {
+				missing = append(missing, arch)
+			}
+		}
+		// Consts that are missing everywhere are reported by the compiler as unsupported syscalls.
+		if len(missing) == 0 || len(missing) == len(use.arches) {
+			continue
+		}
+		for _, arch := range missing {
+			warnings = append(warnings, Warn{
+				pos:  use.pos,
+				arch: arch,
+				typ:  WarnMissingConst,
+				msg:  fmt.Sprintf("%v is not defined", name),
+			})
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		w1, w2 := warnings[i], warnings[j]
+		if w1.pos.File != w2.pos.File {
+			return w1.pos.File < w2.pos.File
+		}
+		if w1.pos.Line != w2.pos.Line {
+			return w1.pos.Line < w2.pos.Line
+		}
+		if w1.msg != w2.msg {
+			return w1.msg < w2.msg
+		}
+		return w1.arch < w2.arch
+	})
+	return warnings, nil
+}
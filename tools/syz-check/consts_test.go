@@ -0,0 +1,133 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConsts(t *testing.T) {
+	arches := []string{targets.I386, targets.AMD64, targets.ARM64}
+	type result struct {
+		line int
+		arch string
+		msg  string
+	}
+	tests := []struct {
+		name   string
+		desc   string
+		consts map[string][]string
+		want   []result
+	}{
+		{
+			name: "defined everywhere",
+			desc: `
+foo(a flags[foo_flags])
+foo_flags = FOO, BAR
+`,
+			consts: map[string][]string{
+				targets.I386:  {"FOO", "BAR"},
+				targets.AMD64: {"FOO", "BAR"},
+				targets.ARM64: {"FOO", "BAR"},
+			},
+		},
+		{
+			name: "missing on some arches",
+			desc: `
+foo(a flags[foo_flags], b const[BAR])
+foo_flags = FOO, BAR
+`,
+			consts: map[string][]string{
+				targets.I386:  {"FOO"},
+				targets.AMD64: {"FOO", "BAR"},
+				targets.ARM64: {"FOO"},
+			},
+			want: []result{
+				{2, targets.I386, "BAR is not defined"},
+				{2, targets.ARM64, "BAR is not defined"},
+			},
+		},
+		{
+			name: "missing everywhere",
+			desc: `
+foo(a const[FOO])
+`,
+		},
+		{
+			name: "unused",
+			desc: `
+define FOO	1
+foo(a const[0])
+`,
+			consts: map[string][]string{
+				targets.AMD64: {"FOO"},
+			},
+		},
+		{
+			name: "syscall numbers",
+			desc: `
+foo()
+`,
+			consts: map[string][]string{
+				targets.AMD64: {"__NR_foo"},
+			},
+		},
+		{
+			name: "meta arches",
+			desc: `
+meta arches["amd64", "arm64"]
+foo(a const[FOO], b const[BAR])
+`,
+			consts: map[string][]string{
+				targets.AMD64: {"FOO", "BAR"},
+				targets.ARM64: {"FOO"},
+			},
+			want: []result{
+				{3, targets.ARM64, "BAR is not defined"},
+			},
+		},
+		{
+			name: "single arch",
+			desc: `
+meta arches["arm64"]
+foo(a const[FOO])
+`,
+			consts: map[string][]string{
+				targets.ARM64: {"FOO"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eh := func(pos ast.Pos, msg string) {
+				t.Logf("%v: %v", pos, msg)
+			}
+			top := ast.Parse([]byte(test.desc), "test.txt", eh)
+			require.NotNil(t, top)
+			constFile := compiler.NewConstFile()
+			for _, arch := range arches {
+				consts := make(map[string]uint64)
+				for _, name := range test.consts[arch] {
+					consts[name] = 1
+				}
+				require.NoError(t, constFile.AddArch(arch, consts, nil))
+			}
+			warnings, err := checkConstsImpl(top, constFile, targets.Linux, arches, eh)
+			require.NoError(t, err)
+			var got []result
+			for _, warn := range warnings {
+				assert.Equal(t, "test.txt", warn.pos.File)
+				assert.Equal(t, WarnMissingConst, warn.typ)
+				got = append(got, result{warn.pos.Line, warn.arch, warn.msg})
+			}
+			assert.ElementsMatch(t, test.want, got)
+		})
+	}
+}
@@ -11,7 +11,14 @@ import (
 	"strings"
 )
 
-func parseKernelObject(obj string) (map[string]*dwarf.StructType, error) {
+// KernelStruct is a struct type extracted from the kernel debug info.
+type KernelStruct struct {
+	*dwarf.StructType
+	// Align is the alignment specified with the aligned attribute, or 0 if it's not specified.
+	Align int64
+}
+
+func parseKernelObject(obj string) (map[string]*KernelStruct, error) {
 	file, err := elf.Open(obj)
 	if err != nil {
 		return nil, err
@@ -43,7 +50,7 @@ func parseKernelObject(obj string) (map[string]*dwarf.StructType, error) {
 	buffer := 100 * numProcs
 	unitc := make(chan Unit, buffer)
 	offsetc := make(chan []dwarf.Offset, buffer)
-	structc := make(chan map[string]*dwarf.StructType, buffer)
+	structc := make(chan map[string]*KernelStruct, buffer)
 	errc := make(chan error)
 
 	go extractCompilationUnits(debugInfo, unitc, errc)
@@ -84,7 +91,7 @@ func parseKernelObject(obj string) (map[string]*dwarf.StructType, error) {
 		errc <- err
 	}()
 
-	result := make(map[string]*dwarf.StructType)
+	result := make(map[string]*KernelStruct)
 	go func() {
 		for structs := range structc {
 			for name, str := range structs {
@@ -164,7 +171,7 @@ func extractOffsets(debugInfo *dwarf.Data, unitc chan Unit, offsetc chan []dwarf
 }
 
 func extractStructs(file *elf.File, debugInfo *dwarf.Data, offsetc chan []dwarf.Offset,
-	structc chan map[string]*dwarf.StructType, errc chan error) {
+	structc chan map[string]*KernelStruct, errc chan error) {
 	if debugInfo == nil {
 		var err error
 		debugInfo, err = file.DWARF()
@@ -173,15 +180,21 @@ func extractStructs(file *elf.File, debugInfo *dwarf.Data, offsetc chan []dwarf.
 			return
 		}
 	}
-	var structs map[string]*dwarf.StructType
-	appendStruct := func(str *dwarf.StructType, name string) {
+	r := debugInfo.Reader()
+	var structs map[string]*KernelStruct
+	appendStruct := func(str *dwarf.StructType, name string, off dwarf.Offset) error {
 		if name == "" || str.ByteSize <= 0 {
-			return
+			return nil
+		}
+		align, err := explicitAlign(r, off)
+		if err != nil {
+			return err
 		}
 		if structs == nil {
-			structs = make(map[string]*dwarf.StructType)
+			structs = make(map[string]*KernelStruct)
 		}
-		structs[name] = str
+		structs[name] = &KernelStruct{str, align}
+		return nil
 	}
 	for offsets := range offsetc {
 		for _, off := range offsets {
@@ -192,18 +205,86 @@ func extractStructs(file *elf.File, debugInfo *dwarf.Data, offsetc chan []dwarf.
 			}
 			switch typ := typ1.(type) {
 			case *dwarf.StructType:
-				appendStruct(typ, typ.StructName)
+				err = appendStruct(typ, typ.StructName, off)
 			case *dwarf.TypedefType:
 				if str, ok := typ.Type.(*dwarf.StructType); ok {
-					appendStruct(str, typ.Name)
+					err = appendStruct(str, typ.Name, off)
 				}
 			default:
 				errc <- fmt.Errorf("got not struct/typedef")
 				return
 			}
+			if err != nil {
+				errc <- err
+				return
+			}
 		}
 		structc <- structs
 		structs = nil
 	}
 	errc <- nil
 }
+
+// explicitAlign returns alignment specified with the aligned attribute for the struct entry at the offset,
+// or for the struct the typedef at the offset refers to. dwarf.StructType does not contain it.
+func explicitAlign(r *dwarf.Reader, off dwarf.Offset) (int64, error) {
+	for {
+		r.Seek(off)
+		ent, err := r.Next()
+		if err != nil || ent == nil {
+			return 0, err
+		}
+		if ent.Tag != dwarf.TagTypedef {
+			align, _ := ent.Val(dwarf.AttrAlignment).(int64)
+			return align, nil
+		}
+		next, ok := ent.Val(dwarf.AttrType).(dwarf.Offset)
+		if !ok {
+			return 0, nil
+		}
+		off = next
+	}
+}
+
+// structAlign returns alignment of the kernel struct. DWARF does not contain it, so we infer it from the fields.
+// Structs with misaligned fields or size are considered packed.
+func structAlign(str *dwarf.StructType, explicit int64, int64Align uint64) uint64 {
+	if explicit != 0 {
+		return uint64(explicit)
+	}
+	align := uint64(1)
+	for _, field := range str.Field {
+		fieldAlign := typeAlign(field.Type, int64Align)
+		if field.BitSize == 0 && uint64(field.ByteOffset)%fieldAlign != 0 {
+			return 1
+		}
+		if align < fieldAlign {
+			align = fieldAlign
+		}
+	}
+	if uint64(str.ByteSize)%align != 0 {
+		return 1
+	}
+	return align
+}
+
+func typeAlign(typ dwarf.Type, int64Align uint64) uint64 {
+	switch t := typ.(type) {
+	case *dwarf.TypedefType:
+		return typeAlign(t.Type, int64Align)
+	case *dwarf.QualType:
+		return typeAlign(t.Type, int64Align)
+	case *dwarf.ArrayType:
+		return typeAlign(t.Type, int64Align)
+	case *dwarf.StructType:
+		return structAlign(t, 0, int64Align)
+	}
+	size := uint64(typ.Size())
+	if size == 8 && int64Align != 0 {
+		return int64Align
+	}
+	if size == 0 {
+		return 1
+	}
+	return size
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"debug/dwarf"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructAlign(t *testing.T) {
+	char, short, i32, i64 := dwarfInt(1), dwarfInt(2), dwarfInt(4), dwarfInt(8)
+	tests := []struct {
+		name       string
+		str        *dwarf.StructType
+		explicit   int64
+		int64Align uint64
+		align      uint64
+	}{
+		{
+			name:  "natural",
+			str:   dwarfStruct(8, dwarfField(0, char), dwarfField(4, i32)),
+			align: 4,
+		},
+		{
+			name:  "packed",
+			str:   dwarfStruct(5, dwarfField(0, char), dwarfField(1, i32)),
+			align: 1,
+		},
+		{
+			name:  "packed tail",
+			str:   dwarfStruct(6, dwarfField(0, i32), dwarfField(4, short)),
+			align: 1,
+		},
+		{
+			name:     "explicit",
+			str:      dwarfStruct(16, dwarfField(0, i32)),
+			explicit: 16,
+			align:    16,
+		},
+		{
+			name:  "int64",
+			str:   dwarfStruct(16, dwarfField(0, i32), dwarfField(8, i64)),
+			align: 8,
+		},
+		{
+			name:       "int64 alignment",
+			str:        dwarfStruct(12, dwarfField(0, i32), dwarfField(4, i64)),
+			int64Align: 4,
+			align:      4,
+		},
+		{
+			name:  "int64 alignment not set",
+			str:   dwarfStruct(12, dwarfField(0, i32), dwarfField(4, i64)),
+			align: 1,
+		},
+		{
+			name: "array",
+			str: dwarfStruct(8, dwarfField(0, char),
+				dwarfField(2, &dwarf.ArrayType{CommonType: dwarf.CommonType{ByteSize: 6}, Type: short, Count: 3})),
+			align: 2,
+		},
+		{
+			name: "typedef",
+			str: dwarfStruct(16, dwarfField(0, char),
+				dwarfField(8, &dwarf.TypedefType{CommonType: dwarf.CommonType{ByteSize: 8}, Type: i64})),
+			align: 8,
+		},
+		{
+			name: "nested",
+			str: dwarfStruct(12, dwarfField(0, char),
+				dwarfField(4, dwarfStruct(8, dwarfField(0, i32), dwarfField(4, short)))),
+			align: 4,
+		},
+		{
+			name: "nested packed",
+			str: dwarfStruct(6, dwarfField(0, char),
+				dwarfField(1, dwarfStruct(5, dwarfField(0, char), dwarfField(1, i32)))),
+			align: 1,
+		},
+		{
+			name: "bitfield",
+			str: dwarfStruct(4, dwarfField(0, char),
+				&dwarf.StructField{Type: i32, ByteOffset: 1, BitSize: 3}),
+			align: 4,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.align, structAlign(test.str, test.explicit, test.int64Align))
+		})
+	}
+}
+
+func TestTypeAlign(t *testing.T) {
+	assert.Equal(t, uint64(8), typeAlign(dwarfInt(8), 0))
+	assert.Equal(t, uint64(4), typeAlign(dwarfInt(8), 4))
+	assert.Equal(t, uint64(4), typeAlign(dwarfInt(4), 8))
+	assert.Equal(t, uint64(2), typeAlign(&dwarf.QualType{Qual: "const", Type: dwarfInt(2)}, 0))
+	assert.Equal(t, uint64(1), typeAlign(dwarfStruct(0), 0))
+}
+
+func dwarfInt(size int64) dwarf.Type {
+	return &dwarf.IntType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: size}}}
+}
+
+func dwarfStruct(size int64, fields ...*dwarf.StructField) *dwarf.StructType {
+	return &dwarf.StructType{
+		CommonType: dwarf.CommonType{ByteSize: size},
+		Kind:       "struct",
+		Field:      fields,
+	}
+}
+
+func dwarfField(offset int64, typ dwarf.Type) *dwarf.StructField {
+	return &dwarf.StructField{Type: typ, ByteOffset: offset}
+}
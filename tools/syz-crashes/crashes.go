@@ -0,0 +1,93 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-crashes queries and cleans up the crashes stored in a syz-manager workdir.
+// Usage:
+//
+//	syz-crashes -workdir=workdir [-title=regexp] [-type=KASAN-WRITE] [-repro=none|syz|c]
+//		[-since=2006-01-02] [-until=2006-01-02] [-syscall=name] [-json]
+//	syz-crashes -workdir=workdir -gc [-max-age=720h] [-max-logs=N] [-max-mb=N]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/syzkaller/pkg/manager"
+	"github.com/google/syzkaller/pkg/tool"
+)
+
+var (
+	flagWorkdir = flag.String("workdir", "", "syz-manager workdir")
+	flagTitle   = flag.String("title", "", "regexp for crash titles")
+	flagType    = flag.String("type", "", "crash type (e.g. KASAN-WRITE)")
+	flagRepro   = flag.String("repro", "", "reproducer status: none/syz/c")
+	flagSince   = flag.String("since", "", "only crashes that happened after this time")
+	flagUntil   = flag.String("until", "", "only crashes that happened before this time")
+	flagSyscall = flag.String("syscall", "", "only crashes with this syscall in the crash log programs")
+	flagJSON    = flag.Bool("json", false, "print the results in JSON")
+	flagGC      = flag.Bool("gc", false, "delete the crash logs that violate the retention limits")
+	flagMaxAge  = flag.Duration("max-age", 0, "delete crash logs older than this (with -gc)")
+	flagMaxLogs = flag.Int("max-logs", 0, "maximum total number of crash logs (with -gc)")
+	flagMaxMB   = flag.Int("max-mb", 0, "maximum total size of crash logs in megabytes (with -gc)")
+)
+
+func main() {
+	defer tool.Init()()
+	if *flagWorkdir == "" {
+		tool.Failf("-workdir is required")
+	}
+	store := manager.ReadCrashStore(*flagWorkdir)
+	if *flagGC {
+		policy := manager.CrashRetention{
+			MaxAge:   *flagMaxAge,
+			MaxCount: *flagMaxLogs,
+			MaxBytes: int64(*flagMaxMB) << 20,
+		}
+		if policy == (manager.CrashRetention{}) {
+			tool.Failf("-gc requires at least one of -max-age/-max-logs/-max-mb")
+		}
+		stats, err := store.ApplyRetention(policy, time.Now())
+		if err != nil {
+			tool.Fail(err)
+		}
+		fmt.Printf("deleted %v crash logs (%v MB), %v crashes\n", stats.Crashes, stats.Bytes>>20, stats.Bugs)
+		return
+	}
+	query, err := manager.ParseCrashQuery(*flagTitle, *flagType, *flagRepro, *flagSince, *flagUntil, *flagSyscall)
+	if err != nil {
+		tool.Fail(err)
+	}
+	list, err := store.Query(query)
+	if err != nil {
+		tool.Fail(err)
+	}
+	if *flagJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(list); err != nil {
+			tool.Fail(err)
+		}
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\tCRASHES\tLAST TIME\tREPRO\tTITLE\n")
+	for _, bug := range list {
+		repro := "-"
+		if bug.HasCRepro {
+			repro = "C"
+		} else if bug.HasRepro {
+			repro = "syz"
+		}
+		last := "-"
+		if len(bug.Crashes) != 0 {
+			last = bug.Crashes[0].Time.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", bug.ID, len(bug.Crashes), last, repro, bug.Title)
+	}
+	w.Flush()
+}
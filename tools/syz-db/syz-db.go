@@ -4,6 +4,8 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -79,6 +81,16 @@ func main() {
 			usage()
 		}
 		rm(args[1], args[2], target)
+	case "export":
+		if len(args) != 3 || target == nil {
+			usage()
+		}
+		export(args[1], args[2], target)
+	case "import":
+		if len(args) != 3 || target == nil {
+			usage()
+		}
+		importJSON(args[1], args[2], target)
 	default:
 		usage()
 	}
@@ -106,6 +118,10 @@ offered:
     syz-db print corpus.db
   remove a syscall from db
     syz-db rm corpus.db syscall_name
+  export db programs in JSON format (one program per line, see docs/program_syntax.md):
+    syz-db export corpus.db corpus.jsonl
+  import programs in JSON format into db (the db is created if it does not exist):
+    syz-db import corpus.jsonl corpus.db
 `)
 	os.Exit(1)
 }
@@ -276,3 +292,70 @@ func rm(file, syscall string, target *prog.Target) {
 		tool.Fail(err)
 	}
 }
+
+// jsonRecord is a line of the JSON corpus export.
+type jsonRecord struct {
+	Key  string
+	Seq  uint64
+	Prog *prog.JSONProg
+}
+
+func export(file, out string, target *prog.Target) {
+	db, err := db.Open(file, false)
+	if err != nil {
+		tool.Failf("failed to open database: %v", err)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		tool.Fail(err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	keys := maps.Keys(db.Records)
+	sort.Strings(keys)
+	for _, key := range keys {
+		rec := db.Records[key]
+		p, err := target.Deserialize(rec.Val, prog.NonStrict)
+		if err != nil {
+			tool.Failf("failed to deserialize %v: %v\n%s", key, err, rec.Val)
+		}
+		if err := enc.Encode(jsonRecord{Key: key, Seq: rec.Seq, Prog: p.ToJSON()}); err != nil {
+			tool.Fail(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tool.Fail(err)
+	}
+}
+
+func importJSON(in, file string, target *prog.Target) {
+	f, err := os.Open(in)
+	if err != nil {
+		tool.Fail(err)
+	}
+	defer f.Close()
+	dstDB, err := db.Open(file, false)
+	if err != nil {
+		tool.Failf("failed to open database: %v", err)
+	}
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for i := 1; dec.More(); i++ {
+		var rec jsonRecord
+		if err := dec.Decode(&rec); err != nil {
+			tool.Failf("failed to parse record #%v: %v", i, err)
+		}
+		if rec.Prog == nil {
+			tool.Failf("record #%v has no program", i)
+		}
+		p, err := target.FromJSON(rec.Prog, prog.NonStrict)
+		if err != nil {
+			tool.Failf("failed to deserialize record #%v: %v", i, err)
+		}
+		data := p.Serialize()
+		dstDB.Save(hash.String(data), data, rec.Seq)
+	}
+	if err := dstDB.Flush(); err != nil {
+		tool.Failf("failed to save db: %v", err)
+	}
+}
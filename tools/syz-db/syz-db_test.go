@@ -7,14 +7,17 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/google/syzkaller/pkg/db"
+	"github.com/google/syzkaller/pkg/hash"
 	"github.com/google/syzkaller/pkg/osutil"
 	"github.com/google/syzkaller/prog"
 	"github.com/google/syzkaller/sys/targets"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDBRemoveMatchLine(t *testing.T) {
@@ -50,3 +53,30 @@ func TestDBRemoveMatchLine(t *testing.T) {
 	expected := fmt.Sprintf("%s\n", strings.Join(want, "\n"))
 	assert.Equal(t, expected, string(db1.Records["rm"].Val))
 }
+
+func TestDBExportImport(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.db")
+	db1, err := db.Open(src, false)
+	require.NoError(t, err)
+	progs := []string{
+		"r0 = open$dir(&(0x7f0000000000)='./file0\\x00', 0x161840, 0x162)\nclose(r0)\n",
+		"getpid()\n",
+	}
+	for i, data := range progs {
+		db1.Save(hash.String([]byte(data)), []byte(data), uint64(i))
+	}
+	require.NoError(t, db1.Flush())
+	target, err := prog.GetTarget(targets.Linux, targets.AMD64)
+	require.NoError(t, err)
+	jsonFile := filepath.Join(dir, "corpus.jsonl")
+	export(src, jsonFile, target)
+	lines, err := os.ReadFile(jsonFile)
+	require.NoError(t, err)
+	assert.Equal(t, len(progs), strings.Count(string(lines), "\n"))
+	dst := filepath.Join(dir, "dst.db")
+	importJSON(jsonFile, dst, target)
+	db2, err := db.Open(dst, false)
+	require.NoError(t, err)
+	assert.Equal(t, db1.Records, db2.Records)
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-descusage shows how a corpus uses the syscall descriptions: how often each syscall,
+// struct field, union option and flag value appears in the corpus programs.
+// This helps to find unreachable descriptions, union options and flag values that
+// are never generated. corpus.db does not contain triage information, so the credited
+// counts are always 0, use the /descusage manager page to see them.
+// Usage:
+//
+//	syz-descusage -corpus corpus.db [-enable call1,call2] [-html] > usage.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/db"
+	"github.com/google/syzkaller/pkg/html/pages"
+	"github.com/google/syzkaller/pkg/mgrconfig"
+	"github.com/google/syzkaller/pkg/tool"
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+)
+
+var (
+	flagOS     = flag.String("os", runtime.GOOS, "target os")
+	flagArch   = flag.String("arch", runtime.GOARCH, "target arch")
+	flagCorpus = flag.String("corpus", "", "name of the corpus file")
+	flagEnable = flag.String("enable", "", "comma-separated list of enabled syscalls (all by default)")
+	flagHTML   = flag.Bool("html", false, "output an HTML page instead of JSON")
+)
+
+func main() {
+	defer tool.Init()()
+	if *flagCorpus == "" {
+		tool.Failf("-corpus is required")
+	}
+	target, err := prog.GetTarget(*flagOS, *flagArch)
+	if err != nil {
+		tool.Fail(err)
+	}
+	var enabled map[*prog.Syscall]bool
+	if *flagEnable != "" {
+		ids, err := mgrconfig.ParseEnabledSyscalls(target, strings.Split(*flagEnable, ","), nil,
+			mgrconfig.AnyDescriptions)
+		if err != nil {
+			tool.Failf("failed to parse enabled syscalls: %v", err)
+		}
+		enabled = make(map[*prog.Syscall]bool)
+		for _, id := range ids {
+			enabled[target.Syscalls[id]] = true
+		}
+	}
+	corpus, err := db.ReadCorpus(*flagCorpus, target)
+	if err != nil {
+		tool.Failf("failed to read corpus: %v", err)
+	}
+	var inputs []prog.UsageInput
+	for _, p := range corpus {
+		inputs = append(inputs, prog.UsageInput{Prog: p})
+	}
+	usage := target.CalculateDescUsage(inputs, enabled)
+	if *flagHTML {
+		if err := pages.WriteDescUsagePage(os.Stdout, usage); err != nil {
+			tool.Fail(err)
+		}
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(usage); err != nil {
+		tool.Fail(err)
+	}
+}
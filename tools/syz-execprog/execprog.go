@@ -54,6 +54,9 @@ var (
 	flagSlowdown   = flag.Int("slowdown", 1, "execution slowdown caused by emulation/instrumentation")
 	flagUnsafe     = flag.Bool("unsafe", false, "use unsafe program deserialization mode")
 	flagGlob       = flag.String("glob", "", "run glob expansion request")
+	flagPinProcs   = flag.Bool("pin_procs", false, "prefer to execute each program in the proc specified in the log "+
+		"(modulo the number of procs), used to reproduce races between concurrently running programs; "+
+		"best-effort: the executor may still use another proc, e.g. while the proc is restarting")
 
 	// The in the stress mode resembles simple unguided fuzzer.
 	// This mode can be used as an intermediate step when porting syzkaller to a new OS,
@@ -138,7 +141,7 @@ func main() {
 		exec |= flatrpc.ExecFlagDedupCover
 	}
 
-	progs := loadPrograms(target, flag.Args())
+	progs, progProcs := loadPrograms(target, flag.Args())
 	if *flagGlob == "" && !*flagStress && len(progs) == 0 {
 		flag.Usage()
 		os.Exit(1)
@@ -148,6 +151,7 @@ func main() {
 		target:    target,
 		done:      done,
 		progs:     progs,
+		progProcs: progProcs,
 		globs:     strings.Split(*flagGlob, ":"),
 		rs:        rand.NewSource(time.Now().UnixNano()),
 		coverFile: *flagCoverFile,
@@ -192,6 +196,7 @@ type Context struct {
 	target      *prog.Target
 	done        func()
 	progs       []*prog.Prog
+	progProcs   []int
 	globs       []string
 	defaultOpts flatrpc.ExecOpts
 	choiceTable *prog.ChoiceTable
@@ -232,6 +237,7 @@ func (ctx *Context) Next() *queue.Request {
 		return req
 	}
 	var p *prog.Prog
+	var avoid []queue.ExecutorID
 	if ctx.stress {
 		p = ctx.createStressProg()
 	} else {
@@ -240,6 +246,9 @@ func (ctx *Context) Next() *queue.Request {
 			return nil
 		}
 		p = ctx.progs[idx]
+		if *flagPinProcs && ctx.progProcs[idx] >= 0 {
+			avoid = pinnedProcAvoid(ctx.progProcs[idx] % *flagProcs)
+		}
 	}
 	if ctx.output {
 		data := p.Serialize()
@@ -249,7 +258,8 @@ func (ctx *Context) Next() *queue.Request {
 	}
 
 	req := &queue.Request{
-		Prog: p,
+		Prog:  p,
+		Avoid: avoid,
 	}
 	if ctx.hints {
 		req.ExecOpts.ExecFlags |= flatrpc.ExecFlagCollectComps
@@ -396,8 +406,24 @@ func (ctx *Context) createStressProg() *prog.Prog {
 	return p
 }
 
-func loadPrograms(target *prog.Target, files []string) []*prog.Prog {
+// pinnedProcAvoid returns the executors to avoid to execute a request in the given proc.
+// Avoidance is only a preference: the executor ignores it if none of the allowed procs is running,
+// so a pinned program may occasionally be executed in another proc.
+func pinnedProcAvoid(proc int) []queue.ExecutorID {
+	var avoid []queue.ExecutorID
+	for i := 0; i < *flagProcs; i++ {
+		if i != proc {
+			avoid = append(avoid, queue.ExecutorID{Proc: i})
+		}
+	}
+	return avoid
+}
+
+// loadPrograms returns the programs and the procs they were executed in according to the logs
+// (-1 if the proc is unknown).
+func loadPrograms(target *prog.Target, files []string) ([]*prog.Prog, []int) {
 	var progs []*prog.Prog
+	var procs []int
 	mode := prog.NonStrict
 	if *flagUnsafe {
 		mode = prog.NonStrictUnsafe
@@ -410,6 +436,7 @@ func loadPrograms(target *prog.Target, files []string) []*prog.Prog {
 					continue
 				}
 				progs = append(progs, p)
+				procs = append(procs, -1)
 			}
 			continue
 		}
@@ -419,8 +446,9 @@ func loadPrograms(target *prog.Target, files []string) []*prog.Prog {
 		}
 		for _, entry := range target.ParseLog(data, mode) {
 			progs = append(progs, entry.P)
+			procs = append(procs, entry.Proc)
 		}
 	}
 	log.Logf(0, "parsed %v programs", len(progs))
-	return progs
+	return progs, procs
 }
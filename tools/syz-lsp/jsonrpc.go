@@ -0,0 +1,116 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// message is a JSON-RPC 2.0 request, response or notification.
+// Requests have both ID and Method, notifications have only Method, and responses have only ID.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *rpcError) Error() string {
+	return fmt.Sprintf("%v (code %v)", err.Message, err.Code)
+}
+
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeRequestFailed  = -32803
+)
+
+// conn reads and writes messages with the LSP base protocol framing
+// (Content-Length header followed by the JSON body).
+type conn struct {
+	r  *textproto.Reader
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{
+		r: textproto.NewReader(bufio.NewReader(r)),
+		w: bufio.NewWriter(w),
+	}
+}
+
+func (c *conn) read() (*message, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("bad Content-Length header %q", header.Get("Content-Length"))
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.r.R, data); err != nil {
+		return nil, err
+	}
+	msg := new(message)
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, &rpcError{codeParseError, err.Error()}
+	}
+	return msg, nil
+}
+
+func (c *conn) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.w, "Content-Length: %v\r\n\r\n", len(data))
+	c.w.Write(data)
+	return c.w.Flush()
+}
+
+func (c *conn) reply(id json.RawMessage, result any, err error) error {
+	msg := &message{ID: id}
+	if err != nil {
+		rerr, ok := err.(*rpcError)
+		if !ok {
+			rerr = &rpcError{codeRequestFailed, err.Error()}
+		}
+		msg.Error = rerr
+	} else {
+		// The result must be present in successful responses even if it's null.
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		msg.Result = data
+	}
+	return c.write(msg)
+}
+
+func (c *conn) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&message{Method: method, Params: data})
+}
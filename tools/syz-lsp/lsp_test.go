@@ -0,0 +1,329 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFiles = map[string]string{
+	"a.txt": `resource fd_test[int32]: -1
+
+test_open(file ptr[in, filename], flags flags[open_flags]) fd_test
+test_read(fd fd_test, buf ptr[out, test_buf], len bytesize[buf])
+test_missing()
+
+open_flags = TEST_RDONLY, TEST_WRONLY
+`,
+	"b.txt": `# Buffers.
+
+test_buf {
+	f0	int32
+	f1	array[int8, TEST_BUF_LEN]
+} [packed]
+`,
+	"a.txt.const": `arches = 32, 32_fork, 64, 64_fork, 64_fuzz
+SYS_test_open = 1
+SYS_test_read = 2
+TEST_RDONLY = 0
+TEST_WRONLY = 1
+TEST_BUF_LEN = 16, 32:8, 32_fork:8
+`,
+}
+
+type testClient struct {
+	t         *testing.T
+	conn      *conn
+	dir       string
+	id        int
+	responses chan *message
+	diags     chan *PublishDiagnosticsParams
+	served    chan error
+}
+
+// startServer runs the server on a copy of testFiles in sys/test dir.
+func startServer(t *testing.T) *testClient {
+	dir := filepath.Join(t.TempDir(), targets.TestOS)
+	require.NoError(t, osutil.MkdirAll(dir))
+	for name, data := range testFiles {
+		require.NoError(t, osutil.WriteFile(filepath.Join(dir, name), []byte(data)))
+	}
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &testClient{
+		t:         t,
+		conn:      newConn(clientIn, clientOut),
+		dir:       dir,
+		responses: make(chan *message, 1),
+		diags:     make(chan *PublishDiagnosticsParams, 100),
+		served:    make(chan error, 1),
+	}
+	srv := newServer(serverIn, serverOut, targets.Linux, targets.TestArch64)
+	go func() {
+		c.served <- srv.serve()
+		serverOut.Close()
+	}()
+	go func() {
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			if msg.Method == "textDocument/publishDiagnostics" {
+				params := new(PublishDiagnosticsParams)
+				if err := json.Unmarshal(msg.Params, params); err != nil {
+					panic(err)
+				}
+				c.diags <- params
+			} else {
+				c.responses <- msg
+			}
+		}
+	}()
+	var res InitializeResult
+	require.NoError(t, c.call("initialize", &InitializeParams{RootURI: pathToURI(dir)}, &res))
+	assert.True(t, res.Capabilities.HoverProvider)
+	c.notify("initialized", struct{}{})
+	t.Cleanup(func() {
+		require.NoError(t, c.call("shutdown", nil, nil))
+		c.notify("exit", nil)
+		require.NoError(t, <-c.served)
+		clientOut.Close()
+	})
+	return c
+}
+
+func (c *testClient) uri(name string) string {
+	return pathToURI(filepath.Join(c.dir, name))
+}
+
+func (c *testClient) call(method string, params, result any) error {
+	c.id++
+	id := json.RawMessage(fmt.Sprint(c.id))
+	data, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	require.NoError(c.t, c.conn.write(&message{ID: id, Method: method, Params: data}))
+	msg := <-c.responses
+	require.Equal(c.t, string(id), string(msg.ID))
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if result != nil {
+		require.NoError(c.t, json.Unmarshal(msg.Result, result))
+	}
+	return nil
+}
+
+func (c *testClient) notify(method string, params any) {
+	require.NoError(c.t, c.conn.notify(method, params))
+}
+
+func (c *testClient) open(name string) {
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: c.uri(name), Version: 1, Text: testFiles[name]},
+	})
+}
+
+func (c *testClient) change(name, text string) {
+	params := &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: c.uri(name)},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+	}
+	c.notify("textDocument/didChange", params)
+}
+
+// diagnostics waits for diagnostics for the file, skipping stale ones that don't satisfy the predicate.
+func (c *testClient) diagnostics(name string, pred func([]Diagnostic) bool) []Diagnostic {
+	timeout := time.After(time.Minute)
+	for {
+		select {
+		case params := <-c.diags:
+			if params.URI == c.uri(name) && pred(params.Diagnostics) {
+				return params.Diagnostics
+			}
+		case <-timeout:
+			c.t.Fatalf("no diagnostics for %v", name)
+		}
+	}
+}
+
+func (c *testClient) position(name string, line int, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: c.uri(name)},
+		Position:     Position{line, character},
+	}
+}
+
+func nonEmpty(diags []Diagnostic) bool { return len(diags) != 0 }
+
+func TestDiagnostics(t *testing.T) {
+	c := startServer(t)
+	c.open("a.txt")
+	diags := c.diagnostics("a.txt", nonEmpty)
+	assert.Equal(t, []Diagnostic{{
+		Range:    Range{Position{4, 0}, Position{4, 12}},
+		Severity: severityWarning,
+		Source:   "syz-lsp",
+		Message:  "unsupported syscall: test_missing due to missing const SYS_test_missing",
+	}}, diags)
+
+	c.change("a.txt", strings.Replace(testFiles["a.txt"], "ptr[out, test_buf]", "ptr[out, test_buf2]", 1))
+	diags = c.diagnostics("a.txt", func(diags []Diagnostic) bool {
+		return len(diags) != 0 && diags[0].Severity == severityError
+	})
+	assert.Equal(t, []Diagnostic{{
+		Range:    Range{Position{3, 35}, Position{3, 44}},
+		Severity: severityError,
+		Source:   "syz-lsp",
+		Message:  "unknown type test_buf2",
+	}}, diags)
+
+	c.change("a.txt", strings.Replace(testFiles["a.txt"], "test_missing()\n", "test_missing(\n", 1))
+	diags = c.diagnostics("a.txt", func(diags []Diagnostic) bool {
+		return len(diags) != 0 && strings.Contains(diags[0].Message, "unexpected")
+	})
+	assert.Len(t, diags, 1)
+	assert.Equal(t, severityError, diags[0].Severity)
+	assert.Equal(t, 4, diags[0].Range.Start.Line)
+
+	// Once the file is fixed, the errors are cleared.
+	c.change("a.txt", strings.Replace(testFiles["a.txt"], "test_missing()\n", "", 1))
+	c.diagnostics("a.txt", func(diags []Diagnostic) bool { return len(diags) == 0 })
+}
+
+func TestHover(t *testing.T) {
+	c := startServer(t)
+	c.open("a.txt")
+	// Wait for the compilation to finish, so that sizes are known.
+	c.diagnostics("a.txt", nonEmpty)
+	hover := func(name string, line, character int) string {
+		var res *Hover
+		require.NoError(t, c.call("textDocument/hover", c.position(name, line, character), &res))
+		if res == nil {
+			return ""
+		}
+		return res.Contents.Value
+	}
+	assert.Equal(t, "```\ntest_buf {\n\tf0\tint32\n\tf1\tarray[int8, TEST_BUF_LEN]\n} [packed]\n```\n"+
+		"size: 20, align: 1\n", hover("a.txt", 3, 40))
+	assert.Equal(t, "```\nresource fd_test[int32]: -1\n```\nsize: 4, align: 4\n", hover("a.txt", 3, 15))
+	assert.Equal(t, "```\nopen_flags = TEST_RDONLY, TEST_WRONLY\n```\n"+
+		"- `TEST_RDONLY` = 0\n- `TEST_WRONLY` = 1\n", hover("a.txt", 6, 3))
+	assert.Equal(t, "`TEST_BUF_LEN`:\n- 32: 8\n- 32_fork: 8\n- 64: 16 (0x10)\n- 64_fork: 16 (0x10)\n"+
+		"- 64_fuzz: 16 (0x10)\n", hover("b.txt", 4, 20))
+	assert.Equal(t, "```\ntest_read(fd fd_test, buf ptr[out, test_buf], len bytesize[buf])\n```\n",
+		hover("a.txt", 3, 0))
+	assert.Equal(t, "", hover("a.txt", 3, 10))
+	assert.Equal(t, "", hover("a.txt", 1, 0))
+}
+
+func TestDefinitionReferences(t *testing.T) {
+	c := startServer(t)
+	var locs []Location
+	require.NoError(t, c.call("textDocument/definition", c.position("a.txt", 3, 15), &locs))
+	assert.Equal(t, []Location{{c.uri("a.txt"), Range{Position{0, 9}, Position{0, 16}}}}, locs)
+
+	require.NoError(t, c.call("textDocument/definition", c.position("a.txt", 3, 38), &locs))
+	assert.Equal(t, []Location{{c.uri("b.txt"), Range{Position{2, 0}, Position{2, 8}}}}, locs)
+
+	params := &ReferenceParams{TextDocumentPositionParams: c.position("a.txt", 0, 10)}
+	params.Context.IncludeDeclaration = true
+	require.NoError(t, c.call("textDocument/references", params, &locs))
+	assert.Equal(t, []Location{
+		{c.uri("a.txt"), Range{Position{0, 9}, Position{0, 16}}},
+		{c.uri("a.txt"), Range{Position{2, 59}, Position{2, 66}}},
+		{c.uri("a.txt"), Range{Position{3, 13}, Position{3, 20}}},
+	}, locs)
+
+	params = &ReferenceParams{TextDocumentPositionParams: c.position("b.txt", 4, 20)}
+	require.NoError(t, c.call("textDocument/references", params, &locs))
+	assert.Equal(t, []Location{{c.uri("b.txt"), Range{Position{4, 16}, Position{4, 28}}}}, locs)
+}
+
+func TestCompletion(t *testing.T) {
+	c := startServer(t)
+	complete := func(text string) []string {
+		c.change("b.txt", text)
+		lines := strings.Split(text, "\n")
+		line := len(lines) - 1
+		var res CompletionList
+		require.NoError(t, c.call("textDocument/completion", c.position("b.txt", line, len(lines[line])), &res))
+		var labels []string
+		for _, item := range res.Items {
+			labels = append(labels, item.Label)
+		}
+		return labels
+	}
+	assert.Equal(t, []string{"fd_test"}, complete("foo {\n\tf0\tfd_"))
+	assert.Equal(t, []string{"int16", "int16be", "int32", "int32be", "int64", "int64be", "int8", "intptr"},
+		complete("foo {\n\tf0\tptr[in, int"))
+	assert.Equal(t, []string{"optional", "open_flags"}, complete("foo(a flags[op"))
+	assert.Equal(t, []string{"packed"}, complete("foo {\n\tf0\tint8\n} [pa"))
+	assert.Equal(t, []string{"varlen"}, complete("foo [\n\tf0\tint8\n] [v"))
+	assert.Equal(t, []string{"if", "in", "inout"}, complete("foo {\n\tf0\tint8\t(i"))
+	assert.Equal(t, []string{"disabled"}, complete("foo(a int8) fd_test (dis"))
+	assert.Equal(t, []string{"ignore_return"}, complete("foo(a int8) (timeout[10], ign"))
+}
+
+func TestFormatting(t *testing.T) {
+	c := startServer(t)
+	var edits []TextEdit
+	require.NoError(t, c.call("textDocument/formatting", &DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: c.uri("b.txt")},
+	}, &edits))
+	assert.Empty(t, edits)
+
+	c.change("b.txt", "# Buffers.\n\n\n\ntest_buf   {\n  f0 int32\n f1\tarray[int8,TEST_BUF_LEN]\n}   [packed]")
+	require.NoError(t, c.call("textDocument/formatting", &DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: c.uri("b.txt")},
+	}, &edits))
+	assert.Equal(t, []TextEdit{{
+		Range:   Range{End: Position{7, 12}},
+		NewText: testFiles["b.txt"],
+	}}, edits)
+
+	c.change("b.txt", "test_buf {")
+	err := c.call("textDocument/formatting", &DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: c.uri("b.txt")},
+	}, &edits)
+	assert.ErrorContains(t, err, "syntax errors")
+}
+
+func TestProtocol(t *testing.T) {
+	c := startServer(t)
+	err := c.call("textDocument/foo", struct{}{}, nil)
+	assert.Equal(t, &rpcError{codeMethodNotFound, `method "textDocument/foo" is not supported`}, err)
+	err = c.call("textDocument/hover", []int{1}, nil)
+	assert.ErrorContains(t, err, "cannot unmarshal")
+	err = c.call("textDocument/hover", c.position("c.txt", 0, 0), nil)
+	assert.ErrorContains(t, err, "unknown document")
+}
+
+func TestPositions(t *testing.T) {
+	f := &file{lines: [][]byte{[]byte("# Привет 🙂 foo_bar baz")}}
+	word, rng := f.wordAt(Position{0, 14})
+	assert.Equal(t, "foo_bar", word)
+	assert.Equal(t, Range{Position{0, 12}, Position{0, 19}}, rng)
+	assert.Equal(t, "foo_bar", string(f.lines[0][byteOffset(f.lines[0], 12):byteOffset(f.lines[0], 19)]))
+
+	path := filepath.Join(t.TempDir(), "a b", "c.txt")
+	uri := pathToURI(path)
+	assert.True(t, strings.HasPrefix(uri, "file:///"), uri)
+	path1, err := uriToPath(uri)
+	require.NoError(t, err)
+	assert.Equal(t, path, path1)
+	_, err = uriToPath("untitled:Untitled-1")
+	assert.Error(t, err)
+}
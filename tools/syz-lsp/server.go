@@ -0,0 +1,402 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/pkg/log"
+)
+
+type server struct {
+	conn      *conn
+	defaultOS string
+	arch      string
+	done      chan struct{}
+
+	mu         sync.Mutex
+	workspaces map[string]*workspace
+	shutdown   bool
+}
+
+func newServer(in io.Reader, out io.Writer, defaultOS, arch string) *server {
+	return &server{
+		conn:       newConn(in, out),
+		defaultOS:  defaultOS,
+		arch:       arch,
+		done:       make(chan struct{}),
+		workspaces: make(map[string]*workspace),
+	}
+}
+
+var errExit = errors.New("exit")
+
+// serve handles messages until the exit notification or the end of the input.
+// It returns nil if the client has properly shut down the server.
+func (s *server) serve() error {
+	defer close(s.done)
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			var rerr *rpcError
+			if errors.As(err, &rerr) {
+				s.conn.reply(json.RawMessage("null"), nil, rerr)
+				continue
+			}
+			return err
+		}
+		if msg.Method == "" {
+			// A response to a request from the server, we don't send them.
+			continue
+		}
+		res, err := s.handle(msg)
+		if err == errExit {
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		}
+		if msg.ID == nil {
+			if err != nil {
+				log.Logf(0, "%v: %v", msg.Method, err)
+			}
+			continue
+		}
+		if err := s.conn.reply(msg.ID, res, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) notify(method string, params any) {
+	if err := s.conn.notify(method, params); err != nil {
+		log.Logf(0, "failed to send %v: %v", method, err)
+	}
+}
+
+func (s *server) handle(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:           textDocumentSyncFull,
+				HoverProvider:              true,
+				DefinitionProvider:         true,
+				ReferencesProvider:         true,
+				DocumentFormattingProvider: true,
+				CompletionProvider: &CompletionOpts{
+					TriggerCharacters: []string{"[", "(", ","},
+				},
+			},
+			ServerInfo: ServerInfo{Name: "syz-lsp"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "exit":
+		return nil, errExit
+	case "textDocument/didOpen":
+		return handle(msg, func(params *DidOpenTextDocumentParams) (any, error) {
+			ws, path, err := s.workspace(params.TextDocument.URI)
+			if err != nil {
+				return nil, err
+			}
+			ws.update(path, []byte(params.TextDocument.Text), true)
+			return nil, nil
+		})
+	case "textDocument/didChange":
+		return handle(msg, func(params *DidChangeTextDocumentParams) (any, error) {
+			ws, path, err := s.workspace(params.TextDocument.URI)
+			if err != nil || len(params.ContentChanges) == 0 {
+				return nil, err
+			}
+			ws.update(path, []byte(params.ContentChanges[len(params.ContentChanges)-1].Text), true)
+			return nil, nil
+		})
+	case "textDocument/didClose":
+		return handle(msg, func(params *DidCloseTextDocumentParams) (any, error) {
+			ws, path, err := s.workspace(params.TextDocument.URI)
+			if err != nil {
+				return nil, err
+			}
+			ws.closeFile(path)
+			return nil, nil
+		})
+	case "textDocument/hover":
+		return handle(msg, s.hover)
+	case "textDocument/definition":
+		return handle(msg, s.definition)
+	case "textDocument/references":
+		return handle(msg, s.references)
+	case "textDocument/completion":
+		return handle(msg, s.completion)
+	case "textDocument/formatting":
+		return handle(msg, s.formatting)
+	}
+	if msg.ID == nil {
+		// Unknown notifications (e.g. initialized, $/cancelRequest) are ignored.
+		return nil, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("method %q is not supported", msg.Method)}
+}
+
+func handle[P any](msg *message, fn func(*P) (any, error)) (any, error) {
+	params := new(P)
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return nil, &rpcError{codeInvalidParams, err.Error()}
+	}
+	return fn(params)
+}
+
+// workspace returns the workspace of the document, creating it on first use.
+func (s *server) workspace(uri string) (*workspace, string, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	dir := filepath.Dir(path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws := s.workspaces[dir]
+	if ws == nil {
+		if ws, err = newWorkspace(s, dir); err != nil {
+			return nil, "", err
+		}
+		s.workspaces[dir] = ws
+	}
+	return ws, path, nil
+}
+
+// document returns the workspace and the file of the document, the workspace is locked.
+func (s *server) document(uri string) (*workspace, *file, error) {
+	ws, path, err := s.workspace(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws.mu.Lock()
+	f := ws.files[path]
+	if f == nil {
+		ws.mu.Unlock()
+		return nil, nil, fmt.Errorf("unknown document %v", uri)
+	}
+	return ws, f, nil
+}
+
+func (s *server) hover(params *TextDocumentPositionParams) (any, error) {
+	ws, f, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.mu.Unlock()
+	word, rng := f.wordAt(params.Position)
+	if word == "" {
+		return nil, nil
+	}
+	var text strings.Builder
+	for _, decl := range ws.index().decls[word] {
+		fmt.Fprintf(&text, "```\n%v```\n", ast.SerializeNode(decl))
+		ws.describeDecl(&text, decl)
+	}
+	if values := ws.constValues(word); len(values) != 0 {
+		text.WriteString(formatConst(word, values))
+	}
+	if text.Len() == 0 {
+		return nil, nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: text.String()},
+		Range:    &rng,
+	}, nil
+}
+
+func (ws *workspace) describeDecl(w io.Writer, decl ast.Node) {
+	switch n := decl.(type) {
+	case *ast.Struct, *ast.Resource:
+		_, _, name := n.Info()
+		typ := ws.types[name]
+		if typ == nil {
+			return
+		}
+		if typ.Varlen() {
+			fmt.Fprintf(w, "size: varlen\n")
+		} else {
+			fmt.Fprintf(w, "size: %v, align: %v\n", typ.Size(), typ.Alignment())
+		}
+		if res := ws.resources[name]; res != nil && len(res.Kind) > 1 {
+			fmt.Fprintf(w, "\nkind: %v\n", strings.Join(res.Kind, " > "))
+		}
+	case *ast.IntFlags:
+		for _, v := range n.Values {
+			if values := ws.constValues(v.Ident); len(values) != 0 {
+				fmt.Fprintf(w, "- %v", formatConst(v.Ident, values))
+			}
+		}
+	}
+}
+
+// constValues returns values of the const for all arches that have it.
+func (ws *workspace) constValues(name string) map[string]uint64 {
+	values := make(map[string]uint64)
+	for arch, consts := range ws.consts {
+		if v, ok := consts[name]; ok {
+			values[arch] = v
+		}
+	}
+	return values
+}
+
+func (s *server) definition(params *TextDocumentPositionParams) (any, error) {
+	ws, f, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.mu.Unlock()
+	word, _ := f.wordAt(params.Position)
+	locs := []Location{}
+	for _, decl := range ws.index().decls[word] {
+		locs = append(locs, ws.location(declName(decl).Pos))
+	}
+	return locs, nil
+}
+
+func (s *server) references(params *ReferenceParams) (any, error) {
+	ws, f, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.mu.Unlock()
+	word, _ := f.wordAt(params.Position)
+	idx := ws.index()
+	locs := []Location{}
+	if params.Context.IncludeDeclaration {
+		for _, decl := range idx.decls[word] {
+			locs = append(locs, ws.location(declName(decl).Pos))
+		}
+	}
+	for _, pos := range idx.refs[word] {
+		locs = append(locs, ws.location(pos))
+	}
+	return locs, nil
+}
+
+func (ws *workspace) location(pos ast.Pos) Location {
+	return ws.files[pos.File].location(pos)
+}
+
+var (
+	// The cursor is in attributes of a struct (after the closing brace) or of a union.
+	structAttrsRe = regexp.MustCompile(`^[}\]]\s*\[[^\]]*$`)
+	// The cursor is in attributes of a syscall (after the arguments and the return type).
+	callAttrsRe = regexp.MustCompile(`^[a-zA-Z0-9_$]+\(.*\)[^()]*\([^)]*$`)
+	// The cursor is in attributes of a struct field.
+	fieldAttrsRe = regexp.MustCompile(`^\s+[a-zA-Z0-9_]+\s+.*\([^)]*$`)
+)
+
+func (s *server) completion(params *TextDocumentPositionParams) (any, error) {
+	ws, f, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.mu.Unlock()
+	line := f.line(params.Position.Line)
+	prefix := line[:byteOffset(line, params.Position.Character)]
+	start := len(prefix)
+	for start > 0 && isIdentChar(prefix[start-1]) {
+		start--
+	}
+	word := string(prefix[start:])
+	prefix = prefix[:start]
+	res := &CompletionList{
+		// The client needs to ask again as the prefix changes, since we filter by the prefix.
+		IsIncomplete: true,
+		Items:        []CompletionItem{},
+	}
+	add := func(name string, kind int, detail string) {
+		if strings.HasPrefix(name, word) {
+			res.Items = append(res.Items, CompletionItem{Label: name, Kind: kind, Detail: detail})
+		}
+	}
+	addAttrs := func(names []string, detail string) {
+		for _, name := range names {
+			add(name, completionProperty, detail)
+		}
+	}
+	attrs := compiler.Attrs()
+	switch {
+	case structAttrsRe.Match(prefix):
+		if prefix[0] == '}' {
+			addAttrs(attrs.Struct, "struct attribute")
+		} else {
+			addAttrs(attrs.Union, "union attribute")
+		}
+	case callAttrsRe.Match(prefix):
+		addAttrs(attrs.Call, "syscall attribute")
+	case fieldAttrsRe.Match(prefix):
+		addAttrs(mergeNames(attrs.StructField, attrs.UnionField), "field attribute")
+	default:
+		for _, name := range compiler.BuiltinTypes() {
+			add(name, completionKeyword, "builtin type")
+		}
+		idx := ws.index()
+		for _, name := range idx.names {
+			kind, detail := completionStruct, "struct"
+			switch decl := idx.decls[name][0].(type) {
+			case *ast.Struct:
+				if decl.IsUnion {
+					detail = "union"
+				}
+			case *ast.Resource:
+				kind, detail = completionClass, "resource"
+			case *ast.TypeDef:
+				kind, detail = completionTypeParameter, "type"
+			case *ast.IntFlags, *ast.StrFlags:
+				kind, detail = completionEnum, "flags"
+			}
+			add(name, kind, detail)
+		}
+	}
+	return res, nil
+}
+
+func mergeNames(a, b []string) []string {
+	seen := make(map[string]bool)
+	var res []string
+	for _, name := range append(a, b...) {
+		if !seen[name] {
+			seen[name] = true
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (s *server) formatting(params *DocumentFormattingParams) (any, error) {
+	ws, f, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.mu.Unlock()
+	if f.desc == nil {
+		return nil, fmt.Errorf("can't format %v: it has syntax errors", filepath.Base(f.path))
+	}
+	formatted := ast.Format(f.desc)
+	if string(formatted) == string(f.text) {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{
+		Range:   Range{End: f.endPosition()},
+		NewText: string(formatted),
+	}}, nil
+}
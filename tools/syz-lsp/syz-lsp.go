@@ -0,0 +1,42 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-lsp is a language server for syzlang descriptions (sys/*/*.txt).
+// It communicates with the editor over stdin/stdout using the Language Server Protocol and provides:
+//   - diagnostics: syntax errors, compiler errors and warnings;
+//   - hover: declarations, sizes of structs/unions/resources and values of consts from .const files;
+//   - go-to-definition and find-references for types, resources, flags and consts;
+//   - completion of type names and attributes;
+//   - formatting (the same as syz-fmt).
+//
+// All .txt files in the directory of an opened file are analyzed together. The OS is inferred from
+// the directory name (sys/OS), and the descriptions are compiled for the -arch architecture.
+//
+// For example, to use it with Neovim:
+//
+//	vim.lsp.start({name = "syz-lsp", cmd = {"syz-lsp"}, root_dir = vim.fs.dirname(vim.api.nvim_buf_get_name(0))})
+package main
+
+import (
+	"flag"
+	"os"
+	"runtime"
+
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/pkg/tool"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+var (
+	flagOS   = flag.String("os", targets.Linux, "OS of descriptions outside of sys/OS dirs")
+	flagArch = flag.String("arch", runtime.GOARCH, "arch to compile descriptions for")
+)
+
+func main() {
+	defer tool.Init()()
+	log.SetName("syz-lsp")
+	srv := newServer(os.Stdin, os.Stdout, *flagOS, *flagArch)
+	if err := srv.serve(); err != nil {
+		log.Fatal(err)
+	}
+}
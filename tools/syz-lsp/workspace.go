@@ -0,0 +1,481 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+// workspace contains all descriptions of one OS (e.g. sys/linux/*.txt).
+// The descriptions are parsed on each change, while compilation is done in the background
+// since it takes few seconds for large OSes.
+type workspace struct {
+	srv    *server
+	dir    string
+	target *targets.Target
+	// consts are values of consts for each arch of the OS.
+	consts map[string]map[string]uint64
+
+	mu    sync.Mutex
+	files map[string]*file
+	// idx is rebuilt lazily after changes.
+	idx *index
+	// types and resources are from the last successful compilation.
+	types     map[string]prog.Type
+	resources map[string]*prog.ResourceDesc
+	// published are files we published non-empty diagnostics for.
+	published  map[string]bool
+	compileReq chan struct{}
+}
+
+type file struct {
+	path string
+	text []byte
+	// lines are text split by new lines.
+	lines [][]byte
+	open  bool
+	// desc is nil if the file has parse errors.
+	desc      *ast.Description
+	parseErrs []posMsg
+}
+
+type posMsg struct {
+	pos ast.Pos
+	msg string
+}
+
+func newWorkspace(srv *server, dir string) (*workspace, error) {
+	// Descriptions of each OS are in sys/OS.
+	OS := filepath.Base(dir)
+	if targets.List[OS] == nil {
+		OS = srv.defaultOS
+	}
+	target := chooseTarget(OS, srv.arch)
+	if target == nil {
+		return nil, fmt.Errorf("unknown OS %q", OS)
+	}
+	ws := &workspace{
+		srv:        srv,
+		dir:        dir,
+		target:     target,
+		files:      make(map[string]*file),
+		published:  make(map[string]bool),
+		compileReq: make(chan struct{}, 1),
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ws.setFile(path, text, false)
+	}
+	constFile := compiler.NewConstFile()
+	if consts, _ := filepath.Glob(filepath.Join(dir, "*.const")); len(consts) != 0 {
+		constFile = compiler.DeserializeConstFile(filepath.Join(dir, "*.const"), func(pos ast.Pos, msg string) {
+			log.Logf(0, "%v: %v", pos, msg)
+		})
+		if constFile == nil {
+			return nil, fmt.Errorf("failed to parse const files in %v", dir)
+		}
+	}
+	ws.consts = make(map[string]map[string]uint64)
+	for arch := range targets.List[target.OS] {
+		ws.consts[arch] = constFile.Arch(arch)
+	}
+	log.Logf(0, "loaded %v files for %v/%v from %v", len(ws.files), target.OS, target.Arch, dir)
+	go ws.compileLoop()
+	ws.requestCompile()
+	return ws, nil
+}
+
+// chooseTarget returns the target for the arch, or the first 64-bit arch of the OS if it does not have the arch.
+func chooseTarget(OS, arch string) *targets.Target {
+	archs := targets.List[OS]
+	if target := archs[arch]; target != nil {
+		return target
+	}
+	var names []string
+	for name := range archs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if archs[name].PtrSize == 8 {
+			return archs[name]
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return archs[names[0]]
+}
+
+// setFile updates contents of the file, the caller must hold the mutex or own the workspace.
+func (ws *workspace) setFile(path string, text []byte, open bool) {
+	f := &file{
+		path:  path,
+		text:  text,
+		lines: bytes.Split(text, []byte{'\n'}),
+		open:  open,
+	}
+	f.desc = ast.Parse(text, path, func(pos ast.Pos, msg string) {
+		f.parseErrs = append(f.parseErrs, posMsg{pos, msg})
+	})
+	ws.files[path] = f
+	ws.idx = nil
+}
+
+func (ws *workspace) update(path string, text []byte, open bool) {
+	ws.mu.Lock()
+	ws.setFile(path, text, open)
+	ws.mu.Unlock()
+	ws.requestCompile()
+}
+
+// closeFile reverts the file to the contents on disk.
+func (ws *workspace) closeFile(path string) {
+	ws.mu.Lock()
+	text, err := os.ReadFile(path)
+	if err == nil {
+		ws.setFile(path, text, false)
+	} else {
+		delete(ws.files, path)
+		ws.idx = nil
+	}
+	ws.mu.Unlock()
+	ws.requestCompile()
+}
+
+func (ws *workspace) file(path string) *file {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.files[path]
+}
+
+func (ws *workspace) requestCompile() {
+	select {
+	case ws.compileReq <- struct{}{}:
+	default:
+	}
+}
+
+func (ws *workspace) compileLoop() {
+	for {
+		select {
+		case <-ws.srv.done:
+			return
+		case <-ws.compileReq:
+		}
+		ws.compile()
+	}
+}
+
+// compile compiles all descriptions and publishes diagnostics.
+// If there are parse errors, only parse errors are published.
+func (ws *workspace) compile() {
+	ws.mu.Lock()
+	files := make(map[string]*file)
+	var paths []string
+	for path, f := range ws.files {
+		files[path] = f
+		paths = append(paths, path)
+	}
+	ws.mu.Unlock()
+	sort.Strings(paths)
+	var msgs []posMsg
+	desc := new(ast.Description)
+	for _, path := range paths {
+		f := files[path]
+		msgs = append(msgs, f.parseErrs...)
+		if f.desc != nil {
+			desc.Nodes = append(desc.Nodes, f.desc.Nodes...)
+		}
+	}
+	severity := severityError
+	var types map[string]prog.Type
+	var resources map[string]*prog.ResourceDesc
+	if len(msgs) == 0 {
+		prg := compiler.Compile(desc, ws.consts[ws.target.Arch], ws.target, func(pos ast.Pos, msg string) {
+			msgs = append(msgs, posMsg{pos, msg})
+		})
+		// On success the compiler reports only warnings.
+		if prg != nil {
+			severity = severityWarning
+			types = make(map[string]prog.Type)
+			for _, typ := range prg.Types {
+				switch typ.(type) {
+				case *prog.StructType, *prog.UnionType, *prog.ResourceType:
+					types[typ.Name()] = typ
+				}
+			}
+			resources = make(map[string]*prog.ResourceDesc)
+			for _, res := range prg.Resources {
+				resources[res.Name] = res
+			}
+		}
+	}
+	diags := make(map[string][]Diagnostic)
+	for _, msg := range msgs {
+		f := files[msg.pos.File]
+		if f == nil {
+			log.Logf(0, "%v: %v", msg.pos, msg.msg)
+			continue
+		}
+		diags[f.path] = append(diags[f.path], Diagnostic{
+			Range:    f.identRange(msg.pos),
+			Severity: severity,
+			Source:   "syz-lsp",
+			Message:  msg.msg,
+		})
+	}
+	ws.mu.Lock()
+	if types != nil {
+		ws.types, ws.resources = types, resources
+	}
+	for path := range ws.published {
+		if diags[path] == nil {
+			diags[path] = []Diagnostic{}
+		}
+	}
+	ws.published = make(map[string]bool)
+	for path, list := range diags {
+		if len(list) != 0 {
+			ws.published[path] = true
+		}
+	}
+	ws.mu.Unlock()
+	paths = paths[:0]
+	for path := range diags {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		ws.srv.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         pathToURI(path),
+			Diagnostics: diags[path],
+		})
+	}
+}
+
+// index contains declarations and references of all named entities of the workspace.
+type index struct {
+	decls map[string][]ast.Node
+	refs  map[string][]ast.Pos
+	// names are sorted names of types that can be referenced from other types.
+	names []string
+}
+
+// index returns the index of the workspace, the caller must hold the mutex.
+func (ws *workspace) index() *index {
+	if ws.idx != nil {
+		return ws.idx
+	}
+	idx := &index{
+		decls: make(map[string][]ast.Node),
+		refs:  make(map[string][]ast.Pos),
+	}
+	addRef := func(name string, pos ast.Pos) {
+		if name != "" {
+			idx.refs[name] = append(idx.refs[name], pos)
+		}
+	}
+	for _, f := range ws.files {
+		if f.desc == nil {
+			continue
+		}
+		for _, n := range f.desc.Nodes {
+			if name := declName(n); name != nil {
+				idx.decls[name.Name] = append(idx.decls[name.Name], n)
+			}
+			ast.Recursive(func(n ast.Node) bool {
+				switch t := n.(type) {
+				case *ast.Type:
+					addRef(t.Ident, t.Pos)
+					for _, col := range t.Colon {
+						addRef(col.Ident, col.Pos)
+					}
+				case *ast.Int:
+					addRef(t.Ident, t.Pos)
+				}
+				return true
+			})(n)
+		}
+	}
+	for name, decls := range idx.decls {
+		switch decls[0].(type) {
+		case *ast.Resource, *ast.Struct, *ast.TypeDef, *ast.IntFlags, *ast.StrFlags:
+			idx.names = append(idx.names, name)
+		}
+	}
+	sort.Strings(idx.names)
+	for _, refs := range idx.refs {
+		sort.Slice(refs, func(i, j int) bool {
+			return refs[i].File < refs[j].File || refs[i].File == refs[j].File && refs[i].Off < refs[j].Off
+		})
+	}
+	ws.idx = idx
+	return idx
+}
+
+func declName(n ast.Node) *ast.Ident {
+	switch n := n.(type) {
+	case *ast.Resource:
+		return n.Name
+	case *ast.Struct:
+		return n.Name
+	case *ast.TypeDef:
+		return n.Name
+	case *ast.IntFlags:
+		return n.Name
+	case *ast.StrFlags:
+		return n.Name
+	case *ast.Call:
+		return n.Name
+	case *ast.Define:
+		return n.Name
+	}
+	return nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (f *file) line(line int) []byte {
+	if line < 0 || line >= len(f.lines) {
+		return nil
+	}
+	return f.lines[line]
+}
+
+// identRange returns the range of the identifier at pos, or of a single character if there is no identifier.
+func (f *file) identRange(pos ast.Pos) Range {
+	line := f.line(pos.Line - 1)
+	start := min(max(pos.Col-1, 0), len(line))
+	end := start
+	for end < len(line) && isIdentChar(line[end]) {
+		end++
+	}
+	if end == start && end < len(line) {
+		end++
+	}
+	return Range{
+		Start: Position{pos.Line - 1, utf16Len(line[:start])},
+		End:   Position{pos.Line - 1, utf16Len(line[:end])},
+	}
+}
+
+// wordAt returns the identifier at the position and its range.
+func (f *file) wordAt(pos Position) (string, Range) {
+	line := f.line(pos.Line)
+	col := byteOffset(line, pos.Character)
+	start, end := col, col
+	for start > 0 && isIdentChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdentChar(line[end]) {
+		end++
+	}
+	return string(line[start:end]), Range{
+		Start: Position{pos.Line, utf16Len(line[:start])},
+		End:   Position{pos.Line, utf16Len(line[:end])},
+	}
+}
+
+// endPosition returns the position past the last character of the file.
+func (f *file) endPosition() Position {
+	return Position{len(f.lines) - 1, utf16Len(f.lines[len(f.lines)-1])}
+}
+
+func (f *file) location(pos ast.Pos) Location {
+	return Location{
+		URI:   pathToURI(f.path),
+		Range: f.identRange(pos),
+	}
+}
+
+// utf16Len returns length of the UTF-8 text in UTF-16 code units.
+func utf16Len(text []byte) int {
+	n := 0
+	for len(text) != 0 {
+		r, size := utf8.DecodeRune(text)
+		n += utf16.RuneLen(r)
+		text = text[size:]
+	}
+	return n
+}
+
+// byteOffset converts UTF-16 offset in the line to byte offset.
+func byteOffset(line []byte, character int) int {
+	off := 0
+	for n := 0; off < len(line) && n < character; {
+		r, size := utf8.DecodeRune(line[off:])
+		n += utf16.RuneLen(r)
+		off += size
+	}
+	return off
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI %q", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+func pathToURI(path string) string {
+	u := &url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+func formatConst(name string, values map[string]uint64) string {
+	var archs []string
+	for arch := range values {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+	same := true
+	for _, arch := range archs {
+		same = same && values[arch] == values[archs[0]]
+	}
+	if same {
+		return fmt.Sprintf("`%v` = %v\n", name, formatValue(values[archs[0]]))
+	}
+	var res strings.Builder
+	fmt.Fprintf(&res, "`%v`:\n", name)
+	for _, arch := range archs {
+		fmt.Fprintf(&res, "- %v: %v\n", arch, formatValue(values[arch]))
+	}
+	return res.String()
+}
+
+func formatValue(v uint64) string {
+	if v < 10 {
+		return fmt.Sprint(v)
+	}
+	return fmt.Sprintf("%v (0x%x)", v, v)
+}
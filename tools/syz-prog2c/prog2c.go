@@ -8,9 +8,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/google/syzkaller/pkg/csource"
+	"github.com/google/syzkaller/pkg/osutil"
 	"github.com/google/syzkaller/prog"
 	_ "github.com/google/syzkaller/sys"
 )
@@ -35,6 +38,14 @@ var (
 	flagDisable    = flag.String("disable", "none", "enable all additional features except listed")
 )
 
+var (
+	flagKselftest = flag.String("kselftest", "", "generate a kselftest regression test with the given name, "+
+		"the test source, Makefile lines and settings are written to the -kselftest_dir directory")
+	flagKselftestDir      = flag.String("kselftest_dir", ".", "output directory for -kselftest")
+	flagKselftestDuration = flag.Duration("kselftest_duration", 30*time.Second, "run time of the -kselftest test")
+	flagTitle             = flag.String("title", "", "title of the bug the program reproduces (for -kselftest)")
+)
+
 func main() {
 	flag.Usage = func() {
 		flag.PrintDefaults()
@@ -97,6 +108,10 @@ func main() {
 		Trace:         *flagTrace,
 		CallComments:  true,
 	}
+	if *flagKselftest != "" {
+		writeKselftest(p, opts)
+		return
+	}
 	src, err := csource.Write(p, opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to generate C source: %v\n", err)
@@ -119,3 +134,40 @@ func main() {
 	os.Remove(bin)
 	fmt.Fprintf(os.Stderr, "binary build OK\n")
 }
+
+func writeKselftest(p *prog.Prog, opts csource.Options) {
+	test, err := csource.WriteKselftest(p, opts, csource.KselftestParams{
+		Name:     *flagKselftest,
+		Title:    *flagTitle,
+		Duration: *flagKselftestDuration,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate kselftest: %v\n", err)
+		os.Exit(1)
+	}
+	if formatted, err := csource.Format(test.Source); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	} else {
+		test.Source = formatted
+	}
+	writeFile(*flagKselftest+".c", test.Source)
+	writeFile(*flagKselftest+".mk", test.Makefile)
+	if test.Settings != nil {
+		writeFile(*flagKselftest+".settings", test.Settings)
+	}
+	fmt.Fprintf(os.Stderr, "copy %v.c into a tools/testing/selftests subdirectory and add %v.mk to its Makefile\n",
+		*flagKselftest, *flagKselftest)
+	if test.Settings != nil {
+		fmt.Fprintf(os.Stderr, "the test needs a longer timeout, add %v.settings to the settings file\n",
+			*flagKselftest)
+	}
+}
+
+func writeFile(name string, data []byte) {
+	file := filepath.Join(*flagKselftestDir, name)
+	if err := osutil.WriteFile(file, data); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "written %v\n", file)
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-progdiff prints the structural difference between two programs:
+// calls are aligned by syscall and resources are matched semantically,
+// so resource renumbering and shifted addresses are not reported.
+// Usage:
+//
+//	syz-progdiff [-html] old.prog new.prog
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/google/syzkaller/pkg/html/pages"
+	"github.com/google/syzkaller/pkg/tool"
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+)
+
+var (
+	flagOS   = flag.String("os", runtime.GOOS, "target os")
+	flagArch = flag.String("arch", runtime.GOARCH, "target arch")
+	flagHTML = flag.Bool("html", false, "output the diff as an HTML page")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: syz-progdiff [flags] old.prog new.prog\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	target, err := prog.GetTarget(*flagOS, *flagArch)
+	if err != nil {
+		tool.Fail(err)
+	}
+	a := loadProg(target, flag.Arg(0))
+	b := loadProg(target, flag.Arg(1))
+	d := prog.Diff(a, b)
+	if *flagHTML {
+		if err := pages.WriteProgDiffPage(os.Stdout, d); err != nil {
+			tool.Fail(err)
+		}
+		return
+	}
+	fmt.Print(d)
+}
+
+func loadProg(target *prog.Target, file string) *prog.Prog {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		tool.Failf("failed to read %v: %v", file, err)
+	}
+	p, err := target.Deserialize(data, prog.NonStrict)
+	if err != nil {
+		tool.Failf("failed to deserialize %v: %v", file, err)
+	}
+	return p
+}
@@ -28,6 +28,8 @@ var (
 	flagCRepro = flag.String("crepro", filepath.Join(".", "repro.c"), "output c file (repro.c)")
 	flagTitle  = flag.String("title", "", "where to save the title of the reproduced bug")
 	flagStrace = flag.String("strace", "", "output strace log (strace_bin must be set)")
+	flagRuns   = flag.Int("reliability_runs", 0, "number of validation runs of the reproducers "+
+		"(overrides config repro_reliability_runs param)")
 )
 
 func main() {
@@ -60,6 +62,10 @@ func main() {
 	if *flagCount > 0 {
 		count = *flagCount
 	}
+	reliabilityRuns := cfg.ReproReliabilityRuns
+	if *flagRuns > 0 {
+		reliabilityRuns = *flagRuns
+	}
 	pool := vm.NewDispatcher(vmPool, nil)
 	pool.ReserveForRun(count)
 
@@ -72,6 +78,9 @@ func main() {
 			Features: flatrpc.AllFeatures,
 			Reporter: reporter,
 			Pool:     pool,
+			Parallel: count,
+
+			ReliabilityRuns: reliabilityRuns,
 		})
 		if err != nil {
 			log.Logf(0, "reproduction failed: %v", err)
@@ -87,7 +96,12 @@ func main() {
 			return
 		}
 
-		fmt.Printf("opts: %+v crepro: %v\n\n", res.Opts, res.CRepro)
+		fmt.Printf("opts: %+v crepro: %v\n", res.Opts, res.CRepro)
+		fmt.Printf("syz reliability: %v\n", res.SyzReliability)
+		if res.CRepro {
+			fmt.Printf("C reliability: %v\n", res.CReliability)
+		}
+		fmt.Println()
 		progSerialized := res.Prog.Serialize()
 		fmt.Printf("%s\n", progSerialized)
 		if err = osutil.WriteFile(*flagOutput, progSerialized); err == nil {
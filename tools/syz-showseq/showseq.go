@@ -0,0 +1,147 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-showseq mines call sequences connected by resources from a corpus and visualizes them.
+// The mined model can be saved with -out and used by syz-manager (see sequence_model config parameter).
+//
+// Usage:
+//
+//	syz-showseq -corpus corpus.db -out sequences.json
+//	syz-showseq -model sequences.json -call listen
+//	syz-showseq -model sequences.json -dot | dot -Tsvg > sequences.svg
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/google/syzkaller/pkg/db"
+	"github.com/google/syzkaller/pkg/osutil"
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+)
+
+var (
+	flagOS         = flag.String("os", runtime.GOOS, "target os")
+	flagArch       = flag.String("arch", runtime.GOARCH, "target arch")
+	flagCorpus     = flag.String("corpus", "", "name of the corpus file to mine")
+	flagModel      = flag.String("model", "", "show a previously mined model instead of mining a corpus")
+	flagOut        = flag.String("out", "", "save the mined model to this file")
+	flagMaxLen     = flag.Int("max-len", prog.DefaultSequenceOpts.MaxLen, "max number of calls in a sequence")
+	flagMinSupport = flag.Int("min-support", prog.DefaultSequenceOpts.MinSupport,
+		"min number of programs that contain a sequence")
+	flagMaxSeqs = flag.Int("max-seqs", prog.DefaultSequenceOpts.MaxSequences, "max number of sequences in the model")
+	flagCall    = flag.String("call", "", "show only sequences that contain this call")
+	flagTop     = flag.Int("top", 100, "show only this number of the most frequent sequences (0 for all)")
+	flagDot     = flag.Bool("dot", false, "output the graph of the sequences in the graphviz dot format")
+)
+
+func main() {
+	flag.Parse()
+	if (*flagCorpus == "") == (*flagModel == "") {
+		fmt.Fprintf(os.Stderr, "specify either -corpus or -model\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	target, err := prog.GetTarget(*flagOS, *flagArch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	var model *prog.SequenceModel
+	if *flagModel != "" {
+		data, err := os.ReadFile(*flagModel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read model: %v\n", err)
+			os.Exit(1)
+		}
+		if model, err = target.ParseSequenceModel(data); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		corpus, err := db.ReadCorpus(*flagCorpus, target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read corpus: %v\n", err)
+			os.Exit(1)
+		}
+		model = target.MineSequences(corpus, prog.SequenceOpts{
+			MaxLen:       *flagMaxLen,
+			MinSupport:   *flagMinSupport,
+			MaxSequences: *flagMaxSeqs,
+		})
+	}
+	if *flagOut != "" {
+		if err := osutil.WriteFile(*flagOut, model.Serialize()); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write model: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	seqs := filterSequences(model.Sequences, *flagCall, *flagTop)
+	if *flagDot {
+		showDot(os.Stdout, seqs)
+	} else {
+		showSequences(os.Stdout, model, seqs)
+	}
+}
+
+func filterSequences(seqs []*prog.CallSequence, call string, top int) []*prog.CallSequence {
+	var res []*prog.CallSequence
+	for _, seq := range seqs {
+		if top != 0 && len(res) == top {
+			break
+		}
+		if call == "" || contains(seq.Calls, call) {
+			res = append(res, seq)
+		}
+	}
+	return res
+}
+
+func contains(list []string, val string) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+func showSequences(w io.Writer, model *prog.SequenceModel, seqs []*prog.CallSequence) {
+	fmt.Fprintf(w, "%v sequences mined from %v programs\n", len(model.Sequences), model.Programs)
+	fmt.Fprintf(w, "%-8v %-6v %v\n", "SUPPORT", "PROB", "SEQUENCE")
+	for _, seq := range seqs {
+		fmt.Fprintf(w, "%-8v %-6.2f %v\n", seq.Support, seq.Prob, seq)
+	}
+}
+
+// showDot outputs the graph of the resource flow between calls of the sequences.
+// Edges are labeled with the resource kind and the total support of the sequences that contain them.
+func showDot(w io.Writer, seqs []*prog.CallSequence) {
+	type edge struct {
+		from, to, kind string
+	}
+	edges := make(map[edge]int)
+	for _, seq := range seqs {
+		for i, call := range seq.Calls[1:] {
+			edges[edge{seq.Calls[seq.Deps[i]], call, seq.Resources[i]}] += seq.Support
+		}
+	}
+	var sorted []edge
+	for e := range edges {
+		sorted = append(sorted, e)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		return a.from < b.from || a.from == b.from && (a.to < b.to || a.to == b.to && a.kind < b.kind)
+	})
+	fmt.Fprintf(w, "digraph sequences {\n")
+	for _, e := range sorted {
+		fmt.Fprintf(w, "\t%q -> %q [label=%q];\n", e.from, e.to, fmt.Sprintf("%v (%v)", e.kind, edges[e]))
+	}
+	fmt.Fprintf(w, "}\n")
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/prog"
+)
+
+// ParseBpftrace parses output of the tools/syz-trace2syz/syscalls.bt script.
+// The output consists of the following lines:
+//
+//	enter <tid> <syscall nr> <arg0> <arg1> <arg2> <arg3> <arg4> <arg5>
+//	str <tid> <arg index> <string until the end of line>
+//	buf <tid> <arg index> <bytes with \xNN escapes>
+//	exit <tid> <return value>
+//
+// str and buf lines replace the corresponding arg of the call the thread is executing,
+// they may be printed on enter (input data) or before exit (output data).
+// Socket addresses passed to connect and bind are decoded the same way strace decodes them.
+func ParseBpftrace(data []byte, target *prog.Target) (*TraceTree, error) {
+	et := newEventTrace(target)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "Attaching ") {
+			continue
+		}
+		if err := et.parseBpftraceLine(line); err != nil {
+			return nil, fmt.Errorf("failed to parse line: %v: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return et.finish(), nil
+}
+
+func (et *eventTrace) parseBpftraceLine(line string) error {
+	kind, rest, _ := strings.Cut(line, " ")
+	switch kind {
+	case "enter":
+		fields := strings.Fields(rest)
+		if len(fields) < 2 {
+			return fmt.Errorf("too few fields")
+		}
+		nums, err := parseNumbers(fields)
+		if err != nil {
+			return err
+		}
+		var args []IrType
+		for _, arg := range nums[2:] {
+			args = append(args, Constant(arg))
+		}
+		et.enterNR(int64(nums[0]), nums[1], args)
+	case "exit":
+		nums, err := parseNumbers(strings.Fields(rest))
+		if err != nil {
+			return err
+		}
+		if len(nums) != 2 {
+			return fmt.Errorf("want 2 fields")
+		}
+		et.exit(int64(nums[0]), int64(nums[1]))
+	case "str", "buf":
+		fields := strings.SplitN(rest, " ", 3)
+		if len(fields) < 2 {
+			return fmt.Errorf("too few fields")
+		}
+		nums, err := parseNumbers(fields[:2])
+		if err != nil {
+			return err
+		}
+		call, idx := et.current(int64(nums[0])), int(nums[1])
+		if call == nil || idx >= len(call.Args) {
+			log.Logf(2, "skipping data for arg %v of unknown call", idx)
+			return nil
+		}
+		val := ""
+		if len(fields) == 3 {
+			val = fields[2]
+		}
+		if kind == "buf" {
+			val = unescapeHex(val)
+		}
+		call.Args[idx] = et.bpftraceData(call.CallName, idx, val)
+	default:
+		return fmt.Errorf("unknown event %q", kind)
+	}
+	return nil
+}
+
+func parseNumbers(fields []string) ([]uint64, error) {
+	var res []uint64
+	for _, field := range fields {
+		v, ok := parseNumber(field)
+		if !ok {
+			return nil, fmt.Errorf("bad number %q", field)
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// bpftraceData converts the arg data to IR. strace prints socket addresses as structs,
+// and that's what proggen expects for them, so they are decoded similarly.
+func (et *eventTrace) bpftraceData(callName string, idx int, val string) IrType {
+	if (callName == "connect" || callName == "bind") && idx == 1 && len(val) >= 2 {
+		if addr := et.sockaddr([]byte(val)); addr != nil {
+			return addr
+		}
+	}
+	return newBufferType(val)
+}
+
+func (et *eventTrace) sockaddr(data []byte) IrType {
+	family := uint64(binary.LittleEndian.Uint16(data))
+	buf := func(start, end int) IrType {
+		if end > len(data) {
+			end = len(data)
+		}
+		if start > end {
+			start = end
+		}
+		return newBufferType(string(data[start:end]))
+	}
+	num := func(start int) IrType {
+		if start+4 > len(data) {
+			return Constant(0)
+		}
+		return Constant(binary.LittleEndian.Uint32(data[start:]))
+	}
+	switch family {
+	case et.consts["AF_INET"]:
+		// {sa_family, sin_port, sin_addr}.
+		return &GroupType{Elems: []IrType{Constant(family), buf(2, 4), buf(4, 8)}}
+	case et.consts["AF_INET6"]:
+		// {sa_family, sin6_port, sin6_flowinfo, sin6_addr, sin6_scope_id}.
+		return &GroupType{Elems: []IrType{Constant(family), buf(2, 4), buf(4, 8), buf(8, 24), num(24)}}
+	case et.consts["AF_UNIX"]:
+		// {sa_family, sun_path}.
+		path := bytes.TrimRight(data[2:], "\x00")
+		return &GroupType{Elems: []IrType{Constant(family), newBufferType(string(path))}}
+	case et.consts["AF_NETLINK"]:
+		// {sa_family, nl_pid, nl_groups}.
+		return &GroupType{Elems: []IrType{Constant(family), num(4), num(8)}}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBpftrace(t *testing.T) {
+	data := `Attaching 3 probes...
+enter 10 257 18446744073709551516 4096 524288 0 0 0
+str 10 1 /etc/hosts
+exit 10 3
+enter 10 0 3 8192 16 0 0 0
+buf 10 1 \x31\x32\x37\x2e\x30
+exit 10 5
+enter 10 42 4 12288 16 0 0 0
+buf 10 1 \x02\x00\x1f\x90\x7f\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00
+exit 10 0
+enter 10 49 5 12288 12 0 0 0
+buf 10 1 \x01\x00/tmp/s\x00\x00\x00\x00
+exit 10 0
+enter 10 56 17 0 0 0 0 0
+exit 11 0
+enter 11 39 0 0 0 0 0 0
+exit 10 11
+exit 11 11
+enter 10 231 0 0 0 0 0 0
+`
+	tree, err := ParseBpftrace([]byte(data), testTarget(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.RootPid != 10 || !reflect.DeepEqual(tree.Ptree[10], []int64{11}) {
+		t.Fatalf("unexpected process tree: %v", tree.Ptree)
+	}
+	calls := tree.TraceMap[10].Calls
+	if len(calls) != 6 {
+		t.Fatalf("expected 6 calls, got %v", calls)
+	}
+	type argTest struct {
+		call int
+		arg  int
+		val  IrType
+	}
+	tests := []argTest{
+		{0, 1, newBufferType("/etc/hosts")},
+		{1, 1, newBufferType("127.0")},
+		{2, 1, &GroupType{Elems: []IrType{Constant(2), newBufferType("\x1f\x90"), newBufferType("\x7f\x00\x00\x01")}}},
+		{3, 1, &GroupType{Elems: []IrType{Constant(1), newBufferType("/tmp/s")}}},
+	}
+	for _, test := range tests {
+		if val := calls[test.call].Args[test.arg]; !reflect.DeepEqual(val, test.val) {
+			t.Errorf("call %v arg %v: expected %#v, got %#v", test.call, test.arg, test.val, val)
+		}
+	}
+	if calls[4].CallName != "clone" || calls[4].Ret != 11 || calls[5].CallName != "exit_group" || calls[5].Paused {
+		t.Fatalf("unexpected calls: %v %v", calls[4], calls[5])
+	}
+	if calls := tree.TraceMap[11].Calls; len(calls) != 1 || calls[0].CallName != "getpid" || calls[0].Ret != 11 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	if _, err := ParseBpftrace([]byte("enter 10 x\n"), testTarget(t)); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
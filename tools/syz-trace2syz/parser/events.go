@@ -0,0 +1,128 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/prog"
+)
+
+// eventTrace builds a TraceTree from separate syscall enter and exit events
+// produced by tracers like perf and bpftrace.
+// Calls are ordered by the enter events, the same way strace prints them.
+type eventTrace struct {
+	target  *prog.Target
+	tree    *TraceTree
+	names   map[uint64]string
+	consts  map[string]uint64
+	pending map[int64]*Syscall
+}
+
+func newEventTrace(target *prog.Target) *eventTrace {
+	et := &eventTrace{
+		target:  target,
+		tree:    NewTraceTree(),
+		names:   make(map[uint64]string),
+		consts:  make(map[string]uint64),
+		pending: make(map[int64]*Syscall),
+	}
+	for _, call := range target.Syscalls {
+		if call.Attrs.Automatic || strings.HasPrefix(call.CallName, "syz_") {
+			continue
+		}
+		et.names[call.NR] = call.CallName
+	}
+	for _, c := range target.Consts {
+		et.consts[c.Name] = c.Value
+	}
+	return et
+}
+
+// enterNR is like enter, but takes the syscall number.
+func (et *eventTrace) enterNR(tid int64, nr uint64, args []IrType) *Syscall {
+	name, ok := et.names[nr]
+	if !ok {
+		log.Logf(2, "skipping unknown syscall %v", nr)
+		delete(et.pending, tid)
+		return nil
+	}
+	return et.enter(tid, name, args)
+}
+
+// enter records start of the call, it stays paused until the corresponding exit.
+func (et *eventTrace) enter(tid int64, name string, args []IrType) *Syscall {
+	call := NewSyscall(tid, name, args, -1, true, false)
+	et.tree.add(call)
+	et.pending[tid] = call
+	return call
+}
+
+// current returns the call the thread is executing.
+func (et *eventTrace) current(tid int64) *Syscall {
+	return et.pending[tid]
+}
+
+// exit records return of the current call of the thread.
+func (et *eventTrace) exit(tid, ret int64) {
+	if et.pending[tid] == nil {
+		// The trace started in the middle of the call, or we skipped it.
+		return
+	}
+	delete(et.pending, tid)
+	et.tree.add(NewSyscall(tid, "tmp", nil, ret, false, true))
+}
+
+// finish returns the trace. Calls that never returned (e.g. exit_group) are treated as finished.
+func (et *eventTrace) finish() *TraceTree {
+	for _, call := range et.pending {
+		call.Paused = false
+	}
+	et.pending = nil
+	if len(et.tree.TraceMap) == 0 {
+		return nil
+	}
+	// Threads that were created before tracing started (e.g. when attaching to a running service)
+	// are attached to the root, otherwise their calls would be lost.
+	reachable := make(map[int64]bool)
+	var walk func(pid int64)
+	walk = func(pid int64) {
+		reachable[pid] = true
+		for _, child := range et.tree.Ptree[pid] {
+			if !reachable[child] {
+				walk(child)
+			}
+		}
+	}
+	walk(et.tree.RootPid)
+	var orphans []int64
+	for pid := range et.tree.TraceMap {
+		if !reachable[pid] {
+			orphans = append(orphans, pid)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i] < orphans[j] })
+	for _, pid := range orphans {
+		if !reachable[pid] {
+			et.tree.Ptree[et.tree.RootPid] = append(et.tree.Ptree[et.tree.RootPid], pid)
+			walk(pid)
+		}
+	}
+	return et.tree
+}
+
+// description returns the generic description of the call, or any of its variants if there is none.
+func (et *eventTrace) description(name string) *prog.Syscall {
+	if meta := et.target.SyscallMap[name]; meta != nil {
+		return meta
+	}
+	for _, meta := range et.target.Syscalls {
+		if meta.CallName == name && !meta.Attrs.Automatic {
+			return meta
+		}
+	}
+	return nil
+}
@@ -5,7 +5,9 @@ package parser
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"net"
 )
 
 // TraceTree struct contains intermediate representation of trace.
@@ -51,6 +53,12 @@ func (trace *Trace) add(call *Syscall) *Syscall {
 	lastCall.Args = append(lastCall.Args, call.Args...)
 	lastCall.Paused = false
 	lastCall.Ret = call.Ret
+	for fd, desc := range call.Fds {
+		if lastCall.Fds == nil {
+			lastCall.Fds = make(map[uint64]string)
+		}
+		lastCall.Fds[fd] = desc
+	}
 	return lastCall
 }
 
@@ -68,10 +76,16 @@ type Syscall struct {
 	Ret      int64
 	Paused   bool
 	Resumed  bool
+	// Fds contains descriptions of file descriptors used in the call
+	// (paths, socket endpoints, etc) printed by strace with -y/-yy.
+	Fds map[uint64]string
 }
 
 // NewSyscall - constructor
 func NewSyscall(pid int64, name string, args []IrType, ret int64, paused, resumed bool) (sys *Syscall) {
+	for i, arg := range args {
+		args[i] = fieldValue(arg)
+	}
 	return &Syscall{
 		CallName: name,
 		Args:     args,
@@ -95,10 +109,51 @@ func (s *Syscall) String() string {
 // GroupType contains arrays and structs
 type GroupType struct {
 	Elems []IrType
+	// Names contains field names of the elements printed by strace (e.g. {fd=3, events=1}).
+	// It is nil if none of the elements are named, otherwise unnamed elements have empty names.
+	Names []string
 }
 
 func newGroupType(elems []IrType) (typ *GroupType) {
-	return &GroupType{Elems: elems}
+	typ = &GroupType{Elems: elems}
+	for i, elem := range elems {
+		f, ok := elem.(*field)
+		if !ok {
+			continue
+		}
+		if typ.Names == nil {
+			typ.Names = make([]string, len(elems))
+		}
+		typ.Names[i] = f.name
+		elems[i] = f.val
+	}
+	return typ
+}
+
+// field is a named value (e.g. fd=3) which exists only during parsing,
+// names are then stored in the enclosing GroupType.
+type field struct {
+	name string
+	val  IrType
+}
+
+func newField(name, val IrType) IrType {
+	val = fieldValue(val)
+	if buf, ok := name.(*BufferType); ok {
+		return &field{name: buf.Val, val: val}
+	}
+	return val
+}
+
+func fieldValue(typ IrType) IrType {
+	if f, ok := typ.(*field); ok {
+		return f.val
+	}
+	return typ
+}
+
+func (f *field) String() string {
+	return fmt.Sprintf("%v=%v", f.name, f.val)
 }
 
 // String implements IrType String()
@@ -114,6 +169,77 @@ func (a *GroupType) String() string {
 	return buf.String()
 }
 
+// newMacro evaluates macro-like expressions that strace prints for some values,
+// e.g. sin_port=htons(8080), st_rdev=makedev(0x1, 0x3) or BPF_STMT(BPF_LD|BPF_W|BPF_ABS, 0x4).
+// Unknown macros are represented as a group of their arguments.
+func newMacro(name string, args []IrType) IrType {
+	constArg := func(i int) uint64 {
+		if i >= len(args) {
+			return 0
+		}
+		if c, ok := fieldValue(args[i]).(Constant); ok {
+			return c.Val()
+		}
+		return 0
+	}
+	switch name {
+	case "htons", "ntohs":
+		// Network byte order values are represented as buffers, the same way strace prints them with -Xraw.
+		val := make([]byte, 2)
+		binary.BigEndian.PutUint16(val, uint16(constArg(0)))
+		return newBufferType(string(val))
+	case "htonl", "ntohl":
+		val := make([]byte, 4)
+		binary.BigEndian.PutUint32(val, uint32(constArg(0)))
+		return newBufferType(string(val))
+	case "inet_addr":
+		if len(args) == 1 {
+			if addr := parseIP(args[0]); addr != nil {
+				return newBufferType(string(addr))
+			}
+		}
+	case "inet_pton":
+		// inet_pton(AF_INET6, "::1", &sin6_addr).
+		if len(args) >= 2 {
+			if addr := parseIP(args[1]); addr != nil {
+				return newBufferType(string(addr))
+			}
+		}
+	case "makedev":
+		// This is the glibc encoding of dev_t.
+		major, minor := constArg(0), constArg(1)
+		return Constant((major&0xfffff000)<<32 | (major&0xfff)<<8 | (minor&0xffffff00)<<12 | minor&0xff)
+	case "KERNEL_VERSION":
+		return Constant(constArg(0)<<16 + constArg(1)<<8 + constArg(2))
+	case "BPF_STMT":
+		return &GroupType{
+			Elems: []IrType{Constant(constArg(0)), Constant(0), Constant(0), Constant(constArg(1))},
+			Names: []string{"code", "jt", "jf", "k"},
+		}
+	case "BPF_JUMP":
+		return &GroupType{
+			Elems: []IrType{Constant(constArg(0)), Constant(constArg(2)), Constant(constArg(3)), Constant(constArg(1))},
+			Names: []string{"code", "jt", "jf", "k"},
+		}
+	}
+	return newGroupType(args)
+}
+
+func parseIP(typ IrType) []byte {
+	buf, ok := fieldValue(typ).(*BufferType)
+	if !ok {
+		return nil
+	}
+	ip := net.ParseIP(buf.Val)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil && !bytes.Contains([]byte(buf.Val), []byte(":")) {
+		return ip4
+	}
+	return ip.To16()
+}
+
 // Constant represents all evaluated expressions produced by strace
 // Constant types are evaluated at parse time
 type Constant uint64
@@ -0,0 +1,197 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	pidRe       = regexp.MustCompile(`^\[pid\s+(\d+)\]\s*`)
+	timestampRe = regexp.MustCompile(`^(\d+\s+)?(\d{2}:\d{2}:\d{2}(\.\d+)?|\d+\.\d+)\s+`)
+	timingRe    = regexp.MustCompile(`\s*<\d+\.\d+>\s*$`)
+)
+
+// normalizeLine converts output of newer strace versions to the form the lexer understands.
+// It handles:
+//   - "[pid N]" prefixes printed when strace writes all processes to a single file;
+//   - timestamps printed with -t/-tt/-ttt/-r;
+//   - syscall times printed with -T/--syscall-times;
+//   - fd annotations printed with -y/-yy/--decode-fds (e.g. 3</dev/null> or 4<TCP:[1.2.3.4:80->...]>),
+//     which are removed from the line and returned separately keyed by the fd;
+//   - "..." markers for truncated strings, arrays and structs printed when strace hits the -s limit.
+func normalizeLine(line string) (string, map[uint64]string) {
+	line = pidRe.ReplaceAllString(line, "$1 ")
+	if m := timestampRe.FindStringSubmatchIndex(line); m != nil {
+		pid := ""
+		if m[2] != -1 {
+			pid = line[m[2]:m[3]]
+		}
+		line = pid + line[m[1]:]
+	}
+	line = timingRe.ReplaceAllString(line, "")
+	var fds map[uint64]string
+	out := new(strings.Builder)
+	skipComma := false
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '"':
+			end := skipString(line, i)
+			out.WriteString(line[i:end])
+			i = end
+			continue
+		case strings.HasPrefix(line[i:], "/*"):
+			end := strings.Index(line[i:], "*/")
+			if end == -1 {
+				end = len(line)
+			} else {
+				end += i + 2
+			}
+			out.WriteString(line[i:end])
+			i = end
+			continue
+		case c == '<' && isFdAnnotation(line, i):
+			end, val := parseFdAnnotation(line, i)
+			fd, err := strconv.ParseInt(fdBefore(line, i), 10, 64)
+			if err == nil {
+				if fds == nil {
+					fds = make(map[uint64]string)
+				}
+				fds[uint64(fd)] = val
+			}
+			i = end
+			continue
+		case c == '<' && (strings.HasPrefix(line[i:], "<unfinished") || strings.HasPrefix(line[i:], "<...") ||
+			strings.HasPrefix(line[i:], "<detached")):
+			// These are understood by the lexer as is.
+			end := strings.IndexByte(line[i:], '>')
+			if end == -1 {
+				end = len(line)
+			} else {
+				end += i + 1
+			}
+			out.WriteString(line[i:end])
+			i = end
+			continue
+		case strings.HasPrefix(line[i:], "..."):
+			// Truncation marker, e.g. "abc"..., [1, 2, ...] or {a=1, ...}. Remove it along with
+			// the preceding comma, or with the following comma if it's the first element.
+			rest := strings.TrimRight(out.String(), " ")
+			rest = strings.TrimSuffix(rest, ",")
+			out.Reset()
+			out.WriteString(rest)
+			if strings.HasSuffix(rest, "{") || strings.HasSuffix(rest, "[") || strings.HasSuffix(rest, "(") {
+				skipComma = true
+			}
+			i += 3
+			continue
+		case skipComma && (c == ',' || c == ' '):
+			i++
+			continue
+		}
+		skipComma = false
+		out.WriteByte(c)
+		i++
+	}
+	return out.String(), fds
+}
+
+// skipString returns the position after the string literal that starts at pos.
+func skipString(line string, pos int) int {
+	i := pos + 1
+	for ; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '"' {
+			i++
+			break
+		}
+	}
+	return min(i, len(line))
+}
+
+// isFdAnnotation checks if '<' at pos starts an fd annotation, i.e. it immediately follows a number.
+func isFdAnnotation(line string, pos int) bool {
+	if pos+1 >= len(line) || line[pos+1] == '<' || line[pos+1] == '>' || line[pos+1] == ' ' {
+		return false
+	}
+	return fdBefore(line, pos) != ""
+}
+
+// fdBefore returns the number that ends at pos if it's a separate token.
+func fdBefore(line string, pos int) string {
+	start := pos
+	for start > 0 && line[start-1] >= '0' && line[start-1] <= '9' {
+		start--
+	}
+	if start == pos {
+		return ""
+	}
+	if start > 0 && line[start-1] == '-' {
+		start--
+	}
+	if start > 0 {
+		prev := line[start-1]
+		if prev == '_' || prev == '<' || prev >= 'a' && prev <= 'z' || prev >= 'A' && prev <= 'Z' {
+			return ""
+		}
+	}
+	return line[start:pos]
+}
+
+// parseFdAnnotation returns the position after the annotation that starts at pos and its value.
+// Nested annotations (e.g. /dev/null<char 1:3>) are not included in the value.
+// Socket endpoints are enclosed in [] and may contain "->", so we don't look for '>' inside of them.
+func parseFdAnnotation(line string, pos int) (int, string) {
+	val := new(strings.Builder)
+	depth, brackets := 0, 0
+	i := pos
+	for ; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '[':
+			brackets++
+		case c == ']' && brackets > 0:
+			brackets--
+		case c == '<' && brackets == 0:
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case c == '>' && brackets == 0:
+			depth--
+			if depth == 0 {
+				return i + 1, unescapeHex(val.String())
+			}
+			continue
+		}
+		if depth == 1 {
+			val.WriteByte(c)
+		}
+	}
+	return i, unescapeHex(val.String())
+}
+
+func unescapeHex(s string) string {
+	if !strings.Contains(s, `\x`) {
+		return s
+	}
+	res := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if v, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				res = append(res, byte(v))
+				i += 3
+				continue
+			}
+		}
+		res = append(res, s[i])
+	}
+	return string(res)
+}
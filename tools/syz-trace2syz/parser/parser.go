@@ -14,9 +14,13 @@ import (
 	"github.com/google/syzkaller/pkg/log"
 )
 
-func parseSyscall(scanner *bufio.Scanner) (int, *Syscall) {
-	lex := newStraceLexer(scanner.Bytes())
+func parseSyscall(line string) (int, *Syscall) {
+	line, fds := normalizeLine(line)
+	lex := newStraceLexer([]byte(line))
 	ret := StraceParse(lex)
+	if lex.result != nil {
+		lex.result.Fds = fds
+	}
 	return ret, lex.result
 }
 
@@ -24,7 +28,9 @@ func shouldSkip(line string) bool {
 	return strings.Contains(line, "ERESTART") ||
 		strings.Contains(line, "+++") ||
 		strings.Contains(line, "---") ||
-		strings.Contains(line, "<ptrace(SYSCALL):No such process>")
+		strings.Contains(line, "<ptrace(SYSCALL):No such process>") ||
+		strings.Contains(line, "<detached ...>") ||
+		strings.HasPrefix(line, "strace: ")
 }
 
 // ParseData parses each line of a strace file in a loop.
@@ -39,7 +45,7 @@ func ParseData(data []byte) (*TraceTree, error) {
 			continue
 		}
 		log.Logf(4, "scanning call: %s", line)
-		ret, call := parseSyscall(scanner)
+		ret, call := parseSyscall(line)
 		if call == nil || ret != 0 {
 			return nil, fmt.Errorf("failed to parse line: %v", line)
 		}
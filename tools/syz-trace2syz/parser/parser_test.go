@@ -6,6 +6,7 @@
 package parser
 
 import (
+	"reflect"
 	"testing"
 
 	_ "github.com/google/syzkaller/sys"
@@ -222,3 +223,122 @@ func TestParseGroupType(t *testing.T) {
 		}
 	}
 }
+
+func TestParseFieldNames(t *testing.T) {
+	type namesTest struct {
+		test  string
+		names []string
+	}
+	tests := []namesTest{
+		{`open({1, 2, 3}) = 0`, nil},
+		{`open({fd=3, events=1, revents=0}) = 0`, []string{"fd", "events", "revents"}},
+		{`open({msg_name=NULL, 1, msg_namelen=16->16}) = 0`, []string{"msg_name", "", "msg_namelen"}},
+		{`open({c_cc[VMIN]=1, c_cc[VTIME]=0}) = 0`, []string{"c_cc[VMIN]", "c_cc[VTIME]"}},
+	}
+	for _, test := range tests {
+		tree, err := ParseData([]byte(test.test))
+		if err != nil {
+			t.Fatal(err)
+		}
+		call := tree.TraceMap[tree.RootPid].Calls[0]
+		group, ok := call.Args[0].(*GroupType)
+		if !ok {
+			t.Fatalf("Expected Group type. Got: %#v", call.Args[0])
+		}
+		if !reflect.DeepEqual(group.Names, test.names) {
+			t.Fatalf("%v: expected names %q, got %q", test.test, test.names, group.Names)
+		}
+		for _, elem := range group.Elems {
+			if _, ok := elem.(Constant); !ok {
+				t.Fatalf("%v: expected constant element, got: %#v", test.test, elem)
+			}
+		}
+	}
+}
+
+func TestNormalizeLine(t *testing.T) {
+	type normalizeTest struct {
+		line string
+		res  string
+		fds  map[uint64]string
+	}
+	tests := []normalizeTest{
+		{`open() = 3`, `open() = 3`, nil},
+		{`[pid  42] open() = 3`, `42 open() = 3`, nil},
+		{`42 12:01:02.123456 open() = 3`, `42 open() = 3`, nil},
+		{`1700000000.123456 open() = 3 <0.000012>`, `open() = 3`, nil},
+		{`close(3</dev/null<char 1:3>>) = 0`, `close(3) = 0`, map[uint64]string{3: "/dev/null"}},
+		{`openat(-100</root>, "\x2f", 0) = 3<\x2f\x74\x6d\x70>`, `openat(-100, "\x2f", 0) = 3`,
+			map[uint64]string{^uint64(99): "/root", 3: "/tmp"}},
+		{`connect(4<TCP:[127.0.0.1:1234->127.0.0.1:80]>, {sa_family=2}, 16) = 0`,
+			`connect(4, {sa_family=2}, 16) = 0`, map[uint64]string{4: "TCP:[127.0.0.1:1234->127.0.0.1:80]"}},
+		{`open(1<<3) = 0`, `open(1<<3) = 0`, nil},
+		{`poll([{fd=3, events=1}, ...], 5, 0) = 0`, `poll([{fd=3, events=1}], 5, 0) = 0`, nil},
+		{`open({...}, [..., 1], "\x61"..., 3) = 0`, `open({}, [1], "\x61", 3) = 0`, nil},
+		{`open(0 /* ... */, 1 <unfinished ...>`, `open(0 /* ... */, 1 <unfinished ...>`, nil},
+	}
+	for _, test := range tests {
+		res, fds := normalizeLine(test.line)
+		if res != test.res {
+			t.Errorf("%v:\nwant: %v\ngot:  %v", test.line, test.res, res)
+		}
+		if !reflect.DeepEqual(fds, test.fds) {
+			t.Errorf("%v: want fds %q, got %q", test.line, test.fds, fds)
+		}
+	}
+}
+
+func TestParseMacros(t *testing.T) {
+	type macroTest struct {
+		test string
+		val  IrType
+	}
+	tests := []macroTest{
+		{`open(htons(8080)) = 0`, newBufferType("\x1f\x90")},
+		{`open(htonl(1)) = 0`, newBufferType("\x00\x00\x00\x01")},
+		{`open(inet_addr("127.0.0.1")) = 0`, newBufferType("\x7f\x00\x00\x01")},
+		{`open(inet_pton(0xa, "::1", &sin6_addr)) = 0`,
+			newBufferType("\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01")},
+		{`open(makedev(0x1, 0x3)) = 0`, Constant(0x103)},
+		{`open(KERNEL_VERSION(5, 10, 0)) = 0`, Constant(0x50a00)},
+		{`open(BPF_JUMP(0x15, 0x3, 0, 1)) = 0`, &GroupType{
+			Elems: []IrType{Constant(0x15), Constant(0), Constant(1), Constant(3)},
+			Names: []string{"code", "jt", "jf", "k"},
+		}},
+		{`open(FOO(1, 2)) = 0`, &GroupType{Elems: []IrType{Constant(1), Constant(2)}}},
+	}
+	for _, test := range tests {
+		tree, err := ParseData([]byte(test.test))
+		if err != nil {
+			t.Fatal(err)
+		}
+		call := tree.TraceMap[tree.RootPid].Calls[0]
+		if !reflect.DeepEqual(call.Args[0], test.val) {
+			t.Fatalf("%v: expected %#v, got %#v", test.test, test.val, call.Args[0])
+		}
+	}
+}
+
+func TestParseDecodedFds(t *testing.T) {
+	data := `[pid 10] 12:00:00.000001 openat(-100</>, "\x2f\x64", 0 <unfinished ...>
+[pid 11] 12:00:00.000002 close(5<socket:[123]>) = 0 <0.000003>
+[pid 10] 12:00:00.000003 <... openat resumed>) = 3</dev/null<char 1:3>> <0.000004>
+[pid 10] 12:00:00.000004 +++ exited with 0 +++`
+	tree, err := ParseData([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.RootPid != 10 {
+		t.Fatalf("Incorrect Root Pid: %d", tree.RootPid)
+	}
+	calls := tree.TraceMap[10].Calls
+	if len(calls) != 1 || calls[0].Ret != 3 || calls[0].Paused {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	if !reflect.DeepEqual(calls[0].Fds, map[uint64]string{^uint64(99): "/", 3: "/dev/null"}) {
+		t.Fatalf("unexpected fds: %q", calls[0].Fds)
+	}
+	if fds := tree.TraceMap[11].Calls[0].Fds; !reflect.DeepEqual(fds, map[uint64]string{5: "socket:[123]"}) {
+		t.Fatalf("unexpected fds: %q", fds)
+	}
+}
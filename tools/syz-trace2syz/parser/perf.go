@@ -0,0 +1,504 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/log"
+	"github.com/google/syzkaller/prog"
+)
+
+// ParsePerf parses output of perf trace:
+//
+//	perf trace -o trace.perf -- ./a.out
+//
+// or syscall tracepoints converted to JSON:
+//
+//	perf record -e 'syscalls:sys_enter_*' -e 'syscalls:sys_exit_*' -- ./a.out
+//	perf data convert --to-json trace.perf.json
+//
+// perf does not dump buffers and prints only some strings (file names with -e probe:vfs_getname),
+// so resulting programs mostly have default pointer values.
+func ParsePerf(data []byte, target *prog.Target) (*TraceTree, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && trimmed[0] == '{' {
+		return parsePerfJSON(data, target)
+	}
+	et := newEventTrace(target)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !et.parsePerfLine(line) {
+			// perf also prints summaries, page faults and other events.
+			log.Logf(2, "skipping line: %s", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return et.finish(), nil
+}
+
+var (
+	perfPrefixRe    = regexp.MustCompile(`^\s*\d+\.\d+\s+\([^)]*\):\s*`)
+	perfThreadRe    = regexp.MustCompile(`^(?:[^(]+?/)?(\d+) +`)
+	perfContinuedRe = regexp.MustCompile(`^\s*\.\.\. \[continued\]: ([a-z_0-9]+)\(\)\)\s*(.*)$`)
+	perfCallRe      = regexp.MustCompile(`^([a-z_][a-z_0-9]*)\(`)
+)
+
+// parsePerfLine parses lines like:
+//
+//	0.123 ( 0.004 ms): cat/1234 openat(dfd: CWD, filename: "/etc/passwd", flags: RDONLY|CLOEXEC) = 3
+//	0.130 (         ): sleep/1235 nanosleep(rqtp: 0x7ffd4c2e1ab0) ...
+//	1.131 (1001.001 ms): sleep/1235  ... [continued]: nanosleep())  = 0
+//
+// The comm/tid part is omitted if a single thread is traced.
+func (et *eventTrace) parsePerfLine(line string) bool {
+	m := perfPrefixRe.FindStringIndex(line)
+	if m == nil {
+		return false
+	}
+	line = line[m[1]:]
+	tid := int64(-1)
+	if m := perfThreadRe.FindStringSubmatch(line); m != nil {
+		tid, _ = strconv.ParseInt(m[1], 10, 64)
+		line = line[len(m[0]):]
+	}
+	if m := perfContinuedRe.FindStringSubmatch(line); m != nil {
+		if call := et.current(tid); call == nil || call.CallName != m[1] {
+			return false
+		}
+		et.exit(tid, parsePerfRet(m[2]))
+		return true
+	}
+	m = perfCallRe.FindStringSubmatchIndex(line)
+	if m == nil {
+		return false
+	}
+	name := line[m[2]:m[3]]
+	end := matchingParen(line, m[1]-1)
+	if end == -1 {
+		return false
+	}
+	call := et.enter(tid, name, nil)
+	call.Args, call.Fds = et.perfArgs(name, splitTopLevel(line[m[1]:end]))
+	rest := strings.TrimSpace(line[end+1:])
+	if !strings.HasPrefix(rest, "...") {
+		et.exit(tid, parsePerfRet(rest))
+	}
+	return true
+}
+
+// parsePerfRet parses return values like "= 3", "= -1 ENOENT (No such file or directory)" or "= ?".
+func parsePerfRet(rest string) int64 {
+	rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "="))
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return -1
+	}
+	val := fields[0]
+	if i := strings.IndexByte(val, '<'); i != -1 {
+		val = val[:i]
+	}
+	if ret, ok := parseNumber(val); ok {
+		return int64(ret)
+	}
+	return -1
+}
+
+// perfArgs converts "name: value" args to IR. perf does not print zero args,
+// so their positions are restored using names of the args in our descriptions.
+func (et *eventTrace) perfArgs(callName string, args []string) ([]IrType, map[uint64]string) {
+	var names []string
+	if meta := et.description(callName); meta != nil {
+		for _, arg := range meta.Args {
+			names = append(names, arg.Name)
+		}
+	}
+	var res []IrType
+	var fds map[uint64]string
+	pos := 0
+	for _, arg := range args {
+		name, val, ok := strings.Cut(arg, ": ")
+		if !ok {
+			name, val = "", arg
+		}
+		idx := pos
+		for i := pos; i < len(names); i++ {
+			if name != "" && (strings.HasPrefix(name, names[i]) || strings.HasPrefix(names[i], name) ||
+				strings.HasSuffix(name, names[i])) {
+				idx = i
+				break
+			}
+		}
+		for len(res) <= idx {
+			res = append(res, Constant(0))
+		}
+		var desc string
+		res[idx], desc = et.perfValue(callName, name, strings.TrimSpace(val))
+		if desc != "" {
+			if c, ok := res[idx].(Constant); ok {
+				if fds == nil {
+					fds = make(map[uint64]string)
+				}
+				fds[c.Val()] = desc
+			}
+		}
+		pos = idx + 1
+	}
+	return res, fds
+}
+
+// perfValue converts a single value printed by perf trace to IR.
+// It also returns the fd description if the value is annotated with it (e.g. 3</etc/passwd>).
+func (et *eventTrace) perfValue(callName, argName, val string) (IrType, string) {
+	// Casts are printed for BTF-decoded struct fields, e.g. .sa_family = (short)2.
+	if strings.HasPrefix(val, "(") {
+		if end := matchingParen(val, 0); end != -1 {
+			val = strings.TrimSpace(val[end+1:])
+		}
+	}
+	switch {
+	case val == "NULL" || val == "":
+		return Constant(0), ""
+	case val[0] == '"':
+		return newBufferType(unquote(val)), ""
+	case val[0] == '/' || val[0] == '.':
+		return newBufferType(val), ""
+	case val[0] == '{' || val[0] == '[':
+		var elems []IrType
+		var names []string
+		named := false
+		inner := strings.TrimSuffix(strings.TrimSuffix(val[1:], "}"), "]")
+		for _, elem := range splitTopLevel(inner) {
+			name := ""
+			if strings.HasPrefix(elem, ".") {
+				if n, v, ok := strings.Cut(elem, "="); ok {
+					name, elem, named = strings.TrimSpace(n[1:]), v, true
+				}
+			} else if n, v, ok := strings.Cut(elem, ": "); ok && isIdentifier(n) {
+				name, elem, named = n, v, true
+			}
+			typ, _ := et.perfValue(callName, name, strings.TrimSpace(elem))
+			elems = append(elems, typ)
+			names = append(names, name)
+		}
+		group := &GroupType{Elems: elems}
+		if named {
+			group.Names = names
+		}
+		return group, ""
+	}
+	desc := ""
+	if i := strings.IndexByte(val, '<'); i > 0 && strings.HasSuffix(val, ">") {
+		val, desc = val[:i], val[i+1:len(val)-1]
+	}
+	var res uint64
+	for _, part := range strings.Split(val, "|") {
+		part = strings.TrimSpace(part)
+		if v, ok := parseNumber(part); ok {
+			res |= v
+			continue
+		}
+		v, ok := et.perfConst(callName, argName, part)
+		if !ok {
+			log.Logf(2, "unknown value %v of arg %v of %v", part, argName, callName)
+		}
+		res |= v
+	}
+	return Constant(res), desc
+}
+
+// perfConstPrefixes are prefixes that perf trace strips from names of values of args (e.g. RDONLY for O_RDONLY).
+// They are keyed by "call:arg" or by the arg name.
+var perfConstPrefixes = map[string][]string{
+	"mmap:flags":   {"MAP_"},
+	"mremap:flags": {"MREMAP_"},
+	"family":       {"AF_"},
+	"domain":       {"AF_"},
+	"type":         {"SOCK_"},
+	"protocol":     {"IPPROTO_"},
+	"prot":         {"PROT_"},
+	"whence":       {"SEEK_"},
+	"cmd":          {"F_"},
+	"option":       {"PR_"},
+	"behavior":     {"MADV_"},
+	"advice":       {"MADV_"},
+	"op":           {"FUTEX_"},
+	"how":          {"SIG_"},
+	"sig":          {"SIG"},
+	"which":        {"RLIMIT_"},
+	"resource":     {"RLIMIT_"},
+	"clockid":      {"CLOCK_"},
+	"which_clock":  {"CLOCK_"},
+	"level":        {"SOL_"},
+	"optname":      {"SO_"},
+	"mode":         {"S_I"},
+}
+
+var perfGenericPrefixes = []string{"O_", "AT_", "PROT_", "MAP_", "AF_", "SOCK_", "F_", "SEEK_", "MADV_", "PR_",
+	"CLONE_", "IPPROTO_", "MSG_", "FUTEX_", "EFD_", "EPOLL_", "TFD_", "MFD_", "GRND_", "SIG", "RLIMIT_", "CLOCK_"}
+
+var perfConstAliases = map[string]string{
+	"CWD": "AT_FDCWD",
+}
+
+func (et *eventTrace) perfConst(callName, argName, name string) (uint64, bool) {
+	if alias, ok := perfConstAliases[name]; ok {
+		name = alias
+	}
+	if v, ok := et.consts[name]; ok {
+		return v, true
+	}
+	var prefixes []string
+	prefixes = append(prefixes, perfConstPrefixes[callName+":"+argName]...)
+	prefixes = append(prefixes, perfConstPrefixes[argName]...)
+	prefixes = append(prefixes, perfGenericPrefixes...)
+	for _, prefix := range prefixes {
+		if v, ok := et.consts[prefix+name]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// parsePerfJSON parses output of perf data convert --to-json for syscall tracepoints:
+//
+//	{"linux-perf-json-version": 1, "samples": [
+//		{"timestamp": 1, "pid": 10, "tid": 10, "comm": "a.out", "__syscall_nr": 257, "dfd": "0xffffff9c", ...},
+//		{"timestamp": 2, "pid": 10, "tid": 10, "comm": "a.out", "__syscall_nr": 257, "ret": "3"}]}
+//
+// Fields of the sys_enter_* tracepoints follow __syscall_nr in the order of the syscall args.
+// Samples of raw_syscalls:sys_enter/sys_exit with id and args fields are supported as well.
+func parsePerfJSON(data []byte, target *prog.Target) (*TraceTree, error) {
+	et := newEventTrace(target)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key != "samples" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := expectDelim(dec, '['); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			sample, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			et.perfSample(sample)
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return nil, err
+		}
+	}
+	return et.finish(), nil
+}
+
+type jsonField struct {
+	name string
+	val  any
+}
+
+// decodeOrdered decodes an object preserving order of the fields.
+func decodeOrdered(dec *json.Decoder) ([]jsonField, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	var fields []jsonField
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var val any
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		fields = append(fields, jsonField{fmt.Sprint(key), val})
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("unexpected json token %v, expected %v", tok, delim)
+	}
+	return nil
+}
+
+// perfSampleFields are fields that perf adds to all samples.
+var perfSampleFields = map[string]bool{
+	"timestamp": true,
+	"pid":       true,
+	"tid":       true,
+	"cpu":       true,
+	"comm":      true,
+	"callchain": true,
+	"period":    true,
+}
+
+func (et *eventTrace) perfSample(sample []jsonField) {
+	tid, nr, nrIdx := int64(-1), uint64(0), -1
+	var ret *int64
+	var args []IrType
+	for i, field := range sample {
+		switch field.name {
+		case "pid":
+			if v, ok := jsonNumber(field.val); ok && tid == -1 {
+				tid = int64(v)
+			}
+		case "tid":
+			if v, ok := jsonNumber(field.val); ok {
+				tid = int64(v)
+			}
+		case "__syscall_nr", "id":
+			nr, _ = jsonNumber(field.val)
+			nrIdx = i
+		case "ret":
+			v, _ := jsonNumber(field.val)
+			r := int64(v)
+			ret = &r
+		case "args":
+			args = nil
+			if elems, ok := field.val.([]any); ok {
+				for _, elem := range elems {
+					args = append(args, jsonValue(elem))
+				}
+			}
+		}
+	}
+	switch {
+	case nrIdx == -1:
+		log.Logf(2, "skipping sample without syscall number")
+	case ret != nil:
+		et.exit(tid, *ret)
+	default:
+		if args == nil {
+			for _, field := range sample[nrIdx+1:] {
+				if perfSampleFields[field.name] {
+					continue
+				}
+				args = append(args, jsonValue(field.val))
+			}
+		}
+		et.enterNR(tid, nr, args)
+	}
+}
+
+func jsonValue(val any) IrType {
+	if v, ok := jsonNumber(val); ok {
+		return Constant(v)
+	}
+	return newBufferType(fmt.Sprint(val))
+}
+
+func jsonNumber(val any) (uint64, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		return parseNumber(v.String())
+	case string:
+		return parseNumber(strings.TrimSpace(v))
+	}
+	return 0, false
+}
+
+// parseNumber parses decimal, hex and negative numbers.
+func parseNumber(s string) (uint64, bool) {
+	if v, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return v, true
+	}
+	if v, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return uint64(v), true
+	}
+	return 0, false
+}
+
+// matchingParen returns the position of the paren that closes the one at pos, or -1.
+func matchingParen(s string, pos int) int {
+	depth := 0
+	for i := pos; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			i = skipString(s, i) - 1
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits comma-separated values that may contain strings, structs and arrays.
+func splitTopLevel(s string) []string {
+	var res []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			i = skipString(s, i) - 1
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				res = append(res, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		res = append(res, last)
+	}
+	return res
+}
+
+func unquote(s string) string {
+	if res, err := strconv.Unquote(s); err == nil {
+		return res
+	}
+	return unescapeHex(strings.TrimSuffix(strings.TrimPrefix(s, `"`), `"`))
+}
+
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i != 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/sys/targets"
+)
+
+func testTarget(t *testing.T) *prog.Target {
+	target, err := prog.GetTarget(targets.Linux, targets.AMD64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return target
+}
+
+func TestParsePerf(t *testing.T) {
+	data := `
+     0.019 ( 0.006 ms): mmap(len: 8192, prot: READ|WRITE, flags: PRIVATE|ANONYMOUS) = 0x7f8e1c5f2000
+     0.043 ( 0.008 ms): openat(dfd: CWD, filename: "/etc/ld.so.cache", flags: RDONLY|CLOEXEC) = 3
+     0.055 ( 0.003 ms): fstat(fd: 3</etc/ld.so.cache>, statbuf: 0x7ffd5a3e1e20) = 0
+     0.060 ( 0.003 ms): access(filename: /etc/ld.so.preload, mode: 4) = -1 ENOENT (No such file or directory)
+     0.160 (         ): nanosleep(rqtp: 0x7ffd5a3e1f00) ...
+     0.170 ( 0.001 ms): majfault [__memmove_avx_unaligned_erms+0x10] => 0x7f8e1c5f2000 (d.)
+     1.162 (1001.002 ms):  ... [continued]: nanosleep())  = 0
+     1.180 (         ): exit_group()                                                = ?
+`
+	tree, err := ParsePerf([]byte(data), testTarget(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.RootPid != -1 {
+		t.Fatalf("Incorrect Root Pid: %d", tree.RootPid)
+	}
+	calls := tree.TraceMap[-1].Calls
+	type callTest struct {
+		name string
+		args []IrType
+		ret  int64
+	}
+	tests := []callTest{
+		{"mmap", []IrType{Constant(0), Constant(8192), Constant(0x3), Constant(0x22)}, 0x7f8e1c5f2000},
+		{"openat", []IrType{Constant(0xffffffffffffff9c), newBufferType("/etc/ld.so.cache"), Constant(0x80000)}, 3},
+		{"fstat", []IrType{Constant(3), Constant(0x7ffd5a3e1e20)}, 0},
+		{"access", []IrType{newBufferType("/etc/ld.so.preload"), Constant(4)}, -1},
+		{"nanosleep", []IrType{Constant(0x7ffd5a3e1f00)}, 0},
+		{"exit_group", nil, -1},
+	}
+	if len(calls) != len(tests) {
+		t.Fatalf("expected %v calls, got %v", len(tests), len(calls))
+	}
+	for i, test := range tests {
+		call := calls[i]
+		if call.CallName != test.name || call.Ret != test.ret || call.Paused ||
+			!reflect.DeepEqual(call.Args, test.args) {
+			t.Errorf("call %v: expected %v %v = %v, got %v", i, test.name, test.args, test.ret, call)
+		}
+	}
+	if !reflect.DeepEqual(calls[2].Fds, map[uint64]string{3: "/etc/ld.so.cache"}) {
+		t.Fatalf("unexpected fds: %q", calls[2].Fds)
+	}
+}
+
+func TestParsePerfThreads(t *testing.T) {
+	data := `
+     0.100 ( 0.002 ms): app/100 getpid() = 100
+     0.110 ( 0.004 ms): app worker/101 close(fd: 5) = 0
+     0.161 ( 0.030 ms): app/100 clone(clone_flags: CHILD_CLEARTID|CHILD_SETTID|0x11) = 102
+     0.170 ( 0.004 ms): app/102 getpid() = 102
+`
+	tree, err := ParsePerf([]byte(data), testTarget(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.RootPid != 100 || len(tree.TraceMap) != 3 {
+		t.Fatalf("unexpected trace: %+v", tree)
+	}
+	// The thread created before tracing started is attached to the root.
+	if !reflect.DeepEqual(tree.Ptree[100], []int64{102, 101}) {
+		t.Fatalf("unexpected process tree: %v", tree.Ptree)
+	}
+	if flags := tree.TraceMap[100].Calls[1].Args[0]; flags != Constant(0x1200011) {
+		t.Fatalf("unexpected clone flags: %v", flags)
+	}
+}
+
+func TestParsePerfJSON(t *testing.T) {
+	data := `{
+	"linux-perf-json-version": 1,
+	"headers": {"arch": "x86_64"},
+	"samples": [
+		{"timestamp": 1, "pid": 7, "tid": 8, "comm": "a", "__syscall_nr": 257,
+			"dfd": "0xffffffffffffff9c", "filename": "0x1000", "flags": "0x80002", "mode": "0x0"},
+		{"timestamp": 2, "pid": 7, "tid": 7, "comm": "a", "__syscall_nr": 39},
+		{"timestamp": 3, "pid": 7, "tid": 8, "comm": "a", "__syscall_nr": 257, "ret": "3"},
+		{"timestamp": 4, "pid": 7, "tid": 7, "comm": "a", "__syscall_nr": 39, "ret": 7},
+		{"timestamp": 5, "pid": 7, "tid": 8, "comm": "a", "id": 3, "args": ["0x3", "0x0", "0x0", "0x0", "0x0", "0x0"]},
+		{"timestamp": 6, "pid": 7, "tid": 8, "comm": "a", "id": 3, "ret": -9},
+		{"timestamp": 7, "pid": 7, "tid": 8, "comm": "a", "__syscall_nr": 100000, "arg": "0x0"}
+	]
+}`
+	tree, err := ParsePerf([]byte(data), testTarget(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := tree.TraceMap[8].Calls
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %v", calls)
+	}
+	want := []IrType{Constant(0xffffffffffffff9c), Constant(0x1000), Constant(0x80002), Constant(0)}
+	if calls[0].CallName != "openat" || calls[0].Ret != 3 || !reflect.DeepEqual(calls[0].Args, want) {
+		t.Fatalf("unexpected call: %v", calls[0])
+	}
+	if calls[1].CallName != "close" || calls[1].Ret != -9 || len(calls[1].Args) != 6 {
+		t.Fatalf("unexpected call: %v", calls[1])
+	}
+	if calls := tree.TraceMap[7].Calls; len(calls) != 1 || calls[0].CallName != "getpid" || calls[0].Ret != 7 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
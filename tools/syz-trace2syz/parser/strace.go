@@ -119,6 +119,7 @@ var StraceToknames = [...]string{
 	"NOFLAG",
 	"NEG",
 }
+
 var StraceStatenames = [...]string{}
 
 const StraceEofCode = 1
@@ -126,7 +127,7 @@ const StraceErrCode = 2
 const StraceInitialStackSize = 16
 
 //line yacctab:1
-var StraceExca = [...]int{
+var StraceExca = [...]int8{
 	-1, 1,
 	1, -1,
 	-2, 0,
@@ -134,132 +135,143 @@ var StraceExca = [...]int{
 
 const StracePrivate = 57344
 
-const StraceLast = 378
-
-var StraceAct = [...]int{
-
-	17, 91, 59, 38, 14, 18, 26, 27, 2, 10,
-	90, 39, 26, 27, 4, 38, 6, 50, 36, 104,
-	48, 30, 57, 50, 25, 49, 24, 30, 29, 56,
-	86, 35, 34, 28, 29, 54, 55, 51, 33, 28,
-	3, 40, 64, 65, 66, 67, 88, 68, 69, 70,
-	71, 72, 73, 74, 40, 11, 49, 97, 100, 80,
-	84, 39, 105, 101, 102, 38, 37, 103, 93, 94,
-	5, 58, 45, 89, 39, 95, 96, 45, 38, 46,
-	92, 25, 36, 24, 99, 85, 114, 83, 45, 82,
-	99, 99, 108, 98, 45, 46, 47, 75, 43, 44,
-	106, 46, 47, 16, 99, 15, 99, 99, 108, 110,
-	99, 108, 99, 108, 109, 0, 111, 97, 100, 61,
-	62, 0, 81, 101, 102, 1, 97, 100, 93, 94,
-	8, 0, 101, 102, 63, 95, 96, 93, 94, 0,
-	92, 25, 0, 24, 95, 96, 113, 0, 0, 92,
-	25, 0, 24, 98, 0, 112, 0, 97, 100, 0,
-	0, 0, 98, 101, 102, 0, 97, 100, 93, 94,
-	0, 0, 101, 102, 0, 95, 96, 93, 94, 0,
-	92, 25, 7, 24, 95, 96, 107, 0, 9, 92,
-	25, 0, 24, 98, 0, 0, 20, 0, 21, 0,
-	22, 0, 98, 23, 26, 27, 0, 52, 53, 20,
-	0, 21, 0, 22, 0, 19, 23, 26, 27, 30,
-	0, 13, 25, 0, 24, 0, 29, 32, 19, 31,
-	0, 28, 30, 0, 77, 25, 78, 24, 20, 29,
-	21, 0, 22, 0, 28, 23, 26, 27, 0, 0,
-	0, 20, 0, 21, 0, 22, 0, 19, 23, 26,
-	27, 30, 0, 13, 25, 0, 24, 76, 29, 0,
-	19, 0, 0, 28, 30, 0, 13, 25, 0, 24,
-	0, 29, 12, 20, 0, 21, 28, 22, 0, 0,
-	23, 26, 27, 0, 0, 0, 20, 0, 21, 0,
-	22, 0, 19, 23, 26, 27, 30, 0, 0, 25,
-	87, 24, 0, 29, 0, 19, 0, 0, 28, 30,
-	0, 0, 25, 0, 24, 0, 29, 41, 42, 0,
-	45, 28, 0, 0, 43, 44, 0, 46, 47, 41,
-	42, 0, 45, 0, 0, 79, 43, 44, 0, 46,
-	47, 42, 0, 45, 0, 0, 0, 43, 44, 0,
-	46, 47, 61, 62, 0, 60, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 63,
+const StraceLast = 456
+
+var StraceAct = [...]int8{
+	17, 101, 7, 65, 14, 19, 41, 42, 9, 2,
+	100, 41, 43, 29, 30, 4, 10, 6, 39, 114,
+	63, 51, 62, 38, 53, 37, 52, 36, 33, 57,
+	58, 27, 42, 26, 11, 32, 41, 40, 60, 61,
+	31, 3, 98, 92, 115, 70, 71, 72, 73, 113,
+	74, 75, 76, 77, 78, 79, 80, 43, 81, 52,
+	29, 30, 86, 56, 59, 55, 88, 107, 110, 99,
+	91, 53, 5, 111, 112, 33, 95, 42, 103, 104,
+	48, 41, 32, 93, 64, 105, 106, 31, 48, 39,
+	102, 27, 109, 26, 90, 49, 124, 82, 48, 54,
+	109, 109, 118, 108, 48, 49, 50, 18, 46, 47,
+	116, 49, 50, 16, 109, 15, 109, 109, 118, 120,
+	109, 118, 109, 118, 119, 22, 121, 23, 28, 24,
+	0, 0, 25, 29, 30, 44, 45, 0, 48, 21,
+	0, 0, 46, 47, 20, 49, 50, 1, 33, 0,
+	13, 27, 8, 26, 0, 32, 35, 0, 34, 0,
+	31, 107, 110, 67, 68, 0, 89, 111, 112, 0,
+	107, 110, 103, 104, 0, 0, 111, 112, 69, 105,
+	106, 103, 104, 0, 102, 27, 0, 26, 105, 106,
+	123, 0, 0, 102, 27, 0, 26, 108, 0, 122,
+	0, 107, 110, 0, 0, 0, 108, 111, 112, 0,
+	0, 0, 103, 104, 67, 68, 0, 66, 0, 105,
+	106, 0, 0, 0, 102, 27, 0, 26, 0, 69,
+	117, 22, 0, 23, 28, 24, 0, 108, 25, 29,
+	30, 0, 0, 0, 22, 21, 23, 28, 24, 0,
+	20, 25, 29, 30, 33, 0, 13, 27, 21, 26,
+	0, 32, 97, 20, 0, 0, 31, 33, 0, 13,
+	27, 0, 26, 0, 32, 94, 0, 0, 22, 31,
+	23, 28, 24, 0, 0, 25, 29, 30, 0, 45,
+	0, 48, 21, 0, 0, 46, 47, 20, 49, 50,
+	0, 33, 0, 84, 27, 85, 26, 22, 32, 23,
+	28, 24, 0, 31, 25, 29, 30, 0, 0, 0,
+	22, 21, 23, 28, 24, 0, 20, 25, 29, 30,
+	33, 0, 13, 27, 21, 26, 83, 32, 0, 20,
+	0, 0, 31, 33, 0, 13, 27, 0, 26, 0,
+	32, 12, 107, 110, 0, 31, 0, 0, 111, 112,
+	0, 0, 0, 103, 104, 0, 0, 0, 0, 0,
+	105, 106, 0, 0, 0, 102, 27, 22, 26, 23,
+	28, 24, 0, 0, 25, 29, 30, 0, 108, 0,
+	0, 21, 0, 0, 0, 0, 20, 0, 0, 0,
+	33, 0, 0, 27, 96, 26, 22, 32, 23, 28,
+	24, 0, 31, 25, 29, 30, 0, 0, 0, 0,
+	21, 0, 0, 0, 0, 20, 0, 0, 0, 33,
+	0, 0, 27, 0, 26, 0, 32, 44, 45, 0,
+	48, 31, 0, 0, 46, 47, 0, 49, 50, 0,
+	0, 0, 0, 0, 0, 87,
 }
-var StracePact = [...]int{
-
-	2, -1000, 36, -21, 2, -29, 20, 247, -1000, 192,
-	3, -4, -5, 292, 25, -1000, -1000, -1000, 322, -6,
-	-1000, 5, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 0,
-	0, -1000, -7, -14, 56, 350, 25, 292, 292, 292,
-	292, 0, 0, 0, 0, 0, 0, 0, -1000, -1000,
-	0, 90, 234, 205, 310, -1000, 107, 77, -1000, 53,
-	-1000, -1000, -1000, 73, 38, -1000, -37, -25, 333, 74,
-	68, 68, -1000, 57, 52, -3, -1000, 279, -1000, -1000,
-	39, -1000, -1000, 160, 33, -1000, -17, -1000, 28, 160,
-	151, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, 160, 292, 160, 120, -1000, -1000, 111,
-	-37, 51, -1000, -1000, -1000,
+
+var StracePact = [...]int16{
+	3, -1000, 38, -20, 3, -22, -1, 316, -1000, 121,
+	-8, -11, -13, 402, -4, -1000, -1000, -1000, -1000, 118,
+	1, 93, -1000, 31, -1000, -1000, -1000, -1000, 30, -1000,
+	-1000, -1000, 48, 48, -1000, -14, -16, 69, 202, -4,
+	402, 402, 402, 402, 48, 48, 48, 48, 48, 48,
+	48, -1000, -1000, 48, -1000, -1000, 90, 303, 274, -1000,
+	420, -1000, 151, 82, -1000, 36, -1000, -1000, -1000, 71,
+	41, -1000, -34, -29, 271, 84, 78, 78, -1000, 60,
+	68, 240, 43, -1000, 373, -1000, 227, -1000, 35, -1000,
+	-1000, 346, 15, -1000, -1000, -17, -1000, -1000, 10, 346,
+	195, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, 346, 402, 346, 164, -1000, -1000, 155,
+	-34, 61, -1000, -1000, -1000,
 }
-var StracePgo = [...]int{
 
-	0, 125, 2, 105, 0, 5, 1, 10, 4, 103,
-	182,
+var StracePgo = [...]uint8{
+	0, 147, 3, 115, 0, 5, 1, 10, 4, 113,
+	107, 2,
 }
-var StraceR1 = [...]int{
 
+var StraceR1 = [...]int8{
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 7, 7, 6, 6, 6, 6, 6,
-	6, 6, 6, 6, 6, 6, 2, 2, 2, 10,
-	10, 10, 8, 8, 8, 8, 8, 5, 5, 5,
+	6, 6, 6, 6, 6, 6, 2, 2, 2, 11,
+	11, 11, 8, 8, 8, 8, 8, 8, 8, 5,
 	5, 5, 5, 5, 5, 5, 5, 5, 5, 5,
-	4, 4, 4, 9, 9, 9, 9, 9, 3, 3,
-	3, 3,
+	5, 5, 4, 4, 4, 10, 10, 9, 9, 9,
+	9, 9, 3, 3, 3, 3,
 }
-var StraceR2 = [...]int{
 
+var StraceR2 = [...]int8{
 	0, 4, 5, 6, 5, 5, 8, 9, 6, 6,
 	10, 9, 2, 1, 2, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 2, 0,
-	3, 2, 1, 1, 1, 1, 2, 1, 1, 1,
-	3, 3, 3, 3, 3, 3, 3, 3, 2, 2,
-	3, 3, 4, 3, 3, 3, 3, 6, 1, 1,
-	1, 1,
+	3, 2, 1, 1, 1, 1, 1, 2, 2, 1,
+	1, 1, 3, 3, 3, 3, 3, 3, 3, 3,
+	2, 2, 3, 3, 4, 4, 4, 3, 3, 3,
+	3, 6, 1, 1, 1, 1,
 }
-var StraceChk = [...]int{
-
-	-1000, -1, 6, 38, 12, 34, 37, -10, -1, -10,
-	38, 35, 35, 29, -8, -3, -9, -4, -5, 23,
-	4, 6, 8, 11, 32, 30, 12, 13, 39, 34,
-	27, 37, 35, 35, 36, 36, -8, 41, 40, 36,
-	16, 17, 18, 24, 25, 20, 27, 28, -4, -5,
-	23, 32, -10, -10, -5, -5, 36, 36, 15, -2,
-	15, 12, 13, 27, -8, -8, -8, -8, -5, -5,
-	-5, -5, -5, -5, -5, 7, 33, 29, 31, 35,
-	-2, 15, 12, 34, 7, 12, 33, 31, 7, 34,
+
+var StraceChk = [...]int16{
+	-1000, -1, 6, 38, 12, 34, 37, -11, -1, -11,
+	38, 35, 35, 29, -8, -3, -9, -4, -10, -5,
+	23, 18, 4, 6, 8, 11, 32, 30, 7, 12,
+	13, 39, 34, 27, 37, 35, 35, 36, 36, -8,
+	41, 40, 36, 16, 17, 18, 24, 25, 20, 27,
+	28, -4, -5, 23, 6, 34, 32, -11, -11, 34,
+	-5, -5, 36, 36, 15, -2, 15, 12, 13, 27,
+	-8, -8, -8, -8, -5, -5, -5, -5, -5, -5,
+	-5, -11, 7, 33, 29, 31, -11, 35, -2, 15,
+	12, 34, 7, 12, 35, 33, 31, 35, 7, 34,
 	-7, -6, 29, 17, 18, 24, 25, 6, 42, -4,
 	7, 12, 13, 34, 36, 34, -7, 35, -6, -7,
 	-8, -7, 35, 35, 35,
 }
-var StraceDef = [...]int{
 
+var StraceDef = [...]int8{
 	0, -2, 0, 29, 0, 29, 0, 0, 12, 0,
-	0, 0, 0, 0, 31, 32, 33, 34, 35, 0,
-	58, 59, 60, 61, 29, 29, 37, 38, 39, 0,
-	0, 1, 0, 0, 0, 0, 30, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 36, 48,
-	0, 0, 0, 0, 0, 49, 0, 0, 2, 4,
-	5, 26, 27, 0, 53, 54, 55, 56, 40, 41,
-	42, 43, 45, 46, 47, 0, 50, 0, 51, 44,
-	8, 9, 3, 0, 0, 28, 0, 52, 0, 0,
+	0, 0, 0, 0, 31, 32, 33, 34, 35, 36,
+	0, 0, 62, 63, 64, 65, 29, 29, 0, 39,
+	40, 41, 0, 0, 1, 0, 0, 0, 0, 30,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 37, 50, 0, 38, 29, 0, 0, 0, 29,
+	0, 51, 0, 0, 2, 4, 5, 26, 27, 0,
+	57, 58, 59, 60, 42, 43, 44, 45, 47, 48,
+	49, 0, 0, 52, 0, 53, 0, 46, 8, 9,
+	3, 0, 0, 28, 55, 0, 54, 56, 0, 0,
 	0, 13, 15, 16, 17, 18, 19, 20, 21, 22,
 	23, 24, 25, 0, 0, 0, 0, 6, 14, 0,
-	57, 0, 11, 7, 10,
+	61, 0, 11, 7, 10,
 }
-var StraceTok1 = [...]int{
 
+var StraceTok1 = [...]int8{
 	1,
 }
-var StraceTok2 = [...]int{
 
+var StraceTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
 	42, 43, 44, 45,
 }
-var StraceTok3 = [...]int{
+
+var StraceTok3 = [...]int8{
 	0,
 }
 
@@ -341,9 +353,9 @@ func StraceErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := StracePact[state]
+	base := int(StracePact[state])
 	for tok := TOKSTART; tok-1 < len(StraceToknames); tok++ {
-		if n := base + tok; n >= 0 && n < StraceLast && StraceChk[StraceAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < StraceLast && int(StraceChk[int(StraceAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -353,13 +365,13 @@ func StraceErrorMessage(state, lookAhead int) string {
 
 	if StraceDef[state] == -2 {
 		i := 0
-		for StraceExca[i] != -1 || StraceExca[i+1] != state {
+		for StraceExca[i] != -1 || int(StraceExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; StraceExca[i] >= 0; i += 2 {
-			tok := StraceExca[i]
+			tok := int(StraceExca[i])
 			if tok < TOKSTART || StraceExca[i+1] == 0 {
 				continue
 			}
@@ -390,30 +402,30 @@ func Stracelex1(lex StraceLexer, lval *StraceSymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = StraceTok1[0]
+		token = int(StraceTok1[0])
 		goto out
 	}
 	if char < len(StraceTok1) {
-		token = StraceTok1[char]
+		token = int(StraceTok1[char])
 		goto out
 	}
 	if char >= StracePrivate {
 		if char < StracePrivate+len(StraceTok2) {
-			token = StraceTok2[char-StracePrivate]
+			token = int(StraceTok2[char-StracePrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(StraceTok3); i += 2 {
-		token = StraceTok3[i+0]
+		token = int(StraceTok3[i+0])
 		if token == char {
-			token = StraceTok3[i+1]
+			token = int(StraceTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = StraceTok2[1] /* unknown char */
+		token = int(StraceTok2[1]) /* unknown char */
 	}
 	if StraceDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", StraceTokname(token), uint(char))
@@ -468,7 +480,7 @@ Stracestack:
 	StraceS[Stracep].yys = Stracestate
 
 Stracenewstate:
-	Stracen = StracePact[Stracestate]
+	Stracen = int(StracePact[Stracestate])
 	if Stracen <= StraceFlag {
 		goto Stracedefault /* simple state */
 	}
@@ -479,8 +491,8 @@ Stracenewstate:
 	if Stracen < 0 || Stracen >= StraceLast {
 		goto Stracedefault
 	}
-	Stracen = StraceAct[Stracen]
-	if StraceChk[Stracen] == Stracetoken { /* valid shift */
+	Stracen = int(StraceAct[Stracen])
+	if int(StraceChk[Stracen]) == Stracetoken { /* valid shift */
 		Stracercvr.char = -1
 		Stracetoken = -1
 		StraceVAL = Stracercvr.lval
@@ -493,7 +505,7 @@ Stracenewstate:
 
 Stracedefault:
 	/* default state action */
-	Stracen = StraceDef[Stracestate]
+	Stracen = int(StraceDef[Stracestate])
 	if Stracen == -2 {
 		if Stracercvr.char < 0 {
 			Stracercvr.char, Stracetoken = Stracelex1(Stracelex, &Stracercvr.lval)
@@ -502,18 +514,18 @@ Stracedefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if StraceExca[xi+0] == -1 && StraceExca[xi+1] == Stracestate {
+			if StraceExca[xi+0] == -1 && int(StraceExca[xi+1]) == Stracestate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			Stracen = StraceExca[xi+0]
+			Stracen = int(StraceExca[xi+0])
 			if Stracen < 0 || Stracen == Stracetoken {
 				break
 			}
 		}
-		Stracen = StraceExca[xi+1]
+		Stracen = int(StraceExca[xi+1])
 		if Stracen < 0 {
 			goto ret0
 		}
@@ -535,10 +547,10 @@ Stracedefault:
 
 			/* find a state where "error" is a legal shift action */
 			for Stracep >= 0 {
-				Stracen = StracePact[StraceS[Stracep].yys] + StraceErrCode
+				Stracen = int(StracePact[StraceS[Stracep].yys]) + StraceErrCode
 				if Stracen >= 0 && Stracen < StraceLast {
-					Stracestate = StraceAct[Stracen] /* simulate a shift of "error" */
-					if StraceChk[Stracestate] == StraceErrCode {
+					Stracestate = int(StraceAct[Stracen]) /* simulate a shift of "error" */
+					if int(StraceChk[Stracestate]) == StraceErrCode {
 						goto Stracestack
 					}
 				}
@@ -574,7 +586,7 @@ Stracedefault:
 	Stracept := Stracep
 	_ = Stracept // guard against "declared and not used"
 
-	Stracep -= StraceR2[Stracen]
+	Stracep -= int(StraceR2[Stracen])
 	// Stracep is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if Stracep+1 >= len(StraceS) {
@@ -585,16 +597,16 @@ Stracedefault:
 	StraceVAL = StraceS[Stracep+1]
 
 	/* consult goto table to find next state */
-	Stracen = StraceR1[Stracen]
-	Straceg := StracePgo[Stracen]
+	Stracen = int(StraceR1[Stracen])
+	Straceg := int(StracePgo[Stracen])
 	Stracej := Straceg + StraceS[Stracep].yys + 1
 
 	if Stracej >= StraceLast {
-		Stracestate = StraceAct[Straceg]
+		Stracestate = int(StraceAct[Straceg])
 	} else {
-		Stracestate = StraceAct[Stracej]
-		if StraceChk[Stracestate] != -Stracen {
-			Stracestate = StraceAct[Straceg]
+		Stracestate = int(StraceAct[Stracej])
+		if int(StraceChk[Stracestate]) != -Stracen {
+			Stracestate = int(StraceAct[Straceg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -823,161 +835,185 @@ Stracedefault:
 		StraceDollar = StraceS[Stracept-1 : Stracept+1]
 //line strace.y:130
 		{
-			StraceVAL.val_type = StraceDollar[1].val_constant
+			StraceVAL.val_type = StraceDollar[1].val_type
 		}
 	case 36:
-		StraceDollar = StraceS[Stracept-2 : Stracept+1]
+		StraceDollar = StraceS[Stracept-1 : Stracept+1]
 //line strace.y:131
 		{
-			StraceVAL.val_type = StraceDollar[2].val_group_type
+			StraceVAL.val_type = StraceDollar[1].val_constant
 		}
 	case 37:
-		StraceDollar = StraceS[Stracept-1 : Stracept+1]
-//line strace.y:134
+		StraceDollar = StraceS[Stracept-2 : Stracept+1]
+//line strace.y:132
 		{
-			StraceVAL.val_constant = Constant(StraceDollar[1].val_int)
+			StraceVAL.val_type = StraceDollar[2].val_group_type
 		}
 	case 38:
-		StraceDollar = StraceS[Stracept-1 : Stracept+1]
-//line strace.y:135
+		StraceDollar = StraceS[Stracept-2 : Stracept+1]
+//line strace.y:133
 		{
-			StraceVAL.val_constant = Constant(StraceDollar[1].val_uint)
+			StraceVAL.val_type = newBufferType("&" + StraceDollar[2].data)
 		}
 	case 39:
 		StraceDollar = StraceS[Stracept-1 : Stracept+1]
 //line strace.y:136
 		{
-			StraceVAL.val_constant = Constant(uint64(0))
+			StraceVAL.val_constant = Constant(StraceDollar[1].val_int)
 		}
 	case 40:
-		StraceDollar = StraceS[Stracept-3 : Stracept+1]
+		StraceDollar = StraceS[Stracept-1 : Stracept+1]
 //line strace.y:137
 		{
-			StraceVAL.val_constant = StraceDollar[1].val_constant | StraceDollar[3].val_constant
+			StraceVAL.val_constant = Constant(StraceDollar[1].val_uint)
 		}
 	case 41:
-		StraceDollar = StraceS[Stracept-3 : Stracept+1]
+		StraceDollar = StraceS[Stracept-1 : Stracept+1]
 //line strace.y:138
 		{
-			StraceVAL.val_constant = StraceDollar[1].val_constant & StraceDollar[3].val_constant
+			StraceVAL.val_constant = Constant(uint64(0))
 		}
 	case 42:
 		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:139
 		{
-			StraceVAL.val_constant = StraceDollar[1].val_constant << StraceDollar[3].val_constant
+			StraceVAL.val_constant = StraceDollar[1].val_constant | StraceDollar[3].val_constant
 		}
 	case 43:
 		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:140
 		{
-			StraceVAL.val_constant = StraceDollar[1].val_constant >> StraceDollar[3].val_constant
+			StraceVAL.val_constant = StraceDollar[1].val_constant & StraceDollar[3].val_constant
 		}
 	case 44:
 		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:141
 		{
-			StraceVAL.val_constant = StraceDollar[2].val_constant
+			StraceVAL.val_constant = StraceDollar[1].val_constant << StraceDollar[3].val_constant
 		}
 	case 45:
 		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:142
 		{
-			StraceVAL.val_constant = StraceDollar[1].val_constant * StraceDollar[3].val_constant
+			StraceVAL.val_constant = StraceDollar[1].val_constant >> StraceDollar[3].val_constant
 		}
 	case 46:
 		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:143
 		{
-			StraceVAL.val_constant = StraceDollar[1].val_constant - StraceDollar[3].val_constant
+			StraceVAL.val_constant = StraceDollar[2].val_constant
 		}
 	case 47:
 		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:144
 		{
-			StraceVAL.val_constant = StraceDollar[1].val_constant + StraceDollar[3].val_constant
+			StraceVAL.val_constant = StraceDollar[1].val_constant * StraceDollar[3].val_constant
 		}
 	case 48:
-		StraceDollar = StraceS[Stracept-2 : Stracept+1]
+		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:145
 		{
-			StraceVAL.val_constant = ^StraceDollar[2].val_constant
+			StraceVAL.val_constant = StraceDollar[1].val_constant - StraceDollar[3].val_constant
 		}
 	case 49:
-		StraceDollar = StraceS[Stracept-2 : Stracept+1]
+		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:146
 		{
-			StraceVAL.val_constant = Constant(-int64(StraceDollar[2].val_constant))
+			StraceVAL.val_constant = StraceDollar[1].val_constant + StraceDollar[3].val_constant
 		}
 	case 50:
-		StraceDollar = StraceS[Stracept-3 : Stracept+1]
-//line strace.y:149
+		StraceDollar = StraceS[Stracept-2 : Stracept+1]
+//line strace.y:147
 		{
-			StraceVAL.val_group_type = newGroupType(StraceDollar[2].val_types)
+			StraceVAL.val_constant = ^StraceDollar[2].val_constant
 		}
 	case 51:
-		StraceDollar = StraceS[Stracept-3 : Stracept+1]
-//line strace.y:150
+		StraceDollar = StraceS[Stracept-2 : Stracept+1]
+//line strace.y:148
 		{
-			StraceVAL.val_group_type = newGroupType(StraceDollar[2].val_types)
+			StraceVAL.val_constant = Constant(-int64(StraceDollar[2].val_constant))
 		}
 	case 52:
-		StraceDollar = StraceS[Stracept-4 : Stracept+1]
+		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:151
 		{
 			StraceVAL.val_group_type = newGroupType(StraceDollar[2].val_types)
 		}
 	case 53:
 		StraceDollar = StraceS[Stracept-3 : Stracept+1]
-//line strace.y:154
+//line strace.y:152
 		{
-			StraceVAL.val_type = StraceDollar[3].val_type
+			StraceVAL.val_group_type = newGroupType(StraceDollar[2].val_types)
 		}
 	case 54:
-		StraceDollar = StraceS[Stracept-3 : Stracept+1]
-//line strace.y:155
+		StraceDollar = StraceS[Stracept-4 : Stracept+1]
+//line strace.y:153
 		{
-			StraceVAL.val_type = StraceDollar[3].val_type
+			StraceVAL.val_group_type = newGroupType(StraceDollar[2].val_types)
 		}
 	case 55:
-		StraceDollar = StraceS[Stracept-3 : Stracept+1]
+		StraceDollar = StraceS[Stracept-4 : Stracept+1]
 //line strace.y:156
 		{
-			StraceVAL.val_type = StraceDollar[3].val_type
+			StraceVAL.val_type = newMacro(StraceDollar[1].data, StraceDollar[3].val_types)
 		}
 	case 56:
-		StraceDollar = StraceS[Stracept-3 : Stracept+1]
+		StraceDollar = StraceS[Stracept-4 : Stracept+1]
 //line strace.y:157
 		{
-			StraceVAL.val_type = StraceDollar[1].val_type
+			StraceVAL.val_type = newMacro(StraceDollar[1].data, StraceDollar[3].val_types)
 		}
 	case 57:
-		StraceDollar = StraceS[Stracept-6 : Stracept+1]
-//line strace.y:158
+		StraceDollar = StraceS[Stracept-3 : Stracept+1]
+//line strace.y:160
 		{
-			StraceVAL.val_type = StraceDollar[6].val_type
+			StraceVAL.val_type = fieldValue(StraceDollar[3].val_type)
 		}
 	case 58:
-		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:161
 		{
-			StraceVAL.val_buf_type = newBufferType(StraceDollar[1].data)
+			StraceVAL.val_type = fieldValue(StraceDollar[3].val_type)
 		}
 	case 59:
-		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:162
 		{
-			StraceVAL.val_buf_type = newBufferType(StraceDollar[1].data)
+			StraceVAL.val_type = newField(StraceDollar[1].val_type, StraceDollar[3].val_type)
 		}
 	case 60:
-		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+		StraceDollar = StraceS[Stracept-3 : Stracept+1]
 //line strace.y:163
 		{
-			StraceVAL.val_buf_type = newBufferType(StraceDollar[1].data)
+			StraceVAL.val_type = StraceDollar[1].val_type
 		}
 	case 61:
-		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+		StraceDollar = StraceS[Stracept-6 : Stracept+1]
 //line strace.y:164
+		{
+			StraceVAL.val_type = newField(newBufferType(StraceDollar[1].data+"["+StraceDollar[3].data+"]"), StraceDollar[6].val_type)
+		}
+	case 62:
+		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+//line strace.y:167
+		{
+			StraceVAL.val_buf_type = newBufferType(StraceDollar[1].data)
+		}
+	case 63:
+		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+//line strace.y:168
+		{
+			StraceVAL.val_buf_type = newBufferType(StraceDollar[1].data)
+		}
+	case 64:
+		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+//line strace.y:169
+		{
+			StraceVAL.val_buf_type = newBufferType(StraceDollar[1].data)
+		}
+	case 65:
+		StraceDollar = StraceS[Stracept-1 : Stracept+1]
+//line strace.y:170
 		{
 			StraceVAL.val_buf_type = newBufferType(StraceDollar[1].data)
 		}
@@ -2,7 +2,7 @@
 // Copyright 2018 syzkaller project authors. All rights reserved.
 // Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
 
-// +build !codeanalysis
+//go:build !codeanalysis
 
 package parser
 
@@ -33,7 +33,7 @@ package parser
 %type <val_buf_type> buf_type
 %type <val_group_type> group_type
 %type <val_constant> constant
-%type <val_type> parenthetical, parentheticals, type, field_type
+%type <val_type> parenthetical, parentheticals, type, field_type, macro_type
 %type <val_types> types
 %type <val_syscall> syscall
 
@@ -45,7 +45,7 @@ package parser
 
 %nonassoc LOWEST
 %nonassoc NOFLAG
-%nonassoc LBRACKET_SQUARE
+%nonassoc LBRACKET_SQUARE LPAREN
 
 %left OR
 %left AND
@@ -127,8 +127,10 @@ type:
     buf_type {$$ = $1}
     | field_type {$$ = $1}
     | group_type {$$ = $1}
+    | macro_type {$$ = $1}
     | constant %prec LOWEST {$$ = $1}
     | ONESCOMP group_type {$$ = $2}
+    | AND IDENTIFIER {$$ = newBufferType("&" + $2)}
 
 constant:
     INT {$$ = Constant($1)}
@@ -150,12 +152,16 @@ group_type:
     | LBRACKET types RBRACKET {$$ = newGroupType($2)}
     | LBRACKET types COMMA RBRACKET {$$ = newGroupType($2)}
 
+macro_type:
+    IDENTIFIER LPAREN types RPAREN {$$ = newMacro($1, $3)}
+    | FLAG LPAREN types RPAREN {$$ = newMacro($1, $3)}
+
 field_type:
-    type COLON type {$$ = $3}
-    | type EQUALAT type {$$ = $3}
-    | type EQUALS type {$$ = $3}
+    type COLON type {$$ = fieldValue($3)}
+    | type EQUALAT type {$$ = fieldValue($3)}
+    | type EQUALS type {$$ = newField($1, $3)}
     | type ARROW type {$$ = $1}
-    | IDENTIFIER LBRACKET_SQUARE FLAG RBRACKET_SQUARE EQUALS type {$$ = $6}
+    | IDENTIFIER LBRACKET_SQUARE FLAG RBRACKET_SQUARE EQUALS type {$$ = newField(newBufferType($1+"["+$3+"]"), $6)}
 
 buf_type:
     STRING_LITERAL {$$ = newBufferType($1)}
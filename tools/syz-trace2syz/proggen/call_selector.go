@@ -18,17 +18,20 @@ var discriminatorArgs = map[string][]int{
 	"socket":      {0, 1, 2},
 	"socketpair":  {0, 1, 2},
 	"ioctl":       {0, 1},
-	"getsockopt":  {1, 2},
-	"setsockopt":  {1, 2},
+	"getsockopt":  {0, 1, 2},
+	"setsockopt":  {0, 1, 2},
 	"accept":      {0},
 	"accept4":     {0},
 	"bind":        {0},
 	"connect":     {0},
+	"epoll_ctl":   {1},
 	"recvfrom":    {0},
 	"sendto":      {0},
 	"sendmsg":     {0},
 	"getsockname": {0},
 	"openat":      {1},
+	"prctl":       {0},
+	"seccomp":     {0},
 }
 
 var openDiscriminatorArgs = map[string]int{
@@ -44,6 +47,7 @@ type callSelector interface {
 func newSelectors(target *prog.Target, returnCache returnCache) []callSelector {
 	sc := newSelectorCommon(target, returnCache)
 	return []callSelector{
+		&netlinkCallSelector{sc},
 		&defaultCallSelector{sc},
 		&openCallSelector{sc},
 	}
@@ -139,8 +143,20 @@ func (cs *openCallSelector) Select(call *parser.Syscall) *prog.Syscall {
 }
 
 func (cs *openCallSelector) matchOpen(meta *prog.Syscall, call *parser.Syscall) (bool, int) {
-	straceFileArg := call.Args[openDiscriminatorArgs[call.CallName]]
-	straceBuf := straceFileArg.(*parser.BufferType).Val
+	idx := openDiscriminatorArgs[call.CallName]
+	if idx >= len(call.Args) {
+		return false, -1
+	}
+	// Tracers other than strace may fail to read the file name.
+	straceFileArg, ok := call.Args[idx].(*parser.BufferType)
+	if !ok {
+		return false, -1
+	}
+	return cs.matchPath(meta, straceFileArg.Val)
+}
+
+// matchPath checks if the open-like call meta opens the file.
+func (cs *selectorCommon) matchPath(meta *prog.Syscall, straceBuf string) (bool, int) {
 	syzFileArg := meta.Args[openDiscriminatorArgs[meta.CallName]].Type
 	if _, ok := syzFileArg.(*prog.PtrType); !ok {
 		return false, -1
@@ -215,12 +231,19 @@ func (cs *defaultCallSelector) matchCall(meta *prog.Syscall, call *parser.Syscal
 		case *prog.ResourceType:
 			// Resources must match one of subtypes,
 			// the more precise match, the higher the score.
-			retArg := cs.returnCache.get(t, arg)
-			if retArg == nil {
-				return -1
+			var kinds []string
+			if retArg := cs.returnCache.get(t, arg); retArg != nil {
+				kinds = retArg.Type().(*prog.ResourceType).Desc.Kind
+			} else if res := cs.fdResource(call, arg); res != nil {
+				// The resource was not returned by any of the preceding calls (e.g. the call that returned it
+				// does not have a description, or the process inherited it), but strace decoded it.
+				kinds = res.Kind
+			} else {
+				// Nothing is known about the resource, so it can't be used to select the variant.
+				continue
 			}
 			matched := false
-			for i, kind := range retArg.Type().(*prog.ResourceType).Desc.Kind {
+			for i, kind := range kinds {
 				if kind == t.Desc.Name {
 					score += i + 1
 					matched = true
@@ -256,3 +279,35 @@ func (cs *defaultCallSelector) matchCall(meta *prog.Syscall, call *parser.Syscal
 	}
 	return score
 }
+
+type netlinkCallSelector struct {
+	*selectorCommon
+}
+
+// Select returns the generic sendmsg for netlink messages decoded by strace.
+// Netlink messages are serialized back into raw buffers, since matching decoded messages
+// with the sendmsg$nl_* descriptions is not supported.
+func (cs *netlinkCallSelector) Select(call *parser.Syscall) *prog.Syscall {
+	if call.CallName != "sendmsg" || len(call.Args) < 2 {
+		return nil
+	}
+	msg, ok := call.Args[1].(*parser.GroupType)
+	if !ok {
+		return nil
+	}
+	for i, name := range msg.Names {
+		if name != "msg_iov" {
+			continue
+		}
+		iovs, ok := msg.Elems[i].(*parser.GroupType)
+		if !ok {
+			return nil
+		}
+		for _, iov := range iovs.Elems {
+			if iov, ok := iov.(*parser.GroupType); ok && len(iov.Elems) != 0 && isNetlinkData(iov.Elems[0]) {
+				return cs.target.SyscallMap[call.CallName]
+			}
+		}
+	}
+	return nil
+}
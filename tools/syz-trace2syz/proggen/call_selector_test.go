@@ -3,7 +3,14 @@
 
 package proggen
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/google/syzkaller/tools/syz-trace2syz/parser"
+)
 
 func TestMatchFilename(t *testing.T) {
 	sc := selectorCommon{}
@@ -40,3 +47,36 @@ func TestMatchFilename(t *testing.T) {
 		}
 	}
 }
+
+func TestFdResource(t *testing.T) {
+	target, err := prog.GetTarget(targets.Linux, targets.AMD64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := newSelectorCommon(target, newRCache())
+	tests := map[string]string{
+		"/dev/kvm":                            "fd_kvm",
+		"/dev/loop0":                          "fd_loop",
+		"/tmp/file":                           "",
+		"TCP:[127.0.0.1:41234->127.0.0.1:80]": "sock_tcp",
+		"TCPv6:[[::1]:41234->[::1]:80]":       "sock_tcp6",
+		"UNIX-STREAM:[12345->12346]":          "sock_unix",
+		"NETLINK:[ROUTE:1234]":                "sock_nl_route",
+		"NETLINK:[NETLINK_GENERIC:1234]":      "sock_nl_generic",
+		"socket:[12345]":                      "sock",
+		"anon_inode:[eventfd]":                "fd_event",
+		"anon_inode:kvm-vcpu:0":               "fd_kvmcpu",
+		"anon_inode:[signalfd]":               "",
+		"pipe:[12345]":                        "",
+	}
+	for desc, want := range tests {
+		call := &parser.Syscall{Fds: map[uint64]string{3: desc}}
+		got := ""
+		if res := sc.fdResource(call, parser.Constant(3)); res != nil {
+			got = res.Name
+		}
+		if got != want {
+			t.Errorf("%v: want %q, got %q", desc, want, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package proggen
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/tools/syz-trace2syz/parser"
+)
+
+// fdPrefixes maps prefixes of the fd descriptions printed by strace -yy to resources.
+// Longer prefixes go first.
+var fdPrefixes = []struct {
+	prefix   string
+	resource string
+}{
+	{"TCPv6:", "sock_tcp6"},
+	{"TCP:", "sock_tcp"},
+	{"UDPv6:", "sock_udp6"},
+	{"UDP:", "sock_udp"},
+	{"UNIX", "sock_unix"},
+	{"NETLINK:[ROUTE", "sock_nl_route"},
+	{"NETLINK:[GENERIC", "sock_nl_generic"},
+	{"NETLINK:[AUDIT", "sock_nl_audit"},
+	{"NETLINK:[NETFILTER", "sock_nl_netfilter"},
+	{"NETLINK:[XFRM", "sock_nl_xfrm"},
+	{"socket:", "sock"},
+	{"anon_inode:[eventfd]", "fd_event"},
+	{"anon_inode:[eventpoll]", "fd_epoll"},
+	{"anon_inode:[timerfd]", "fd_timer"},
+	{"anon_inode:inotify", "fd_inotify"},
+	{"anon_inode:[fanotify]", "fd_fanotify"},
+	{"anon_inode:bpf-map", "fd_bpf_map"},
+	{"anon_inode:bpf-prog", "fd_bpf_prog"},
+	{"anon_inode:[io_uring]", "fd_io_uring"},
+	{"anon_inode:[userfaultfd]", "fd_uffd"},
+	{"anon_inode:[perf_event]", "fd_perf"},
+	{"anon_inode:[pidfd]", "fd_pidfd"},
+	{"pid:", "fd_pidfd"},
+	{"anon_inode:kvm-vm", "fd_kvmvm"},
+	{"anon_inode:kvm-vcpu", "fd_kvmcpu"},
+}
+
+// fdResource returns the resource of the file descriptor passed as arg to the call
+// based on the description printed by strace with -y/-yy, or nil if it's unknown.
+func (cs *selectorCommon) fdResource(call *parser.Syscall, arg parser.IrType) *prog.ResourceDesc {
+	fd, ok := arg.(parser.Constant)
+	if !ok {
+		return nil
+	}
+	desc := call.Fds[fd.Val()]
+	if desc == "" {
+		return nil
+	}
+	if strings.HasPrefix(desc, "/") {
+		return cs.fileResource(desc)
+	}
+	// Depending on the version, strace prints netlink protocols with or without the prefix.
+	desc = strings.Replace(desc, "[NETLINK_", "[", 1)
+	for _, fdPrefix := range fdPrefixes {
+		if !strings.HasPrefix(desc, fdPrefix.prefix) {
+			continue
+		}
+		for _, res := range cs.target.Resources {
+			if res.Name == fdPrefix.resource {
+				return res
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// fileResource returns the resource returned by the open call variant that opens the file.
+func (cs *selectorCommon) fileResource(path string) *prog.ResourceDesc {
+	var callNames []string
+	for callName := range openDiscriminatorArgs {
+		callNames = append(callNames, callName)
+	}
+	sort.Strings(callNames)
+	for _, callName := range callNames {
+		for _, variant := range cs.callSet(callName) {
+			if match, _ := cs.matchPath(variant, path); !match {
+				continue
+			}
+			if res, ok := variant.Ret.(*prog.ResourceType); ok {
+				return res.Desc
+			}
+		}
+	}
+	return nil
+}
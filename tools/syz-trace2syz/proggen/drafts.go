@@ -0,0 +1,643 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package proggen
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/tools/syz-trace2syz/parser"
+)
+
+// Drafts collects system calls and ioctls from traces that don't have descriptions
+// and synthesizes draft descriptions for them.
+//
+// Types of arguments are inferred from the values decoded by strace: structs from
+// the named fields, arrays from the unnamed groups, strings from buffers.
+// Directions and sizes of ioctl arguments are inferred from the ioctl command encoding.
+// Return values that are later passed to other calls become resources, so e.g.
+// an unsupported ioctl on a file descriptor returned by open("/dev/foo") gets
+// a new fd_foo resource and an openat$foo call that creates it.
+// The result is a draft that needs to be reviewed: e.g. widths of integers,
+// and pointers vs inline arrays can't be inferred precisely.
+type Drafts struct {
+	target  *prog.Target
+	fds     *selectorCommon
+	names   map[string]bool
+	ioctls  map[uint64]bool
+	calls   map[string]*draftCall
+	openers map[string]*draftResource
+	// Resources of the return values in the current trace.
+	values map[uint64]*draftResource
+	// Resources of the target that were passed to draft calls.
+	resources map[string]*draftResource
+	// Types produced by Format.
+	inputs  map[string]bool
+	structs map[string]string
+}
+
+type draftCall struct {
+	name string
+	// Identifier of the call used to name related types and resources, e.g. foo_c0104601.
+	id string
+	// Generic description of the call (ioctl), if any.
+	meta *prog.Syscall
+	cmd  uint64
+	args []*draftArg
+	res  *draftResource
+}
+
+type draftArg struct {
+	vals []parser.IrType
+	// Resources of the observed values, nil if the value is not a return value of a preceding call.
+	res []*draftResource
+}
+
+type draftResource struct {
+	name string
+	// Tag used to name the calls that use the resource.
+	tag string
+	// Path of the file for resources returned by openat.
+	path string
+	// Call that returns the resource, if it's a draft.
+	call *draftCall
+	// Resources of arguments of the described calls the resource was passed to.
+	bases map[string]bool
+	kinds []string
+}
+
+// NewDrafts creates an empty set of drafts for the target.
+func NewDrafts(target *prog.Target) *Drafts {
+	d := &Drafts{
+		target:    target,
+		fds:       newSelectorCommon(target, nil),
+		names:     make(map[string]bool),
+		ioctls:    make(map[uint64]bool),
+		calls:     make(map[string]*draftCall),
+		openers:   make(map[string]*draftResource),
+		values:    make(map[uint64]*draftResource),
+		resources: make(map[string]*draftResource),
+	}
+	for _, res := range target.Resources {
+		d.names[res.Name] = true
+	}
+	for _, call := range target.Syscalls {
+		d.names[call.Name] = true
+		if call.CallName == "ioctl" && len(call.Args) > 1 {
+			if cmd, ok := call.Args[1].Type.(*prog.ConstType); ok {
+				d.ioctls[cmd.Val] = true
+			}
+		}
+	}
+	prog.ForeachType(target.Syscalls, func(typ prog.Type, ctx *prog.TypeCtx) {
+		d.names[typ.Name()] = true
+	})
+	return d
+}
+
+func (d *Drafts) startTrace() {
+	d.values = make(map[uint64]*draftResource)
+}
+
+// add records a call from the trace along with the selected description.
+func (d *Drafts) add(call *parser.Syscall, meta *prog.Syscall) {
+	if dc := d.draft(call, meta); dc != nil {
+		d.addDraft(dc, call)
+		return
+	}
+	for i := range call.Args {
+		if r := d.value(call, call.Args[i]); r != nil && r.call != nil {
+			d.addBase(r, meta, i)
+		}
+	}
+	res, ok := meta.Ret.(*prog.ResourceType)
+	if !ok || call.Ret <= 0 {
+		return
+	}
+	r := d.resource(res.Desc)
+	if idx, ok := openDiscriminatorArgs[meta.Name]; ok && res.Desc.Name == "fd" && idx < len(call.Args) {
+		if path, ok := call.Args[idx].(*parser.BufferType); ok && isPrintable(path.Val) {
+			r = d.opener(path.Val)
+		}
+	}
+	d.values[uint64(call.Ret)] = r
+}
+
+// addBase records that the resource returned by a draft call was passed to the i-th argument of the call.
+func (d *Drafts) addBase(r *draftResource, meta *prog.Syscall, i int) {
+	if meta == nil || i >= len(meta.Args) {
+		return
+	}
+	if res, ok := meta.Args[i].Type.(*prog.ResourceType); ok {
+		r.bases[d.resource(res.Desc).name] = true
+	}
+}
+
+// draft returns the draft for the call if the call does not have a description.
+func (d *Drafts) draft(call *parser.Syscall, meta *prog.Syscall) *draftCall {
+	if meta == nil {
+		dc := d.calls[call.CallName]
+		if dc == nil {
+			dc = &draftCall{
+				name: d.uniqueName(call.CallName),
+				id:   call.CallName,
+			}
+			d.calls[call.CallName] = dc
+		}
+		return dc
+	}
+	if meta.Name != "ioctl" || len(call.Args) < 2 {
+		return nil
+	}
+	cmd, ok := call.Args[1].(parser.Constant)
+	if !ok || d.ioctls[cmd.Val()] {
+		return nil
+	}
+	key := fmt.Sprintf("ioctl$%x", cmd.Val())
+	dc := d.calls[key]
+	if dc == nil {
+		tag := "fd"
+		if r := d.value(call, call.Args[0]); r != nil {
+			tag = r.tag
+		}
+		id := fmt.Sprintf("%v_%x", tag, cmd.Val())
+		dc = &draftCall{
+			name: d.uniqueName("ioctl$" + id),
+			id:   id,
+			meta: meta,
+			cmd:  cmd.Val(),
+		}
+		d.calls[key] = dc
+	}
+	return dc
+}
+
+func (d *Drafts) addDraft(dc *draftCall, call *parser.Syscall) {
+	for i, val := range call.Args {
+		if i >= len(dc.args) {
+			dc.args = append(dc.args, new(draftArg))
+		}
+		arg := dc.args[i]
+		r := d.value(call, val)
+		if r != nil && r.call != nil {
+			d.addBase(r, dc.meta, i)
+		}
+		arg.vals = append(arg.vals, val)
+		arg.res = append(arg.res, r)
+	}
+	if call.Ret <= 0 {
+		return
+	}
+	if dc.res == nil {
+		dc.res = &draftResource{
+			tag:   dc.id,
+			call:  dc,
+			bases: make(map[string]bool),
+		}
+	}
+	d.values[uint64(call.Ret)] = dc.res
+}
+
+func (d *Drafts) value(call *parser.Syscall, val parser.IrType) *draftResource {
+	c, ok := val.(parser.Constant)
+	if !ok {
+		return nil
+	}
+	if r := d.values[c.Val()]; r != nil {
+		return r
+	}
+	// The value is not returned by any of the preceding calls, but strace may have decoded it as an fd.
+	if res := d.fds.fdResource(call, val); res != nil {
+		return d.resource(res)
+	}
+	if path := call.Fds[c.Val()]; strings.HasPrefix(path, "/") && isPrintable(path) {
+		return d.opener(path)
+	}
+	return nil
+}
+
+func (d *Drafts) resource(desc *prog.ResourceDesc) *draftResource {
+	r := d.resources[desc.Name]
+	if r == nil {
+		r = &draftResource{
+			name:  desc.Name,
+			tag:   strings.TrimPrefix(desc.Name, "fd_"),
+			kinds: desc.Kind,
+		}
+		d.resources[desc.Name] = r
+	}
+	return r
+}
+
+func (d *Drafts) opener(path string) *draftResource {
+	r := d.openers[path]
+	if r == nil {
+		tag := identifier(strings.TrimPrefix(strings.TrimPrefix(path, "/dev/"), "/"))
+		name := d.uniqueName("fd_" + tag)
+		r = &draftResource{
+			name:  name,
+			tag:   strings.TrimPrefix(name, "fd_"),
+			path:  path,
+			kinds: []string{"fd", name},
+		}
+		d.openers[path] = r
+	}
+	return r
+}
+
+func (d *Drafts) uniqueName(name string) string {
+	res := name
+	for i := 1; d.names[res]; i++ {
+		res = fmt.Sprintf("%v%v", name, i)
+	}
+	d.names[res] = true
+	return res
+}
+
+// Format returns the draft descriptions in the syzlang format.
+func (d *Drafts) Format() []byte {
+	if len(d.calls) == 0 {
+		return nil
+	}
+	// Names of the types are allocated while formatting, so that Format can be called repeatedly.
+	names := d.names
+	d.names = maps.Clone(names)
+	defer func() { d.names = names }()
+	d.structs = make(map[string]string)
+	var calls []*draftCall
+	for _, dc := range d.calls {
+		calls = append(calls, dc)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].name < calls[j].name
+	})
+	for _, dc := range calls {
+		if dc.res != nil {
+			d.resolveResource(dc.res)
+		}
+	}
+	// Resources that are not used as inputs of any calls are not useful,
+	// so types of arguments are inferred first to figure out which resources are used.
+	d.inputs = make(map[string]bool)
+	var lines []string
+	for _, dc := range calls {
+		lines = append(lines, d.formatCall(dc))
+	}
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# Draft descriptions synthesized by syz-trace2syz from strace traces.\n")
+	fmt.Fprintf(buf, "# Types are inferred from the values observed in the traces and need to be reviewed.\n\n")
+	var openers []*draftResource
+	for _, r := range d.openers {
+		if d.inputs[r.name] {
+			openers = append(openers, r)
+		}
+	}
+	sort.Slice(openers, func(i, j int) bool {
+		return openers[i].name < openers[j].name
+	})
+	if len(openers) != 0 {
+		fmt.Fprintf(buf, "include <uapi/linux/fcntl.h>\n\n")
+	}
+	for _, r := range openers {
+		fmt.Fprintf(buf, "resource %v[fd]\n", r.name)
+	}
+	for _, dc := range calls {
+		if r := dc.res; r != nil && d.inputs[r.name] {
+			fmt.Fprintf(buf, "resource %v[%v]\n", r.name, d.resourceBase(r))
+		}
+	}
+	buf.WriteString("\n")
+	for _, r := range openers {
+		fmt.Fprintf(buf, "openat$%v(fd const[AT_FDCWD], file ptr[in, string[%q]], flags flags[open_flags],"+
+			" mode const[0]) %v\n", r.tag, r.path, r.name)
+	}
+	for i, dc := range calls {
+		buf.WriteString(lines[i])
+		if r := dc.res; r != nil && d.inputs[r.name] {
+			fmt.Fprintf(buf, " %v", r.name)
+		}
+		buf.WriteString("\n")
+	}
+	var structs []string
+	for name := range d.structs {
+		structs = append(structs, name)
+	}
+	sort.Strings(structs)
+	for _, name := range structs {
+		fmt.Fprintf(buf, "\n%v {\n%v}\n", name, d.structs[name])
+	}
+	desc := ast.Parse(buf.Bytes(), "drafts.txt", nil)
+	if desc == nil {
+		panic(fmt.Sprintf("failed to parse generated drafts:\n%s", buf.Bytes()))
+	}
+	return ast.Format(desc)
+}
+
+// resolveResource names the resource returned by a draft call
+// based on the resources of the described calls it was passed to.
+func (d *Drafts) resolveResource(r *draftResource) {
+	var kinds []string
+	for base := range r.bases {
+		kinds1 := d.resources[base].kinds
+		if kinds == nil {
+			kinds = kinds1
+			continue
+		}
+		kinds = commonKinds(kinds, kinds1)
+	}
+	if len(kinds) == 0 {
+		r.name = d.uniqueName(r.tag + "_res")
+	} else {
+		r.name = d.uniqueName(kinds[0] + "_" + r.tag)
+	}
+	r.kinds = append(append([]string{}, kinds...), r.name)
+}
+
+func (d *Drafts) resourceBase(r *draftResource) string {
+	if len(r.kinds) == 1 {
+		return "intptr"
+	}
+	return r.kinds[len(r.kinds)-2]
+}
+
+func commonKinds(a, b []string) []string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
+
+func (d *Drafts) formatCall(dc *draftCall) string {
+	var args []string
+	for i, arg := range dc.args {
+		name := fmt.Sprintf("a%v", i)
+		if dc.meta != nil && i < len(dc.meta.Args) {
+			name = dc.meta.Args[i].Name
+		}
+		var typ string
+		switch {
+		case dc.meta != nil && i == 1:
+			typ = fmt.Sprintf("const[0x%x]", dc.cmd)
+		case dc.meta != nil && i == 2:
+			typ = d.ioctlArgType(dc, arg)
+		default:
+			typ = d.argType(dc, i, arg)
+		}
+		args = append(args, name+" "+typ)
+	}
+	return fmt.Sprintf("%v(%v)", dc.name, strings.Join(args, ", "))
+}
+
+// Linux ioctl command encoding (see include/uapi/asm-generic/ioctl.h).
+const (
+	iocSizeShift = 16
+	iocSizeMask  = 1<<14 - 1
+	iocDirShift  = 30
+	iocWrite     = 1
+	iocRead      = 2
+)
+
+func (d *Drafts) ioctlArgType(dc *draftCall, arg *draftArg) string {
+	size := int(dc.cmd >> iocSizeShift & iocSizeMask)
+	var dir string
+	switch dc.cmd >> iocDirShift {
+	case iocWrite:
+		dir = "in"
+	case iocRead:
+		dir = "out"
+	case iocWrite | iocRead:
+		dir = "inout"
+	default:
+		return d.argType(dc, 2, arg)
+	}
+	if s := newShape(arg.vals); s.pointer() {
+		return fmt.Sprintf("ptr[%v, %v]", dir, d.elemType(s, dc.id+"_arg", size))
+	}
+	if size == 0 {
+		return fmt.Sprintf("ptr[%v, array[int8]]", dir)
+	}
+	return fmt.Sprintf("ptr[%v, array[int8, %v]]", dir, size)
+}
+
+func (d *Drafts) argType(dc *draftCall, i int, arg *draftArg) string {
+	if s := newShape(arg.vals); s.pointer() {
+		name := fmt.Sprintf("%v_a%v", dc.id, i)
+		if dc.meta != nil && i < len(dc.meta.Args) {
+			name = dc.id + "_" + dc.meta.Args[i].Name
+		}
+		return fmt.Sprintf("ptr[in, %v]", d.elemType(s, name, 0))
+	}
+	var kinds []string
+	for _, r := range arg.res {
+		if r == nil {
+			kinds = nil
+			break
+		}
+		if kinds == nil {
+			kinds = r.kinds
+		} else {
+			kinds = commonKinds(kinds, r.kinds)
+		}
+	}
+	if len(kinds) != 0 {
+		d.inputs[kinds[len(kinds)-1]] = true
+		return kinds[len(kinds)-1]
+	}
+	if dc.meta != nil && i < len(dc.meta.Args) {
+		if res, ok := dc.meta.Args[i].Type.(*prog.ResourceType); ok {
+			return res.Desc.Name
+		}
+	}
+	return "intptr"
+}
+
+// elemType returns type of the pointer element with the given shape.
+// If size is not 0, it is the expected size of the element.
+func (d *Drafts) elemType(s *shape, name string, size int) string {
+	switch {
+	case s.fields != nil:
+		return d.structType(s, name, size)
+	case s.array:
+		if len(s.lens) != 1 || s.elem == nil || !s.elem.integer() {
+			break
+		}
+		n := slices.Collect(maps.Keys(s.lens))[0]
+		if n == 1 {
+			// strace prints pointers to integers as [1].
+			return d.fieldType(s.elem, name, size)
+		}
+		if size == n*4 || size == n*8 {
+			return fmt.Sprintf("array[%v, %v]", d.fieldType(s.elem, name, size/n), n)
+		}
+	case s.strs != nil:
+		printable := true
+		for _, str := range s.strs {
+			printable = printable && isPrintable(str)
+		}
+		switch {
+		case !printable:
+			return "array[int8]"
+		case strings.HasPrefix(s.strs[0], "/") || strings.HasPrefix(s.strs[0], "."):
+			return "filename"
+		default:
+			return "string"
+		}
+	}
+	return d.fieldType(s, name, 0)
+}
+
+func (d *Drafts) structType(s *shape, name string, size int) string {
+	name = d.uniqueName(name)
+	intSize := 0
+	if size != 0 && size == len(s.fields)*8 {
+		intSize = 8
+		for _, f := range s.fields {
+			if f.shape.fields != nil || f.shape.array || f.shape.strs != nil {
+				intSize = 0
+			}
+		}
+	}
+	buf := new(bytes.Buffer)
+	used := map[string]bool{prog.ParentRef: true, prog.SyscallRef: true}
+	for _, f := range s.fields {
+		fieldName := identifier(f.name)
+		for i := 1; used[fieldName]; i++ {
+			fieldName = fmt.Sprintf("%v%v", identifier(f.name), i)
+		}
+		used[fieldName] = true
+		fmt.Fprintf(buf, "\t%v\t%v\n", fieldName, d.fieldType(f.shape, name+"_"+fieldName, intSize))
+	}
+	d.structs[name] = buf.String()
+	return name
+}
+
+func (d *Drafts) fieldType(s *shape, name string, intSize int) string {
+	switch {
+	case s.fields != nil:
+		return d.structType(s, name, 0)
+	case s.array:
+		if s.elem == nil {
+			return "array[int8]"
+		}
+		return fmt.Sprintf("array[%v]", d.fieldType(s.elem, name, 0))
+	case s.strs != nil:
+		return fmt.Sprintf("ptr[in, %v]", d.elemType(s, name, 0))
+	}
+	switch intSize {
+	case 4:
+		return "int32"
+	case 8:
+		return "int64"
+	}
+	for _, v := range s.ints {
+		// Values that don't fit into 32 bits both as unsigned and as sign-extended negative.
+		if int64(v) < -1<<31 || int64(v) > 1<<32-1 {
+			return "int64"
+		}
+	}
+	return "int32"
+}
+
+// shape is the merged structure of the values observed for an argument or a field.
+type shape struct {
+	ints   []uint64
+	strs   []string
+	fields []*shapeField
+	array  bool
+	elem   *shape
+	lens   map[int]bool
+}
+
+type shapeField struct {
+	name  string
+	shape *shape
+}
+
+func newShape(vals []parser.IrType) *shape {
+	s := new(shape)
+	for _, val := range vals {
+		s.merge(val)
+	}
+	return s
+}
+
+func (s *shape) pointer() bool {
+	return s.fields != nil || s.array || s.strs != nil
+}
+
+func (s *shape) integer() bool {
+	return !s.pointer() && s.ints != nil
+}
+
+func (s *shape) merge(val parser.IrType) {
+	switch v := val.(type) {
+	case parser.Constant:
+		s.ints = append(s.ints, v.Val())
+	case *parser.BufferType:
+		s.strs = append(s.strs, v.Val)
+	case *parser.GroupType:
+		if v.Names == nil {
+			s.array = true
+			if s.lens == nil {
+				s.lens = make(map[int]bool)
+			}
+			s.lens[len(v.Elems)] = true
+			for _, elem := range v.Elems {
+				if s.elem == nil {
+					s.elem = new(shape)
+				}
+				s.elem.merge(elem)
+			}
+			return
+		}
+		for i, elem := range v.Elems {
+			name := v.Names[i]
+			if name == "" {
+				name = fmt.Sprintf("f%v", i)
+			}
+			s.field(name).merge(elem)
+		}
+	}
+}
+
+func (s *shape) field(name string) *shape {
+	for _, f := range s.fields {
+		if f.name == name {
+			return f.shape
+		}
+	}
+	f := &shapeField{name: name, shape: new(shape)}
+	s.fields = append(s.fields, f)
+	return f.shape
+}
+
+func identifier(name string) string {
+	res := []byte(name)
+	for i, c := range res {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			res[i] = '_'
+		}
+	}
+	name = strings.Trim(string(res), "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "f" + name
+	}
+	return name
+}
+
+func isPrintable(str string) bool {
+	for _, c := range []byte(str) {
+		if c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
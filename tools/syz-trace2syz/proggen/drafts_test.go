@@ -0,0 +1,123 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+//go:build !codeanalysis
+
+package proggen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/syzkaller/pkg/ast"
+	"github.com/google/syzkaller/pkg/compiler"
+	"github.com/google/syzkaller/prog"
+	_ "github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/sys/targets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrafts(t *testing.T) {
+	target, err := prog.GetTarget(targets.Linux, targets.AMD64)
+	require.NoError(t, err)
+	drafts := NewDrafts(target)
+	traces := []string{`
+openat(-100, "\x2f\x64\x65\x76\x2f\x66\x6f\x6f", 2) = 3
+ioctl(3, 0x5421, [1]) = 0
+ioctl(3, 0xc0104601, {version=1, flags=0x2, name="\x61\x62\x63"}) = 0
+ioctl(3, 0x80044602, [5]) = 0
+ioctl(3, 0x4603, 0) = 4
+ioctl(4, 0x40104604, {0x1, 0xffffffffffffffff}) = 0
+ioctl(4, 0x80084605, 0x7ffd1000) = 0
+foo_bar(4, "\x2f\x74\x6d\x70\x2f\x66", [1, 2, 3], {a=1, b={c=2, d=0x100000000}}) = 5
+ioctl(5, 0xfe06, 0) = 0
+close(5) = 0
+`, `
+openat(-100, "\x2f\x64\x65\x76\x2f\x66\x6f\x6f", 0) = 3
+ioctl(3, 0xc0104601, {version=2, flags=0, name="\x78", pad=0}) = 0
+ioctl(3, 0x4603, 0) = 5
+foo_bar(5, NULL, [], {a=1, b={c=0, d=0}}, 0x20) = -1 EINVAL (Invalid argument)
+foo_create(1) = 7
+foo_use(7, 1) = 0
+foo_use(7, 2) = 0
+`}
+	for _, trace := range traces {
+		_, err := ParseDataDrafts([]byte(strings.TrimSpace(trace)), target, drafts)
+		require.NoError(t, err)
+	}
+	got := string(drafts.Format())
+	want := `# Draft descriptions synthesized by syz-trace2syz from strace traces.
+# Types are inferred from the values observed in the traces and need to be reviewed.
+
+include <uapi/linux/fcntl.h>
+
+resource fd_foo[fd]
+resource fd_foo_bar[fd]
+resource foo_create_res[intptr]
+resource fd_foo_4603[fd]
+
+openat$foo(fd const[AT_FDCWD], file ptr[in, string["/dev/foo"]], flags flags[open_flags], mode const[0]) fd_foo
+foo_bar(a0 fd_foo_4603, a1 ptr[in, filename], a2 ptr[in, array[int32]], a3 ptr[in, foo_bar_a3], a4 intptr) fd_foo_bar
+foo_create(a0 intptr) foo_create_res
+foo_use(a0 foo_create_res, a1 intptr)
+ioctl$foo_4603(fd fd_foo, cmd const[0x4603], arg intptr) fd_foo_4603
+ioctl$foo_4603_40104604(fd fd_foo_4603, cmd const[0x40104604], arg ptr[in, array[int64, 2]])
+ioctl$foo_4603_80084605(fd fd_foo_4603, cmd const[0x80084605], arg ptr[out, array[int8, 8]])
+ioctl$foo_80044602(fd fd_foo, cmd const[0x80044602], arg ptr[out, int32])
+ioctl$foo_bar_fe06(fd fd_foo_bar, cmd const[0xfe06], arg intptr)
+ioctl$foo_c0104601(fd fd_foo, cmd const[0xc0104601], arg ptr[inout, foo_c0104601_arg])
+
+foo_bar_a3 {
+	a	int32
+	b	foo_bar_a3_b
+}
+
+foo_bar_a3_b {
+	c	int32
+	d	int64
+}
+
+foo_c0104601_arg {
+	version	int32
+	flags	int32
+	name	ptr[in, string]
+	pad	int32
+}
+`
+	assert.Equal(t, want, got)
+	assert.Equal(t, got, string(drafts.Format()), "Format is not idempotent")
+	if testing.Short() {
+		return
+	}
+	checkDrafts(t, target, got)
+}
+
+// checkDrafts checks that the drafts can be compiled along with the target descriptions.
+func checkDrafts(t *testing.T, target *prog.Target, drafts string) {
+	dir := filepath.Join("..", "..", "..", "sys", target.OS)
+	var errors []string
+	eh := func(pos ast.Pos, msg string) {
+		errors = append(errors, fmt.Sprintf("%v: %v", pos, msg))
+	}
+	desc := ast.ParseGlob(filepath.Join(dir, "*.txt"), eh)
+	require.NotNil(t, desc, "%q", errors)
+	draftsDesc := ast.Parse([]byte(drafts), "drafts.txt", eh)
+	require.NotNil(t, draftsDesc, "%q", errors)
+	desc.Nodes = append(desc.Nodes, draftsDesc.Nodes...)
+	constFile := compiler.DeserializeConstFile(filepath.Join(dir, "*.const"), eh)
+	require.NotNil(t, constFile, "%q", errors)
+	sysTarget := targets.Get(target.OS, target.Arch)
+	consts := constFile.Arch(target.Arch)
+	// Draft system calls don't have numbers in the const files yet.
+	for _, n := range draftsDesc.Nodes {
+		if call, ok := n.(*ast.Call); ok {
+			if name := sysTarget.SyscallPrefix + call.CallName; consts[name] == 0 {
+				consts[name] = 1000
+			}
+		}
+	}
+	require.NotNil(t, compiler.Compile(desc, consts, sysTarget, eh), "%q", errors)
+}
@@ -41,6 +41,10 @@ func (ctx *context) genSockaddrStorage(syzType *prog.UnionType, dir prog.Dir, st
 			idx = field2Opt["ll"]
 		}
 
+	case *parser.BufferType:
+		// Tracers other than strace may pass raw bytes of addresses they don't decode.
+		log.Logf(2, "generating default sockaddr_storage for raw address")
+		return syzType.DefaultArg(dir)
 	default:
 		log.Fatalf("unable to parse sockaddr_storage. Unsupported type: %#v", strType)
 	}
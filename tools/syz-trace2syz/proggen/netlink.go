@@ -0,0 +1,222 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package proggen
+
+import (
+	"encoding/binary"
+
+	"github.com/google/syzkaller/tools/syz-trace2syz/parser"
+)
+
+const (
+	nlmsgHdrLen  = 16
+	nlattrHdrLen = 4
+)
+
+// netlinkData serializes netlink messages decoded by strace back to bytes, e.g.
+//
+//	[{nlmsg_len=40, nlmsg_type=0x12, nlmsg_flags=0x1, nlmsg_seq=1, nlmsg_pid=0},
+//		{ifi_family=0, ifi_type=0, ifi_index=0, ifi_flags=0, ifi_change=0}, [{nla_len=7, nla_type=0x3}, "lo"]]
+//
+// Layouts of the payload structs are unknown, so a single payload struct in a message is serialized
+// into the space that is left according to nlmsg_len, and field sizes are guessed from its size.
+// It returns nil if the value does not look like netlink messages.
+func netlinkData(val parser.IrType, order binary.ByteOrder) []byte {
+	group, ok := val.(*parser.GroupType)
+	if !ok {
+		return nil
+	}
+	nl := &netlinkSerializer{order: order}
+	if nl.isMessage(group) {
+		return nl.message(group)
+	}
+	// Multiple messages.
+	var data []byte
+	for _, elem := range group.Elems {
+		msg, ok := elem.(*parser.GroupType)
+		if !ok || !nl.isMessage(msg) {
+			return nil
+		}
+		data = append(data, nl.message(msg)...)
+	}
+	return data
+}
+
+func isNetlinkData(val parser.IrType) bool {
+	return netlinkData(val, binary.LittleEndian) != nil
+}
+
+type netlinkSerializer struct {
+	order binary.ByteOrder
+}
+
+// isMessage checks if the group starts with nlmsghdr. Old strace versions name the fields len, type, etc.
+func (nl *netlinkSerializer) isMessage(group *parser.GroupType) bool {
+	return nl.hasHeader(group, "nlmsg_len") || nl.hasHeader(group, "len")
+}
+
+func (nl *netlinkSerializer) isAttr(group *parser.GroupType) bool {
+	return nl.hasHeader(group, "nla_len")
+}
+
+func (nl *netlinkSerializer) hasHeader(group *parser.GroupType, name string) bool {
+	if len(group.Elems) == 0 {
+		return false
+	}
+	hdr, ok := group.Elems[0].(*parser.GroupType)
+	return ok && len(hdr.Names) != 0 && hdr.Names[0] == name
+}
+
+func (nl *netlinkSerializer) message(group *parser.GroupType) []byte {
+	hdr := group.Elems[0].(*parser.GroupType)
+	size := int(groupConst(hdr, 0))
+	data := make([]byte, nlmsgHdrLen)
+	nl.order.PutUint32(data[0:], uint32(size))
+	nl.order.PutUint16(data[4:], uint16(groupConst(hdr, 1)))
+	nl.order.PutUint16(data[6:], uint16(groupConst(hdr, 2)))
+	nl.order.PutUint32(data[8:], uint32(groupConst(hdr, 3)))
+	nl.order.PutUint32(data[12:], uint32(groupConst(hdr, 4)))
+	var parts [][]byte
+	known, unknown := nlmsgHdrLen, -1
+	for _, elem := range group.Elems[1:] {
+		if s, ok := elem.(*parser.GroupType); ok && unknown == -1 && nl.isStruct(s) {
+			unknown = len(parts)
+			parts = append(parts, nil)
+			continue
+		}
+		part := nl.value(elem, 0)
+		known += len(part)
+		parts = append(parts, part)
+	}
+	if unknown != -1 {
+		parts[unknown] = nl.structData(group.Elems[1+unknown].(*parser.GroupType), size-known)
+	}
+	for _, part := range parts {
+		data = append(data, part...)
+	}
+	for len(data) < size {
+		data = append(data, 0)
+	}
+	return data
+}
+
+// isStruct checks if the group is a payload struct (e.g. ifinfomsg) rather than attributes or messages.
+func (nl *netlinkSerializer) isStruct(group *parser.GroupType) bool {
+	return group.Names != nil && !nl.isMessage(group) && !nl.isAttr(group)
+}
+
+func (nl *netlinkSerializer) attr(group *parser.GroupType) []byte {
+	hdr := group.Elems[0].(*parser.GroupType)
+	size := int(groupConst(hdr, 0))
+	data := make([]byte, nlattrHdrLen)
+	nl.order.PutUint16(data[0:], uint16(size))
+	nl.order.PutUint16(data[2:], uint16(groupConst(hdr, 1)))
+	var val parser.IrType = &parser.GroupType{Elems: group.Elems[1:]}
+	if len(group.Elems) == 2 {
+		val = group.Elems[1]
+	}
+	data = append(data, nl.value(val, max(size-nlattrHdrLen, 0))...)
+	for len(data) < size || len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+	return data[:max(size+3, nlattrHdrLen)&^3]
+}
+
+// value serializes the value to size bytes, or to as many bytes as it needs if size is 0.
+func (nl *netlinkSerializer) value(val parser.IrType, size int) []byte {
+	var data []byte
+	switch a := val.(type) {
+	case parser.Constant:
+		data = make([]byte, 8)
+		nl.order.PutUint64(data, a.Val())
+		switch size {
+		case 0:
+			size = 4
+			fallthrough
+		case 1, 2, 4:
+			if nl.order == binary.BigEndian {
+				data = data[8-size:]
+			}
+		}
+	case *parser.BufferType:
+		data = []byte(a.Val)
+	case *parser.GroupType:
+		switch {
+		case nl.isAttr(a):
+			data = nl.attr(a)
+		case nl.isMessage(a):
+			data = nl.message(a)
+		case a.Names != nil:
+			data = nl.structData(a, size)
+		default:
+			// Array of attributes or values.
+			elemSize := 0
+			if size != 0 && len(a.Elems) != 0 && size%len(a.Elems) == 0 {
+				elemSize = size / len(a.Elems)
+			}
+			for _, elem := range a.Elems {
+				data = append(data, nl.value(elem, elemSize)...)
+			}
+		}
+	}
+	if size == 0 {
+		return data
+	}
+	for len(data) < size {
+		data = append(data, 0)
+	}
+	return data[:size]
+}
+
+// netlinkStructs contains layouts (offsets and sizes of the fields printed by strace)
+// of common netlink payload structs keyed by the name of the first field.
+var netlinkStructs = map[string][][2]int{
+	"cmd":          {{0, 1}, {1, 1}},                                                         // genlmsghdr
+	"rtgen_family": {{0, 1}},                                                                 // rtgenmsg
+	"ifi_family":   {{0, 1}, {2, 2}, {4, 4}, {8, 4}, {12, 4}},                                // ifinfomsg
+	"ifa_family":   {{0, 1}, {1, 1}, {2, 1}, {3, 1}, {4, 4}},                                 // ifaddrmsg
+	"rtm_family":   {{0, 1}, {1, 1}, {2, 1}, {3, 1}, {4, 1}, {5, 1}, {6, 1}, {7, 1}, {8, 4}}, // rtmsg
+	"ndm_family":   {{0, 1}, {4, 4}, {8, 2}, {10, 1}, {11, 1}},                               // ndmsg
+	"tcm_family":   {{0, 1}, {4, 4}, {8, 4}, {12, 4}, {16, 4}},                               // tcmsg
+	"nfgen_family": {{0, 1}, {1, 1}, {2, 2}},                                                 // nfgenmsg
+}
+
+// structData serializes a struct into size bytes. Layouts of the common structs are known.
+// Otherwise, if all fields have the same size, the struct is serialized precisely,
+// and if they don't, only the first field is serialized as a byte (it's usually the family or the command).
+func (nl *netlinkSerializer) structData(group *parser.GroupType, size int) []byte {
+	if size <= 0 {
+		return nil
+	}
+	n := len(group.Elems)
+	if layout := netlinkStructs[group.Names[0]]; len(layout) == n {
+		data := make([]byte, max(size, layout[n-1][0]+layout[n-1][1]))
+		for i, field := range layout {
+			copy(data[field[0]:], nl.value(group.Elems[i], field[1]))
+		}
+		return data[:size]
+	}
+	for _, fieldSize := range []int{1, 2, 4, 8} {
+		if size == n*fieldSize {
+			var data []byte
+			for _, elem := range group.Elems {
+				data = append(data, nl.value(elem, fieldSize)...)
+			}
+			return data
+		}
+	}
+	data := make([]byte, size)
+	data[0] = byte(groupConst(group, 0))
+	return data
+}
+
+func groupConst(group *parser.GroupType, i int) uint64 {
+	if i >= len(group.Elems) {
+		return 0
+	}
+	if c, ok := group.Elems[i].(parser.Constant); ok {
+		return c.Val()
+	}
+	return 0
+}
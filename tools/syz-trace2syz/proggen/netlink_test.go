@@ -0,0 +1,53 @@
+// Copyright 2026 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+//go:build !codeanalysis
+
+package proggen
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/google/syzkaller/tools/syz-trace2syz/parser"
+)
+
+func TestNetlinkData(t *testing.T) {
+	tests := []struct {
+		arg  string
+		data string
+	}{
+		{
+			`[{nlmsg_len=40, nlmsg_type=0x12, nlmsg_flags=0x301, nlmsg_seq=1, nlmsg_pid=0}, ` +
+				`{ifi_family=0x7, ifi_type=0x1, ifi_index=2, ifi_flags=0x3, ifi_change=0x4}, ` +
+				`[{nla_len=7, nla_type=0x3}, "\x6c\x6f"]]`,
+			"28000000120001030100000000000000" + "0700010002000000" + "0300000004000000" + "070003006c6f0000",
+		},
+		{
+			`[{nlmsg_len=36, nlmsg_type=0x10, nlmsg_flags=0x1, nlmsg_seq=3, nlmsg_pid=0}, {cmd=0x3, version=0x1}, ` +
+				`[[{nla_len=6, nla_type=0x1}, 0x10], [{nla_len=8, nla_type=0x8000}, [{nla_len=4, nla_type=0x1}]]]]`,
+			"24000000100001000300000000000000" + "03010000" + "0600010010000000" + "0800008004000100",
+		},
+		{
+			`[[{nlmsg_len=16, nlmsg_type=0x3e8, nlmsg_flags=0x5, nlmsg_seq=4, nlmsg_pid=0}], ` +
+				`[{nlmsg_len=24, nlmsg_type=0x3e9, nlmsg_flags=0x5, nlmsg_seq=5, nlmsg_pid=0}, {a=1, b=2}]]`,
+			"10000000e8030500040000000000000018000000e9030500050000000000000001000000" + "02000000",
+		},
+		{
+			`[1, 2]`,
+			"",
+		},
+	}
+	for _, test := range tests {
+		tree, err := parser.ParseData([]byte("sendto(3, " + test.arg + ") = 0"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		arg := tree.TraceMap[tree.RootPid].Calls[0].Args[1]
+		got := hex.EncodeToString(netlinkData(arg, binary.LittleEndian))
+		if got != test.data {
+			t.Errorf("%v:\nwant: %v\ngot:  %v", test.arg, test.data, got)
+		}
+	}
+}
@@ -10,43 +10,61 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/google/syzkaller/pkg/log"
 	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/sys/targets"
 	"github.com/google/syzkaller/tools/syz-trace2syz/parser"
 )
 
 func ParseFile(filename string, target *prog.Target) ([]*prog.Prog, error) {
+	return ParseFileDrafts(filename, target, nil)
+}
+
+// ParseFileDrafts is like ParseFile, but also records calls without descriptions in drafts.
+func ParseFileDrafts(filename string, target *prog.Target, drafts *Drafts) ([]*prog.Prog, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("error reading file: %v", err)
 	}
-	return ParseData(data, target)
+	return ParseDataDrafts(data, target, drafts)
 }
 
 func ParseData(data []byte, target *prog.Target) ([]*prog.Prog, error) {
+	return ParseDataDrafts(data, target, nil)
+}
+
+// ParseDataDrafts is like ParseData, but also records calls without descriptions in drafts.
+func ParseDataDrafts(data []byte, target *prog.Target, drafts *Drafts) ([]*prog.Prog, error) {
 	tree, err := parser.ParseData(data)
 	if err != nil {
 		return nil, err
 	}
+	return ParseTree(tree, target, drafts), nil
+}
+
+// ParseTree converts a trace parsed by one of the parser functions (e.g. parser.ParsePerf) to programs.
+func ParseTree(tree *parser.TraceTree, target *prog.Target, drafts *Drafts) []*prog.Prog {
 	if tree == nil {
-		return nil, nil
+		return nil
 	}
 	var progs []*prog.Prog
-	parseTree(tree, tree.RootPid, target, &progs)
-	return progs, nil
+	parseTree(tree, tree.RootPid, target, drafts, &progs)
+	return progs
 }
 
 // parseTree groups system calls in the trace by process id.
 // The tree preserves process hierarchy i.e. parent->[]child
-func parseTree(tree *parser.TraceTree, pid int64, target *prog.Target, progs *[]*prog.Prog) {
+func parseTree(tree *parser.TraceTree, pid int64, target *prog.Target, drafts *Drafts, progs *[]*prog.Prog) {
 	log.Logf(2, "parsing trace pid %v", pid)
-	if p := genProg(tree.TraceMap[pid], target); p != nil {
+	if p := genProg(tree.TraceMap[pid], target, drafts); p != nil {
 		*progs = append(*progs, p)
 	}
 	for _, childPid := range tree.Ptree[pid] {
 		if tree.TraceMap[childPid] != nil {
-			parseTree(tree, childPid, target, progs)
+			parseTree(tree, childPid, target, drafts, progs)
 		}
 	}
 }
@@ -57,18 +75,23 @@ type context struct {
 	target            *prog.Target
 	selectors         []callSelector
 	returnCache       returnCache
+	drafts            *Drafts
 	currentStraceCall *parser.Syscall
 	currentSyzCall    *prog.Call
 }
 
 // genProg converts a trace to one of our programs.
-func genProg(trace *parser.Trace, target *prog.Target) *prog.Prog {
+func genProg(trace *parser.Trace, target *prog.Target, drafts *Drafts) *prog.Prog {
 	retCache := newRCache()
 	ctx := &context{
 		builder:     prog.MakeProgGen(target),
 		target:      target,
 		selectors:   newSelectors(target, retCache),
 		returnCache: retCache,
+		drafts:      drafts,
+	}
+	if drafts != nil {
+		drafts.startTrace()
 	}
 	for _, sCall := range trace.Calls {
 		if sCall.Paused {
@@ -102,6 +125,9 @@ func (ctx *context) genCall() *prog.Call {
 	log.Logf(3, "parsing call: %s", ctx.currentStraceCall.CallName)
 	straceCall := ctx.currentStraceCall
 	meta := ctx.Select(straceCall)
+	if ctx.drafts != nil {
+		ctx.drafts.add(straceCall, meta)
+	}
 	if meta == nil {
 		log.Logf(2, "skipping call: %s which has no matching description", ctx.currentStraceCall.CallName)
 		return nil
@@ -237,6 +263,10 @@ func (ctx *context) genStruct(syzType *prog.StructType, dir prog.Dir, traceType
 		// ioctl(3, 35111, {ifr_name="\x6c\x6f", ifr_hwaddr=00:00:00:00:00:00}) = 0
 		// if_hwaddr gets parsed as a BufferType but our syscall descriptions have it as a struct type
 		return syzType.DefaultArg(dir)
+	case parser.Constant:
+		// The selected variant may not match the actual argument, e.g. setsockopt(3, SOL_TCP, TCP_NODELAY, [1])
+		// matches setsockopt$inet_tcp_TLS_TX because TCP_NODELAY == TLS_TX.
+		return syzType.DefaultArg(dir)
 	default:
 		log.Fatalf("unsupported type for struct: %#v", a)
 	}
@@ -338,9 +368,21 @@ func (ctx *context) genBuffer(syzType *prog.BufferType, dir prog.Dir, traceType
 		bArr := make([]byte, 8)
 		binary.LittleEndian.PutUint64(bArr, val)
 		bufVal = bArr
+	case *parser.GroupType:
+		// Netlink messages sent with generic sendto/sendmsg are decoded by strace.
+		if bufVal = netlinkData(a, targets.Get(ctx.target.OS, ctx.target.Arch).HostEndian); bufVal == nil {
+			// Calls without matching descriptions (e.g. unsupported ioctls) have buffers in place of structs
+			// that strace decoded.
+			return syzType.DefaultArg(dir)
+		}
 	default:
 		log.Fatalf("unsupported type for buffer: %#v", traceType)
 	}
+	if syzType.Kind == prog.BufferFilename && escapingFilename(string(bufVal)) {
+		// Programs can't refer to files outside of the working dir, e.g. open("/dev/foo")
+		// that does not match any openat$ description.
+		return syzType.DefaultArg(dir)
+	}
 	// strace always drops the null byte for buffer types but we only need to add it back for filenames and strings
 	switch syzType.Kind {
 	case prog.BufferFilename, prog.BufferString:
@@ -475,6 +517,11 @@ func (ctx *context) addr(syzType prog.Type, dir prog.Dir, size uint64, data prog
 	return prog.MakePointerArg(syzType, dir, ctx.builder.Allocate(size, data.Type().Alignment()), data)
 }
 
+func escapingFilename(file string) bool {
+	file = filepath.Clean(file)
+	return strings.HasPrefix(file, "/") || strings.HasPrefix(file, "..")
+}
+
 func shouldSkip(c *parser.Syscall) bool {
 	switch c.CallName {
 	case "write":
@@ -7,6 +7,9 @@ package proggen
 
 import (
 	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -280,7 +283,7 @@ ioctl$RTC_WKALM_SET(r0, 0x4028700f, &(0x7f0000000040)={0x0, 0x0, {0x0, 0x0, 0x0,
 		if err != nil {
 			t.Fatal(err)
 		}
-		p := genProg(tree.TraceMap[tree.RootPid], target)
+		p := genProg(tree.TraceMap[tree.RootPid], target, nil)
 		if p == nil {
 			t.Fatalf("failed to parse trace")
 		}
@@ -291,3 +294,63 @@ ioctl$RTC_WKALM_SET(r0, 0x4028700f, &(0x7f0000000040)={0x0, 0x0, {0x0, 0x0, 0x0,
 		}
 	}
 }
+
+// TestParseTestdata checks that traces produced by various strace versions and options,
+// as well as perf and bpftrace traces, can be converted.
+func TestParseTestdata(t *testing.T) {
+	target, err := prog.GetTarget(targets.Linux, targets.AMD64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target.ConstMap = make(map[string]uint64)
+	for _, c := range target.Consts {
+		target.ConstMap[c.Name] = c.Value
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no testdata: %v", err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			drafts := NewDrafts(target)
+			progs, err := parseTestFile(file, target, drafts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(progs) == 0 {
+				t.Fatalf("no programs")
+			}
+			for _, p := range progs {
+				data := p.Serialize()
+				if _, err := target.Deserialize(data, prog.NonStrict); err != nil {
+					t.Fatalf("failed to deserialize program: %v\n%s", err, data)
+				}
+				t.Logf("%s", data)
+			}
+			t.Logf("%s", drafts.Format())
+		})
+	}
+}
+
+func parseTestFile(file string, target *prog.Target, drafts *Drafts) ([]*prog.Prog, error) {
+	var parse func([]byte, *prog.Target) (*parser.TraceTree, error)
+	switch {
+	case strings.HasSuffix(file, ".trace"):
+		return ParseFileDrafts(file, target, drafts)
+	case strings.HasSuffix(file, ".perf"), strings.HasSuffix(file, ".perf.json"):
+		parse = parser.ParsePerf
+	case strings.HasSuffix(file, ".bt.out"):
+		parse = parser.ParseBpftrace
+	default:
+		return nil, fmt.Errorf("unknown trace format: %v", file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := parse(data, target)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTree(tree, target, drafts), nil
+}
@@ -10,11 +10,31 @@
 //	strace -o trace -a 1 -s 65500 -v -xx -f -Xraw ./a.out
 //	syz-trace2syz -file trace
 //
-// Intended for seed selection or debugging
+// Intended for seed selection or debugging.
+//
+// Traces may also include timestamps (-t/-tt/-ttt/-r), syscall times (-T),
+// decoded file descriptors (-y/-yy) and -X verbose comments. Decoded file descriptors
+// help to select the right descriptions for fds that were not created in the trace:
+//
+//	strace -o trace -a 1 -s 65500 -v -xx -f -Xraw -yy -T ./a.out
+//
+// With -descriptions flag it also synthesizes draft descriptions for system calls
+// and ioctls in the traces that don't have descriptions yet:
+//
+//	syz-trace2syz -dir traces -descriptions sys/linux/drafts.txt
+//
+// With -format flag it also converts output of perf trace (text or JSON produced by perf data convert)
+// and of the syscalls.bt bpftrace script, which are cheap enough to attach to real services:
+//
+//	perf trace -o trace.perf -p $(pidof nginx)
+//	syz-trace2syz -format perf -file trace.perf
+//	BPFTRACE_MAX_STRLEN=1024 bpftrace tools/syz-trace2syz/syscalls.bt nginx > trace.bt
+//	syz-trace2syz -format bpftrace -file trace.bt
 package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -25,6 +45,7 @@ import (
 	"github.com/google/syzkaller/prog"
 	_ "github.com/google/syzkaller/sys"
 	"github.com/google/syzkaller/sys/targets"
+	"github.com/google/syzkaller/tools/syz-trace2syz/parser"
 	"github.com/google/syzkaller/tools/syz-trace2syz/proggen"
 )
 
@@ -32,6 +53,9 @@ var (
 	flagFile        = flag.String("file", "", "file to parse")
 	flagDir         = flag.String("dir", "", "directory to parse")
 	flagDeserialize = flag.String("deserialize", "", "(Optional) directory to store deserialized programs")
+	flagDescs       = flag.String("descriptions", "",
+		"(Optional) file to store draft descriptions of calls without descriptions")
+	flagFormat = flag.String("format", "strace", "format of the traces: strace, perf or bpftrace")
 )
 
 const (
@@ -42,7 +66,17 @@ const (
 func main() {
 	flag.Parse()
 	target := initializeTarget(goos, arch)
-	progs := parseTraces(target)
+	var drafts *proggen.Drafts
+	if *flagDescs != "" {
+		drafts = proggen.NewDrafts(target)
+	}
+	progs := parseTraces(target, drafts)
+	if drafts != nil {
+		if err := osutil.WriteFile(*flagDescs, drafts.Format()); err != nil {
+			log.Fatalf("failed to write descriptions: %v", err)
+		}
+		log.Logf(0, "wrote draft descriptions to %v", *flagDescs)
+	}
 	log.Logf(0, "successfully converted traces; generating corpus.db")
 	pack(progs)
 }
@@ -59,7 +93,7 @@ func initializeTarget(os, arch string) *prog.Target {
 	return target
 }
 
-func parseTraces(target *prog.Target) []*prog.Prog {
+func parseTraces(target *prog.Target, drafts *proggen.Drafts) []*prog.Prog {
 	var ret []*prog.Prog
 	var names []string
 
@@ -77,7 +111,7 @@ func parseTraces(target *prog.Target) []*prog.Prog {
 	log.Logf(0, "parsing %v traces", totalFiles)
 	for i, file := range names {
 		log.Logf(1, "parsing file %v/%v: %v", i+1, totalFiles, filepath.Base(names[i]))
-		progs, err := proggen.ParseFile(file, target)
+		progs, err := parseFile(file, target, drafts)
 		if err != nil {
 			log.Fatalf("%v", err)
 		}
@@ -94,6 +128,29 @@ func parseTraces(target *prog.Target) []*prog.Prog {
 	return ret
 }
 
+func parseFile(file string, target *prog.Target, drafts *proggen.Drafts) ([]*prog.Prog, error) {
+	var parse func([]byte, *prog.Target) (*parser.TraceTree, error)
+	switch *flagFormat {
+	case "strace":
+		return proggen.ParseFileDrafts(file, target, drafts)
+	case "perf":
+		parse = parser.ParsePerf
+	case "bpftrace":
+		parse = parser.ParseBpftrace
+	default:
+		return nil, fmt.Errorf("unknown trace format %q", *flagFormat)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	tree, err := parse(data, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %w", file, err)
+	}
+	return proggen.ParseTree(tree, target, drafts), nil
+}
+
 func getTraceFiles(dir string) []string {
 	infos, err := os.ReadDir(dir)
 	if err != nil {
@@ -72,6 +72,13 @@ func (p *Pool[T]) SetDefault(def Runner[T]) {
 	p.kickDefault()
 }
 
+// Restart forces all VMs that are not reserved for custom runners to restart.
+func (p *Pool[T]) Restart() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.kickDefault()
+}
+
 func (p *Pool[T]) kickDefault() {
 	for _, inst := range p.instances {
 		if !inst.reserved() {
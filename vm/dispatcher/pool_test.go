@@ -202,6 +202,44 @@ func TestPoolNewDefault(t *testing.T) {
 	<-done
 }
 
+func TestPoolRestart(t *testing.T) {
+	var starts, running atomic.Int64
+	mgr := NewPool[*nilInstance](
+		10,
+		func(idx int) (*nilInstance, error) {
+			return &nilInstance{}, nil
+		},
+		func(ctx context.Context, _ *nilInstance, _ UpdateInfo) {
+			starts.Add(1)
+			running.Add(1)
+			<-ctx.Done()
+			running.Add(-1)
+		},
+	)
+	mgr.ReserveForRun(2)
+	done := make(chan bool)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		mgr.Loop(ctx)
+		close(done)
+	}()
+
+	for running.Load() != 8 {
+		time.Sleep(time.Second / 10)
+	}
+	mgr.Restart()
+	// Only the non-reserved instances are restarted.
+	for starts.Load() != 16 {
+		time.Sleep(time.Second / 10)
+	}
+	for running.Load() != 8 {
+		time.Sleep(time.Second / 10)
+	}
+
+	cancel()
+	<-done
+}
+
 func TestPoolPause(t *testing.T) {
 	mgr := NewPool[*nilInstance](
 		10,